@@ -171,7 +171,16 @@ func runProductionBuildCommand(cmd *cobra.Command, args []string) error {
 			float64(metrics.OriginalSize)/1024/1024,
 			float64(metrics.OptimizedSize)/1024/1024)
 	}
-	
+
+	if metrics.AssetsCompressed > 0 {
+		fmt.Printf("   Precompressed files: %d (%.1f%% of original size)\n",
+			metrics.AssetsCompressed, metrics.CompressionRatio*100)
+	}
+
+	for _, warning := range metrics.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+
 	if len(metrics.ValidationErrors) > 0 {
 		fmt.Printf("⚠️  Validation warnings: %d\n", len(metrics.ValidationErrors))
 	}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/conneroisu/templar/internal/cache"
+	"github.com/conneroisu/templar/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the build cache",
+	Long: `Manage the on-disk cache kept in build.cache_dir.
+
+The cache holds parsed component metadata, Go code generated by templ and
+rendered preview HTML, keyed by file content so that restarts skip work done
+before. Entries written by other templar or templ versions are dropped
+automatically, and the least recently used entries are evicted once the cache
+grows past build.cache_max_size bytes.
+
+Examples:
+  templar cache stats                 # Show what the cache holds
+  templar cache stats --format json   # Show cache statistics as JSON
+  templar cache clean                 # Remove all cache entries`,
+}
+
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove all cache entries",
+	Long: `Remove every entry from the build cache, including those of other
+templar and templ versions.`,
+	RunE: runCacheClean,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show build cache statistics",
+	Long: `Show the location, version and size of the build cache, with the number
+of entries in each of its namespaces.`,
+	RunE: runCacheStats,
+}
+
+var cacheFormat string
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+
+	cacheCmd.AddCommand(cacheCleanCmd)
+	cacheCmd.AddCommand(cacheStatsCmd)
+
+	cacheStatsCmd.Flags().StringVar(&cacheFormat, "format", "table", "Output format (table, json)")
+}
+
+func runCacheClean(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	freed, err := cache.Clean(cfg.Build.CacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to clean cache: %w", err)
+	}
+
+	fmt.Printf("Removed %s from %s\n", formatCacheSize(freed), cfg.Build.CacheDir)
+	return nil
+}
+
+func runCacheStats(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	diskCache, err := cache.Open(cfg.Build.CacheDir, cfg.Build.CacheMaxSize)
+	if err != nil {
+		return fmt.Errorf("failed to open cache: %w", err)
+	}
+	stats, err := diskCache.Stats()
+	if err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	switch cacheFormat {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	case "table":
+		fmt.Printf("Directory: %s\n", stats.Dir)
+		fmt.Printf("Version:   %s\n", stats.Version)
+		fmt.Printf("Size:      %s of %s\n\n", formatCacheSize(stats.Size), formatCacheSize(stats.MaxSize))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAMESPACE\tENTRIES\tSIZE")
+		for _, namespace := range cache.Namespaces {
+			namespaceStats := stats.Namespaces[namespace]
+			fmt.Fprintf(w, "%s\t%d\t%s\n", namespace, namespaceStats.Entries, formatCacheSize(namespaceStats.Size))
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unsupported format: %s", cacheFormat)
+	}
+}
+
+// formatCacheSize formats a size in bytes for display
+func formatCacheSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
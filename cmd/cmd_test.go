@@ -335,6 +335,50 @@ func TestGenerateMockValue(t *testing.T) {
 	}
 }
 
+func TestGeneratePropTypes_UniqueFileNames(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":               "module example.com/app\n\ngo 1.24\n",
+		"ui/card.templ":        "package ui\n\ntempl Card(title string) {\n\t<div>{ title }</div>\n}\n",
+		"legacy/ui/card.templ": "package ui\n\ntempl Card(count int) {\n\t<div></div>\n}\n",
+		"admin/card.templ":     "package admin\n\ntempl Card(admin bool) {\n\t<div></div>\n}\n",
+	}
+	var components []*types.ComponentInfo
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		if filepath.Ext(name) != ".templ" {
+			continue
+		}
+		pkg := filepath.Base(filepath.Dir(name))
+		components = append(components, &types.ComponentInfo{
+			ID:       "example.com/app/" + filepath.ToSlash(filepath.Dir(name)) + ".Card",
+			Name:     "Card",
+			Package:  pkg,
+			FilePath: path,
+			Kind:     types.ComponentKindTempl,
+		})
+	}
+
+	outputDir := filepath.Join(dir, "schemas")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+	results := generatePropTypes(context.Background(), components, outputDir, "jsonschema")
+
+	written := make(map[string][]string)
+	for _, result := range results {
+		require.True(t, result.Success, "%s: %s", result.Component, result.Error)
+		for _, file := range result.Files {
+			written[result.Component] = append(written[result.Component], filepath.Base(file))
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"example.com/app/admin.Card":     {"admin.card.schema.json"},
+		"example.com/app/legacy/ui.Card": {"ui.card.schema.json"},
+		"example.com/app/ui.Card":        {"ui.card-2.schema.json"},
+	}, written)
+}
+
 // CLI-focused tests are below. Business logic tests are in internal/services/
 
 func TestServeCommand(t *testing.T) {
@@ -300,6 +300,7 @@ func showConfigYAML(cfg *config.Config) error {
 		}
 	}
 	fmt.Printf("  cache_dir: \"%s\"\n", cfg.Build.CacheDir)
+	fmt.Printf("  cache_max_size: %d\n", cfg.Build.CacheMaxSize)
 	fmt.Println()
 
 	// Preview configuration
@@ -331,6 +332,11 @@ func showConfigYAML(cfg *config.Config) error {
 	fmt.Printf("  css_injection: %t\n", cfg.Development.CSSInjection)
 	fmt.Printf("  state_preservation: %t\n", cfg.Development.StatePreservation)
 	fmt.Printf("  error_overlay: %t\n", cfg.Development.ErrorOverlay)
+	fmt.Printf("  editor_url: %s\n", cfg.Development.EditorURL)
+	fmt.Println("  watcher:")
+	fmt.Printf("    backend: %s\n", cfg.Development.Watcher.Backend)
+	fmt.Printf("    poll_interval: %s\n", cfg.Development.Watcher.PollInterval)
+	fmt.Printf("    max_poll_interval: %s\n", cfg.Development.Watcher.MaxPollInterval)
 	fmt.Println()
 
 	// Plugins configuration
@@ -374,13 +380,20 @@ func showConfigJSON(cfg *config.Config) error {
 
 	fmt.Printf("  \"build\": {\n")
 	fmt.Printf("    \"command\": \"%s\",\n", cfg.Build.Command)
-	fmt.Printf("    \"cache_dir\": \"%s\"\n", cfg.Build.CacheDir)
+	fmt.Printf("    \"cache_dir\": \"%s\",\n", cfg.Build.CacheDir)
+	fmt.Printf("    \"cache_max_size\": %d\n", cfg.Build.CacheMaxSize)
 	fmt.Printf("  },\n")
 
 	fmt.Printf("  \"development\": {\n")
 	fmt.Printf("    \"hot_reload\": %t,\n", cfg.Development.HotReload)
 	fmt.Printf("    \"css_injection\": %t,\n", cfg.Development.CSSInjection)
-	fmt.Printf("    \"error_overlay\": %t\n", cfg.Development.ErrorOverlay)
+	fmt.Printf("    \"error_overlay\": %t,\n", cfg.Development.ErrorOverlay)
+	fmt.Printf("    \"editor_url\": \"%s\",\n", cfg.Development.EditorURL)
+	fmt.Printf("    \"watcher\": {\n")
+	fmt.Printf("      \"backend\": \"%s\",\n", cfg.Development.Watcher.Backend)
+	fmt.Printf("      \"poll_interval\": \"%s\",\n", cfg.Development.Watcher.PollInterval)
+	fmt.Printf("      \"max_poll_interval\": \"%s\"\n", cfg.Development.Watcher.MaxPollInterval)
+	fmt.Printf("    }\n")
 	fmt.Printf("  }\n")
 
 	fmt.Println("}")
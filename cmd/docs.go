@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/conneroisu/templar/internal/config"
+	"github.com/conneroisu/templar/internal/docs"
+	"github.com/conneroisu/templar/internal/mockdata"
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/renderer"
+	"github.com/conneroisu/templar/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var (
+	docsOutput      string
+	docsFormats     []string
+	docsTitle       string
+	docsBaseURL     string
+	docsStylesheets []string
+	docsNoRender    bool
+	docsPaths       []string
+)
+
+// docsCmd represents the docs command
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Build a documentation site for your components",
+	Long: `Build a static documentation site for the exported components in the project.
+
+Each component page shows:
+
+- The doc comment above the templ declaration
+- A prop table with each parameter's type, allowed enum values, whether it
+  is required and the value it defaults to when left out
+- The default preview and every story, rendered into embedded frames
+- The components it uses and the components using it
+
+The site is written as HTML with a search box, and as Markdown or MDX pages
+that embed the same rendered frames, for inclusion in an existing docs
+portal. A search-index.json lists every component for other tools.
+
+Examples:
+  templar docs                                # HTML and Markdown in dist/docs
+  templar docs --format html,mdx -o ./site    # HTML and MDX
+  templar docs --no-render                    # Skip rendering examples
+  templar docs --stylesheet /static/app.css   # Style the example frames
+  templar docs --base-url https://ui.example.com/components`,
+	RunE: runDocsCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(docsCmd)
+
+	docsCmd.Flags().StringVarP(&docsOutput, "output", "o", "dist/docs", "Output directory")
+	docsCmd.Flags().StringSliceVarP(&docsFormats, "format", "f", []string{"html", "md"}, "Output formats (html, md, mdx)")
+	docsCmd.Flags().StringVar(&docsTitle, "title", "Components", "Site title")
+	docsCmd.Flags().StringVar(&docsBaseURL, "base-url", "", "URL the site is served from, used to link example frames from Markdown and MDX pages")
+	docsCmd.Flags().StringSliceVar(&docsStylesheets, "stylesheet", nil, "Stylesheets linked from example frames")
+	docsCmd.Flags().BoolVar(&docsNoRender, "no-render", false, "List examples without rendering them")
+	docsCmd.Flags().StringSliceVar(&docsPaths, "path", nil, "Additional paths to scan for components")
+}
+
+func runDocsCommand(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	formats, err := docs.ParseFormats(docsFormats)
+	if err != nil {
+		return err
+	}
+
+	componentRegistry := registry.NewComponentRegistry()
+	componentScanner := scanner.NewComponentScanner(componentRegistry, cfg)
+	for _, path := range append(cfg.Components.ScanPaths, docsPaths...) {
+		if err := componentScanner.ScanDirectory(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to scan directory %s: %v\n", path, err)
+		}
+	}
+	if componentRegistry.Count() == 0 {
+		fmt.Println("No components found to document")
+		return nil
+	}
+
+	generator := docs.NewGenerator(componentRegistry, docsOutput, docs.Options{
+		Title:       docsTitle,
+		Formats:     formats,
+		BaseURL:     docsBaseURL,
+		Stylesheets: docsStylesheets,
+	})
+
+	if !docsNoRender {
+		// The preview wrapper is rendered alongside the components, so it
+		// must be registered even when it lies outside the scan paths
+		wrapper := cfg.Preview.Wrapper
+		if strings.HasSuffix(wrapper, ".templ") {
+			if _, err := os.Stat(wrapper); err == nil {
+				if err := componentScanner.ScanFile(wrapper); err != nil {
+					return fmt.Errorf("failed to scan wrapper %s: %w", wrapper, err)
+				}
+			}
+		}
+
+		componentRenderer := renderer.NewComponentRenderer(componentRegistry)
+		if dir := cfg.Preview.FixturesDir(); dir != "" {
+			componentRenderer.SetFixtures(mockdata.NewFixtures(dir))
+		}
+		componentRenderer.SetWrapper(wrapper)
+		defer componentRenderer.Close()
+		generator.SetRenderer(componentRenderer)
+	}
+
+	fmt.Printf("📚 Documenting %d components...\n", componentRegistry.Count())
+	result, err := generator.Generate(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to generate docs: %w", err)
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	fmt.Printf("✅ Documented %d components in %s (%d files)\n", result.Components, docsOutput, len(result.Files))
+	return nil
+}
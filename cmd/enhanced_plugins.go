@@ -278,12 +278,13 @@ func runEnhancedPluginsHealth(cmd *cobra.Command, args []string) error {
 	}
 	defer epm.Shutdown(ctx)
 
-	// Get loaded plugins with health info
-	loadedPlugins := epm.GetLoadedPlugins()
+	// Check health and resource usage, disabling plugins over their limits
+	loadedPlugins := epm.CheckHealth(ctx)
 
 	var healthData []EnhancedPluginHealthItem
 	for name, loaded := range loadedPlugins {
-		if loaded.State == plugins.PluginStateEnabled {
+		// Plugins disabled for exceeding their limits are listed with the reason
+		if loaded.State == plugins.PluginStateEnabled || loaded.Health.Status == plugins.HealthStatusUnhealthy {
 			healthData = append(healthData, EnhancedPluginHealthItem{
 				Name:   name,
 				Health: loaded.Health,
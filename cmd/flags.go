@@ -22,7 +22,7 @@ type StandardFlags struct {
 	Props     string `flag:"props" desc:"Component properties (JSON or @file.json)" default:""`
 	PropsFile string `flag:"props-file,P" desc:"Properties file path (JSON)" default:""`
 	MockData  string `flag:"mock,m" desc:"Mock data file, pattern, or 'auto'" default:""`
-	Wrapper   string `flag:"wrapper,w" desc:"Wrapper template path" default:""`
+	Wrapper   string `flag:"wrapper,w" desc:"Wrapper layout component or .templ file" default:""`
 
 	// Build flags (consistent build configuration)
 	WatchPattern string `flag:"watch,W" desc:"File watch pattern for auto-rebuild" default:"**/*.templ"`
@@ -66,7 +66,7 @@ func addComponentFlags(cmd *cobra.Command, flags *StandardFlags) {
 	cmd.Flags().StringVar(&flags.Props, "props", "", "Component properties (JSON string or @file.json)")
 	cmd.Flags().StringVarP(&flags.PropsFile, "props-file", "P", "", "Properties file path (JSON)")
 	cmd.Flags().StringVarP(&flags.MockData, "mock", "m", "", "Mock data file, pattern, or 'auto' for generation")
-	cmd.Flags().StringVarP(&flags.Wrapper, "wrapper", "w", "", "Wrapper template path")
+	cmd.Flags().StringVarP(&flags.Wrapper, "wrapper", "w", "", "Wrapper layout component or .templ file")
 }
 
 func addBuildFlags(cmd *cobra.Command, flags *StandardFlags) {
@@ -437,7 +437,7 @@ func addEnhancedComponentFlags(cmd *cobra.Command, flags *EnhancedStandardFlags)
 	cmd.Flags().StringVar(&flags.Props, "props", "", "Component properties (JSON string or @file.json)")
 	cmd.Flags().StringVarP(&flags.PropsFile, "props-file", "P", "", "Properties file path (JSON)")
 	cmd.Flags().StringVarP(&flags.MockData, "mock", "m", "", "Mock data file, pattern, or 'auto' for generation")
-	cmd.Flags().StringVarP(&flags.Wrapper, "wrapper", "w", "", "Wrapper template path")
+	cmd.Flags().StringVarP(&flags.Wrapper, "wrapper", "w", "", "Wrapper layout component or .templ file")
 	
 	// Add validation for JSON props
 	AddFlagValidation(cmd, "props", ValidateJSON)
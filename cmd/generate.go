@@ -1,15 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/conneroisu/templar/internal/config"
 	"github.com/conneroisu/templar/internal/registry"
 	"github.com/conneroisu/templar/internal/scanner"
+	"github.com/conneroisu/templar/internal/schema"
 	"github.com/conneroisu/templar/internal/types"
 	"github.com/spf13/cobra"
 	"golang.org/x/text/cases"
@@ -31,6 +34,8 @@ var generateCmd = &cobra.Command{
 
 - Go code generation from templ files
 - Type definitions for component parameters
+- JSON Schema and TypeScript types for component props, resolved from
+  the Go types of their parameters
 - Mock data generators for testing
 - Component documentation
 
@@ -38,6 +43,8 @@ Examples:
   templar generate                     # Generate code for all components
   templar generate Button Card         # Generate code for specific components
   templar generate --format go        # Generate Go code only
+  templar generate --format jsonschema # One schema per component plus defs.schema.json
+  templar generate --format typescript # One props interface per component plus defs.ts
   templar generate --output ./gen     # Output to specific directory`,
 	RunE: runGenerateCommand,
 }
@@ -46,7 +53,7 @@ func init() {
 	rootCmd.AddCommand(generateCmd)
 
 	generateCmd.Flags().BoolVar(&generateAll, "all", false, "Generate code for all components (default if no components specified)")
-	generateCmd.Flags().StringVarP(&generateFormat, "format", "f", "go", "Output format (go, types, mocks, docs)")
+	generateCmd.Flags().StringVarP(&generateFormat, "format", "f", "go", "Output format (go, types, mocks, docs, jsonschema, typescript)")
 	generateCmd.Flags().StringVarP(&generateOutput, "output", "o", "", "Output directory (default: current directory)")
 	generateCmd.Flags().StringSliceVar(&generatePaths, "path", nil, "Additional paths to scan for components")
 }
@@ -101,18 +108,14 @@ func runGenerateCommand(cmd *cobra.Command, args []string) error {
 	if len(args) == 0 || generateAll {
 		componentsToGenerate = allComponents
 	} else {
-		// Generate for specific components
-		componentMap := make(map[string]*types.ComponentInfo)
-		for _, comp := range allComponents {
-			componentMap[comp.Name] = comp
-		}
-
+		// Generate for specific components by qualified ID or unambiguous name
 		for _, name := range args {
-			if comp, exists := componentMap[name]; exists {
-				componentsToGenerate = append(componentsToGenerate, comp)
-			} else {
-				fmt.Fprintf(os.Stderr, "Warning: component '%s' not found\n", name)
+			comp, err := componentRegistry.Resolve(name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				continue
 			}
+			componentsToGenerate = append(componentsToGenerate, comp)
 		}
 	}
 
@@ -138,8 +141,20 @@ func runGenerateCommand(cmd *cobra.Command, args []string) error {
 		OutputDir: outputDir,
 	}
 
-	for _, component := range componentsToGenerate {
-		result := generateComponentCode(component, outputDir, generateFormat)
+	var results []GenerateResult
+	switch generateFormat {
+	case "jsonschema", "typescript":
+		// Prop types are resolved for all components at once so that they
+		// share one set of definitions
+		results = generatePropTypes(cmd.Context(), componentsToGenerate, outputDir, generateFormat)
+		summary.Total = len(results)
+	default:
+		for _, component := range componentsToGenerate {
+			results = append(results, generateComponentCode(component, outputDir, generateFormat))
+		}
+	}
+
+	for _, result := range results {
 		summary.Results = append(summary.Results, result)
 
 		if result.Success {
@@ -155,7 +170,7 @@ func runGenerateCommand(cmd *cobra.Command, args []string) error {
 
 func generateComponentCode(component *types.ComponentInfo, outputDir, format string) GenerateResult {
 	result := GenerateResult{
-		Component: component.Name,
+		Component: component.ID,
 		Files:     make([]string, 0),
 		Success:   true,
 	}
@@ -333,6 +348,138 @@ func generateDocumentation(component *types.ComponentInfo, outputDir string, res
 	return nil
 }
 
+// generatePropTypes writes the props of templ components as JSON Schema or
+// TypeScript, with the named types they share in one definitions file
+func generatePropTypes(ctx context.Context, components []*types.ComponentInfo, outputDir, format string) []GenerateResult {
+	var templates []*types.ComponentInfo
+	for _, component := range components {
+		if component.Kind == "" || component.Kind == types.ComponentKindTempl {
+			templates = append(templates, component)
+		}
+	}
+	// Registry order varies between runs; sorting keeps file name suffixes
+	// stable
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].ID < templates[j].ID
+	})
+
+	set := schema.Load(ctx, templates)
+	results := make([]GenerateResult, 0, len(templates)+1)
+	taken := map[string]bool{schema.DefsModule: true}
+
+	for _, component := range templates {
+		result := GenerateResult{
+			Component: component.ID,
+			Files:     make([]string, 0),
+			Success:   true,
+		}
+
+		if err, failed := set.Errors[component.ID]; failed {
+			result.Success = false
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		baseName := uniqueFileName(taken, fileSlug(component.Package)+"."+fileSlug(component.Name))
+		fileName, content, err := renderPropTypes(set, component, baseName, format)
+		if err == nil {
+			err = writeGeneratedFile(filepath.Join(outputDir, fileName), content, &result)
+		}
+		if err != nil {
+			result.Success = false
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	if len(set.Defs) == 0 {
+		return results
+	}
+
+	defs := GenerateResult{Component: "shared definitions", Files: make([]string, 0), Success: true}
+	fileName, content, err := renderPropTypeDefs(set, format)
+	if err == nil {
+		err = writeGeneratedFile(filepath.Join(outputDir, fileName), content, &defs)
+	}
+	if err != nil {
+		defs.Success = false
+		defs.Error = err.Error()
+	}
+
+	return append(results, defs)
+}
+
+// renderPropTypes renders a component's props in the given format,
+// returning the name of the file to write them to
+func renderPropTypes(set *schema.Set, component *types.ComponentInfo, baseName, format string) (string, []byte, error) {
+	if format == "typescript" {
+		source, err := set.TypeScript(component.ID)
+		return baseName + ".ts", []byte(source), err
+	}
+
+	fileName := baseName + ".schema.json"
+	document, err := set.Document(component.ID, fileName)
+	if err != nil {
+		return "", nil, err
+	}
+	content, err := json.MarshalIndent(document, "", "  ")
+	return fileName, content, err
+}
+
+// fileSlug turns a package or component name into a lowercase file name
+// part
+func fileSlug(name string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			slug.WriteRune(r)
+			dash = false
+		} else if !dash && slug.Len() > 0 {
+			slug.WriteByte('-')
+			dash = true
+		}
+	}
+	result := strings.TrimSuffix(slug.String(), "-")
+	if result == "" {
+		return "component"
+	}
+	return result
+}
+
+// uniqueFileName returns name, or name with a numeric suffix when another
+// component already took it
+func uniqueFileName(taken map[string]bool, name string) string {
+	candidate := name
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+	taken[candidate] = true
+	return candidate
+}
+
+// renderPropTypeDefs renders the shared definitions in the given format
+func renderPropTypeDefs(set *schema.Set, format string) (string, []byte, error) {
+	if format == "typescript" {
+		return schema.DefsModule + ".ts", []byte(set.TypeScriptDefs()), nil
+	}
+	content, err := json.MarshalIndent(set.DefsDocument(), "", "  ")
+	return schema.DefsFile, content, err
+}
+
+// writeGeneratedFile writes a generated file and records it in the result
+func writeGeneratedFile(filePath string, content []byte, result *GenerateResult) error {
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		content = append(content, '\n')
+	}
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(filePath), err)
+	}
+	result.Files = append(result.Files, filePath)
+	return nil
+}
+
 func convertGoTypeToTypeScript(goType string) string {
 	switch goType {
 	case "string":
@@ -130,6 +130,7 @@ func outputListJSON(components []*types.ComponentInfo) error {
 
 	for i, component := range components {
 		item := map[string]interface{}{
+			"id":        component.ID,
 			"name":      component.Name,
 			"package":   component.Package,
 			"file_path": component.FilePath,
@@ -164,6 +165,7 @@ func outputYAML(components []*types.ComponentInfo) error {
 
 	for i, component := range components {
 		item := map[string]interface{}{
+			"id":        component.ID,
 			"name":      component.Name,
 			"package":   component.Package,
 			"file_path": component.FilePath,
@@ -182,7 +184,7 @@ func outputYAML(components []*types.ComponentInfo) error {
 		}
 
 		if listWithDeps {
-			item["dependencies"] = []string{}
+			item["dependencies"] = component.Dependencies
 		}
 
 		output[i] = item
@@ -198,7 +200,7 @@ func outputTable(components []*types.ComponentInfo) error {
 	defer w.Flush()
 
 	// Write header
-	header := "NAME\tPACKAGE\tFILE\tFUNCTION"
+	header := "ID\tNAME\tPACKAGE\tFILE\tFUNCTION"
 	if listWithProps {
 		header += "\tPARAMETERS"
 	}
@@ -208,7 +210,7 @@ func outputTable(components []*types.ComponentInfo) error {
 	fmt.Fprintln(w, header)
 
 	// Write separator
-	separator := strings.Repeat("-", 4) + "\t" + strings.Repeat("-", 7) + "\t" + strings.Repeat("-", 4) + "\t" + strings.Repeat("-", 8)
+	separator := strings.Repeat("-", 2) + "\t" + strings.Repeat("-", 4) + "\t" + strings.Repeat("-", 7) + "\t" + strings.Repeat("-", 4) + "\t" + strings.Repeat("-", 8)
 	if listWithProps {
 		separator += "\t" + strings.Repeat("-", 10)
 	}
@@ -219,7 +221,8 @@ func outputTable(components []*types.ComponentInfo) error {
 
 	// Write components
 	for _, component := range components {
-		row := fmt.Sprintf("%s\t%s\t%s\t%s",
+		row := fmt.Sprintf("%s\t%s\t%s\t%s\t%s",
+			component.ID,
 			component.Name,
 			component.Package,
 			component.FilePath,
@@ -235,7 +238,7 @@ func outputTable(components []*types.ComponentInfo) error {
 		}
 
 		if listWithDeps {
-			row += "\t" + "" // Empty for now
+			row += "\t" + strings.Join(component.Dependencies, ", ")
 		}
 
 		fmt.Fprintln(w, row)
@@ -249,7 +252,7 @@ func outputTable(components []*types.ComponentInfo) error {
 
 func outputListCSV(components []*types.ComponentInfo) error {
 	// Write header
-	header := "name,package,file_path,function"
+	header := "id,name,package,file_path,function"
 	if listWithProps {
 		header += ",parameters"
 	}
@@ -260,7 +263,8 @@ func outputListCSV(components []*types.ComponentInfo) error {
 
 	// Write components
 	for _, component := range components {
-		row := fmt.Sprintf("%s,%s,%s,%s",
+		row := fmt.Sprintf("%s,%s,%s,%s,%s",
+			component.ID,
 			component.Name,
 			component.Package,
 			component.FilePath,
@@ -276,7 +280,7 @@ func outputListCSV(components []*types.ComponentInfo) error {
 		}
 
 		if listWithDeps {
-			row += "," // Empty for now
+			row += "," + strings.Join(component.Dependencies, ";")
 		}
 
 		fmt.Println(row)
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/conneroisu/templar/internal/config"
+	"github.com/conneroisu/templar/internal/lsp"
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/scanner"
+	"github.com/conneroisu/templar/internal/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lspTemplCommand    string
+	lspPaths           []string
+	lspNoAccessibility bool
+)
+
+// lspCmd represents the lsp command
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run the templar language server",
+	Long: `Run a language server for templ files over stdin and stdout.
+
+templar adds component-aware features on top of templ's language server:
+
+- Completion of @Component( names, with placeholders for their parameters
+- Completion of parameters inside a component call
+- Hover showing a component's props, their types and its documentation
+- Go to definition from a call to the component's declaration
+- Diagnostics for calls with the wrong number of arguments, circular
+  dependencies, component validation and accessibility issues
+
+Everything else is forwarded to "templ lsp", so configure your editor to
+run "templar lsp" instead of "templ lsp" for .templ files.
+
+Examples:
+  templar lsp                              # Run alongside templ lsp
+  templar lsp --templ "templ lsp -log /tmp/templ.log"
+  templar lsp --no-accessibility           # Skip accessibility diagnostics`,
+	RunE: runLSPCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+
+	lspCmd.Flags().StringVar(&lspTemplCommand, "templ", "templ lsp", "Command running templ's language server")
+	lspCmd.Flags().StringSliceVar(&lspPaths, "path", nil, "Additional paths to scan for components")
+	lspCmd.Flags().BoolVar(&lspNoAccessibility, "no-accessibility", false, "Disable accessibility diagnostics")
+}
+
+func runLSPCommand(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	templCommand := strings.Fields(lspTemplCommand)
+	if len(templCommand) == 0 {
+		return fmt.Errorf("--templ must name a command")
+	}
+
+	// Stdout carries the protocol, so progress goes to stderr
+	componentRegistry := registry.NewComponentRegistry()
+	componentScanner := scanner.NewComponentScanner(componentRegistry, cfg)
+	for _, path := range append(cfg.Components.ScanPaths, lspPaths...) {
+		if err := componentScanner.ScanDirectory(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to scan directory %s: %v\n", path, err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "templar lsp: found %d components\n", componentRegistry.Count())
+
+	server := lsp.NewServer(componentRegistry, lsp.Options{
+		TemplCommand:         templCommand,
+		Rescan:               componentScanner.ScanFile,
+		Validate:             validateForLSP,
+		DisableAccessibility: lspNoAccessibility,
+	})
+	return server.Serve(cmd.Context(), os.Stdin, os.Stdout)
+}
+
+// validateForLSP runs the checks of templar validate on a component
+func validateForLSP(component *types.ComponentInfo) ([]string, []string) {
+	result := validateComponent(component)
+	return result.Errors, result.Warnings
+}
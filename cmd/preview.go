@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -29,6 +30,8 @@ with configurable properties and mock data.
 
 Examples:
   templar preview Button                              # Preview Button component
+  templar preview ui.Button                           # Disambiguate by package
+  templar preview github.com/acme/app/ui.Button       # Preview by fully qualified ID
   templar preview Button --props '{"text":"Click me"}' # Preview with inline props
   templar preview Button --props-file props.json     # Preview with props from file
   templar preview Button --props @props.json         # Preview with props from file (alternative)
@@ -85,9 +88,13 @@ func runPreview(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Find the requested component
-	component, exists := componentRegistry.Get(componentName)
-	if !exists {
+	// Find the requested component by qualified ID or unambiguous name
+	component, err := componentRegistry.Resolve(componentName)
+	var ambiguous *registry.AmbiguousComponentError
+	if stderrors.As(err, &ambiguous) {
+		return err
+	}
+	if err != nil {
 		// Create enhanced error with suggestions
 		ctx := &errors.SuggestionContext{
 			Registry:       componentRegistry,
@@ -103,7 +110,7 @@ func runPreview(cmd *cobra.Command, args []string) error {
 		return enhancedErr
 	}
 
-	fmt.Printf("🎭 Previewing component: %s\n", componentName)
+	fmt.Printf("🎭 Previewing component: %s\n", component.ID)
 	fmt.Printf("   File: %s\n", component.FilePath)
 	fmt.Printf("   Package: %s\n", component.Package)
 
@@ -122,14 +129,50 @@ func runPreview(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Fixtures from preview.mock_data sit between generated mock data and
+	// explicit --mock and --props values
+	var fixtures *mockdata.Fixtures
+	if dir := cfg.Preview.FixturesDir(); dir != "" {
+		fixtures, err = mockdata.LoadFixtures(dir)
+		if err != nil {
+			return fmt.Errorf("failed to load mock data fixtures: %w", err)
+		}
+		fmt.Printf("📦 Using mock data fixtures from %s\n", dir)
+	}
+
 	// Generate mock data if not provided
-	if mockData == nil && props == nil {
+	generated := mockData == nil && props == nil
+	if generated {
 		mockData = generateIntelligentMockData(component)
 		fmt.Println("🎲 Generated intelligent mock data for component parameters")
 	}
 
+	// The --wrapper flag overrides preview.wrapper; the layout is rendered by
+	// the preview renderer, so it must be registered alongside the component
+	wrapper := previewFlags.Wrapper
+	if wrapper == "" {
+		wrapper = cfg.Preview.Wrapper
+	}
+	if strings.HasSuffix(wrapper, ".templ") {
+		if _, err := os.Stat(wrapper); err == nil {
+			if err := componentScanner.ScanFile(wrapper); err != nil {
+				return fmt.Errorf("failed to scan wrapper %s: %w", wrapper, err)
+			}
+		}
+	}
+	var layout *types.ComponentInfo
+	if wrapper != "" {
+		layout, err = renderer.ResolveLayout(componentRegistry, wrapper)
+		if err != nil {
+			return fmt.Errorf("failed to resolve wrapper: %w", err)
+		}
+		if layout != nil {
+			fmt.Printf("🖼️  Using wrapper layout: %s\n", layout.ID)
+		}
+	}
+
 	// Create preview-specific server
-	srv, err := createPreviewServer(cfg, component, props, mockData)
+	srv, err := createPreviewServer(cfg, component, layout, props, mockData, generated, fixtures)
 	if err != nil {
 		return fmt.Errorf("failed to create preview server: %w", err)
 	}
@@ -208,10 +251,13 @@ func generateMockValue(paramType string) interface{} {
 	}
 }
 
-func createPreviewServer(cfg *config.Config, component *types.ComponentInfo, props map[string]interface{}, mockData map[string]interface{}) (*server.PreviewServer, error) {
-	// Create a new registry with just the preview component
+func createPreviewServer(cfg *config.Config, component *types.ComponentInfo, layout *types.ComponentInfo, props map[string]interface{}, mockData map[string]interface{}, generated bool, fixtures *mockdata.Fixtures) (*server.PreviewServer, error) {
+	// Create a new registry with just the preview component and its layout
 	previewRegistry := registry.NewComponentRegistry()
 	previewRegistry.Register(component)
+	if layout != nil {
+		previewRegistry.Register(layout)
+	}
 
 	// Create preview server
 	srv, err := server.New(cfg)
@@ -221,9 +267,14 @@ func createPreviewServer(cfg *config.Config, component *types.ComponentInfo, pro
 
 	// Create custom renderer for preview
 	previewRenderer := renderer.NewComponentRenderer(previewRegistry)
+	previewRenderer.SetFixtures(fixtures)
+	if layout != nil {
+		previewRenderer.SetWrapper(layout.ID)
+	}
+	defer previewRenderer.Close()
 
 	// Generate preview HTML
-	html, err := generatePreviewHTML(component, props, mockData, previewRenderer)
+	html, err := generatePreviewHTML(component, props, mockData, generated, previewRenderer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate preview HTML: %w", err)
 	}
@@ -242,29 +293,50 @@ func createPreviewServer(cfg *config.Config, component *types.ComponentInfo, pro
 	return srv, nil
 }
 
-func generatePreviewHTML(component *types.ComponentInfo, props map[string]interface{}, mockData map[string]interface{}, renderer *renderer.ComponentRenderer) (string, error) {
-	// Use provided props or generated mock data
-	data := props
-	if data == nil {
-		data = mockData
+func generatePreviewHTML(component *types.ComponentInfo, props map[string]interface{}, mockData map[string]interface{}, generated bool, renderer *renderer.ComponentRenderer) (string, error) {
+	fixtureProps, err := renderer.Fixtures().Props(component, mockdata.DefaultVariant)
+	if err != nil {
+		return "", fmt.Errorf("failed to load mock data fixtures: %w", err)
 	}
 
-	// Generate component HTML
-	componentHTML, err := renderer.RenderComponent(component.Name)
+	// Fixtures override generated mock data; a mock file and explicit props
+	// override the fixtures
+	data := mockdata.MergeProps(mockData, fixtureProps)
+	if !generated {
+		data = mockdata.MergeProps(fixtureProps, mockData)
+		for name, value := range props {
+			data[name] = value
+		}
+	}
+
+	// Generate component HTML. Generated mock data is left to the renderer,
+	// which only keeps values that fit the parameter types.
+	var componentHTML string
+	if generated {
+		componentHTML, err = renderer.RenderComponent(component.ID)
+	} else {
+		componentHTML, err = renderer.RenderComponentWithProps(component.ID, data)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to render component: %w", err)
 	}
 
-	// Create wrapper HTML
-	wrapperHTML := generateWrapperHTML(component, data, componentHTML)
+	// Use the wrapper layout when one is set, the built-in page otherwise
+	page, ok, err := renderer.RenderWrapperPage(component.Name, componentHTML, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to render wrapper: %w", err)
+	}
+	if ok {
+		return page, nil
+	}
 
-	return wrapperHTML, nil
+	return generateWrapperHTML(component, data, componentHTML), nil
 }
 
+// generateWrapperHTML creates the built-in preview page, used when no wrapper
+// layout is configured
 func generateWrapperHTML(component *types.ComponentInfo, data map[string]interface{}, componentHTML string) string {
-	// Create a simple wrapper if custom wrapper is not specified
-	if previewFlags.Wrapper == "" {
-		return fmt.Sprintf(`<!DOCTYPE html>
+	return fmt.Sprintf(`<!DOCTYPE html>
 <html lang="en">
 <head>
     <meta charset="UTF-8">
@@ -353,20 +425,15 @@ func generateWrapperHTML(component *types.ComponentInfo, data map[string]interfa
     </script>
 </body>
 </html>`,
-			component.Name,
-			component.Name,
-			component.Package,
-			component.FilePath,
-			component.Name,
-			componentHTML,
-			formatJSON(data),
-			previewFlags.Port,
-		)
-	}
-
-	// Use custom wrapper (placeholder for now)
-	return fmt.Sprintf(`<!-- Custom wrapper would be loaded from %s -->
-%s`, previewFlags.Wrapper, componentHTML)
+		component.Name,
+		component.Name,
+		component.Package,
+		component.FilePath,
+		component.Name,
+		componentHTML,
+		formatJSON(data),
+		previewFlags.Port,
+	)
 }
 
 func formatJSON(data interface{}) string {
@@ -100,18 +100,14 @@ func runValidateCommand(cmd *cobra.Command, args []string) error {
 	if len(args) == 0 || validateAll {
 		componentsToValidate = allComponents
 	} else {
-		// Validate specific components
-		componentMap := make(map[string]*types.ComponentInfo)
-		for _, comp := range allComponents {
-			componentMap[comp.Name] = comp
-		}
-
+		// Validate specific components by qualified ID or unambiguous name
 		for _, name := range args {
-			if comp, exists := componentMap[name]; exists {
-				componentsToValidate = append(componentsToValidate, comp)
-			} else {
-				fmt.Fprintf(os.Stderr, "Warning: component '%s' not found\n", name)
+			comp, err := componentRegistry.Resolve(name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				continue
 			}
+			componentsToValidate = append(componentsToValidate, comp)
 		}
 	}
 
@@ -171,7 +167,7 @@ func runValidateCommand(cmd *cobra.Command, args []string) error {
 
 func validateComponent(component *types.ComponentInfo) ValidationResult {
 	result := ValidationResult{
-		Component: component.Name,
+		Component: component.ID,
 		Valid:     true,
 		Errors:    make([]string, 0),
 		Warnings:  make([]string, 0),
@@ -209,9 +205,9 @@ func validateComponent(component *types.ComponentInfo) ValidationResult {
 		file.Close()
 	}
 
-	// Validate dependencies exist
+	// Validate dependency IDs
 	for _, dep := range component.Dependencies {
-		if err := validateComponentName(dep); err != nil {
+		if err := validateComponentID(dep); err != nil {
 			result.Warnings = append(result.Warnings, fmt.Sprintf("Dependency '%s' has invalid name: %v", dep, err))
 		}
 	}
@@ -232,6 +228,22 @@ func validateComponent(component *types.ComponentInfo) ValidationResult {
 	return result
 }
 
+// validateComponentID validates a qualified component ID such as
+// "github.com/acme/app/ui.Button" by validating each import path segment
+func validateComponentID(id string) error {
+	if id == "" {
+		return fmt.Errorf("empty component ID")
+	}
+
+	for _, segment := range strings.Split(id, "/") {
+		if err := validateComponentName(segment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func validateComponentName(name string) error {
 	// Reuse the existing validation function from handlers
 	// This ensures consistency across the application
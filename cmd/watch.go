@@ -58,7 +58,11 @@ func runWatch(cmd *cobra.Command, args []string) error {
 	componentScanner := scanner.NewComponentScanner(componentRegistry)
 
 	// Create file watcher directly - no adapter needed
-	fileWatcher, err := watcher.NewFileWatcher(300 * time.Millisecond)
+	fileWatcher, err := watcher.NewFileWatcherWithOptions(300*time.Millisecond, watcher.Options{
+		Backend:         cfg.Development.Watcher.Backend,
+		PollInterval:    cfg.Development.Watcher.PollInterval,
+		MaxPollInterval: cfg.Development.Watcher.MaxPollInterval,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create file watcher: %w", err)
 	}
@@ -0,0 +1,258 @@
+package build
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlTagPattern   = regexp.MustCompile(`<([a-zA-Z][a-zA-Z0-9-]*)`)
+	htmlClassPattern = regexp.MustCompile(`(?i)\sclass\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	htmlIDPattern    = regexp.MustCompile(`(?i)\sid\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// usedSelectors records the elements, classes and ids present in a page
+type usedSelectors struct {
+	tags    map[string]bool
+	classes map[string]bool
+	ids     map[string]bool
+}
+
+// collectUsedSelectors scans rendered HTML for the elements, classes and ids
+// it contains. The html and body elements of the surrounding page always
+// count as present.
+func collectUsedSelectors(html string) *usedSelectors {
+	used := &usedSelectors{
+		tags:    map[string]bool{"html": true, "body": true},
+		classes: make(map[string]bool),
+		ids:     make(map[string]bool),
+	}
+
+	for _, match := range htmlTagPattern.FindAllStringSubmatch(html, -1) {
+		used.tags[strings.ToLower(match[1])] = true
+	}
+	for _, match := range htmlClassPattern.FindAllStringSubmatch(html, -1) {
+		for _, class := range strings.Fields(match[1] + " " + match[2]) {
+			used.classes[class] = true
+		}
+	}
+	for _, match := range htmlIDPattern.FindAllStringSubmatch(html, -1) {
+		if id := strings.TrimSpace(match[1] + match[2]); id != "" {
+			used.ids[id] = true
+		}
+	}
+
+	return used
+}
+
+// extractCriticalCSS returns the rules of a stylesheet that can apply to the
+// rendered HTML, that is the rules with a selector whose elements, classes
+// and ids all occur in it. @media and @supports blocks keep their matching
+// rules, other blocks such as @font-face and @keyframes are kept whole, and
+// @import and @charset statements are dropped.
+func extractCriticalCSS(css, html string) string {
+	minified, err := MinifyCSS(css)
+	if err != nil {
+		return ""
+	}
+	return filterCSSRules(minified, collectUsedSelectors(html))
+}
+
+// filterCSSRules keeps the rules of minified CSS that match the used selectors
+func filterCSSRules(css string, used *usedSelectors) string {
+	var out strings.Builder
+
+	for i := 0; i < len(css); {
+		end := cssPreludeEnd(css, i)
+		if end >= len(css) {
+			break
+		}
+		prelude := strings.TrimSpace(css[i:end])
+		if css[end] == ';' {
+			// A statement at-rule such as @import
+			i = end + 1
+			continue
+		}
+
+		blockEnd := cssBlockEnd(css, end)
+		block := css[end+1 : blockEnd]
+		i = blockEnd + 1
+
+		switch {
+		case strings.HasPrefix(prelude, "@media"), strings.HasPrefix(prelude, "@supports"):
+			if inner := filterCSSRules(block, used); inner != "" {
+				out.WriteString(prelude + "{" + inner + "}")
+			}
+		case strings.HasPrefix(prelude, "@"):
+			out.WriteString(prelude + "{" + block + "}")
+		default:
+			var kept []string
+			for _, selector := range splitSelectors(prelude) {
+				if used.matches(selector) {
+					kept = append(kept, selector)
+				}
+			}
+			if len(kept) > 0 {
+				out.WriteString(strings.Join(kept, ",") + "{" + block + "}")
+			}
+		}
+	}
+
+	return out.String()
+}
+
+// cssPreludeEnd returns the offset of the brace opening the block, or the
+// semicolon ending the statement, that follows offset i
+func cssPreludeEnd(css string, i int) int {
+	for i < len(css) {
+		switch css[i] {
+		case '{', ';':
+			return i
+		case '"', '\'':
+			end, err := scanQuoted(css, i)
+			if err != nil {
+				return len(css)
+			}
+			i = end
+			continue
+		}
+		i++
+	}
+	return len(css)
+}
+
+// cssBlockEnd returns the offset of the brace closing the block opened at
+// offset open
+func cssBlockEnd(css string, open int) int {
+	depth := 0
+	for i := open; i < len(css); i++ {
+		switch css[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case '"', '\'':
+			end, err := scanQuoted(css, i)
+			if err != nil {
+				return len(css) - 1
+			}
+			i = end - 1
+		}
+	}
+	return len(css) - 1
+}
+
+// splitSelectors splits a selector list on the commas outside parentheses
+// and attribute selectors
+func splitSelectors(prelude string) []string {
+	var selectors []string
+	depth, start := 0, 0
+	for i := 0; i < len(prelude); i++ {
+		switch prelude[i] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				selectors = append(selectors, strings.TrimSpace(prelude[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(selectors, strings.TrimSpace(prelude[start:]))
+}
+
+// matches reports whether every element, class and id a selector names is
+// present. Attribute selectors and the arguments of pseudo-classes such as
+// :not() are not checked, so the match errs towards keeping rules.
+func (u *usedSelectors) matches(selector string) bool {
+	// compoundStart is true where a type selector may begin
+	compoundStart := true
+	for i := 0; i < len(selector); {
+		c := selector[i]
+		switch {
+		case c == '.' || c == '#':
+			name, end := cssName(selector, i+1)
+			if c == '.' && !u.classes[name] || c == '#' && !u.ids[name] {
+				return false
+			}
+			i = end
+			compoundStart = false
+
+		case c == ':':
+			for i < len(selector) && selector[i] == ':' {
+				i++
+			}
+			_, i = cssName(selector, i)
+			if i < len(selector) && selector[i] == '(' {
+				i = skipCSSGroup(selector, i, '(', ')')
+			}
+			compoundStart = false
+
+		case c == '[':
+			i = skipCSSGroup(selector, i, '[', ']')
+			compoundStart = false
+
+		case c == ' ' || c == '>' || c == '+' || c == '~':
+			i++
+			compoundStart = true
+
+		case c == '*':
+			i++
+			compoundStart = false
+
+		case compoundStart && isIdentByte(c):
+			name, end := cssName(selector, i)
+			if !u.tags[strings.ToLower(name)] {
+				return false
+			}
+			i = end
+			compoundStart = false
+
+		default:
+			i++
+		}
+	}
+	return true
+}
+
+// cssName reads an identifier starting at offset i, resolving backslash
+// escapes such as the one in .md\:flex
+func cssName(selector string, i int) (string, int) {
+	var name strings.Builder
+	for i < len(selector) {
+		c := selector[i]
+		if c == '\\' && i+1 < len(selector) {
+			name.WriteByte(selector[i+1])
+			i += 2
+			continue
+		}
+		if !isIdentByte(c) && c != '-' {
+			break
+		}
+		name.WriteByte(c)
+		i++
+	}
+	return name.String(), i
+}
+
+// skipCSSGroup returns the offset just past the group opened at offset i
+func skipCSSGroup(selector string, i int, open, close byte) int {
+	depth := 0
+	for ; i < len(selector); i++ {
+		switch selector[i] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(selector)
+}
@@ -0,0 +1,37 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractCriticalCSS(t *testing.T) {
+	css := `@charset "utf-8";
+@import url("theme.css");
+body { margin: 0; }
+.card, .panel { padding: 1rem; }
+.card > h2.title:hover { color: red; }
+.card .missing { color: blue; }
+#hero { height: 50vh; }
+#footer { height: 2rem; }
+ul li { list-style: none; }
+button[disabled], .btn:not(.active) { opacity: .5; }
+.md\:flex { display: flex; }
+@media (max-width: 600px) { .card { padding: 0; } .panel { padding: 0; } }
+@media print { .panel { display: none; } }
+@font-face { font-family: "Inter"; src: url(inter.woff2); }
+`
+	html := `<div id="hero" class="card md:flex"><h2 class='title'>Hi</h2><button class="btn">Go</button></div>`
+
+	assert.Equal(t,
+		`body{margin:0}.card{padding:1rem}.card>h2.title:hover{color:red}#hero{height:50vh}`+
+			`button[disabled],.btn:not(.active){opacity:.5}.md\:flex{display:flex}`+
+			`@media (max-width:600px){.card{padding:0}}`+
+			`@font-face{font-family:"Inter";src:url(inter.woff2)}`,
+		extractCriticalCSS(css, html))
+}
+
+func TestExtractCriticalCSS_InvalidStylesheet(t *testing.T) {
+	assert.Empty(t, extractCriticalCSS(`a { content: "open }`, "<a>x</a>"))
+}
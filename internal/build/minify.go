@@ -0,0 +1,621 @@
+package build
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MinifyCSS removes comments and insignificant whitespace from a stylesheet.
+// Strings, url() values and /*! license comments are kept verbatim, and the
+// whitespace that separates tokens inside selectors, calc() expressions and
+// media queries is reduced to a single space rather than dropped.
+func MinifyCSS(src string) (string, error) {
+	var out strings.Builder
+	out.Grow(len(src))
+
+	pendingSpace := false
+	// last is the last byte written, used to decide whether a space is needed,
+	// and is zero after a comment, which separates tokens by itself
+	var last byte
+
+	write := func(s string) {
+		if s == "" {
+			return
+		}
+		if pendingSpace && last != 0 && !cssDropsSpaceAfter(last) && !cssDropsSpaceBefore(s[0]) {
+			out.WriteByte(' ')
+		}
+		pendingSpace = false
+		out.WriteString(s)
+		last = s[len(s)-1]
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case isCSSSpace(c):
+			for i < len(src) && isCSSSpace(src[i]) {
+				i++
+			}
+			pendingSpace = true
+
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return "", fmt.Errorf("unterminated comment at offset %d", i)
+			}
+			comment := src[i : i+2+end+2]
+			i += len(comment)
+			if strings.HasPrefix(comment, "/*!") {
+				write(comment)
+				last = 0
+				continue
+			}
+			// A comment separates tokens like whitespace does
+			pendingSpace = true
+
+		case c == '"' || c == '\'':
+			end, err := scanQuoted(src, i)
+			if err != nil {
+				return "", err
+			}
+			write(src[i:end])
+			i = end
+
+		case c == ';':
+			// Semicolons before a closing brace are redundant
+			j := i + 1
+			for j < len(src) && isCSSSpace(src[j]) {
+				j++
+			}
+			if j < len(src) && src[j] == '}' {
+				i = j
+				continue
+			}
+			if last == ';' || last == '{' {
+				i++
+				continue
+			}
+			pendingSpace = false
+			write(";")
+			i++
+
+		case isCSSURLStart(src, i):
+			end := strings.IndexByte(src[i:], ')')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated url() at offset %d", i)
+			}
+			inner := strings.TrimSpace(src[i+4 : i+end])
+			if strings.HasPrefix(inner, "\"") || strings.HasPrefix(inner, "'") {
+				// Quoted URLs are tokenized like any other string
+				write(src[i : i+4])
+				i += 4
+				continue
+			}
+			write(src[i:i+4] + inner + ")")
+			i += end + 1
+
+		default:
+			j := i + 1
+			for j < len(src) && !isCSSSpace(src[j]) && !strings.ContainsRune("/\"';{}(),:>~", rune(src[j])) && !isCSSURLStart(src, j) {
+				j++
+			}
+			if cssPunctuation(c) {
+				j = i + 1
+			}
+			if c == ':' && cssInDeclaration(src, i) {
+				// Only selectors need the space, as in "a :hover"
+				pendingSpace = false
+			}
+			write(src[i:j])
+			i = j
+		}
+	}
+
+	return out.String(), nil
+}
+
+// cssPunctuation reports whether c is written as a token of its own
+func cssPunctuation(c byte) bool {
+	return strings.IndexByte("{}(),:>~/", c) >= 0
+}
+
+// cssDropsSpaceAfter reports whether whitespace after c is insignificant.
+// A colon is included because declarations are far more common than the
+// descendant pseudo-class selectors it could change, which are only
+// affected by whitespace before the colon.
+func cssDropsSpaceAfter(c byte) bool {
+	return strings.IndexByte("{};:,>~(", c) >= 0
+}
+
+// cssDropsSpaceBefore reports whether whitespace before c is insignificant.
+// Whitespace before an opening parenthesis or a colon is kept, since
+// "and (" and "a :hover" mean something else without it.
+func cssDropsSpaceBefore(c byte) bool {
+	return strings.IndexByte("{};,>~)!", c) >= 0
+}
+
+// cssInDeclaration reports whether offset i lies in a declaration rather
+// than a selector, that is whether a semicolon or closing brace follows
+// before the next opening brace
+func cssInDeclaration(src string, i int) bool {
+	for i < len(src) {
+		switch src[i] {
+		case '{':
+			return false
+		case ';', '}':
+			return true
+		case '"', '\'':
+			end, err := scanQuoted(src, i)
+			if err != nil {
+				return true
+			}
+			i = end
+			continue
+		}
+		i++
+	}
+	return true
+}
+
+func isCSSSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// isCSSURLStart reports whether a url( token starts at offset i
+func isCSSURLStart(src string, i int) bool {
+	if i+4 > len(src) || !strings.EqualFold(src[i:i+4], "url(") {
+		return false
+	}
+	return i == 0 || !isIdentByte(src[i-1])
+}
+
+// scanQuoted returns the offset just past the string literal starting at
+// offset start, honouring backslash escapes
+func scanQuoted(src string, start int) (int, error) {
+	quote := src[start]
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1, nil
+		case '\n':
+			return 0, fmt.Errorf("unterminated string at offset %d", start)
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at offset %d", start)
+}
+
+// jsTokenKind classifies JavaScript tokens for the minifier
+type jsTokenKind int
+
+const (
+	jsWord jsTokenKind = iota // identifiers, keywords and numbers
+	jsString
+	jsTemplate
+	jsRegex
+	jsPunct
+	jsComment // preserved license comments
+)
+
+type jsToken struct {
+	kind jsTokenKind
+	text string
+	// newlineBefore records a line terminator in the whitespace or comments
+	// preceding the token, which matters for automatic semicolon insertion
+	newlineBefore bool
+	// property marks words following a dot, such as the keyword in a.return
+	property bool
+	// endsOperand marks postfix ++ and -- and the braces closing an object
+	// literal, after which a slash is a division
+	endsOperand bool
+}
+
+// jsBrace records what an open brace belongs to
+type jsBrace int
+
+const (
+	jsBraceBlock jsBrace = iota
+	jsBraceObject
+	// jsBraceSubstitution resumes the template literal when closed
+	jsBraceSubstitution
+)
+
+// jsRegexKeywords are the keywords after which a slash starts a regular
+// expression rather than a division
+var jsRegexKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true,
+	"new": true, "delete": true, "void": true, "throw": true, "case": true,
+	"do": true, "else": true, "yield": true, "await": true,
+}
+
+// jsRestrictedKeywords may not be followed by a line terminator without
+// ending the statement
+var jsRestrictedKeywords = map[string]bool{
+	"return": true, "throw": true, "break": true, "continue": true, "yield": true,
+}
+
+// jsPunctuators lists multi-character punctuators, longest first
+var jsPunctuators = []string{
+	">>>=", "...", "===", "!==", "**=", "<<=", ">>=", ">>>", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", ">=", "&&", "||", "??", "?.", "++", "--", "+=", "-=",
+	"*=", "/=", "%=", "&=", "|=", "^=", "**", "<<", ">>",
+}
+
+// MinifyJS removes comments and insignificant whitespace from a script. It
+// tokenizes its input so that strings, template literals and regular
+// expressions are kept verbatim, and keeps the line breaks that automatic
+// semicolon insertion depends on. Comments starting with /*! are kept.
+func MinifyJS(src string) (string, error) {
+	tokens, err := tokenizeJS(src)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	out.Grow(len(src))
+	var prev *jsToken
+	for i := range tokens {
+		token := &tokens[i]
+		if prev != nil {
+			out.WriteString(jsSeparator(prev, token))
+		}
+		out.WriteString(token.text)
+		prev = token
+	}
+	return out.String(), nil
+}
+
+// jsSeparator returns the whitespace needed between two tokens
+func jsSeparator(prev, next *jsToken) string {
+	if next.newlineBefore {
+		if prev.kind == jsWord && jsRestrictedKeywords[prev.text] {
+			return "\n"
+		}
+		if next.text == "++" || next.text == "--" {
+			return "\n"
+		}
+		if jsEndsStatement(prev) && !jsContinuesExpression(next) {
+			return "\n"
+		}
+		if prev.kind == jsComment || next.kind == jsComment {
+			return "\n"
+		}
+	}
+
+	if prev.kind == jsComment || next.kind == jsComment {
+		return ""
+	}
+
+	a, b := prev.text[len(prev.text)-1], next.text[0]
+	switch {
+	case isIdentByte(a) && isIdentByte(b):
+		return " "
+	case (a == '+' || a == '-') && b == a:
+		return " "
+	case a == '/' && (b == '/' || b == '*'):
+		return " "
+	case a == '<' && b == '!', prev.text == "--" && b == '>':
+		// Never form <!-- or -->, which open and close HTML-like comments
+		return " "
+	case prev.kind == jsWord && isDigit(prev.text[0]) && b == '.' &&
+		!strings.ContainsAny(prev.text, ".eExXbBoO"):
+		// 1 .toString() is not 1.toString()
+		return " "
+	}
+	return ""
+}
+
+// jsEndsStatement reports whether a statement may end with the token, so
+// that a line break after it can terminate the statement
+func jsEndsStatement(token *jsToken) bool {
+	switch token.kind {
+	case jsWord, jsString, jsTemplate, jsRegex:
+		return true
+	case jsPunct:
+		switch token.text {
+		case ")", "]", "}", "++", "--":
+			return true
+		}
+	}
+	return false
+}
+
+// jsContinuesExpression reports whether the token can only continue the
+// expression before it. A line break before such a token never inserts a
+// semicolon, so dropping it leaves the parse unchanged; a ( or [ after a
+// line break already calls or indexes the previous line.
+func jsContinuesExpression(token *jsToken) bool {
+	switch token.kind {
+	case jsPunct:
+		switch token.text {
+		case "!", "~", "{", "++", "--", "#", "@":
+			return false
+		}
+		return true
+	case jsTemplate:
+		// A template literal after an expression is a tagged template,
+		// unless it continues a substitution
+		return token.text[0] == '`'
+	}
+	return false
+}
+
+// jsRegexAllowed reports whether a slash following token starts a regular
+// expression. After a closing brace a regex is assumed unless the brace
+// closes an object literal, since a block is far more likely there.
+func jsRegexAllowed(token *jsToken) bool {
+	if token == nil {
+		return true
+	}
+	switch token.kind {
+	case jsWord:
+		return jsRegexKeywords[token.text] && !token.property
+	case jsTemplate:
+		// The start of a substitution is followed by an expression
+		return strings.HasSuffix(token.text, "${")
+	case jsString, jsRegex:
+		return false
+	case jsPunct:
+		return !token.endsOperand && token.text != ")" && token.text != "]"
+	}
+	return true
+}
+
+// jsOpensObject reports whether a brace following token opens an object
+// literal rather than a block. Only punctuators that must be followed by an
+// expression are trusted; anywhere else a block is assumed.
+func jsOpensObject(token *jsToken) bool {
+	if token == nil || token.kind != jsPunct || token.endsOperand {
+		return false
+	}
+	switch token.text {
+	case ")", "]", "}", ";", "{", ":", "=>":
+		return false
+	}
+	return true
+}
+
+func tokenizeJS(src string) ([]jsToken, error) {
+	var tokens []jsToken
+	newline := false
+	// braces tracks open braces, to resume template literals after their
+	// substitutions and to tell object literals from blocks
+	var braces []jsBrace
+
+	last := func() *jsToken {
+		if len(tokens) == 0 {
+			return nil
+		}
+		return &tokens[len(tokens)-1]
+	}
+	emit := func(kind jsTokenKind, text string) *jsToken {
+		property := false
+		if prev := last(); prev != nil && prev.kind == jsPunct {
+			property = prev.text == "." || prev.text == "?."
+		}
+		tokens = append(tokens, jsToken{kind: kind, text: text, newlineBefore: newline, property: property})
+		newline = false
+		return last()
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n' || c == '\r':
+			newline = true
+			i++
+
+		case c == ' ' || c == '\t' || c == '\v' || c == '\f':
+			i++
+
+		case strings.HasPrefix(src[i:], "\u2028") || strings.HasPrefix(src[i:], "\u2029"):
+			newline = true
+			i += 3
+
+		case strings.HasPrefix(src[i:], "\u00a0"):
+			i += len("\u00a0")
+
+		case strings.HasPrefix(src[i:], "\ufeff"):
+			i += len("\ufeff")
+
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			end := strings.IndexAny(src[i:], "\r\n")
+			if end < 0 {
+				end = len(src) - i
+			}
+			i += end
+
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at offset %d", i)
+			}
+			comment := src[i : i+2+end+2]
+			if strings.HasPrefix(comment, "/*!") {
+				emit(jsComment, comment)
+			} else if strings.ContainsAny(comment, "\r\n") {
+				newline = true
+			}
+			i += len(comment)
+
+		case c == '"' || c == '\'':
+			end, err := scanJSString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			emit(jsString, src[i:end])
+			i = end
+
+		case c == '`':
+			end, substitution, err := scanTemplate(src, i+1)
+			if err != nil {
+				return nil, err
+			}
+			if substitution {
+				braces = append(braces, jsBraceSubstitution)
+			}
+			emit(jsTemplate, src[i:end])
+			i = end
+
+		case c == '/' && jsRegexAllowed(last()):
+			end, err := scanRegex(src, i)
+			if err != nil {
+				return nil, err
+			}
+			emit(jsRegex, src[i:end])
+			i = end
+
+		case c == '{':
+			if jsOpensObject(last()) {
+				braces = append(braces, jsBraceObject)
+			} else {
+				braces = append(braces, jsBraceBlock)
+			}
+			emit(jsPunct, "{")
+			i++
+
+		case c == '}':
+			brace := jsBraceBlock
+			if len(braces) > 0 {
+				brace = braces[len(braces)-1]
+				braces = braces[:len(braces)-1]
+			}
+			if brace == jsBraceSubstitution {
+				// The end of a template substitution continues the literal
+				end, substitution, err := scanTemplate(src, i+1)
+				if err != nil {
+					return nil, err
+				}
+				if substitution {
+					braces = append(braces, jsBraceSubstitution)
+				}
+				emit(jsTemplate, src[i:end])
+				i = end
+				continue
+			}
+			emit(jsPunct, "}").endsOperand = brace == jsBraceObject
+			i++
+
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			j := i + 1
+			for j < len(src) {
+				if isIdentByte(src[j]) || src[j] == '.' {
+					j++
+				} else if (src[j] == '+' || src[j] == '-') && (src[j-1] == 'e' || src[j-1] == 'E') && !strings.ContainsAny(src[i:j], "xX") {
+					j++
+				} else {
+					break
+				}
+			}
+			emit(jsWord, src[i:j])
+			i = j
+
+		case isIdentByte(c):
+			j := i + 1
+			for j < len(src) && isIdentByte(src[j]) {
+				j++
+			}
+			emit(jsWord, src[i:j])
+			i = j
+
+		default:
+			punct := src[i : i+1]
+			for _, candidate := range jsPunctuators {
+				if strings.HasPrefix(src[i:], candidate) {
+					punct = candidate
+					break
+				}
+			}
+			// ?. followed by a digit is a conditional, as in a?.5:b
+			if punct == "?." && i+2 < len(src) && isDigit(src[i+2]) {
+				punct = "?"
+			}
+			// ++ and -- are postfix after an operand on the same line
+			postfix := (punct == "++" || punct == "--") && !newline && !jsRegexAllowed(last())
+			emit(jsPunct, punct).endsOperand = postfix
+			i += len(punct)
+		}
+	}
+
+	return tokens, nil
+}
+
+// scanJSString returns the offset just past the string literal starting at
+// offset start. Escaped line terminators continue the string.
+func scanJSString(src string, start int) (int, error) {
+	quote := src[start]
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			if i+2 < len(src) && src[i+1] == '\r' && src[i+2] == '\n' {
+				i++
+			}
+			i++
+		case quote:
+			return i + 1, nil
+		case '\n', '\r':
+			return 0, fmt.Errorf("unterminated string at offset %d", start)
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at offset %d", start)
+}
+
+// scanTemplate scans a template literal from offset start, just after a
+// backtick or the closing brace of a substitution. It returns the offset
+// just past the closing backtick, or past the ${ opening a substitution.
+func scanTemplate(src string, start int) (end int, substitution bool, err error) {
+	for i := start; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '`':
+			return i + 1, false, nil
+		case '$':
+			if i+1 < len(src) && src[i+1] == '{' {
+				return i + 2, true, nil
+			}
+		}
+	}
+	return 0, false, fmt.Errorf("unterminated template literal at offset %d", start)
+}
+
+// scanRegex returns the offset just past the regular expression literal,
+// including its flags, starting at offset start
+func scanRegex(src string, start int) (int, error) {
+	inClass := false
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n', '\r':
+			return 0, fmt.Errorf("unterminated regular expression at offset %d", start)
+		case '/':
+			if inClass {
+				continue
+			}
+			i++
+			for i < len(src) && isIdentByte(src[i]) {
+				i++
+			}
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated regular expression at offset %d", start)
+}
+
+// isIdentByte reports whether c can be part of an identifier or number.
+// Bytes of multi-byte UTF-8 sequences are treated as identifier parts.
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c == '\\' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
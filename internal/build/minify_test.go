@@ -0,0 +1,152 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinifyCSS_Tokenizer(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name: "rules and comments",
+			input: `/* layout */
+.card , .panel > h2 {
+    color : red ;
+    margin: 0 auto;
+}
+`,
+			want: `.card,.panel>h2{color:red;margin:0 auto}`,
+		},
+		{
+			name:  "license comments are kept",
+			input: "/*! MIT */\nbody { margin: 0; }",
+			want:  "/*! MIT */body{margin:0}",
+		},
+		{
+			name:  "strings and urls are verbatim",
+			input: `a::after { content: "  /* not a comment */  "; background: url( img/a  b.png ) ; }`,
+			want:  `a::after{content:"  /* not a comment */  ";background:url(img/a  b.png)}`,
+		},
+		{
+			name:  "significant whitespace",
+			input: ".nav :hover { width: calc(100% - 2 * 1rem); }\n@media screen and (max-width: 600px) { a { color: red !important } }",
+			want:  ".nav :hover{width:calc(100% - 2 * 1rem)}@media screen and (max-width:600px){a{color:red!important}}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MinifyCSS(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := MinifyCSS(`a { content: "open }`)
+	assert.ErrorContains(t, err, "unterminated string")
+}
+
+func TestMinifyJS_Tokenizer(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name: "comments and whitespace",
+			input: `// greet the user
+function greet ( name ) {
+    /* build the message */
+    const message = "Hello, " + name ;
+    return message ;
+}`,
+			want: `function greet(name){const message="Hello, "+name;return message;}`,
+		},
+		{
+			name:  "strings keep comment markers",
+			input: `const url = "http://example.com" ; const s = '/* x */'`,
+			want:  `const url="http://example.com";const s='/* x */'`,
+		},
+		{
+			name:  "regex literals",
+			input: "const re = /\\/\\/ [a/b] */g ; if ( re.test( x ) ) { y = a / b / c }",
+			want:  "const re=/\\/\\/ [a/b] */g;if(re.test(x)){y=a/b/c}",
+		},
+		{
+			name:  "regex after keyword",
+			input: "function f ( s ) {\n  return /^ +$/.test( s )\n}",
+			want:  "function f(s){return/^ +$/.test(s)}",
+		},
+		{
+			name:  "template literals",
+			input: "const html = `<p class=\"${ cls }\">  ${ items.map( i => `<b>${ i }</b>` ).join( '' ) }  </p>` ;",
+			want:  "const html=`<p class=\"${cls}\">  ${items.map(i=>`<b>${i}</b>`).join('')}  </p>`;",
+		},
+		{
+			name:  "template substitutions with objects",
+			input: "const s = `${ JSON.stringify( { a: 1 } ) } // not a comment`",
+			want:  "const s=`${JSON.stringify({a:1})} // not a comment`",
+		},
+		{
+			name:  "line breaks needed by semicolon insertion",
+			input: "let a = 1\nlet b = a\n++b\nreturn\nx",
+			want:  "let a=1\nlet b=a\n++b\nreturn\nx",
+		},
+		{
+			name:  "line breaks before tokens that start a statement",
+			input: "var a = 1\n!function () { }()\nb = a\n~c\nlet x = 1\n{ y() }\nz\n#p in o",
+			want:  "var a=1\n!function(){}()\nb=a\n~c\nlet x=1\n{y()}\nz\n#p in o",
+		},
+		{
+			name:  "line breaks before tokens that continue an expression",
+			input: "a = b\n(c)\nd = e\n[0]\nf = g\n.h\n+ i",
+			want:  "a=b(c)\nd=e[0]\nf=g.h+i",
+		},
+		{
+			name:  "division after postfix operators and object literals",
+			input: "x = i++ / 2 / j-- ; y = { a: 1 } / 2\nif ( x ) { }\n/re/.test( s )",
+			want:  "x=i++/2/j--;y={a:1}/2\nif(x){}\n/re/.test(s)",
+		},
+		{
+			name:  "operators that must stay apart",
+			input: "a = b + +c - -d; e = f++ + g; h = 1 .toString(); i = 2.5 .toFixed()",
+			want:  "a=b+ +c- -d;e=f++ +g;h=1 .toString();i=2.5.toFixed()",
+		},
+		{
+			name:  "division after property keyword",
+			input: "x = obj.in / 2 / 1",
+			want:  "x=obj.in/2/1",
+		},
+		{
+			name:  "license comments are kept",
+			input: "/*! lib v1 | MIT */\nvar lib = {}",
+			want:  "/*! lib v1 | MIT */\nvar lib={}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MinifyJS(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMinifyJS_Unterminated(t *testing.T) {
+	for _, input := range []string{
+		`const s = "open`,
+		"const t = `open ${ a }",
+		"const r = /open",
+		"/* open",
+	} {
+		_, err := MinifyJS(input)
+		assert.Error(t, err, input)
+	}
+}
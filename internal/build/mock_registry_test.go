@@ -1,6 +1,7 @@
 package build
 
 import (
+	"fmt"
 	"sync"
 
 	"github.com/conneroisu/templar/internal/interfaces"
@@ -54,6 +55,14 @@ func (m *MockComponentRegistry) Get(name string) (*types.ComponentInfo, bool) {
 	return component, exists
 }
 
+// Resolve retrieves a component by name, reporting missing components as errors
+func (m *MockComponentRegistry) Resolve(name string) (*types.ComponentInfo, error) {
+	if component, exists := m.Get(name); exists {
+		return component, nil
+	}
+	return nil, fmt.Errorf("component not found: %s", name)
+}
+
 // GetAll returns all registered components
 func (m *MockComponentRegistry) GetAll() []*types.ComponentInfo {
 	m.mutex.RLock()
@@ -0,0 +1,109 @@
+package build
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/conneroisu/templar/internal/config"
+)
+
+func TestAssetOptimizer_Optimize(t *testing.T) {
+	dir := t.TempDir()
+	css := strings.Repeat(".card {\n    color : red ;\n}\n", 50)
+	js := strings.Repeat("function add ( a, b ) {\n    // sum\n    return a + b ;\n}\n", 50)
+	files := map[string]string{
+		"css/app.css":      css,
+		"js/app.js":        js,
+		"js/vendor.min.js": "var x = 1 ;",
+		"js/broken.js":     `const s = "open`,
+		"index.html":       strings.Repeat("<p>Hello</p>\n", 100),
+		"images/logo.png":  strings.Repeat("x", 1000),
+		"tiny.json":        `{}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	cfg := &config.Config{}
+	cfg.Production.Compression = config.CompressionSettings{
+		Enabled:    true,
+		Algorithms: []string{"gzip", "brotli"},
+		Level:      9,
+		Extensions: []string{".css", ".js", ".html", ".json"},
+	}
+
+	result, err := NewAssetOptimizer(cfg).Optimize(context.Background(), dir, OptimizerOptions{
+		CSS:         true,
+		JavaScript:  true,
+		Compression: true,
+	})
+	require.NoError(t, err)
+
+	// CSS and JavaScript are minified in place, *.min.js is left alone
+	minifiedCSS, err := os.ReadFile(filepath.Join(dir, "css", "app.css"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(minifiedCSS), ".card{color:red}.card{"))
+	minifiedJS, err := os.ReadFile(filepath.Join(dir, "js", "app.js"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(minifiedJS), "function add(a,b){return a+b;}"))
+	vendor, err := os.ReadFile(filepath.Join(dir, "js", "vendor.min.js"))
+	require.NoError(t, err)
+	assert.Equal(t, "var x = 1 ;", string(vendor))
+
+	assert.Equal(t, 2, result.FilesMinified)
+	assert.Equal(t, int64(len(css)+len(js)), result.OriginalSize)
+	assert.Equal(t, int64(len(minifiedCSS)+len(minifiedJS)), result.MinifiedSize)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "broken.js not minified: unterminated string")
+
+	// Siblings decompress to the optimized files
+	gz, err := os.Open(filepath.Join(dir, "css", "app.css.gz"))
+	require.NoError(t, err)
+	defer gz.Close()
+	gzReader, err := gzip.NewReader(gz)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(gzReader)
+	require.NoError(t, err)
+	assert.Equal(t, minifiedCSS, decompressed)
+
+	br, err := os.ReadFile(filepath.Join(dir, "index.html.br"))
+	require.NoError(t, err)
+	decompressed, err = io.ReadAll(brotli.NewReader(bytes.NewReader(br)))
+	require.NoError(t, err)
+	assert.Equal(t, files["index.html"], string(decompressed))
+
+	// Other extensions and files that do not shrink get no siblings
+	assert.NoFileExists(t, filepath.Join(dir, "images", "logo.png.gz"))
+	assert.NoFileExists(t, filepath.Join(dir, "tiny.json.gz"))
+	assert.NoFileExists(t, filepath.Join(dir, "js", "app.js.zz"))
+
+	assert.Equal(t, 3, result.FilesCompressed)
+	assert.Len(t, result.CompressedSizes, 2)
+	assert.Less(t, result.CompressedSizes["brotli"], result.UncompressedSize)
+
+	// A second run leaves the siblings out of the next pass
+	result, err = NewAssetOptimizer(cfg).Optimize(context.Background(), dir, OptimizerOptions{Compression: true})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.FilesCompressed)
+	assert.NoFileExists(t, filepath.Join(dir, "css", "app.css.gz.gz"))
+}
+
+func TestAssetOptimizer_UnsupportedAlgorithm(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Production.Compression.Algorithms = []string{"zstd"}
+
+	_, err := NewAssetOptimizer(cfg).Optimize(context.Background(), t.TempDir(), OptimizerOptions{Compression: true})
+	assert.ErrorContains(t, err, `unsupported compression algorithm "zstd"`)
+}
@@ -84,6 +84,8 @@ type BuildTask struct {
 	Priority int
 	// Timestamp records when the task was created for ordering
 	Timestamp time.Time
+	// Context carries the trace of the change that queued the task, if any
+	Context context.Context
 }
 
 // BuildResult represents the result of a build operation
@@ -95,6 +97,8 @@ type BuildResult struct {
 	Duration     time.Duration
 	CacheHit     bool
 	Hash         string
+	// Context carries the trace of the build, if any
+	Context context.Context
 }
 
 // BuildCallback is called when a build completes
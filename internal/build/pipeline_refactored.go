@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/conneroisu/templar/internal/cache"
 	"github.com/conneroisu/templar/internal/errors"
 	"github.com/conneroisu/templar/internal/interfaces"
 	"github.com/conneroisu/templar/internal/types"
@@ -121,6 +122,12 @@ func (rbp *RefactoredBuildPipeline) Stop() error {
 
 // Build processes a single component through the pipeline.
 func (rbp *RefactoredBuildPipeline) Build(component *types.ComponentInfo) error {
+	return rbp.BuildWithContext(context.Background(), component)
+}
+
+// BuildWithContext processes a single component through the pipeline. The
+// build is traced as part of the trace ctx belongs to.
+func (rbp *RefactoredBuildPipeline) BuildWithContext(ctx context.Context, component *types.ComponentInfo) error {
 	if !rbp.started {
 		return errors.NewBuildError("ERR_PIPELINE_NOT_STARTED", "pipeline is not started", nil)
 	}
@@ -129,6 +136,7 @@ func (rbp *RefactoredBuildPipeline) Build(component *types.ComponentInfo) error
 		Component: component,
 		Priority:  0, // Normal priority
 		Timestamp: time.Now(),
+		Context:   ctx,
 	}
 	
 	return rbp.queueManager.Enqueue(task)
@@ -136,6 +144,12 @@ func (rbp *RefactoredBuildPipeline) Build(component *types.ComponentInfo) error
 
 // BuildWithPriority builds a component with high priority.
 func (rbp *RefactoredBuildPipeline) BuildWithPriority(component *types.ComponentInfo) {
+	rbp.BuildWithPriorityContext(context.Background(), component)
+}
+
+// BuildWithPriorityContext builds a component with high priority. The build
+// is traced as part of the trace ctx belongs to.
+func (rbp *RefactoredBuildPipeline) BuildWithPriorityContext(ctx context.Context, component *types.ComponentInfo) {
 	if !rbp.started {
 		return // Cannot enqueue if not started
 	}
@@ -144,6 +158,7 @@ func (rbp *RefactoredBuildPipeline) BuildWithPriority(component *types.Component
 		Component: component,
 		Priority:  1, // High priority
 		Timestamp: time.Now(),
+		Context:   ctx,
 	}
 	
 	rbp.queueManager.EnqueuePriority(task)
@@ -170,6 +185,15 @@ func (rbp *RefactoredBuildPipeline) ClearCache() {
 	// Note: ClearMmapCache method will be added to interface if needed
 }
 
+// SetDiskCache makes the pipeline restore the Go code generated for
+// unchanged templ files from a disk cache, which survives restarts.
+func (rbp *RefactoredBuildPipeline) SetDiskCache(diskCache *cache.DiskCache) {
+	// Use concrete type access for extended functionality
+	if concreteWorker, ok := rbp.workerManager.(*WorkerManager); ok {
+		concreteWorker.SetDiskCache(diskCache)
+	}
+}
+
 // GetQueueStats returns current queue statistics.
 func (rbp *RefactoredBuildPipeline) GetQueueStats() QueueStats {
 	return rbp.queueManager.GetQueueStats()
@@ -53,6 +53,7 @@ func (p *ObjectPools) GetBuildResult() *BuildResult {
 	result.Duration = 0
 	result.Hash = ""
 	result.CacheHit = false
+	result.Context = nil
 	result.ParsedErrors = result.ParsedErrors[:0] // Keep capacity for efficiency
 	return result
 }
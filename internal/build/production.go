@@ -81,6 +81,7 @@ type ProductionBuildMetrics struct {
 	OriginalSize     int64             `json:"original_size_bytes"`
 	OptimizedSize    int64             `json:"optimized_size_bytes"`
 	CompressionRatio float64           `json:"compression_ratio"`
+	CompressedSizes  map[string]int64  `json:"compressed_sizes,omitempty"` // precompressed bytes by algorithm
 	
 	// Bundle analysis
 	BundleSizes      map[string]int64  `json:"bundle_sizes"`
@@ -184,7 +185,7 @@ func (p *ProductionBuildPipeline) Build(ctx context.Context, components []*types
 	
 	// Phase 4: Static Site Generation
 	if options.StaticGeneration {
-		staticPages, err := p.generateStaticSite(ctx, components, options)
+		staticPages, err := p.generateStaticSite(ctx, components, options, artifacts.BundledAssets)
 		if err != nil {
 			return nil, fmt.Errorf("static site generation failed: %w", err)
 		}
@@ -291,28 +292,70 @@ func (p *ProductionBuildPipeline) bundleAssets(ctx context.Context, manifest *As
 	return p.bundler.Bundle(ctx, manifest, bundlerOptions)
 }
 
-// generateStaticSite creates static HTML files from components
-func (p *ProductionBuildPipeline) generateStaticSite(ctx context.Context, components []*types.ComponentInfo, options ProductionBuildOptions) ([]string, error) {
+// generateStaticSite creates static HTML files from components. Critical
+// CSS is extracted from the bundled stylesheets and inlined into each page.
+func (p *ProductionBuildPipeline) generateStaticSite(ctx context.Context, components []*types.ComponentInfo, options ProductionBuildOptions, bundledAssets []string) ([]string, error) {
 	generatorOptions := StaticGenerationOptions{
 		Prerendering:  options.Prerendering,
 		CriticalCSS:   options.CriticalCSS,
+		InlineCSS:     options.CriticalCSS,
 		CDNPath:       options.CDNPath,
 		Environment:   options.Environment,
 	}
+	for _, asset := range bundledAssets {
+		if filepath.Ext(asset) == ".css" {
+			generatorOptions.Stylesheets = append(generatorOptions.Stylesheets, asset)
+		}
+	}
 	
-	return p.generator.Generate(ctx, components, generatorOptions)
+	pages, err := p.generator.Generate(ctx, components, generatorOptions)
+	p.buildMetrics.Warnings = append(p.buildMetrics.Warnings, p.generator.Warnings()...)
+	return pages, err
 }
 
-// optimizeAssets performs post-bundle optimization
+// optimizeAssets performs post-bundle optimization. It covers the whole
+// output directory so that generated pages are precompressed with the assets.
 func (p *ProductionBuildPipeline) optimizeAssets(ctx context.Context, options ProductionBuildOptions) error {
 	optimizerOptions := OptimizerOptions{
 		Images:      options.OptimizeImages,
-		CSS:         options.OptimizeCSS,
-		JavaScript:  options.OptimizeJS,
+		CSS:         options.OptimizeCSS && options.Minification,
+		JavaScript:  options.OptimizeJS && options.Minification,
 		Compression: options.Compression,
 	}
 	
-	return p.optimizer.Optimize(ctx, p.assetsDir, optimizerOptions)
+	result, err := p.optimizer.Optimize(ctx, p.outputDir, optimizerOptions)
+	if result != nil {
+		p.recordOptimization(result)
+	}
+	return err
+}
+
+// recordOptimization adds the sizes reported by the optimizer to the build
+// metrics. The compression ratio is that of the smallest algorithm.
+func (p *ProductionBuildPipeline) recordOptimization(result *OptimizationResult) {
+	metrics := p.buildMetrics
+	metrics.AssetsMinified += result.FilesMinified
+	metrics.AssetsCompressed += result.FilesCompressed
+	metrics.OriginalSize += result.OriginalSize
+	metrics.OptimizedSize += result.MinifiedSize
+	metrics.Warnings = append(metrics.Warnings, result.Warnings...)
+	
+	if len(result.CompressedSizes) == 0 {
+		return
+	}
+	if metrics.CompressedSizes == nil {
+		metrics.CompressedSizes = make(map[string]int64)
+	}
+	smallest := int64(-1)
+	for algorithm, size := range result.CompressedSizes {
+		metrics.CompressedSizes[algorithm] += size
+		if smallest < 0 || size < smallest {
+			smallest = size
+		}
+	}
+	if result.UncompressedSize > 0 {
+		metrics.CompressionRatio = float64(smallest) / float64(result.UncompressedSize)
+	}
 }
 
 // generateAssetManifest creates a manifest file for asset references
@@ -4,12 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	htmlpkg "html"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/conneroisu/templar/internal/config"
+	"github.com/conneroisu/templar/internal/mockdata"
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/renderer"
 	"github.com/conneroisu/templar/internal/types"
 )
 
@@ -19,6 +23,14 @@ type StaticSiteGenerator struct {
 	outputDir    string
 	templateCache map[string]string
 	layoutCache   map[string]string
+	// fixtures supply component props from preview.mock_data
+	fixtures *mockdata.Fixtures
+	// renderer renders the component previews embedded in pages
+	renderer *renderer.ComponentRenderer
+	// warnings collects the previews that failed to render
+	warnings []string
+	// stylesheet holds the CSS that critical CSS is extracted from
+	stylesheet string
 }
 
 // StaticGenerationOptions configures static site generation
@@ -37,6 +49,7 @@ type StaticGenerationOptions struct {
 	
 	// Performance
 	InlineCSS       bool              `json:"inline_css"`
+	Stylesheets     []string          `json:"stylesheets,omitempty"` // CSS files critical CSS is extracted from
 	MinifyHTML      bool              `json:"minify_html"`
 	OptimizeImages  bool              `json:"optimize_images"`
 	
@@ -83,12 +96,107 @@ type SitemapEntry struct {
 
 // NewStaticSiteGenerator creates a new static site generator
 func NewStaticSiteGenerator(cfg *config.Config, outputDir string) *StaticSiteGenerator {
-	return &StaticSiteGenerator{
+	generator := &StaticSiteGenerator{
 		config:        cfg,
 		outputDir:     outputDir,
 		templateCache: make(map[string]string),
 		layoutCache:   make(map[string]string),
 	}
+	if cfg != nil {
+		if dir := cfg.Preview.FixturesDir(); dir != "" {
+			generator.fixtures = mockdata.NewFixtures(dir)
+		}
+	}
+	return generator
+}
+
+// SetRenderer sets the renderer used for component previews, for callers
+// that already hold one. By default Generate renders through a renderer over
+// the components it is given.
+func (s *StaticSiteGenerator) SetRenderer(componentRenderer *renderer.ComponentRenderer) {
+	s.renderer = componentRenderer
+}
+
+// Warnings returns the previews that failed to render during the last
+// Generate. Their pages show the error in place of the component.
+func (s *StaticSiteGenerator) Warnings() []string {
+	return s.warnings
+}
+
+// newRenderer creates a renderer over the given components, with the
+// configured fixtures and preview wrapper
+func (s *StaticSiteGenerator) newRenderer(components []*types.ComponentInfo) *renderer.ComponentRenderer {
+	reg := registry.NewComponentRegistry()
+	for _, component := range components {
+		reg.Register(component)
+	}
+
+	componentRenderer := renderer.NewComponentRenderer(reg)
+	componentRenderer.SetFixtures(s.fixtures)
+	if s.config != nil {
+		componentRenderer.SetWrapper(s.config.Preview.Wrapper)
+	}
+	return componentRenderer
+}
+
+// renderedPreview is the rendered HTML of a component or one of its stories,
+// with the props it was rendered with
+type renderedPreview struct {
+	html  string
+	props map[string]interface{}
+}
+
+// renderPreview renders a component with the props of its default preview
+func (s *StaticSiteGenerator) renderPreview(component *types.ComponentInfo) (*renderedPreview, error) {
+	props, err := s.renderer.PreviewProps(component)
+	if err != nil {
+		return nil, fmt.Errorf("loading props for %s: %w", component.Name, err)
+	}
+
+	html, err := s.renderer.RenderComponentWithProps(component.ID, props)
+	if err != nil {
+		html = s.renderError(component.Name, err)
+	}
+	return &renderedPreview{html: html, props: props}, nil
+}
+
+// renderVariant renders a story of a component inside its wrapper and frame
+func (s *StaticSiteGenerator) renderVariant(component *types.ComponentInfo, example types.ComponentExample) (*renderedPreview, error) {
+	props, err := s.renderer.StoryProps(component, example)
+	if err != nil {
+		return nil, fmt.Errorf("loading props for %s story %s: %w", component.Name, example.Name, err)
+	}
+
+	html, err := s.renderer.RenderComponentWithProps(component.ID, props)
+	if err == nil {
+		html, err = s.renderer.PresentStory(component, example, html)
+	}
+	if err != nil {
+		html = renderer.StoryFrame(example, s.renderError(component.Name+" - "+example.Name, err))
+	}
+	return &renderedPreview{html: html, props: props}, nil
+}
+
+// renderError records a preview that failed to render and returns the
+// notice shown in its place
+func (s *StaticSiteGenerator) renderError(name string, err error) string {
+	s.warnings = append(s.warnings, fmt.Sprintf("failed to render %s: %v", name, err))
+	return fmt.Sprintf("<div class=\"render-error\">Failed to render %s: %s</div>",
+		htmlpkg.EscapeString(name), htmlpkg.EscapeString(err.Error()))
+}
+
+// writePreviewProps writes the props of a preview as a JSON script block
+func writePreviewProps(html *strings.Builder, props map[string]interface{}) error {
+	if len(props) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(props)
+	if err != nil {
+		return fmt.Errorf("failed to marshal preview props: %w", err)
+	}
+	// json.Marshal escapes <, > and &, so values cannot close the script element
+	html.WriteString(fmt.Sprintf("      <script type=\"application/json\" class=\"preview-props\">%s</script>\n", data))
+	return nil
 }
 
 // Generate creates static HTML files from templ components
@@ -100,6 +208,18 @@ func (s *StaticSiteGenerator) Generate(ctx context.Context, components []*types.
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 	
+	s.warnings = nil
+	if err := s.loadStylesheets(options); err != nil {
+		return nil, err
+	}
+	if s.renderer == nil {
+		s.renderer = s.newRenderer(components)
+		defer func() {
+			s.renderer.Close()
+			s.renderer = nil
+		}()
+	}
+	
 	// Generate component pages
 	for _, component := range components {
 		if component.IsExported && component.IsRenderable {
@@ -169,8 +289,13 @@ func (s *StaticSiteGenerator) generateComponentPage(ctx context.Context, compone
 		return nil, fmt.Errorf("failed to create page directory: %w", err)
 	}
 	
+	preview, err := s.renderPreview(component)
+	if err != nil {
+		return nil, err
+	}
+	
 	// Generate HTML content
-	htmlContent, err := s.renderComponentHTML(component, options)
+	htmlContent, err := s.renderComponentHTML(component, preview, options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to render component HTML: %w", err)
 	}
@@ -186,10 +311,17 @@ func (s *StaticSiteGenerator) generateComponentPage(ctx context.Context, compone
 	}
 	generatedFiles = append(generatedFiles, pagePath)
 	
+	// Write the isolated preview page the catalog embeds in an iframe
+	framePath, err := s.writeFramePage(component, component.Name, s.getFramePagePath(component), preview, options)
+	if err != nil {
+		return nil, err
+	}
+	generatedFiles = append(generatedFiles, framePath)
+	
 	// Generate JSON representation if requested
 	if options.OutputFormat == "json" || options.OutputFormat == "both" {
 		jsonPath := strings.TrimSuffix(pagePath, ".html") + ".json"
-		jsonContent, err := s.generateComponentJSON(component, options)
+		jsonContent, err := s.generateComponentJSON(component, preview, options)
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate JSON: %w", err)
 		}
@@ -203,8 +335,13 @@ func (s *StaticSiteGenerator) generateComponentPage(ctx context.Context, compone
 	// Generate component variants if they exist
 	if len(component.Examples) > 0 {
 		for _, example := range component.Examples {
-			variantPath := strings.TrimSuffix(pagePath, ".html") + "-" + s.sanitizeFileName(example.Name) + ".html"
-			variantHTML, err := s.renderComponentVariant(component, example, options)
+			variant, err := s.renderVariant(component, example)
+			if err != nil {
+				return nil, err
+			}
+			
+			variantPath := filepath.Join(s.outputDir, s.getVariantPagePath(component, example))
+			variantHTML, err := s.renderComponentVariant(component, example, variant, options)
 			if err != nil {
 				return nil, fmt.Errorf("failed to render variant %s: %w", example.Name, err)
 			}
@@ -213,6 +350,13 @@ func (s *StaticSiteGenerator) generateComponentPage(ctx context.Context, compone
 				return nil, fmt.Errorf("failed to write variant file: %w", err)
 			}
 			generatedFiles = append(generatedFiles, variantPath)
+			
+			framePath, err := s.writeFramePage(component, component.Name+" - "+example.Name,
+				s.getVariantFramePath(component, example), variant, options)
+			if err != nil {
+				return nil, err
+			}
+			generatedFiles = append(generatedFiles, framePath)
 		}
 	}
 	
@@ -356,8 +500,60 @@ func (s *StaticSiteGenerator) generateIndexPage(ctx context.Context, components
 
 // HTML Generation Methods
 
+// writeFramePage writes the isolated page of a preview: the rendered
+// component alone, inside the preview wrapper when one is configured, ready
+// to be embedded in an iframe
+func (s *StaticSiteGenerator) writeFramePage(component *types.ComponentInfo, title, relPath string, preview *renderedPreview, options StaticGenerationOptions) (string, error) {
+	framePath := filepath.Join(s.outputDir, relPath)
+	
+	page, ok, err := s.renderer.RenderWrapperDocument(component.Name, preview.html)
+	if err != nil {
+		s.warnings = append(s.warnings, fmt.Sprintf("preview wrapper for %s: %v", title, err))
+	}
+	if !ok {
+		page = s.renderFrameHTML(title, preview.html, options)
+	}
+	
+	if options.MinifyHTML {
+		page = s.minifyHTML(page)
+	}
+	
+	if err := os.WriteFile(framePath, []byte(page), 0644); err != nil {
+		return "", fmt.Errorf("failed to write preview frame: %w", err)
+	}
+	return framePath, nil
+}
+
+// renderFrameHTML generates a bare page around a rendered preview
+func (s *StaticSiteGenerator) renderFrameHTML(title, previewHTML string, options StaticGenerationOptions) string {
+	var html strings.Builder
+	
+	html.WriteString("<!DOCTYPE html>\n")
+	html.WriteString("<html lang=\"en\">\n")
+	html.WriteString("<head>\n")
+	html.WriteString(fmt.Sprintf("  <title>%s</title>\n", htmlpkg.EscapeString(title)))
+	html.WriteString("  <meta charset=\"UTF-8\">\n")
+	html.WriteString("  <meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\">\n")
+	
+	if options.CDNPath != "" {
+		html.WriteString(fmt.Sprintf("  <link rel=\"stylesheet\" href=\"%s/css/main.css\">\n", options.CDNPath))
+	} else {
+		html.WriteString("  <link rel=\"stylesheet\" href=\"/assets/css/main.css\">\n")
+	}
+	
+	s.writeCriticalCSS(&html, previewHTML, options)
+	
+	html.WriteString("</head>\n")
+	html.WriteString("<body class=\"templar-frame\">\n")
+	html.WriteString(previewHTML)
+	html.WriteString("\n</body>\n")
+	html.WriteString("</html>\n")
+	
+	return html.String()
+}
+
 // renderComponentHTML generates HTML for a component page
-func (s *StaticSiteGenerator) renderComponentHTML(component *types.ComponentInfo, options StaticGenerationOptions) (string, error) {
+func (s *StaticSiteGenerator) renderComponentHTML(component *types.ComponentInfo, preview *renderedPreview, options StaticGenerationOptions) (string, error) {
 	var html strings.Builder
 	
 	html.WriteString("<!DOCTYPE html>\n")
@@ -380,14 +576,7 @@ func (s *StaticSiteGenerator) renderComponentHTML(component *types.ComponentInfo
 	}
 	
 	// Add critical CSS inline if enabled
-	if options.CriticalCSS && options.InlineCSS {
-		criticalCSS := s.extractCriticalCSS(component)
-		if criticalCSS != "" {
-			html.WriteString("  <style>\n")
-			html.WriteString(criticalCSS)
-			html.WriteString("  </style>\n")
-		}
-	}
+	s.writeCriticalCSS(&html, preview.html, options)
 	
 	html.WriteString("</head>\n")
 	html.WriteString("<body>\n")
@@ -402,9 +591,14 @@ func (s *StaticSiteGenerator) renderComponentHTML(component *types.ComponentInfo
 	
 	// Add component preview
 	html.WriteString("    <div class=\"component-preview\">\n")
-	html.WriteString("      <!-- Component would be rendered here -->\n")
-	html.WriteString(fmt.Sprintf("      <div class=\"placeholder\">%s Component Preview</div>\n", component.Name))
+	html.WriteString(preview.html)
+	html.WriteString("\n")
+	if err := writePreviewProps(&html, preview.props); err != nil {
+		return "", err
+	}
 	html.WriteString("    </div>\n")
+	html.WriteString(fmt.Sprintf("    <p class=\"preview-frame\"><a href=\"%s\">Open in isolation</a></p>\n",
+		filepath.Base(s.getFramePagePath(component))))
 	
 	// Add component documentation
 	if len(component.Parameters) > 0 {
@@ -430,6 +624,23 @@ func (s *StaticSiteGenerator) renderComponentHTML(component *types.ComponentInfo
 		html.WriteString("    </section>\n")
 	}
 	
+	// Link the story variants declared for the component
+	if len(component.Examples) > 0 {
+		html.WriteString("    <section class=\"component-stories\">\n")
+		html.WriteString("      <h2>Stories</h2>\n")
+		html.WriteString("      <ul>\n")
+		for _, example := range component.Examples {
+			html.WriteString(fmt.Sprintf("        <li><a href=\"%s\">%s</a>",
+				filepath.Base(s.getVariantPagePath(component, example)), htmlpkg.EscapeString(example.Name)))
+			if example.Description != "" {
+				html.WriteString(fmt.Sprintf(" - %s", htmlpkg.EscapeString(example.Description)))
+			}
+			html.WriteString("</li>\n")
+		}
+		html.WriteString("      </ul>\n")
+		html.WriteString("    </section>\n")
+	}
+	
 	html.WriteString("  </main>\n")
 	
 	// Add JavaScript if needed
@@ -446,7 +657,7 @@ func (s *StaticSiteGenerator) renderComponentHTML(component *types.ComponentInfo
 }
 
 // renderComponentVariant generates HTML for a component variant/example
-func (s *StaticSiteGenerator) renderComponentVariant(component *types.ComponentInfo, example types.ComponentExample, options StaticGenerationOptions) (string, error) {
+func (s *StaticSiteGenerator) renderComponentVariant(component *types.ComponentInfo, example types.ComponentExample, variant *renderedPreview, options StaticGenerationOptions) (string, error) {
 	var html strings.Builder
 	
 	html.WriteString("<!DOCTYPE html>\n")
@@ -472,9 +683,20 @@ func (s *StaticSiteGenerator) renderComponentVariant(component *types.ComponentI
 		html.WriteString(fmt.Sprintf("    <p>%s</p>\n", example.Description))
 	}
 	
+	// The story frame carries the story's viewport and background
 	html.WriteString("    <div class=\"variant-preview\">\n")
-	html.WriteString(fmt.Sprintf("      <!-- %s variant would be rendered here -->\n", example.Name))
+	html.WriteString(variant.html)
+	html.WriteString("\n")
+	if err := writePreviewProps(&html, variant.props); err != nil {
+		return "", err
+	}
 	html.WriteString("    </div>\n")
+	html.WriteString(fmt.Sprintf("    <p class=\"preview-frame\"><a href=\"%s\">Open in isolation</a></p>\n",
+		filepath.Base(s.getVariantFramePath(component, example))))
+	
+	if example.Notes != "" {
+		html.WriteString(fmt.Sprintf("    <section class=\"variant-notes\">\n      <h2>Notes</h2>\n      <p>%s</p>\n    </section>\n", htmlpkg.EscapeString(example.Notes)))
+	}
 	html.WriteString("  </main>\n")
 	html.WriteString("</body>\n")
 	html.WriteString("</html>\n")
@@ -580,6 +802,8 @@ func (s *StaticSiteGenerator) generateComponentCatalogHTML(components []*types.C
 		if component.IsExported && component.IsRenderable {
 			html.WriteString("      <div class=\"component-card\">\n")
 			html.WriteString(fmt.Sprintf("        <h3><a href=\"%s\">%s</a></h3>\n", s.getComponentPagePath(component), component.Name))
+			html.WriteString(fmt.Sprintf("        <iframe class=\"component-frame\" src=\"%s\" title=\"%s preview\" loading=\"lazy\"></iframe>\n",
+				s.getFramePagePath(component), htmlpkg.EscapeString(component.Name)))
 			
 			if component.Description != "" {
 				html.WriteString(fmt.Sprintf("        <p>%s</p>\n", component.Description))
@@ -610,6 +834,24 @@ func (s *StaticSiteGenerator) getComponentPagePath(component *types.ComponentInf
 	return fmt.Sprintf("%s.html", sanitizedName)
 }
 
+// getVariantPagePath generates the page path for a component story variant,
+// placed next to the component page
+func (s *StaticSiteGenerator) getVariantPagePath(component *types.ComponentInfo, example types.ComponentExample) string {
+	pagePath := s.getComponentPagePath(component)
+	return strings.TrimSuffix(pagePath, ".html") + "-" + s.sanitizeFileName(example.Name) + ".html"
+}
+
+// getFramePagePath generates the path of a component's isolated preview
+// page, placed next to the component page
+func (s *StaticSiteGenerator) getFramePagePath(component *types.ComponentInfo) string {
+	return strings.TrimSuffix(s.getComponentPagePath(component), ".html") + ".frame.html"
+}
+
+// getVariantFramePath generates the path of a story's isolated preview page
+func (s *StaticSiteGenerator) getVariantFramePath(component *types.ComponentInfo, example types.ComponentExample) string {
+	return strings.TrimSuffix(s.getVariantPagePath(component, example), ".html") + ".frame.html"
+}
+
 // sanitizeFileName creates a safe filename from a string
 func (s *StaticSiteGenerator) sanitizeFileName(name string) string {
 	// Convert to lowercase and replace non-alphanumeric with hyphens
@@ -650,22 +892,50 @@ func (s *StaticSiteGenerator) minifyHTML(html string) string {
 	return result
 }
 
-// extractCriticalCSS extracts critical CSS for a component (placeholder)
-func (s *StaticSiteGenerator) extractCriticalCSS(component *types.ComponentInfo) string {
-	// Placeholder implementation
-	// In a real implementation, this would analyze the component and extract
-	// the minimal CSS needed for above-the-fold rendering
-	return "/* Critical CSS for " + component.Name + " */"
+// loadStylesheets reads the stylesheets critical CSS is extracted from
+func (s *StaticSiteGenerator) loadStylesheets(options StaticGenerationOptions) error {
+	s.stylesheet = ""
+	if !options.CriticalCSS || !options.InlineCSS {
+		return nil
+	}
+	
+	var css strings.Builder
+	for _, path := range options.Stylesheets {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read stylesheet %s: %w", path, err)
+		}
+		css.Write(content)
+		css.WriteString("\n")
+	}
+	s.stylesheet = css.String()
+	return nil
+}
+
+// writeCriticalCSS inlines the stylesheet rules used by the rendered HTML
+func (s *StaticSiteGenerator) writeCriticalCSS(html *strings.Builder, renderedHTML string, options StaticGenerationOptions) {
+	if !options.CriticalCSS || !options.InlineCSS || s.stylesheet == "" {
+		return
+	}
+	
+	criticalCSS := extractCriticalCSS(s.stylesheet, renderedHTML)
+	if criticalCSS != "" {
+		html.WriteString("  <style>\n")
+		html.WriteString(criticalCSS)
+		html.WriteString("\n  </style>\n")
+	}
 }
 
 // generateComponentJSON creates a JSON representation of a component
-func (s *StaticSiteGenerator) generateComponentJSON(component *types.ComponentInfo, options StaticGenerationOptions) (string, error) {
+func (s *StaticSiteGenerator) generateComponentJSON(component *types.ComponentInfo, preview *renderedPreview, options StaticGenerationOptions) (string, error) {
 	componentData := map[string]interface{}{
 		"name":        component.Name,
 		"package":     component.Package,
 		"description": component.Description,
 		"parameters":  component.Parameters,
 		"examples":    component.Examples,
+		"props":       preview.props,
+		"html":        preview.html,
 		"metadata":    component.Metadata,
 		"generated_at": time.Now(),
 		"build_info": map[string]interface{}{
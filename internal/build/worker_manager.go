@@ -11,8 +11,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/conneroisu/templar/internal/cache"
 	"github.com/conneroisu/templar/internal/errors"
 	"github.com/conneroisu/templar/internal/interfaces"
+	"github.com/conneroisu/templar/internal/tracing"
 )
 
 // WorkerManager manages a pool of build workers with configurable parallelism.
@@ -33,6 +35,8 @@ type WorkerManager struct {
 	objectPools *ObjectPools
 	// errorParser processes build errors and provides detailed diagnostics
 	errorParser *errors.ErrorParser
+	// diskCache keeps generated Go code across runs; nil when disabled
+	diskCache *cache.DiskCache
 	// workerWg synchronizes worker goroutine lifecycle
 	workerWg sync.WaitGroup
 	// cancel terminates all worker operations gracefully
@@ -131,21 +135,55 @@ func (wm *WorkerManager) worker(ctx context.Context, queue interfaces.TaskQueue)
 func (wm *WorkerManager) processBuildTask(ctx context.Context, task BuildTask) BuildResult {
 	startTime := time.Now()
 	
+	// Continue the trace of the change that queued the task, keeping the
+	// worker's own cancellation
+	var span *tracing.Span
+	if parent := tracing.SpanFromContext(task.Context); parent != nil {
+		ctx = tracing.ContextWithSpan(ctx, parent)
+		_, queued := tracing.Start(ctx, "build.queue", tracing.WithStartTime(task.Timestamp))
+		queued.EndAt(startTime)
+		ctx, span = tracing.Start(ctx, "build.component", tracing.WithStartTime(startTime))
+		span.SetAttribute("component", task.Component.Name)
+	}
+	
 	// Use object pool for build result
 	buildResult := wm.objectPools.GetBuildResult()
 	buildResult.Component = task.Component
 	buildResult.CacheHit = false
 	buildResult.Hash = ""
+	if span != nil {
+		buildResult.Context = ctx
+		defer func() {
+			span.SetAttribute("cache_hit", buildResult.CacheHit)
+			span.RecordError(buildResult.Error)
+			span.End()
+		}()
+	}
 	
 	// Generate hash for caching
 	hash := wm.hashProvider.GenerateContentHash(task.Component)
 	buildResult.Hash = hash
 	
-	// Check cache first (if cache is integrated into the system)
-	// For now, we'll proceed with compilation
+	// Code generated earlier for the same templ source is restored from the
+	// disk cache instead of running templ generate
+	wm.mu.RLock()
+	diskCache := wm.diskCache
+	wm.mu.RUnlock()
+	if diskCache != nil && diskCache.RestoreGenerated(task.Component.FilePath) {
+		buildResult.CacheHit = true
+		buildResult.Duration = time.Since(startTime)
+		if wm.metrics != nil {
+			wm.metrics.RecordBuild(*buildResult)
+		}
+		return *buildResult
+	}
 	
 	// Execute build with pooled output buffer
 	output, err := wm.compiler.CompileWithPools(ctx, task.Component, wm.objectPools)
+	if err == nil && diskCache != nil {
+		// A failed write only costs a templ generate on the next run
+		_ = diskCache.StoreGenerated(task.Component.FilePath)
+	}
 	
 	// Parse errors if build failed
 	var parsedErrors []*errors.ParsedError
@@ -170,6 +208,15 @@ func (wm *WorkerManager) processBuildTask(ctx context.Context, task BuildTask) B
 	return *buildResult
 }
 
+// SetDiskCache makes workers restore the Go code generated for unchanged
+// templ files from a disk cache instead of running templ generate.
+func (wm *WorkerManager) SetDiskCache(diskCache *cache.DiskCache) {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	
+	wm.diskCache = diskCache
+}
+
 // GetWorkerStats returns current worker pool statistics.
 func (wm *WorkerManager) GetWorkerStats() WorkerStats {
 	wm.mu.RLock()
@@ -0,0 +1,445 @@
+// Package cache provides a content-addressed cache on disk that keeps scan
+// metadata, generated Go code and rendered HTML across runs.
+//
+// Entries live under a directory named after the templar and templ versions
+// that produced them. Opening the cache removes the directories of other
+// versions, so an upgrade never serves output from an older generator.
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/conneroisu/templar/internal/version"
+)
+
+// Namespaces group the entries of the cache by what they hold
+const (
+	// NamespaceScan holds the components parsed from a file
+	NamespaceScan = "scan"
+	// NamespaceGenerate holds the Go code templ generates for a templ file
+	NamespaceGenerate = "generate"
+	// NamespaceRender holds the HTML of a component rendered with given props
+	NamespaceRender = "render"
+)
+
+const (
+	// DefaultMaxSize is the size the cache is trimmed to when none is configured
+	DefaultMaxSize = 256 << 20
+
+	// versionDirPrefix starts the name of every version directory
+	versionDirPrefix = "templar-"
+	// evictionRatio is the share of the maximum size eviction trims down to,
+	// so that a full cache isn't trimmed on every write
+	evictionRatio = 0.9
+)
+
+// Namespaces lists the namespaces of the cache
+var Namespaces = []string{NamespaceScan, NamespaceGenerate, NamespaceRender}
+
+// DiskCache is a size-limited, content-addressed cache on disk. Entries are
+// files named by their key; the least recently used are evicted first. It is
+// safe for concurrent use, and by several processes sharing the directory.
+type DiskCache struct {
+	// root is the configured cache directory
+	root string
+	// dir is the directory of the current version's entries
+	dir     string
+	version string
+	maxSize int64
+
+	// mutex guards size and serializes eviction
+	mutex sync.Mutex
+	size  int64
+
+	hits      int64
+	misses    int64
+	writes    int64
+	evictions int64
+}
+
+// Stats describes the contents and use of a cache
+type Stats struct {
+	Dir        string                    `json:"dir"`
+	Version    string                    `json:"version"`
+	Size       int64                     `json:"size"`
+	MaxSize    int64                     `json:"max_size"`
+	Namespaces map[string]NamespaceStats `json:"namespaces"`
+	// Hits, Misses, Writes and Evictions count the operations of this process
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Writes    int64 `json:"writes"`
+	Evictions int64 `json:"evictions"`
+}
+
+// NamespaceStats describes the entries of a namespace
+type NamespaceStats struct {
+	Entries int   `json:"entries"`
+	Size    int64 `json:"size"`
+}
+
+// Open opens the cache in root, creating it if needed and removing the
+// entries written by other templar or templ versions. A maxSize of zero or
+// less selects DefaultMaxSize.
+func Open(root string, maxSize int64) (*DiskCache, error) {
+	if root == "" {
+		return nil, errors.New("cache directory is not set")
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	v := Version()
+	c := &DiskCache{
+		root:    root,
+		dir:     filepath.Join(root, versionDirPrefix+Key(v)[:16]),
+		version: v,
+		maxSize: maxSize,
+	}
+	if err := os.MkdirAll(c.dir, 0750); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	stale, err := versionDirs(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range stale {
+		if dir != c.dir {
+			if err := os.RemoveAll(dir); err != nil {
+				return nil, fmt.Errorf("removing outdated cache %s: %w", dir, err)
+			}
+		}
+	}
+
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		c.size += entry.size
+	}
+	return c, nil
+}
+
+// Clean removes every cache entry under root, of any version, and returns the
+// number of bytes freed
+func Clean(root string) (int64, error) {
+	dirs, err := versionDirs(root)
+	if err != nil {
+		return 0, err
+	}
+
+	var freed int64
+	for _, dir := range dirs {
+		size, err := dirSize(dir)
+		if err != nil {
+			return freed, err
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return freed, fmt.Errorf("removing cache %s: %w", dir, err)
+		}
+		freed += size
+	}
+	return freed, nil
+}
+
+// Key derives a cache key from the parts identifying an entry
+func Key(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ContentKey derives the cache key of data produced from a file's content
+func ContentKey(path string, content []byte) string {
+	sum := sha256.Sum256(content)
+	return Key(path, hex.EncodeToString(sum[:]))
+}
+
+// Get returns the entry stored under key in a namespace
+func (c *DiskCache) Get(namespace, key string) ([]byte, bool) {
+	path, err := c.entryPath(namespace, key)
+	if err != nil {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, false
+	}
+
+	// The modification time orders entries for eviction
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	atomic.AddInt64(&c.hits, 1)
+	return data, true
+}
+
+// Set stores data under key in a namespace, evicting the least recently used
+// entries when the cache grows past its maximum size
+func (c *DiskCache) Set(namespace, key string, data []byte) error {
+	path, err := c.entryPath(namespace, key)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > c.maxSize {
+		return fmt.Errorf("cache entry of %d bytes exceeds the cache size limit", len(data))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	var previous int64
+	if info, err := os.Stat(path); err == nil {
+		previous = info.Size()
+	}
+
+	// Readers never see a partly written entry
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	atomic.AddInt64(&c.writes, 1)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.size += int64(len(data)) - previous
+	if c.size > c.maxSize {
+		return c.evictLocked()
+	}
+	return nil
+}
+
+// Dir returns the directory holding the current version's entries
+func (c *DiskCache) Dir() string {
+	return c.dir
+}
+
+// Stats returns the contents of the cache and the operations of this process
+func (c *DiskCache) Stats() (Stats, error) {
+	stats := Stats{
+		Dir:        c.dir,
+		Version:    c.version,
+		MaxSize:    c.maxSize,
+		Namespaces: make(map[string]NamespaceStats, len(Namespaces)),
+		Hits:       atomic.LoadInt64(&c.hits),
+		Misses:     atomic.LoadInt64(&c.misses),
+		Writes:     atomic.LoadInt64(&c.writes),
+		Evictions:  atomic.LoadInt64(&c.evictions),
+	}
+	for _, namespace := range Namespaces {
+		stats.Namespaces[namespace] = NamespaceStats{}
+	}
+
+	entries, err := c.entries()
+	if err != nil {
+		return stats, err
+	}
+	for _, entry := range entries {
+		namespace := stats.Namespaces[entry.namespace]
+		namespace.Entries++
+		namespace.Size += entry.size
+		stats.Namespaces[entry.namespace] = namespace
+		stats.Size += entry.size
+	}
+	return stats, nil
+}
+
+// entryPath returns the file of an entry, spreading entries over
+// subdirectories by the first byte of their key
+func (c *DiskCache) entryPath(namespace, key string) (string, error) {
+	if !isHex(key) || len(key) < 4 {
+		return "", fmt.Errorf("invalid cache key %q", key)
+	}
+	known := false
+	for _, name := range Namespaces {
+		known = known || name == namespace
+	}
+	if !known {
+		return "", fmt.Errorf("unknown cache namespace %q", namespace)
+	}
+	return filepath.Join(c.dir, namespace, key[:2], key), nil
+}
+
+// evictLocked removes the least recently used entries until the cache is
+// back under evictionRatio of its maximum size. The size is recounted from
+// disk, as other processes may share the cache.
+func (c *DiskCache) evictLocked() error {
+	entries, err := c.entries()
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].modTime.Before(entries[j].modTime) })
+
+	c.size = 0
+	for _, entry := range entries {
+		c.size += entry.size
+	}
+
+	target := int64(float64(c.maxSize) * evictionRatio)
+	for _, entry := range entries {
+		if c.size <= target {
+			break
+		}
+		if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("evicting cache entry: %w", err)
+		}
+		c.size -= entry.size
+		atomic.AddInt64(&c.evictions, 1)
+	}
+	return nil
+}
+
+// entry is a cache entry found on disk
+type entry struct {
+	path      string
+	namespace string
+	size      int64
+	modTime   time.Time
+}
+
+// entries lists the entries of the current version
+func (c *DiskCache) entries() ([]entry, error) {
+	var entries []entry
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			// Evicted since the directory was read
+			return nil
+		}
+		rel, err := filepath.Rel(c.dir, path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{
+			path:      path,
+			namespace: strings.SplitN(filepath.ToSlash(rel), "/", 2)[0],
+			size:      info.Size(),
+			modTime:   info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading cache directory: %w", err)
+	}
+	return entries, nil
+}
+
+// versionDirs returns the version directories under root
+func versionDirs(root string) ([]string, error) {
+	items, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading cache directory: %w", err)
+	}
+
+	var dirs []string
+	for _, item := range items {
+		if item.IsDir() && strings.HasPrefix(item.Name(), versionDirPrefix) {
+			dirs = append(dirs, filepath.Join(root, item.Name()))
+		}
+	}
+	return dirs, nil
+}
+
+// dirSize returns the total size of the files under dir
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// writeFileAtomic writes data to a temporary file beside path and renames it
+// into place
+func writeFileAtomic(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	versionOnce  sync.Once
+	versionValue string
+)
+
+// Version identifies the templar build and templ generator whose output the
+// cache holds. Output of the templ command is preferred over the templ module
+// templar was built with, as that command generates the Go code.
+func Version() string {
+	versionOnce.Do(func() {
+		templVersion := "unknown"
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, dep := range info.Deps {
+				if dep.Path == "github.com/a-h/templ" {
+					templVersion = dep.Version
+				}
+			}
+		}
+		if output, err := exec.Command("templ", "version").Output(); err == nil {
+			templVersion = string(bytes.TrimSpace(output))
+		}
+
+		versionValue = fmt.Sprintf("templar %s (%s), templ %s, %s",
+			version.GetVersion(), version.GetGitCommit(), templVersion, runtime.Version())
+	})
+	return versionValue
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskCache(t *testing.T) {
+	root := t.TempDir()
+
+	// Entries of other versions are removed on open
+	outdated := filepath.Join(root, versionDirPrefix+"outdated")
+	require.NoError(t, os.MkdirAll(filepath.Join(outdated, NamespaceScan), 0750))
+
+	c, err := Open(root, 0)
+	require.NoError(t, err)
+	assert.NoDirExists(t, outdated)
+	assert.Equal(t, int64(DefaultMaxSize), c.maxSize)
+
+	key := Key("components/card.templ", "abc")
+	_, ok := c.Get(NamespaceScan, key)
+	assert.False(t, ok)
+
+	require.NoError(t, c.Set(NamespaceScan, key, []byte("card")))
+	data, ok := c.Get(NamespaceScan, key)
+	require.True(t, ok)
+	assert.Equal(t, "card", string(data))
+
+	assert.Error(t, c.Set("unknown", key, nil))
+	assert.Error(t, c.Set(NamespaceScan, "../escape", nil))
+
+	// A reopened cache finds the entries of earlier runs
+	reopened, err := Open(root, 0)
+	require.NoError(t, err)
+	data, ok = reopened.Get(NamespaceScan, key)
+	require.True(t, ok)
+	assert.Equal(t, "card", string(data))
+
+	stats, err := reopened.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, NamespaceStats{Entries: 1, Size: 4}, stats.Namespaces[NamespaceScan])
+	assert.Equal(t, NamespaceStats{}, stats.Namespaces[NamespaceRender])
+	assert.Equal(t, int64(4), stats.Size)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, Version(), stats.Version)
+
+	freed, err := Clean(root)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), freed)
+	_, ok = reopened.Get(NamespaceScan, key)
+	assert.False(t, ok)
+}
+
+func TestDiskCacheEviction(t *testing.T) {
+	c, err := Open(t.TempDir(), 100)
+	require.NoError(t, err)
+
+	data := make([]byte, 40)
+	keys := []string{Key("a"), Key("b"), Key("c")}
+	for i, key := range keys[:2] {
+		require.NoError(t, c.Set(NamespaceRender, key, data))
+		old := time.Now().Add(time.Duration(i-10) * time.Minute)
+		path, err := c.entryPath(NamespaceRender, key)
+		require.NoError(t, err)
+		require.NoError(t, os.Chtimes(path, old, old))
+	}
+
+	// Reading an entry makes it the most recently used
+	_, ok := c.Get(NamespaceRender, keys[0])
+	require.True(t, ok)
+
+	require.NoError(t, c.Set(NamespaceRender, keys[2], data))
+	_, ok = c.Get(NamespaceRender, keys[1])
+	assert.False(t, ok, "the least recently used entry is evicted")
+	for _, key := range []string{keys[0], keys[2]} {
+		_, ok = c.Get(NamespaceRender, key)
+		assert.True(t, ok)
+	}
+
+	stats, err := c.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(80), stats.Size)
+	assert.Equal(t, int64(1), stats.Evictions)
+
+	assert.Error(t, c.Set(NamespaceRender, keys[0], make([]byte, 101)))
+}
+
+func TestGenerated(t *testing.T) {
+	c, err := Open(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	templPath := filepath.Join(dir, "card.templ")
+	goPath := filepath.Join(dir, "card_templ.go")
+	require.NoError(t, os.WriteFile(templPath, []byte("templ Card() {}"), 0644))
+
+	assert.False(t, c.RestoreGenerated(templPath))
+	require.NoError(t, os.WriteFile(goPath, []byte("package card // v1"), 0644))
+	require.NoError(t, c.StoreGenerated(templPath))
+
+	// Removed output is restored for the same templ source
+	require.NoError(t, os.Remove(goPath))
+	assert.True(t, c.RestoreGenerated(templPath))
+	assert.FileExists(t, goPath)
+	code, err := os.ReadFile(goPath)
+	require.NoError(t, err)
+	assert.Equal(t, "package card // v1", string(code))
+
+	// Changed sources need generating again
+	require.NoError(t, os.WriteFile(templPath, []byte("templ Card() { <div></div> }"), 0644))
+	assert.False(t, c.RestoreGenerated(templPath))
+	assert.False(t, c.RestoreGenerated(filepath.Join(dir, "card.go")))
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GeneratedPath returns the Go file templ generates for a templ file
+func GeneratedPath(templPath string) string {
+	return strings.TrimSuffix(templPath, ".templ") + "_templ.go"
+}
+
+// RestoreGenerated writes the cached Go code generated for a templ file next
+// to it, unless it is already there. It reports false when the templ file's
+// current content has no cached output, in which case templ generate must run.
+func (c *DiskCache) RestoreGenerated(templPath string) bool {
+	if !strings.HasSuffix(templPath, ".templ") {
+		return false
+	}
+	key, err := generatedKey(templPath)
+	if err != nil {
+		return false
+	}
+	code, ok := c.Get(NamespaceGenerate, key)
+	if !ok {
+		return false
+	}
+
+	// Rewriting an identical file would only wake up file watchers
+	goPath := GeneratedPath(templPath)
+	if existing, err := os.ReadFile(goPath); err == nil && bytes.Equal(existing, code) {
+		return true
+	}
+	return writeFileAtomic(goPath, code) == nil
+}
+
+// StoreGenerated caches the Go code generated next to a templ file
+func (c *DiskCache) StoreGenerated(templPath string) error {
+	key, err := generatedKey(templPath)
+	if err != nil {
+		return err
+	}
+	code, err := os.ReadFile(GeneratedPath(templPath))
+	if err != nil {
+		return fmt.Errorf("reading generated code: %w", err)
+	}
+	return c.Set(NamespaceGenerate, key, code)
+}
+
+// generatedKey returns the key of the code generated for a templ file's
+// current content
+func generatedKey(templPath string) (string, error) {
+	absPath, err := filepath.Abs(templPath)
+	if err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return "", fmt.Errorf("reading templ file: %w", err)
+	}
+	return ContentKey(absPath, content), nil
+}
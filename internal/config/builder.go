@@ -4,6 +4,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -63,6 +64,7 @@ func (cb *ConfigBuilder) WithBasicSettings() *ConfigBuilder {
 		Watch: []string{"**/*.templ"},
 		Ignore: []string{"node_modules", ".git"},
 		CacheDir: ".templar/cache",
+		CacheMaxSize: 256 << 20,
 	}
 	return cb
 }
@@ -77,6 +79,12 @@ func (cb *ConfigBuilder) WithDevelopmentMode() *ConfigBuilder {
 		CSSInjection: true,
 		ErrorOverlay: true,
 		StatePreservation: false,
+		EditorURL: "vscode://file/{file}:{line}:{column}",
+		Watcher: WatcherConfig{
+			Backend: "auto",
+			PollInterval: 500 * time.Millisecond,
+			MaxPollInterval: 5 * time.Second,
+		},
 	}
 	cb.config.Preview = PreviewConfig{
 		MockData: "auto",
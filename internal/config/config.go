@@ -69,6 +69,8 @@ type BuildConfig struct {
 	Watch    []string `yaml:"watch"`
 	Ignore   []string `yaml:"ignore"`
 	CacheDir string   `yaml:"cache_dir"`
+	// CacheMaxSize limits the on-disk cache in CacheDir, in bytes
+	CacheMaxSize int64 `yaml:"cache_max_size"`
 }
 
 type PreviewConfig struct {
@@ -77,6 +79,16 @@ type PreviewConfig struct {
 	AutoProps bool   `yaml:"auto_props"`
 }
 
+// FixturesDir returns the directory of prop fixtures named by mock_data, or
+// "" when mock data is only generated ("auto", "manual", "none" or unset)
+func (p PreviewConfig) FixturesDir() string {
+	switch p.MockData {
+	case "", "auto", "manual", "none":
+		return ""
+	}
+	return p.MockData
+}
+
 type ComponentsConfig struct {
 	ScanPaths       []string `yaml:"scan_paths"`
 	ExcludePatterns []string `yaml:"exclude_patterns"`
@@ -87,6 +99,24 @@ type DevelopmentConfig struct {
 	CSSInjection      bool `yaml:"css_injection"`
 	StatePreservation bool `yaml:"state_preservation"`
 	ErrorOverlay      bool `yaml:"error_overlay"`
+	// EditorURL links error overlay locations to an editor. {file}, {line}
+	// and {column} are replaced with the location.
+	EditorURL string `yaml:"editor_url"`
+	// Watcher selects how file changes are detected
+	Watcher WatcherConfig `yaml:"watcher"`
+}
+
+// WatcherConfig configures file change detection
+type WatcherConfig struct {
+	// Backend is "fsnotify", "poll", or "auto" to use fsnotify and fall back
+	// to polling when the operating system's watch limits are reached.
+	// Polling also works on network file systems, Docker bind mounts and
+	// WSL drives.
+	Backend string `yaml:"backend"`
+	// PollInterval is how often polling checks for changes. It doubles while
+	// nothing changes, up to MaxPollInterval.
+	PollInterval    time.Duration `yaml:"poll_interval"`
+	MaxPollInterval time.Duration `yaml:"max_poll_interval"`
 }
 
 // ProductionConfig defines production-specific build and deployment settings
@@ -389,6 +419,21 @@ type MonitoringConfig struct {
 	MetricsPath   string `yaml:"metrics_path"`
 	HTTPPort      int    `yaml:"http_port"`
 	AlertsEnabled bool   `yaml:"alerts_enabled"`
+
+	Tracing TracingConfig `yaml:"tracing"`
+}
+
+// TracingConfig configures tracing of file changes through scanning,
+// building, rendering and the browser reload
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// OTLPEndpoint is the base URL of an OpenTelemetry collector accepting
+	// OTLP/HTTP, such as http://localhost:4318
+	OTLPEndpoint string `yaml:"otlp_endpoint"`
+	// File is a file spans are appended to as JSON lines
+	File string `yaml:"file"`
+	// MaxTraces is how many recent traces /api/traces keeps, 100 if zero
+	MaxTraces int `yaml:"max_traces"`
 }
 
 // CSSConfig defines CSS framework integration configuration
@@ -463,6 +508,9 @@ func loadDefaults(config *Config) {
 	if config.Build.CacheDir == "" {
 		config.Build.CacheDir = ".templar/cache"
 	}
+	if config.Build.CacheMaxSize == 0 {
+		config.Build.CacheMaxSize = 256 << 20 // 256MB
+	}
 
 	// Apply default values for AuthConfig if not set
 	if config.Server.Auth.Mode == "" {
@@ -504,6 +552,18 @@ func loadDefaults(config *Config) {
 	if !viper.IsSet("development.error_overlay") {
 		config.Development.ErrorOverlay = true
 	}
+	if !viper.IsSet("development.editor_url") {
+		config.Development.EditorURL = "vscode://file/{file}:{line}:{column}"
+	}
+	if !viper.IsSet("development.watcher.backend") {
+		config.Development.Watcher.Backend = "auto"
+	}
+	if !viper.IsSet("development.watcher.poll_interval") {
+		config.Development.Watcher.PollInterval = 500 * time.Millisecond
+	}
+	if !viper.IsSet("development.watcher.max_poll_interval") {
+		config.Development.Watcher.MaxPollInterval = 5 * time.Second
+	}
 
 	// Apply default values for PluginsConfig if not set
 	if len(config.Plugins.DiscoveryPaths) == 0 {
@@ -837,6 +897,14 @@ func applyOverrides(config *Config) {
 		}
 	}
 
+	// Handle build settings set via viper
+	if viper.IsSet("build.cache_dir") {
+		config.Build.CacheDir = viper.GetString("build.cache_dir")
+	}
+	if viper.IsSet("build.cache_max_size") {
+		config.Build.CacheMaxSize = viper.GetInt64("build.cache_max_size")
+	}
+
 	// Handle development settings set via viper (workaround for viper bool handling)
 	if viper.IsSet("development.hot_reload") {
 		config.Development.HotReload = viper.GetBool("development.hot_reload")
@@ -850,6 +918,18 @@ func applyOverrides(config *Config) {
 	if viper.IsSet("development.error_overlay") {
 		config.Development.ErrorOverlay = viper.GetBool("development.error_overlay")
 	}
+	if viper.IsSet("development.editor_url") {
+		config.Development.EditorURL = viper.GetString("development.editor_url")
+	}
+	if viper.IsSet("development.watcher.backend") {
+		config.Development.Watcher.Backend = viper.GetString("development.watcher.backend")
+	}
+	if viper.IsSet("development.watcher.poll_interval") {
+		config.Development.Watcher.PollInterval = viper.GetDuration("development.watcher.poll_interval")
+	}
+	if viper.IsSet("development.watcher.max_poll_interval") {
+		config.Development.Watcher.MaxPollInterval = viper.GetDuration("development.watcher.max_poll_interval")
+	}
 
 	// Handle preview settings
 	if viper.IsSet("preview.auto_props") {
@@ -899,6 +979,18 @@ func applyOverrides(config *Config) {
 	if viper.IsSet("monitoring.alerts_enabled") {
 		config.Monitoring.AlertsEnabled = viper.GetBool("monitoring.alerts_enabled")
 	}
+	if viper.IsSet("monitoring.tracing.enabled") {
+		config.Monitoring.Tracing.Enabled = viper.GetBool("monitoring.tracing.enabled")
+	}
+	if viper.IsSet("monitoring.tracing.otlp_endpoint") {
+		config.Monitoring.Tracing.OTLPEndpoint = viper.GetString("monitoring.tracing.otlp_endpoint")
+	}
+	if viper.IsSet("monitoring.tracing.file") {
+		config.Monitoring.Tracing.File = viper.GetString("monitoring.tracing.file")
+	}
+	if viper.IsSet("monitoring.tracing.max_traces") {
+		config.Monitoring.Tracing.MaxTraces = viper.GetInt("monitoring.tracing.max_traces")
+	}
 }
 
 // Load reads configuration from all available sources and returns a fully populated Config struct.
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -125,6 +126,7 @@ func TestConfigStructure(t *testing.T) {
 	assert.Equal(t, []string{"**/*.templ"}, config.Build.Watch)
 	assert.Equal(t, []string{"node_modules", ".git"}, config.Build.Ignore)
 	assert.Equal(t, ".templar/cache", config.Build.CacheDir)
+	assert.Equal(t, int64(256<<20), config.Build.CacheMaxSize)
 
 	// Test PreviewConfig
 	assert.Equal(t, "auto", config.Preview.MockData)
@@ -223,10 +225,11 @@ func TestLoadDefaults(t *testing.T) {
 			config: Config{},
 			expected: Config{
 				Build: BuildConfig{
-					Command:  "templ generate",
-					Watch:    []string{"**/*.templ"},
-					Ignore:   []string{"node_modules", ".git"},
-					CacheDir: ".templar/cache",
+					Command:      "templ generate",
+					Watch:        []string{"**/*.templ"},
+					Ignore:       []string{"node_modules", ".git"},
+					CacheDir:     ".templar/cache",
+					CacheMaxSize: 256 << 20,
 				},
 				Server: ServerConfig{
 					Auth: AuthConfig{
@@ -248,6 +251,12 @@ func TestLoadDefaults(t *testing.T) {
 					HotReload:    true,
 					CSSInjection: true,
 					ErrorOverlay: true,
+					EditorURL:    "vscode://file/{file}:{line}:{column}",
+					Watcher: WatcherConfig{
+						Backend:         "auto",
+						PollInterval:    500 * time.Millisecond,
+						MaxPollInterval: 5 * time.Second,
+					},
 				},
 				Plugins: PluginsConfig{
 					DiscoveryPaths:   []string{"./plugins", "~/.templar/plugins"},
@@ -275,10 +284,11 @@ func TestLoadDefaults(t *testing.T) {
 			},
 			expected: Config{
 				Build: BuildConfig{
-					Command:  "custom build command", // Preserved
-					Watch:    []string{"**/*.templ"},
-					Ignore:   []string{"node_modules", ".git"},
-					CacheDir: ".templar/cache",
+					Command:      "custom build command", // Preserved
+					Watch:        []string{"**/*.templ"},
+					Ignore:       []string{"node_modules", ".git"},
+					CacheDir:     ".templar/cache",
+					CacheMaxSize: 256 << 20,
 				},
 				Server: ServerConfig{
 					Auth: AuthConfig{
@@ -300,6 +310,12 @@ func TestLoadDefaults(t *testing.T) {
 					HotReload:    true,
 					CSSInjection: true,
 					ErrorOverlay: true,
+					EditorURL:    "vscode://file/{file}:{line}:{column}",
+					Watcher: WatcherConfig{
+						Backend:         "auto",
+						PollInterval:    500 * time.Millisecond,
+						MaxPollInterval: 5 * time.Second,
+					},
 				},
 				Plugins: PluginsConfig{
 					DiscoveryPaths:   []string{"./plugins", "~/.templar/plugins"},
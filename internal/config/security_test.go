@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestValidateServerConfig_Security tests server configuration security validation
@@ -255,6 +256,69 @@ func TestValidateComponentsConfig_Security(t *testing.T) {
 	}
 }
 
+// TestValidateDevelopmentConfig_Security tests editor URL validation
+func TestValidateDevelopmentConfig_Security(t *testing.T) {
+	tests := []struct {
+		name        string
+		editorURL   string
+		expectError bool
+		errorType   string
+	}{
+		{name: "vscode url", editorURL: "vscode://file/{file}:{line}:{column}"},
+		{name: "jetbrains url", editorURL: "idea://open?file={file}&line={line}"},
+		{name: "no editor url", editorURL: ""},
+		{name: "relative url", editorURL: "{file}:{line}", expectError: true, errorType: "absolute url"},
+		{name: "javascript url", editorURL: "javascript:alert('{file}')", expectError: true, errorType: "not allowed"},
+		{name: "data url", editorURL: "DATA:text/html,{file}", expectError: true, errorType: "not allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewConfigValidator()
+			validator.validateDevelopment(&DevelopmentConfig{EditorURL: tt.editorURL})
+
+			if tt.expectError {
+				require.Len(t, validator.errors, 1)
+				assert.Contains(t, strings.ToLower(validator.errors[0].Error()), tt.errorType)
+			} else {
+				assert.Empty(t, validator.errors)
+			}
+		})
+	}
+}
+
+// TestValidateTracingConfig tests tracing exporter validation
+func TestValidateTracingConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		tracing     TracingConfig
+		expectError bool
+		errorType   string
+	}{
+		{name: "disabled", tracing: TracingConfig{}},
+		{name: "otlp collector", tracing: TracingConfig{Enabled: true, OTLPEndpoint: "http://localhost:4318"}},
+		{name: "trace file", tracing: TracingConfig{Enabled: true, File: "./logs/traces.jsonl"}},
+		{name: "otlp without scheme", tracing: TracingConfig{OTLPEndpoint: "localhost:4318"}, expectError: true, errorType: "http or https url"},
+		{name: "otlp grpc scheme", tracing: TracingConfig{OTLPEndpoint: "grpc://localhost:4317"}, expectError: true, errorType: "http or https url"},
+		{name: "trace file traversal", tracing: TracingConfig{File: "../../etc/traces"}, expectError: true, errorType: "path"},
+		{name: "negative max traces", tracing: TracingConfig{MaxTraces: -1}, expectError: true, errorType: "negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewConfigValidator()
+			validator.validateMonitoring(&MonitoringConfig{LogLevel: "info", LogFormat: "json", Tracing: tt.tracing})
+
+			if tt.expectError {
+				require.Len(t, validator.errors, 1)
+				assert.Contains(t, strings.ToLower(validator.errors[0].Error()), tt.errorType)
+			} else {
+				assert.Empty(t, validator.errors)
+			}
+		})
+	}
+}
+
 // TestValidatePath_Security tests path validation security
 func TestValidatePath_Security(t *testing.T) {
 	tests := []struct {
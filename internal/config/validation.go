@@ -4,6 +4,7 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"path/filepath"
 	"strings"
 )
@@ -73,6 +74,7 @@ func (cv *ConfigValidator) ValidateAll(config *Config) error {
 	cv.validateServer(&config.Server)
 	cv.validateBuild(&config.Build)
 	cv.validateComponents(&config.Components)
+	cv.validateDevelopment(&config.Development)
 	cv.validatePlugins(&config.Plugins)
 	cv.validateMonitoring(&config.Monitoring)
 	cv.validateProduction(&config.Production)
@@ -146,6 +148,9 @@ func (cv *ConfigValidator) validateBuild(config *BuildConfig) {
 			cv.addError("build.cache_dir", err)
 		}
 	}
+	if config.CacheMaxSize < 0 {
+		cv.addError("build.cache_max_size", fmt.Errorf("cache size limit cannot be negative"))
+	}
 
 	// Validate build command (basic security check)
 	if config.Command != "" {
@@ -186,6 +191,31 @@ func (cv *ConfigValidator) validateComponents(config *ComponentsConfig) {
 	}
 }
 
+// validateDevelopment validates development configuration
+func (cv *ConfigValidator) validateDevelopment(config *DevelopmentConfig) {
+	// The editor URL is linked from preview pages, so it must not be able to
+	// run script in them
+	if config.EditorURL != "" {
+		editorURL, err := url.Parse(config.EditorURL)
+		if err != nil || editorURL.Scheme == "" {
+			cv.addError("development.editor_url", fmt.Errorf("editor URL '%s' must be an absolute URL such as vscode://file/{file}:{line}:{column}", config.EditorURL))
+		} else if cv.contains([]string{"javascript", "data", "vbscript"}, strings.ToLower(editorURL.Scheme)) {
+			cv.addError("development.editor_url", fmt.Errorf("editor URL scheme '%s' is not allowed", editorURL.Scheme))
+		}
+	}
+
+	watcher := config.Watcher
+	if watcher.Backend != "" && !cv.contains([]string{"auto", "fsnotify", "poll"}, watcher.Backend) {
+		cv.addError("development.watcher.backend", fmt.Errorf("watcher backend '%s' must be auto, fsnotify or poll", watcher.Backend))
+	}
+	if watcher.PollInterval < 0 {
+		cv.addError("development.watcher.poll_interval", fmt.Errorf("poll interval cannot be negative"))
+	}
+	if watcher.MaxPollInterval > 0 && watcher.MaxPollInterval < watcher.PollInterval {
+		cv.addError("development.watcher.max_poll_interval", fmt.Errorf("max poll interval %v is shorter than the poll interval %v", watcher.MaxPollInterval, watcher.PollInterval))
+	}
+}
+
 // validatePlugins validates plugins configuration
 func (cv *ConfigValidator) validatePlugins(config *PluginsConfig) {
 	// Validate discovery paths
@@ -228,6 +258,22 @@ func (cv *ConfigValidator) validateMonitoring(config *MonitoringConfig) {
 			cv.addError("monitoring.metrics_path", err)
 		}
 	}
+
+	tracing := config.Tracing
+	if tracing.OTLPEndpoint != "" {
+		endpoint, err := url.Parse(tracing.OTLPEndpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			cv.addError("monitoring.tracing.otlp_endpoint", fmt.Errorf("OTLP endpoint '%s' must be an http or https URL such as http://localhost:4318", tracing.OTLPEndpoint))
+		}
+	}
+	if tracing.File != "" {
+		if err := cv.validatePath(tracing.File); err != nil {
+			cv.addError("monitoring.tracing.file", err)
+		}
+	}
+	if tracing.MaxTraces < 0 {
+		cv.addError("monitoring.tracing.max_traces", fmt.Errorf("max traces cannot be negative"))
+	}
 }
 
 // validateProduction validates production configuration
@@ -481,9 +527,9 @@ func validateComponentsConfigDetails(config *ComponentsConfig, result *Validatio
 
 func validatePreviewConfigDetails(config *PreviewConfig, result *ValidationResult) {
 	// Basic preview validation
-	if config.MockData != "auto" && config.MockData != "none" && config.MockData != "" {
+	if dir := config.FixturesDir(); dir != "" {
 		// Validate as path
-		if err := validatePath(config.MockData); err != nil {
+		if err := validatePath(dir); err != nil {
 			result.Errors = append(result.Errors, ValidationError{
 				Field:   "preview.mock_data",
 				Value:   config.MockData,
@@ -495,11 +541,11 @@ func validatePreviewConfigDetails(config *PreviewConfig, result *ValidationResul
 
 func validateDevelopmentConfigDetails(config *DevelopmentConfig, result *ValidationResult) {
 	// No specific errors for development config, just warnings
-	if config.HotReload && config.StatePreservation {
+	if !config.HotReload && config.StatePreservation {
 		result.Warnings = append(result.Warnings, ValidationError{
 			Field:   "development",
-			Value:   "hot_reload + state_preservation",
-			Message: "state preservation with hot reload may cause unexpected behavior",
+			Value:   "state_preservation without hot_reload",
+			Message: "state preservation only applies to hot reloaded previews",
 		})
 	}
 }
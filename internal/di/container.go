@@ -418,7 +418,11 @@ func (c *ServiceContainer) registerCoreServices() error {
 
 	// Register FileWatcher
 	c.RegisterSingleton("watcher", func(resolver DependencyResolver) (interface{}, error) {
-		return watcher.NewFileWatcher(300 * time.Millisecond)
+		return watcher.NewFileWatcherWithOptions(300*time.Millisecond, watcher.Options{
+			Backend:         c.config.Development.Watcher.Backend,
+			PollInterval:    c.config.Development.Watcher.PollInterval,
+			MaxPollInterval: c.config.Development.Watcher.MaxPollInterval,
+		})
 	}).WithTag("core")
 
 	// Register PreviewServer with dependency injection
@@ -0,0 +1,193 @@
+// Package docs builds a static documentation site for the components in a
+// registry. Every exported templ component gets a page with its doc
+// comment, a prop table, rendered examples of its stories and the
+// components it uses and is used by. Pages are written as HTML with a
+// client-side search, and as Markdown or MDX for docs portals that render
+// their own pages.
+package docs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/renderer"
+)
+
+// Format is an output format of the docs site
+type Format string
+
+const (
+	// FormatHTML writes a navigable HTML site with search
+	FormatHTML Format = "html"
+	// FormatMarkdown writes a Markdown page per component
+	FormatMarkdown Format = "md"
+	// FormatMDX writes an MDX page per component
+	FormatMDX Format = "mdx"
+)
+
+// Options configures a docs site
+type Options struct {
+	// Title is the name of the site shown in page titles and navigation
+	Title string
+	// Formats lists the formats to write, HTML and Markdown when empty
+	Formats []Format
+	// BaseURL prefixes the example frame URLs embedded in Markdown and MDX
+	// pages, for portals serving the frames from elsewhere. Frames are
+	// linked relative to the pages when empty.
+	BaseURL string
+	// Stylesheets are linked from example frames, so rendered components
+	// are styled as in the application
+	Stylesheets []string
+}
+
+// Result lists what a Generate call wrote
+type Result struct {
+	// Files are the written files
+	Files []string
+	// Components is the number of documented components
+	Components int
+	// Warnings describe examples that failed to render and props whose
+	// types could not be resolved
+	Warnings []string
+}
+
+// Generator writes the docs site of a registry's components
+type Generator struct {
+	registry  *registry.ComponentRegistry
+	outputDir string
+	options   Options
+	// renderer renders the examples; examples are listed without a
+	// rendering when it is nil
+	renderer *renderer.ComponentRenderer
+	result   *Result
+}
+
+// NewGenerator creates a generator writing the docs of the components in
+// reg to outputDir
+func NewGenerator(reg *registry.ComponentRegistry, outputDir string, options Options) *Generator {
+	if options.Title == "" {
+		options.Title = "Components"
+	}
+	if len(options.Formats) == 0 {
+		options.Formats = []Format{FormatHTML, FormatMarkdown}
+	}
+	return &Generator{registry: reg, outputDir: outputDir, options: options}
+}
+
+// SetRenderer sets the renderer used to render component examples
+func (g *Generator) SetRenderer(componentRenderer *renderer.ComponentRenderer) {
+	g.renderer = componentRenderer
+}
+
+// ParseFormats parses a list of format names
+func ParseFormats(names []string) ([]Format, error) {
+	formats := make([]Format, 0, len(names))
+	for _, name := range names {
+		switch format := Format(strings.ToLower(strings.TrimSpace(name))); format {
+		case FormatHTML, FormatMarkdown, FormatMDX:
+			formats = append(formats, format)
+		case "markdown":
+			formats = append(formats, FormatMarkdown)
+		default:
+			return nil, fmt.Errorf("unsupported docs format %q (supported: html, md, mdx)", name)
+		}
+	}
+	return formats, nil
+}
+
+// Generate writes the docs site
+func (g *Generator) Generate(ctx context.Context) (*Result, error) {
+	g.result = &Result{}
+	site := g.buildSite(ctx)
+	g.result.Components = len(site.pages)
+
+	if err := os.MkdirAll(g.outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, page := range site.pages {
+		if err := g.renderExamples(page); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, format := range g.options.Formats {
+		var err error
+		switch format {
+		case FormatHTML:
+			err = g.writeHTML(site)
+		case FormatMarkdown, FormatMDX:
+			err = g.writeMarkdown(site, format)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := g.writeSearchIndex(site); err != nil {
+		return nil, err
+	}
+
+	return g.result, nil
+}
+
+// writeFile writes a file below the output directory and records it
+func (g *Generator) writeFile(relPath string, content []byte) error {
+	path := filepath.Join(g.outputDir, filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", relPath, err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", relPath, err)
+	}
+	g.result.Files = append(g.result.Files, path)
+	return nil
+}
+
+func (g *Generator) warn(format string, args ...interface{}) {
+	g.result.Warnings = append(g.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// searchEntry is a component in the search index
+type searchEntry struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Package     string   `json:"package"`
+	Description string   `json:"description,omitempty"`
+	Props       []string `json:"props,omitempty"`
+	URL         string   `json:"url"`
+}
+
+// searchIndex lists the documented components for search
+func searchIndex(site *site) []searchEntry {
+	entries := make([]searchEntry, 0, len(site.pages))
+	for _, page := range site.pages {
+		props := make([]string, len(page.Props))
+		for i, prop := range page.Props {
+			props[i] = prop.Name
+		}
+		entries = append(entries, searchEntry{
+			ID:          page.ID,
+			Name:        page.Name,
+			Package:     page.Package,
+			Description: page.Summary,
+			Props:       props,
+			URL:         page.htmlPath(),
+		})
+	}
+	return entries
+}
+
+// writeSearchIndex writes the search index as JSON for other tools
+func (g *Generator) writeSearchIndex(site *site) error {
+	data, err := json.MarshalIndent(searchIndex(site), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal search index: %w", err)
+	}
+	return g.writeFile("search-index.json", data)
+}
@@ -0,0 +1,223 @@
+package docs
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/renderer"
+	"github.com/conneroisu/templar/internal/scanner"
+)
+
+var testProject = map[string]string{
+	"go.mod": "module example.com/app\n\ngo 1.24\n",
+	"models/models.go": `package models
+
+type Size string
+
+const (
+	SizeSmall Size = "sm"
+	SizeLarge Size = "lg"
+)
+
+type Link struct {
+	Href  string ` + "`json:\"href\"`" + `
+	Label string ` + "`json:\"label\"`" + `
+}
+`,
+	"components/nav.templ": `package components
+
+import "example.com/app/models"
+
+// Nav renders {links} as a <nav> element.
+//
+// Use it once per page.
+templ Nav(links []models.Link, size models.Size, footer *string) {
+	<nav>
+		for _, link := range links {
+			@Item(link)
+		}
+	</nav>
+}
+
+// Item renders one link
+templ Item(link models.Link) {
+	<a href={ templ.SafeURL(link.Href) }>{ link.Label }</a>
+}
+`,
+	"components/nav.stories.yaml": `components:
+  Nav:
+    - name: Large
+      description: Navigation at the large size
+      props:
+        size: SizeLarge
+`,
+}
+
+// generateDocs scans the test project and writes its docs without
+// rendering examples
+func generateDocs(t *testing.T, formats ...Format) (string, *Result) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range testProject {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	t.Chdir(dir)
+
+	reg := registry.NewComponentRegistry()
+	require.NoError(t, scanner.NewComponentScanner(reg).ScanDirectory("components"))
+
+	generator := NewGenerator(reg, "site", Options{Title: "UI", Formats: formats})
+	result, err := generator.Generate(context.Background())
+	require.NoError(t, err)
+	return filepath.Join(dir, "site"), result
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestGenerate_HTML(t *testing.T) {
+	out, result := generateDocs(t, FormatHTML)
+
+	assert.Equal(t, 2, result.Components)
+	assert.Empty(t, result.Warnings)
+	for _, name := range []string{"index.html", "docs.css", "search.js", "search-index.json",
+		"components/components.nav.html", "components/components.item.html"} {
+		assert.FileExists(t, filepath.Join(out, name))
+	}
+	assert.NoDirExists(t, filepath.Join(out, "markdown"))
+	assert.NoDirExists(t, filepath.Join(out, "frames"), "examples are not rendered without a renderer")
+
+	nav := readFile(t, filepath.Join(out, "components", "components.nav.html"))
+	assert.Contains(t, nav, "<p>Nav renders {links} as a &lt;nav&gt; element.</p>")
+	assert.Contains(t, nav, "<p>Use it once per page.</p>")
+	assert.Contains(t, nav, "templ Nav(links []models.Link, size models.Size, footer *string)")
+	assert.Contains(t, nav, `<td><code>models.Size</code><div class="values"><code>&#34;sm&#34;</code> | <code>&#34;lg&#34;</code></div></td>`)
+	assert.Contains(t, nav, "<h3>Large</h3>")
+	assert.Contains(t, nav, `<a href="components.item.html">Item</a>`)
+	assert.Contains(t, nav, `<a href="../components/components.nav.html" aria-current="page">Nav</a>`)
+
+	item := readFile(t, filepath.Join(out, "components", "components.item.html"))
+	assert.Contains(t, item, `<h2 id="used-by">Used by</h2>`)
+	assert.Contains(t, item, `<a href="components.nav.html">Nav</a>`)
+
+	var index []searchEntry
+	require.NoError(t, json.Unmarshal([]byte(readFile(t, filepath.Join(out, "search-index.json"))), &index))
+	require.Len(t, index, 2)
+	assert.Equal(t, "Nav", index[1].Name)
+	assert.Equal(t, "Nav renders {links} as a <nav> element.", index[1].Description)
+	assert.Equal(t, []string{"links", "size", "footer"}, index[1].Props)
+	assert.Equal(t, "components/components.nav.html", index[1].URL)
+}
+
+func TestGenerate_Markdown(t *testing.T) {
+	out, _ := generateDocs(t, FormatMarkdown, FormatMDX)
+
+	md := readFile(t, filepath.Join(out, "markdown", "components.nav.md"))
+	assert.Contains(t, md, "title: \"Nav\"\n")
+	assert.Contains(t, md, "Nav renders {links} as a &lt;nav&gt; element.\n")
+	assert.Contains(t, md, "| `size` | `models.Size` (`\"sm\"`, `\"lg\"`) | Yes | `\"\"` |  |\n")
+	assert.Contains(t, md, "| `footer` | `*string` | No | `nil` |  |\n")
+	assert.Contains(t, md, "```json\n{\n  \"size\": \"SizeLarge\"\n}\n```")
+	assert.Contains(t, md, "- [Item](./components.item.md) `example.com/app/components.Item`")
+	assert.NotContains(t, md, "<iframe")
+
+	mdx := readFile(t, filepath.Join(out, "markdown", "components.nav.mdx"))
+	assert.Contains(t, mdx, `Nav renders \{links\} as a &lt;nav&gt; element.`)
+	assert.Contains(t, mdx, "- [Item](./components.item.mdx)")
+
+	index := readFile(t, filepath.Join(out, "markdown", "index.md"))
+	assert.Contains(t, index, "## example.com/app/components\n")
+	assert.Contains(t, index, "- [Item](./components.item.md): Item renders one link\n")
+}
+
+func TestMarkdownFrames(t *testing.T) {
+	p := &page{Name: "Nav", Slug: "ui.nav", Examples: []*example{
+		{Name: "Default", Slug: "default", Frame: "frames/ui.nav/default.html", Height: 160},
+		{Name: "Broken", Slug: "broken", Error: "exit status 1"},
+	}}
+
+	g := NewGenerator(nil, "", Options{})
+	md := g.markdownPage(p, ".md", false)
+	assert.Contains(t, md, `<iframe src="../frames/ui.nav/default.html" title="Nav - Default" width="100%" height="160" loading="lazy"></iframe>`)
+	assert.Contains(t, md, "> Failed to render: exit status 1")
+
+	g = NewGenerator(nil, "", Options{BaseURL: "https://ui.example.com/docs/"})
+	md = g.markdownPage(p, ".md", false)
+	assert.Contains(t, md, `<iframe src="https://ui.example.com/docs/frames/ui.nav/default.html"`)
+}
+
+func TestBuildSiteSkipsWrapper(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range testProject {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	wrapper := filepath.Join("layouts", "preview.templ")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "layouts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, wrapper), []byte(`package layouts
+
+templ Preview(title string) {
+	<main>{ children... }</main>
+}
+`), 0644))
+	t.Chdir(dir)
+
+	reg := registry.NewComponentRegistry()
+	componentScanner := scanner.NewComponentScanner(reg)
+	require.NoError(t, componentScanner.ScanDirectory("components"))
+	require.NoError(t, componentScanner.ScanFile(wrapper))
+
+	componentRenderer := renderer.NewComponentRenderer(reg)
+	defer componentRenderer.Close()
+	componentRenderer.SetWrapper("./" + wrapper)
+
+	generator := NewGenerator(reg, "site", Options{})
+	generator.SetRenderer(componentRenderer)
+	site := generator.buildSite(context.Background())
+
+	var names []string
+	for _, page := range site.pages {
+		names = append(names, page.Name)
+	}
+	assert.Equal(t, []string{"Item", "Nav"}, names)
+}
+
+func TestParseFormats(t *testing.T) {
+	formats, err := ParseFormats([]string{"html", " MDX ", "markdown"})
+	require.NoError(t, err)
+	assert.Equal(t, []Format{FormatHTML, FormatMDX, FormatMarkdown}, formats)
+
+	_, err = ParseFormats([]string{"pdf"})
+	assert.Error(t, err)
+}
+
+func TestZeroValue(t *testing.T) {
+	tests := map[string]string{
+		"string":          `""`,
+		"int64":           "0",
+		"bool":            "false",
+		"*User":           "nil",
+		"[]string":        "nil",
+		"map[string]int":  "nil",
+		"templ.Component": "nil",
+		"[2]int":          "[2]int{}",
+		"models.User":     "models.User{}",
+	}
+	for goType, expected := range tests {
+		assert.Equal(t, expected, zeroValue(goType, nil), goType)
+	}
+}
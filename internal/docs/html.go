@@ -0,0 +1,268 @@
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmlpkg "html"
+	"html/template"
+	"strings"
+)
+
+// htmlData is the data of an HTML page
+type htmlData struct {
+	Title string
+	// Root is the relative path from the page to the site root
+	Root   string
+	Groups []group
+	// Page is the documented component, nil on the index page
+	Page *page
+}
+
+var siteTemplate = template.Must(template.New("site").Funcs(template.FuncMap{"paragraphs": paragraphs}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <title>{{if .Page}}{{.Page.Name}} - {{end}}{{.Title}}</title>
+  <link rel="stylesheet" href="{{.Root}}docs.css">
+</head>
+<body>
+  <nav class="sidebar" aria-label="Components">
+    <a class="site-title" href="{{.Root}}index.html">{{.Title}}</a>
+    <input id="search" type="search" placeholder="Search components" aria-label="Search components" autocomplete="off">
+    <ul id="search-results" hidden></ul>
+    <div id="component-nav">
+    {{- range .Groups}}
+      <h2>{{.Package}}</h2>
+      <ul>
+      {{- range .Pages}}
+        <li><a href="{{$.Root}}components/{{.Slug}}.html"{{if and $.Page (eq $.Page.Slug .Slug)}} aria-current="page"{{end}}>{{.Name}}</a></li>
+      {{- end}}
+      </ul>
+    {{- end}}
+    </div>
+  </nav>
+  <main>
+  {{- if .Page}}{{template "component" .}}{{else}}{{template "index" .}}{{end}}
+  </main>
+  <script>window.templarDocsRoot = "{{.Root}}";</script>
+  <script src="{{.Root}}search.js"></script>
+</body>
+</html>
+{{define "index"}}
+    <h1>{{.Title}}</h1>
+    {{- range .Groups}}
+    <section>
+      <h2>{{.Package}}</h2>
+      <dl class="component-list">
+      {{- range .Pages}}
+        <dt><a href="components/{{.Slug}}.html">{{.Name}}</a></dt>
+        <dd>{{.Summary}}</dd>
+      {{- end}}
+      </dl>
+    </section>
+    {{- end}}
+{{end}}
+{{define "component"}}
+  {{- with .Page}}
+    <h1>{{.Name}}</h1>
+    <p class="meta"><code>{{.ID}}</code>{{if .Source}} &middot; <code>{{.Source}}</code>{{end}}</p>
+    {{- range paragraphs .Description}}
+    <p>{{.}}</p>
+    {{- end}}
+    <pre class="signature"><code>{{.Signature}}</code></pre>
+
+    <h2 id="props">Props</h2>
+    {{- if .Props}}
+    <table class="props">
+      <thead><tr><th>Name</th><th>Type</th><th>Required</th><th>Default</th><th>Description</th></tr></thead>
+      <tbody>
+      {{- range .Props}}
+        <tr>
+          <td><code>{{.Name}}</code></td>
+          <td><code>{{.Type}}</code>{{if .Values}}<div class="values">{{range $i, $v := .Values}}{{if $i}} | {{end}}<code>{{$v}}</code>{{end}}</div>{{end}}</td>
+          <td>{{if .Required}}Yes{{else}}No{{end}}</td>
+          <td><code>{{.Default}}</code></td>
+          <td>{{.Description}}</td>
+        </tr>
+      {{- end}}
+      </tbody>
+    </table>
+    {{- else}}
+    <p>This component takes no props.</p>
+    {{- end}}
+
+    {{- if .Examples}}
+    <h2 id="examples">Examples</h2>
+    {{- range .Examples}}
+    <section class="example" id="example-{{.Slug}}">
+      <h3>{{.Name}}</h3>
+      {{- range paragraphs .Description}}
+      <p>{{.}}</p>
+      {{- end}}
+      {{- if .Frame}}
+      <iframe src="{{$.Root}}{{.Frame}}" title="{{$.Page.Name}} - {{.Name}}" height="{{.Height}}" loading="lazy"></iframe>
+      {{- else if .Error}}
+      <div class="render-error">Failed to render: {{.Error}}</div>
+      {{- end}}
+      {{- if .Props}}
+      <details><summary>Props</summary><pre><code>{{.Props}}</code></pre></details>
+      {{- end}}
+      {{- range paragraphs .Notes}}
+      <p class="notes">{{.}}</p>
+      {{- end}}
+    </section>
+    {{- end}}
+    {{- end}}
+
+    <h2 id="uses">Uses</h2>
+    {{template "links" .Uses}}
+    <h2 id="used-by">Used by</h2>
+    {{template "links" .UsedBy}}
+  {{- end}}
+{{end}}
+{{define "links"}}
+  {{- if .}}
+    <ul class="links">
+    {{- range .}}
+      <li>{{if .Slug}}<a href="{{.Slug}}.html">{{.Name}}</a>{{else}}{{.Name}}{{end}} <code>{{.ID}}</code></li>
+    {{- end}}
+    </ul>
+  {{- else}}
+    <p>None</p>
+  {{- end}}
+{{end}}
+`))
+
+// writeHTML writes the HTML pages, their stylesheet and the search script
+func (g *Generator) writeHTML(site *site) error {
+	data := htmlData{Title: g.options.Title, Groups: site.groups}
+
+	var buf bytes.Buffer
+	if err := siteTemplate.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to render index page: %w", err)
+	}
+	if err := g.writeFile("index.html", buf.Bytes()); err != nil {
+		return err
+	}
+
+	data.Root = "../"
+	for _, page := range site.pages {
+		data.Page = page
+		buf.Reset()
+		if err := siteTemplate.Execute(&buf, data); err != nil {
+			return fmt.Errorf("failed to render page for %s: %w", page.ID, err)
+		}
+		if err := g.writeFile(page.htmlPath(), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if err := g.writeFile("docs.css", []byte(stylesheet)); err != nil {
+		return err
+	}
+
+	// The index is loaded as a script rather than fetched, so search also
+	// works when the pages are opened from disk
+	index, err := json.Marshal(searchIndex(site))
+	if err != nil {
+		return fmt.Errorf("failed to marshal search index: %w", err)
+	}
+	script := "window.templarDocsIndex = " + string(index) + ";\n" + searchScript
+	return g.writeFile("search.js", []byte(script))
+}
+
+// framePage wraps a rendered example in a page of its own, for embedding in
+// an iframe
+func (g *Generator) framePage(title, body string) string {
+	var page strings.Builder
+	page.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n")
+	page.WriteString("  <meta charset=\"UTF-8\">\n")
+	page.WriteString("  <meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\">\n")
+	fmt.Fprintf(&page, "  <title>%s</title>\n", htmlpkg.EscapeString(title))
+	for _, stylesheet := range g.options.Stylesheets {
+		fmt.Fprintf(&page, "  <link rel=\"stylesheet\" href=\"%s\">\n", htmlpkg.EscapeString(stylesheet))
+	}
+	page.WriteString("</head>\n<body>\n")
+	page.WriteString(body)
+	page.WriteString("\n</body>\n</html>\n")
+	return page.String()
+}
+
+const stylesheet = `* { box-sizing: border-box; }
+body { margin: 0; display: flex; font-family: system-ui, -apple-system, sans-serif; color: #1f2937; line-height: 1.5; }
+.sidebar { position: sticky; top: 0; width: 260px; height: 100vh; overflow-y: auto; padding: 1rem; border-right: 1px solid #e5e7eb; background: #f9fafb; flex-shrink: 0; }
+.sidebar h2 { margin: 1rem 0 0.25rem; font-size: 0.75rem; color: #6b7280; text-transform: uppercase; word-break: break-all; }
+.sidebar ul { list-style: none; margin: 0; padding: 0; }
+.sidebar a { display: block; padding: 0.125rem 0.5rem; border-radius: 4px; color: inherit; text-decoration: none; }
+.sidebar a:hover, .sidebar a[aria-current="page"] { background: #e5e7eb; }
+.site-title { font-weight: 600; font-size: 1.125rem; margin-bottom: 0.75rem; }
+#search { width: 100%; padding: 0.375rem 0.5rem; border: 1px solid #d1d5db; border-radius: 4px; font: inherit; }
+#search-results { margin: 0.5rem 0; }
+#search-results small { display: block; color: #6b7280; }
+main { flex: 1; min-width: 0; max-width: 960px; padding: 2rem; }
+.meta { color: #6b7280; }
+pre { padding: 0.75rem; overflow-x: auto; background: #f3f4f6; border-radius: 4px; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 0.875em; }
+table { width: 100%; border-collapse: collapse; }
+th, td { padding: 0.5rem; border-bottom: 1px solid #e5e7eb; text-align: left; vertical-align: top; }
+.values { margin-top: 0.25rem; color: #6b7280; }
+.example { margin-bottom: 2rem; }
+.example iframe { width: 100%; border: 1px solid #e5e7eb; border-radius: 4px; background: #fff; }
+.render-error { padding: 0.75rem; color: #991b1b; background: #fef2f2; border: 1px solid #fecaca; border-radius: 4px; }
+.notes { color: #4b5563; }
+.component-list dt { margin-top: 0.75rem; font-weight: 600; }
+.component-list dd { margin: 0; color: #4b5563; }
+`
+
+const searchScript = `(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("search-results");
+  var nav = document.getElementById("component-nav");
+  if (!input) return;
+
+  function matches(entry, terms) {
+    var text = [entry.name, entry.package, entry.description || "", (entry.props || []).join(" ")].join(" ").toLowerCase();
+    return terms.every(function (term) { return text.indexOf(term) !== -1; });
+  }
+
+  input.addEventListener("input", function () {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.innerHTML = "";
+    results.hidden = terms.length === 0;
+    nav.hidden = terms.length > 0;
+    if (terms.length === 0) return;
+
+    var found = window.templarDocsIndex.filter(function (entry) { return matches(entry, terms); });
+    found.sort(function (a, b) {
+      var aName = a.name.toLowerCase().indexOf(terms[0]) === 0 ? 0 : 1;
+      var bName = b.name.toLowerCase().indexOf(terms[0]) === 0 ? 0 : 1;
+      return aName - bName || a.name.localeCompare(b.name);
+    });
+    found.forEach(function (entry) {
+      var item = document.createElement("li");
+      var link = document.createElement("a");
+      link.href = window.templarDocsRoot + entry.url;
+      link.textContent = entry.name;
+      var detail = document.createElement("small");
+      detail.textContent = entry.description || entry.package;
+      link.appendChild(detail);
+      item.appendChild(link);
+      results.appendChild(item);
+    });
+    if (found.length === 0) {
+      var empty = document.createElement("li");
+      empty.textContent = "No components found";
+      results.appendChild(empty);
+    }
+  });
+
+  input.addEventListener("keydown", function (event) {
+    if (event.key === "Enter") {
+      var first = results.querySelector("a");
+      if (first) window.location.href = first.href;
+    }
+  });
+})();
+`
@@ -0,0 +1,176 @@
+package docs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// markdownDir holds the Markdown and MDX pages, next to the HTML site so
+// that example frames resolve relative to both
+const markdownDir = "markdown"
+
+// writeMarkdown writes a Markdown or MDX page per component and an index
+func (g *Generator) writeMarkdown(site *site, format Format) error {
+	ext := "." + string(format)
+	mdx := format == FormatMDX
+
+	var index strings.Builder
+	frontMatter(&index, g.options.Title, "")
+	fmt.Fprintf(&index, "# %s\n", text(g.options.Title, mdx))
+	for _, group := range site.groups {
+		fmt.Fprintf(&index, "\n## %s\n\n", text(group.Package, mdx))
+		for _, page := range group.Pages {
+			fmt.Fprintf(&index, "- [%s](./%s%s)", text(page.Name, mdx), page.Slug, ext)
+			if page.Summary != "" {
+				fmt.Fprintf(&index, ": %s", text(page.Summary, mdx))
+			}
+			index.WriteString("\n")
+		}
+	}
+	if err := g.writeFile(markdownDir+"/index"+ext, []byte(index.String())); err != nil {
+		return err
+	}
+
+	for _, page := range site.pages {
+		content := g.markdownPage(page, ext, mdx)
+		if err := g.writeFile(markdownDir+"/"+page.Slug+ext, []byte(content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownPage renders the page of a component. MDX pages are Markdown with
+// text escaped so that it is not read as JSX.
+func (g *Generator) markdownPage(page *page, ext string, mdx bool) string {
+	var md strings.Builder
+	frontMatter(&md, page.Name, page.Summary)
+
+	fmt.Fprintf(&md, "# %s\n\n", text(page.Name, mdx))
+	md.WriteString(code(page.ID))
+	if page.Source != "" {
+		md.WriteString(" · " + code(page.Source))
+	}
+	md.WriteString("\n\n")
+	for _, paragraph := range paragraphs(page.Description) {
+		md.WriteString(text(paragraph, mdx) + "\n\n")
+	}
+	fmt.Fprintf(&md, "```templ\n%s\n```\n\n", page.Signature)
+
+	md.WriteString("## Props\n\n")
+	if len(page.Props) == 0 {
+		md.WriteString("This component takes no props.\n\n")
+	} else {
+		md.WriteString("| Name | Type | Required | Default | Description |\n")
+		md.WriteString("| --- | --- | --- | --- | --- |\n")
+		for _, prop := range page.Props {
+			propType := code(prop.Type)
+			if len(prop.Values) > 0 {
+				values := make([]string, len(prop.Values))
+				for i, value := range prop.Values {
+					values[i] = code(value)
+				}
+				propType += " (" + strings.Join(values, ", ") + ")"
+			}
+			required := "No"
+			if prop.Required {
+				required = "Yes"
+			}
+			fmt.Fprintf(&md, "| %s | %s | %s | %s | %s |\n",
+				cell(code(prop.Name)), cell(propType), required, cell(code(prop.Default)), cell(text(prop.Description, mdx)))
+		}
+		md.WriteString("\n")
+	}
+
+	if len(page.Examples) > 0 {
+		md.WriteString("## Examples\n\n")
+		for _, example := range page.Examples {
+			fmt.Fprintf(&md, "### %s\n\n", text(example.Name, mdx))
+			for _, paragraph := range paragraphs(example.Description) {
+				md.WriteString(text(paragraph, mdx) + "\n\n")
+			}
+			if example.Frame != "" {
+				// width and height attributes are valid in both HTML and JSX
+				fmt.Fprintf(&md, "<iframe src=%s title=%s width=\"100%%\" height=\"%d\" loading=\"lazy\"></iframe>\n\n",
+					strconv.Quote(g.frameURL(example.Frame)), strconv.Quote(page.Name+" - "+example.Name), example.Height)
+			} else if example.Error != "" {
+				fmt.Fprintf(&md, "> Failed to render: %s\n\n", text(example.Error, mdx))
+			}
+			if example.Props != "" {
+				fmt.Fprintf(&md, "```json\n%s\n```\n\n", example.Props)
+			}
+			for _, paragraph := range paragraphs(example.Notes) {
+				md.WriteString(text(paragraph, mdx) + "\n\n")
+			}
+		}
+	}
+
+	md.WriteString("## Uses\n\n")
+	writeMarkdownLinks(&md, page.Uses, ext, mdx)
+	md.WriteString("## Used by\n\n")
+	writeMarkdownLinks(&md, page.UsedBy, ext, mdx)
+
+	return strings.TrimSuffix(md.String(), "\n")
+}
+
+// frameURL locates an example frame from the Markdown pages
+func (g *Generator) frameURL(frame string) string {
+	if g.options.BaseURL != "" {
+		return strings.TrimSuffix(g.options.BaseURL, "/") + "/" + frame
+	}
+	return "../" + frame
+}
+
+func writeMarkdownLinks(md *strings.Builder, links []link, ext string, mdx bool) {
+	if len(links) == 0 {
+		md.WriteString("None\n\n")
+		return
+	}
+	for _, l := range links {
+		if l.Slug != "" {
+			fmt.Fprintf(md, "- [%s](./%s%s) %s\n", text(l.Name, mdx), l.Slug, ext, code(l.ID))
+		} else {
+			fmt.Fprintf(md, "- %s %s\n", text(l.Name, mdx), code(l.ID))
+		}
+	}
+	md.WriteString("\n")
+}
+
+// frontMatter writes the YAML front matter docs portals read titles from
+func frontMatter(md *strings.Builder, title, description string) {
+	md.WriteString("---\n")
+	fmt.Fprintf(md, "title: %s\n", strconv.Quote(title))
+	if description != "" {
+		fmt.Fprintf(md, "description: %s\n", strconv.Quote(description))
+	}
+	md.WriteString("---\n\n")
+}
+
+// text escapes prose for Markdown, and for MDX also the characters that
+// would start JSX or an expression
+func text(s string, mdx bool) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;")
+	if mdx {
+		replacer = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;", "{", `\{`, "}", `\}`)
+	}
+	return replacer.Replace(s)
+}
+
+// code formats a code span, with a fence long enough for backticks inside
+func code(s string) string {
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+// cell makes text safe inside a table cell
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,456 @@
+package docs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/conneroisu/templar/internal/schema"
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// site is the documented components, grouped by package
+type site struct {
+	pages  []*page
+	groups []group
+	// byID finds pages by component ID
+	byID map[string]*page
+}
+
+// group is the pages of one package
+type group struct {
+	Package string
+	Pages   []*page
+}
+
+// page documents one component
+type page struct {
+	ID          string
+	Name        string
+	Package     string
+	Slug        string
+	Description string
+	// Summary is the first sentence of the description
+	Summary   string
+	Signature string
+	Source    string
+	Props     []prop
+	Examples  []*example
+	Uses      []link
+	UsedBy    []link
+
+	component *types.ComponentInfo
+}
+
+// prop is a row of a prop table
+type prop struct {
+	Name        string
+	Type        string
+	Required    bool
+	Default     string
+	Description string
+	// Values lists the allowed values of enum types
+	Values []string
+}
+
+// example is a rendered story, or the component's default preview
+type example struct {
+	Name        string
+	Slug        string
+	Description string
+	Notes       string
+	// Props holds the props the example renders with, as indented JSON
+	Props string
+	// Frame is the path of the example's frame page, empty when it was not
+	// rendered
+	Frame  string
+	Height int
+	Error  string
+
+	story *types.ComponentExample
+}
+
+// link refers to another component, with the slug of its page when it is
+// documented
+type link struct {
+	ID   string
+	Name string
+	Slug string
+}
+
+// defaultFrameHeight is the height of example frames without a viewport
+const defaultFrameHeight = 160
+
+func (p *page) htmlPath() string {
+	return "components/" + p.Slug + ".html"
+}
+
+func (e *example) framePath(p *page) string {
+	return "frames/" + p.Slug + "/" + e.Slug + ".html"
+}
+
+// buildSite collects the exported templ components of the registry into
+// pages, resolving their prop types with go/packages. The preview wrapper
+// file is scanned only to render the examples and is left out.
+func (g *Generator) buildSite(ctx context.Context) *site {
+	wrapperFile := g.wrapperFile()
+
+	var components []*types.ComponentInfo
+	for _, component := range g.registry.GetAll() {
+		if wrapperFile != "" && sameFile(component.FilePath, wrapperFile) {
+			continue
+		}
+		if component.IsExported && (component.Kind == "" || component.Kind == types.ComponentKindTempl) {
+			components = append(components, component)
+		}
+	}
+	sort.Slice(components, func(i, j int) bool {
+		if packageOf(components[i]) != packageOf(components[j]) {
+			return packageOf(components[i]) < packageOf(components[j])
+		}
+		return components[i].ID < components[j].ID
+	})
+
+	schemas := schema.Load(ctx, components)
+
+	s := &site{byID: make(map[string]*page)}
+	slugs := make(map[string]bool)
+	for _, component := range components {
+		p := &page{
+			ID:          component.ID,
+			Name:        component.Name,
+			Package:     packageOf(component),
+			Slug:        uniqueSlug(slugs, slugify(component.Package)+"."+slugify(component.Name)),
+			Description: strings.TrimSpace(component.Description),
+			Signature:   signature(component),
+			component:   component,
+		}
+		p.Summary = summary(p.Description)
+		if component.FilePath != "" {
+			p.Source = component.FilePath
+			if component.Line > 0 {
+				p.Source += ":" + strconv.Itoa(component.Line)
+			}
+		}
+
+		propSchema := schemas.Components[component.ID]
+		if err := schemas.Errors[component.ID]; err != nil {
+			g.warn("prop types of %s: %v", component.ID, err)
+		}
+		p.Props = props(component, propSchema, schemas.Defs)
+		p.Examples = examples(component)
+
+		s.pages = append(s.pages, p)
+		s.byID[p.ID] = p
+	}
+
+	for _, p := range s.pages {
+		for _, dep := range p.component.Dependencies {
+			p.Uses = append(p.Uses, s.link(dep))
+		}
+		for _, dependent := range g.registry.GetDependents(p.ID) {
+			p.UsedBy = append(p.UsedBy, s.link(dependent.ID))
+		}
+		sortLinks(p.Uses)
+		sortLinks(p.UsedBy)
+	}
+
+	for _, p := range s.pages {
+		if n := len(s.groups); n > 0 && s.groups[n-1].Package == p.Package {
+			s.groups[n-1].Pages = append(s.groups[n-1].Pages, p)
+			continue
+		}
+		s.groups = append(s.groups, group{Package: p.Package, Pages: []*page{p}})
+	}
+
+	return s
+}
+
+// wrapperFile returns the .templ file of the renderer's preview wrapper, or
+// an empty string when there is none
+func (g *Generator) wrapperFile() string {
+	if g.renderer == nil {
+		return ""
+	}
+	return g.renderer.WrapperFile()
+}
+
+// sameFile reports whether two paths name the same file
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// packageOf names the package of a component by import path, falling back
+// to the package name
+func packageOf(component *types.ComponentInfo) string {
+	if component.ImportPath != "" {
+		return component.ImportPath
+	}
+	return component.Package
+}
+
+// link refers to a component by ID
+func (s *site) link(id string) link {
+	if p, ok := s.byID[id]; ok {
+		return link{ID: id, Name: p.Name, Slug: p.Slug}
+	}
+	name := id
+	if i := strings.LastIndex(id, "."); i >= 0 {
+		name = id[i+1:]
+	}
+	return link{ID: id, Name: name}
+}
+
+func sortLinks(links []link) {
+	sort.Slice(links, func(i, j int) bool { return links[i].ID < links[j].ID })
+}
+
+// props builds the prop table of a component. Types are described by the
+// resolved schema when there is one, and by the declared Go type otherwise.
+func props(component *types.ComponentInfo, propSchema *schema.Schema, defs map[string]*schema.Schema) []prop {
+	rows := make([]prop, 0, len(component.Parameters))
+	for _, param := range component.Parameters {
+		if param.Name == "" || param.Name == "_" {
+			continue
+		}
+		row := prop{
+			Name:        param.Name,
+			Type:        param.Type,
+			Required:    !param.Optional,
+			Description: param.Description,
+		}
+
+		var resolved *schema.Schema
+		if propSchema != nil {
+			resolved, _ = propSchema.Properties.Lookup(param.Name)
+			row.Required = false
+			for _, name := range propSchema.Required {
+				if name == param.Name {
+					row.Required = true
+				}
+			}
+		}
+		if name, ok := resolved.DefName(); ok {
+			resolved = defs[name]
+		}
+		if resolved != nil {
+			for _, value := range resolved.Enum {
+				encoded, _ := json.Marshal(value)
+				row.Values = append(row.Values, string(encoded))
+			}
+		}
+
+		if param.Default != nil {
+			row.Default = fmt.Sprintf("%v", param.Default)
+		} else {
+			row.Default = zeroValue(param.Type, resolved)
+		}
+
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// zeroValue returns the Go zero value of a parameter type, which is what
+// the component receives when the prop is left out
+func zeroValue(goType string, resolved *schema.Schema) string {
+	goType = strings.TrimSpace(strings.TrimPrefix(goType, "..."))
+	switch {
+	case strings.HasPrefix(goType, "*"), strings.HasPrefix(goType, "[]"), strings.HasPrefix(goType, "map["),
+		strings.HasPrefix(goType, "func"), strings.HasPrefix(goType, "chan"), strings.HasPrefix(goType, "<-chan"),
+		strings.HasPrefix(goType, "interface"), goType == "any", goType == "error", goType == "templ.Component":
+		return "nil"
+	case strings.HasPrefix(goType, "["):
+		return goType + "{}"
+	}
+
+	switch goType {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64",
+		"uintptr", "byte", "rune", "float32", "float64", "complex64", "complex128":
+		return "0"
+	}
+
+	if resolved != nil {
+		switch resolved.Type {
+		case "string":
+			return `""`
+		case "boolean":
+			return "false"
+		case "integer", "number":
+			return "0"
+		}
+		if resolved.Type == "" && resolved.Ref == "" && resolved.Properties == nil {
+			// Interfaces and type parameters
+			return "nil"
+		}
+	}
+	return goType + "{}"
+}
+
+// examples lists a component's default preview followed by its stories
+func examples(component *types.ComponentInfo) []*example {
+	var list []*example
+	slugs := make(map[string]bool)
+	if component.IsRenderable {
+		list = append(list, &example{Name: "Default", Slug: uniqueSlug(slugs, "default"), Height: defaultFrameHeight})
+	}
+	for i := range component.Examples {
+		story := &component.Examples[i]
+		e := &example{
+			Name:        story.Name,
+			Slug:        uniqueSlug(slugs, slugify(story.Name)),
+			Description: story.Description,
+			Notes:       story.Notes,
+			Height:      defaultFrameHeight,
+			story:       story,
+		}
+		if story.Viewport.Height > 0 {
+			e.Height = story.Viewport.Height
+		}
+		if len(story.Props) > 0 {
+			e.Props = indentJSON(story.Props)
+		}
+		list = append(list, e)
+	}
+	return list
+}
+
+// renderExamples renders the examples of a page into frame pages
+func (g *Generator) renderExamples(p *page) error {
+	if g.renderer == nil || !p.component.IsRenderable {
+		return nil
+	}
+
+	for _, e := range p.Examples {
+		var props map[string]interface{}
+		var err error
+		if e.story == nil {
+			props, err = g.renderer.PreviewProps(p.component)
+		} else {
+			props, err = g.renderer.StoryProps(p.component, *e.story)
+		}
+		if err != nil {
+			e.Error = err.Error()
+			g.warn("failed to load props for %s - %s: %v", p.ID, e.Name, err)
+			continue
+		}
+		if len(props) > 0 {
+			e.Props = indentJSON(props)
+		}
+
+		html, err := g.renderer.RenderComponentWithProps(p.ID, props)
+		if err == nil && e.story != nil {
+			html, err = g.renderer.PresentStory(p.component, *e.story, html)
+		}
+		if err != nil {
+			e.Error = err.Error()
+			g.warn("failed to render %s - %s: %v", p.ID, e.Name, err)
+			continue
+		}
+
+		frame, ok, err := g.renderer.RenderWrapperDocument(p.Name, html)
+		if err != nil {
+			g.warn("preview wrapper for %s - %s: %v", p.ID, e.Name, err)
+		}
+		if !ok {
+			frame = g.framePage(p.Name+" - "+e.Name, html)
+		}
+		if err := g.writeFile(e.framePath(p), []byte(frame)); err != nil {
+			return err
+		}
+		e.Frame = e.framePath(p)
+	}
+	return nil
+}
+
+// signature formats a component's templ declaration
+func signature(component *types.ComponentInfo) string {
+	var sig strings.Builder
+	sig.WriteString("templ ")
+	if component.Receiver != nil {
+		fmt.Fprintf(&sig, "(%s %s) ", component.Receiver.Name, component.Receiver.Type)
+	}
+	sig.WriteString(component.Name)
+	if len(component.TypeParameters) > 0 {
+		sig.WriteString("[" + joinParams(component.TypeParameters) + "]")
+	}
+	sig.WriteString("(" + joinParams(component.Parameters) + ")")
+	return sig.String()
+}
+
+func joinParams(params []types.ParameterInfo) string {
+	parts := make([]string, len(params))
+	for i, param := range params {
+		parts[i] = param.Name + " " + param.Type
+	}
+	return strings.Join(parts, ", ")
+}
+
+// summary returns the first sentence of a description
+func summary(description string) string {
+	paragraph, _, _ := strings.Cut(description, "\n\n")
+	paragraph = strings.Join(strings.Fields(paragraph), " ")
+	if i := strings.Index(paragraph, ". "); i >= 0 {
+		return paragraph[:i+1]
+	}
+	return paragraph
+}
+
+// paragraphs splits a doc comment into paragraphs
+func paragraphs(text string) []string {
+	var result []string
+	for _, paragraph := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			result = append(result, paragraph)
+		}
+	}
+	return result
+}
+
+func indentJSON(value interface{}) string {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// slugify turns a name into a lowercase file name
+func slugify(name string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			slug.WriteRune(r)
+			dash = false
+		} else if !dash && slug.Len() > 0 {
+			slug.WriteByte('-')
+			dash = true
+		}
+	}
+	result := strings.TrimSuffix(slug.String(), "-")
+	if result == "" {
+		return "component"
+	}
+	return result
+}
+
+// uniqueSlug returns slug, or slug with a numeric suffix when it is taken
+func uniqueSlug(taken map[string]bool, slug string) string {
+	candidate := slug
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", slug, i)
+	}
+	taken[candidate] = true
+	return candidate
+}
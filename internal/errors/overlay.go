@@ -0,0 +1,275 @@
+package errors
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// templErrorPattern matches the source positions templ records next to
+	// each expression in the code it generates
+	templErrorPattern = regexp.MustCompile("templ\\.Error\\{Err: [^,]+, FileName: `([^`]*)`, Line: (\\d+), Col: (\\d+)\\}")
+	// templFuncPattern matches the function generated for a templ component
+	templFuncPattern = regexp.MustCompile(`^func (\w+)\(`)
+)
+
+// OverlayOptions configures the error overlay shown in the browser
+type OverlayOptions struct {
+	// EditorURL is the link opening an error's location in an editor, such
+	// as vscode://file/{file}:{line}:{column}. {file} is replaced with the
+	// absolute path, {line} and {column} with the position. Without it the
+	// locations are not linked.
+	EditorURL string
+	// ContextRadius is the number of source lines shown around each error
+	ContextRadius int
+}
+
+// SourceLocation returns the location an error should be reported at.
+// Errors in code generated by templ are mapped back to the .templ file and
+// line the code was generated from; other locations are returned unchanged.
+func (pe *ParsedError) SourceLocation() (file string, line, column int) {
+	if strings.HasSuffix(pe.File, "_templ.go") && pe.Line > 0 {
+		if file, line, column, ok := mapTemplLocation(pe.File, pe.Line); ok {
+			return file, line, column
+		}
+	}
+	return pe.File, pe.Line, pe.Column
+}
+
+// mapTemplLocation maps a line of a generated _templ.go file to its .templ
+// source. Expressions are mapped through the position templ records after
+// them, Go code templ copied verbatim through its text, and any other line
+// to the declaration of the component it belongs to.
+func mapTemplLocation(goFile string, line int) (string, int, int, bool) {
+	generated, err := readLines(goFile)
+	if err != nil || line > len(generated) {
+		return "", 0, 0, false
+	}
+	templFile := strings.TrimSuffix(goFile, "_templ.go") + ".templ"
+	source, err := readLines(templFile)
+	if err != nil {
+		return "", 0, 0, false
+	}
+
+	// Checking an expression's error follows it within a few lines. Line 1,
+	// column 0 marks expressions templ has no position for.
+	for i := line - 1; i < min(len(generated), line+3); i++ {
+		matches := templErrorPattern.FindStringSubmatch(generated[i])
+		if matches == nil {
+			continue
+		}
+		templLine, _ := strconv.Atoi(matches[2])
+		templCol, _ := strconv.Atoi(matches[3])
+		if templLine > 1 || templCol > 0 {
+			return templFile, templLine, templCol, true
+		}
+		break
+	}
+
+	if code := strings.TrimSpace(generated[line-1]); len(code) > 1 {
+		for i, sourceLine := range source {
+			if strings.TrimSpace(sourceLine) == code {
+				return templFile, i + 1, strings.Index(sourceLine, code) + 1, true
+			}
+		}
+	}
+
+	for i := line - 1; i >= 0; i-- {
+		matches := templFuncPattern.FindStringSubmatch(generated[i])
+		if matches == nil {
+			continue
+		}
+		declaration := "templ " + matches[1] + "("
+		for j, sourceLine := range source {
+			if strings.HasPrefix(strings.TrimSpace(sourceLine), declaration) {
+				return templFile, j + 1, 0, true
+			}
+		}
+		break
+	}
+
+	return "", 0, 0, false
+}
+
+// FormatErrorOverlay renders build errors as the overlay preview pages show
+// over the component. Each error is listed at its source location, with the
+// surrounding code and a link opening it in the editor, followed by hints on
+// fixing the build.
+func FormatErrorOverlay(errors []*ParsedError, opts OverlayOptions) string {
+	if len(errors) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString(overlayStyles)
+	builder.WriteString(`<div class="templar-overlay" role="dialog" aria-label="Build errors"><div class="panel">`)
+	builder.WriteString(`<header><h2>`)
+	if len(errors) == 1 {
+		builder.WriteString("Build failed with 1 error")
+	} else {
+		builder.WriteString(fmt.Sprintf("Build failed with %d errors", len(errors)))
+	}
+	builder.WriteString(`</h2><button type="button" class="close" data-templar-close aria-label="Close">&times;</button></header>`)
+
+	var output []string
+	for _, err := range errors {
+		output = append(output, err.RawError)
+		writeOverlayEntry(&builder, err, opts)
+	}
+
+	builder.WriteString(`<section class="hints"><h3>Hints</h3><ul>`)
+	for _, suggestion := range BuildFailureError(strings.Join(output, "\n"), nil) {
+		builder.WriteString(`<li><strong>` + html.EscapeString(suggestion.Title) + `</strong> ` + html.EscapeString(suggestion.Description))
+		if suggestion.Command != "" {
+			builder.WriteString(` <code>` + html.EscapeString(suggestion.Command) + `</code>`)
+		}
+		if suggestion.Example != "" {
+			builder.WriteString(`<pre>` + html.EscapeString(suggestion.Example) + `</pre>`)
+		}
+		builder.WriteString(`</li>`)
+	}
+	builder.WriteString(`</ul></section></div></div>`)
+
+	return builder.String()
+}
+
+// writeOverlayEntry renders a single error of the overlay
+func writeOverlayEntry(builder *strings.Builder, err *ParsedError, opts OverlayOptions) {
+	builder.WriteString(fmt.Sprintf(`<section class="entry %s">`, strings.ToLower(err.severityString())))
+	builder.WriteString(fmt.Sprintf(`<div class="kind">[%s] %s</div>`, err.severityString(), err.typeString()))
+	builder.WriteString(`<div class="message">` + html.EscapeString(err.Message) + `</div>`)
+
+	file, line, column := err.SourceLocation()
+	if file != "" {
+		location := html.EscapeString(formatLocation(file, line, column))
+		if link := editorLink(opts.EditorURL, file, line, column); link != "" {
+			builder.WriteString(`<a class="location" href="` + html.EscapeString(link) + `" title="Open in editor">` + location + `</a>`)
+		} else {
+			builder.WriteString(`<span class="location">` + location + `</span>`)
+		}
+		if file != err.File {
+			builder.WriteString(`<span class="generated">generated at ` + html.EscapeString(formatLocation(err.File, err.Line, err.Column)) + `</span>`)
+		}
+
+		if frame := codeFrame(file, line, opts.ContextRadius); len(frame) > 0 {
+			builder.WriteString(`<pre class="frame">`)
+			for _, frameLine := range frame {
+				class := "line"
+				if strings.HasPrefix(frameLine, "→ ") {
+					class = "line current"
+				}
+				builder.WriteString(`<span class="` + class + `">` + html.EscapeString(frameLine) + "</span>\n")
+			}
+			builder.WriteString(`</pre>`)
+		}
+	}
+
+	if err.Suggestion != "" {
+		builder.WriteString(`<div class="suggestion">` + html.EscapeString(err.Suggestion) + `</div>`)
+	}
+	builder.WriteString(`</section>`)
+}
+
+// codeFrame returns the numbered lines of a source file around a line, with
+// the line itself marked by getContextLines
+func codeFrame(file string, line, radius int) []string {
+	lines, err := readLines(file)
+	if err != nil || line < 1 || line > len(lines) {
+		return nil
+	}
+
+	index := line - 1
+	start := max(0, index-radius)
+	end := min(len(lines), index+radius+1)
+	width := len(strconv.Itoa(end))
+
+	numbered := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		numbered = append(numbered, fmt.Sprintf("%*d | %s", width, i+1, lines[i]))
+	}
+	return getContextLines(numbered, index-start, radius)
+}
+
+// editorLink fills an editor URL in with a location
+func editorLink(editorURL, file string, line, column int) string {
+	if editorURL == "" {
+		return ""
+	}
+	if absolute, err := filepath.Abs(file); err == nil {
+		file = absolute
+	}
+
+	segments := strings.Split(filepath.ToSlash(file), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	// Absolute paths start with a slash, which a URL such as
+	// vscode://file/{file} already has
+	path := strings.Join(segments, "/")
+	if strings.Contains(editorURL, "/{file}") {
+		path = strings.TrimPrefix(path, "/")
+	}
+
+	return strings.NewReplacer(
+		"{file}", path,
+		"{line}", strconv.Itoa(max(line, 1)),
+		"{column}", strconv.Itoa(max(column, 1)),
+	).Replace(editorURL)
+}
+
+// formatLocation formats a location as file:line:column
+func formatLocation(file string, line, column int) string {
+	location := file
+	if line > 0 {
+		location += fmt.Sprintf(":%d", line)
+		if column > 0 {
+			location += fmt.Sprintf(":%d", column)
+		}
+	}
+	return location
+}
+
+// readLines reads a file's lines
+func readLines(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n"), nil
+}
+
+// overlayStyles styles the overlay. Preview pages render it in a shadow root,
+// so the rules neither leak into nor are affected by the page's stylesheets.
+const overlayStyles = `<style>
+.templar-overlay { position: fixed; inset: 0; z-index: 2147483647; overflow: auto; padding: 2rem 1rem; background: rgba(15, 23, 42, 0.85); color: #e2e8f0; font: 14px/1.5 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+.panel { max-width: 960px; margin: 0 auto; padding: 1.5rem; border-top: 4px solid #f87171; border-radius: 6px; background: #1e293b; box-shadow: 0 20px 40px rgba(0, 0, 0, 0.4); }
+header { display: flex; align-items: center; justify-content: space-between; }
+h2 { margin: 0; color: #f87171; font-size: 1.25rem; }
+h3 { margin: 0 0 0.5rem; font-size: 1rem; }
+.close { border: 0; background: none; color: #94a3b8; font-size: 1.5rem; line-height: 1; cursor: pointer; }
+.close:hover { color: #f8fafc; }
+.entry { margin-top: 1.25rem; padding-top: 1.25rem; border-top: 1px solid #334155; }
+.kind { color: #f87171; font-weight: bold; }
+.warn .kind { color: #fbbf24; }
+.info .kind { color: #60a5fa; }
+.message { margin: 0.25rem 0; color: #f8fafc; white-space: pre-wrap; }
+.location { color: #7dd3fc; }
+a.location:hover { text-decoration: underline; }
+.generated { margin-left: 0.75rem; color: #64748b; }
+.frame { margin: 0.75rem 0 0; padding: 0.75rem 0; overflow-x: auto; border-radius: 4px; background: #0f172a; tab-size: 4; }
+.line { display: block; padding: 0 0.75rem; color: #94a3b8; }
+.line.current { background: rgba(248, 113, 113, 0.15); color: #fecaca; }
+.suggestion { margin-top: 0.5rem; color: #86efac; }
+.hints { margin-top: 1.5rem; padding-top: 1.25rem; border-top: 1px solid #334155; color: #cbd5e1; }
+.hints ul { margin: 0; padding-left: 1.25rem; }
+.hints li { margin: 0.25rem 0; }
+.hints code, .hints pre { color: #86efac; }
+.hints pre { margin: 0.25rem 0; white-space: pre-wrap; }
+</style>`
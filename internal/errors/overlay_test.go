@@ -0,0 +1,164 @@
+package errors
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const overlayTestTempl = `package components
+
+templ Card(title string) {
+	<div class="card">
+		<h2>{ title }</h2>
+	</div>
+}
+`
+
+const overlayTestGenerated = "// Code generated by templ - DO NOT EDIT.\n" +
+	"\n" +
+	"package components\n" +
+	"\n" +
+	"func Card(title string) templ.Component {\n" +
+	"	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {\n" +
+	"		var templ_7745c5c3_Var2 string\n" +
+	"		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(titel)\n" +
+	"		if templ_7745c5c3_Err != nil {\n" +
+	"			return templ.Error{Err: templ_7745c5c3_Err, FileName: `components/card.templ`, Line: 5, Col: 13}\n" +
+	"		}\n" +
+	"		templ_7745c5c3_Err = undefinedHelper()\n" +
+	"		return nil\n" +
+	"	})\n" +
+	"}\n"
+
+func writeOverlayProject(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "components")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "card.templ"), []byte(overlayTestTempl), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "card_templ.go"), []byte(overlayTestGenerated), 0644))
+	return dir
+}
+
+func TestParsedError_SourceLocation(t *testing.T) {
+	dir := writeOverlayProject(t)
+	generated := filepath.Join(dir, "card_templ.go")
+	source := filepath.Join(dir, "card.templ")
+
+	tests := []struct {
+		name           string
+		err            ParsedError
+		expectedFile   string
+		expectedLine   int
+		expectedColumn int
+	}{
+		{
+			name:           "expression",
+			err:            ParsedError{File: generated, Line: 8, Column: 62},
+			expectedFile:   source,
+			expectedLine:   5,
+			expectedColumn: 13,
+		},
+		{
+			name:           "copied code",
+			err:            ParsedError{File: generated, Line: 3, Column: 1},
+			expectedFile:   source,
+			expectedLine:   1,
+			expectedColumn: 1,
+		},
+		{
+			name:         "generated code",
+			err:          ParsedError{File: generated, Line: 12, Column: 24},
+			expectedFile: source,
+			expectedLine: 3,
+		},
+		{
+			name:           "templ file",
+			err:            ParsedError{File: source, Line: 4, Column: 2},
+			expectedFile:   source,
+			expectedLine:   4,
+			expectedColumn: 2,
+		},
+		{
+			name:           "missing generated file",
+			err:            ParsedError{File: filepath.Join(dir, "missing_templ.go"), Line: 4, Column: 2},
+			expectedFile:   filepath.Join(dir, "missing_templ.go"),
+			expectedLine:   4,
+			expectedColumn: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, line, column := tt.err.SourceLocation()
+			assert.Equal(t, tt.expectedFile, file)
+			assert.Equal(t, tt.expectedLine, line)
+			assert.Equal(t, tt.expectedColumn, column)
+		})
+	}
+}
+
+func TestFormatErrorOverlay(t *testing.T) {
+	dir := writeOverlayProject(t)
+
+	html := FormatErrorOverlay([]*ParsedError{
+		{
+			Type:       BuildErrorTypeGoCompile,
+			Severity:   ErrorSeverityError,
+			File:       filepath.Join(dir, "card_templ.go"),
+			Line:       8,
+			Column:     62,
+			Message:    "undefined: titel",
+			Suggestion: "Check the variable name",
+			RawError:   "card_templ.go:8:62: undefined: titel",
+		},
+		{
+			Type:     BuildErrorTypeTemplSyntax,
+			Severity: ErrorSeverityWarning,
+			File:     filepath.Join(dir, "card.templ"),
+			Line:     4,
+			Message:  "unexpected <script>",
+		},
+	}, OverlayOptions{EditorURL: "vscode://file/{file}:{line}:{column}", ContextRadius: 1})
+
+	assert.Contains(t, html, "Build failed with 2 errors")
+	assert.Contains(t, html, "undefined: titel")
+	assert.Contains(t, html, "unexpected &lt;script&gt;")
+	assert.Contains(t, html, "Check the variable name")
+	assert.Contains(t, html, `class="entry warn"`)
+
+	// The generated location is mapped to the templ source and linked
+	source := filepath.ToSlash(filepath.Join(dir, "card.templ"))
+	assert.Contains(t, html, `href="vscode://file/`+strings.TrimPrefix(source, "/")+`:5:13"`)
+	assert.Contains(t, html, "generated at "+filepath.Join(dir, "card_templ.go")+":8:62")
+
+	// The code frame shows the surrounding lines, marking the error's
+	assert.Contains(t, html, `<span class="line">  4 | 	&lt;div class=&#34;card&#34;&gt;</span>`)
+	assert.Contains(t, html, `<span class="line current">→ 5 | 		&lt;h2&gt;{ title }&lt;/h2&gt;</span>`)
+	assert.Contains(t, html, `<span class="line">  6 | 	&lt;/div&gt;</span>`)
+
+	// Hints come from the build output
+	assert.Contains(t, html, "Check imports and types")
+
+	// Without an editor URL locations are not linked
+	html = FormatErrorOverlay([]*ParsedError{{File: "card.templ", Line: 1, Message: "oops"}}, OverlayOptions{})
+	assert.Contains(t, html, "Build failed with 1 error")
+	assert.Contains(t, html, `<span class="location">card.templ:1</span>`)
+	assert.NotContains(t, html, "href=")
+
+	assert.Empty(t, FormatErrorOverlay(nil, OverlayOptions{}))
+}
+
+func TestEditorLink(t *testing.T) {
+	file := filepath.Join(string(filepath.Separator), "src", "my app", "card.templ")
+
+	assert.Equal(t, "vscode://file/src/my%20app/card.templ:5:1",
+		editorLink("vscode://file/{file}:{line}:{column}", file, 5, 0))
+	assert.Equal(t, "idea://open?file=/src/my%20app/card.templ&line=5",
+		editorLink("idea://open?file={file}&line={line}", file, 5, 3))
+	assert.Empty(t, editorLink("", file, 5, 3))
+}
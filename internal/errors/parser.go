@@ -79,7 +79,7 @@ func (ep *ErrorParser) ParseError(output string) []*ParsedError {
 		// Try templ patterns first
 		if err := ep.tryParseWithPatterns(line, ep.templPatterns); err != nil {
 			// Add context lines
-			err.Context = ep.getContextLines(lines, i, 2)
+			err.Context = getContextLines(lines, i, 2)
 			errors = append(errors, err)
 			continue
 		}
@@ -87,7 +87,7 @@ func (ep *ErrorParser) ParseError(output string) []*ParsedError {
 		// Try Go patterns
 		if err := ep.tryParseWithPatterns(line, ep.goPatterns); err != nil {
 			// Add context lines
-			err.Context = ep.getContextLines(lines, i, 2)
+			err.Context = getContextLines(lines, i, 2)
 			errors = append(errors, err)
 			continue
 		}
@@ -100,7 +100,7 @@ func (ep *ErrorParser) ParseError(output string) []*ParsedError {
 				Severity: ErrorSeverityError,
 				Message:  line,
 				RawError: line,
-				Context:  ep.getContextLines(lines, i, 1),
+				Context:  getContextLines(lines, i, 1),
 			})
 		}
 	}
@@ -129,7 +129,9 @@ func (ep *ErrorParser) tryParseWithPatterns(line string, patterns []errorPattern
 	return nil
 }
 
-func (ep *ErrorParser) getContextLines(lines []string, index int, radius int) []string {
+// getContextLines returns the lines within radius of a line, marking the line
+// itself with an arrow
+func getContextLines(lines []string, index int, radius int) []string {
 	start := max(0, index-radius)
 	end := min(len(lines), index+radius+1)
 
@@ -58,6 +58,10 @@ type ChangeEvent struct {
 	Path    string
 	ModTime time.Time
 	Size    int64
+
+	// DetectedAt is when the watcher first saw the change, before
+	// debouncing, so that traces of the change start there
+	DetectedAt time.Time
 }
 
 // ChangeHandlerFunc is the concrete change handler function type
@@ -113,9 +117,13 @@ type ComponentRegistry interface {
 	// Register adds or updates a component in the registry
 	Register(component *types.ComponentInfo)
 
-	// Get retrieves a component by name
+	// Get retrieves a component by fully qualified ID or unambiguous name
 	Get(name string) (*types.ComponentInfo, bool)
 
+	// Resolve retrieves a component by fully qualified ID or unambiguous name,
+	// returning an error that explains why the lookup failed
+	Resolve(name string) (*types.ComponentInfo, error)
+
 	// GetAll returns all registered components
 	GetAll() []*types.ComponentInfo
 
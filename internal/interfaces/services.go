@@ -290,6 +290,7 @@ const (
 	MetricFormatPrometheus MetricFormat = iota
 	MetricFormatJSON
 	MetricFormatInfluxDB
+	MetricFormatOpenMetrics
 )
 
 // HistogramStats represents statistics from a histogram metric
@@ -547,6 +548,8 @@ func (mf MetricFormat) String() string {
 		return "json"
 	case MetricFormatInfluxDB:
 		return "influxdb"
+	case MetricFormatOpenMetrics:
+		return "openmetrics"
 	default:
 		return "unknown"
 	}
@@ -0,0 +1,414 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"html"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	templparser "github.com/a-h/templ/parser/v2"
+
+	"github.com/conneroisu/templar/internal/accessibility"
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// diagnosticSource labels the diagnostics published by templar
+const diagnosticSource = "templar"
+
+// positionAttribute marks each element of the HTML skeleton a template is
+// checked as with the offset of its tag name in the templ source
+const positionAttribute = "data-templar-pos"
+
+// spreadAttribute marks elements whose attributes are spread from an
+// expression. Any attribute may be among them, so they are not checked.
+const spreadAttribute = "data-templar-spread"
+
+var positionPattern = regexp.MustCompile(positionAttribute + `="(\d+)"`)
+
+// voidElements have no end tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// pageRules are accessibility rules that only make sense for a whole page,
+// not for a component rendered as part of one
+var pageRules = []string{
+	"missing-heading-structure",
+	"missing-skip-link",
+	"missing-title-element",
+}
+
+// diagnose checks a document, returning templar's diagnostics for it. Syntax
+// errors are left to templ.
+func (s *Server) diagnose(ctx context.Context, uri, text string) []Diagnostic {
+	file, err := templparser.ParseString(text)
+	if err != nil {
+		return nil
+	}
+	s.docs.parsed(uri, text, file)
+
+	docPath := uriToPath(uri)
+	imports := fileImports(file)
+
+	diagnostics := make([]Diagnostic, 0)
+	for _, node := range file.Nodes {
+		template, ok := node.(*templparser.HTMLTemplate)
+		if !ok {
+			continue
+		}
+		diagnostics = append(diagnostics, s.checkCalls(text, docPath, imports, template)...)
+		diagnostics = append(diagnostics, s.checkComponent(text, docPath, template)...)
+		diagnostics = append(diagnostics, s.checkAccessibility(ctx, text, template)...)
+	}
+	return diagnostics
+}
+
+// checkCalls reports calls to components with the wrong number of arguments
+func (s *Server) checkCalls(text, docPath string, imports map[string]string, template *templparser.HTMLTemplate) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	check := func(expression templparser.Expression) {
+		call, ok := parseCall(expression.Value)
+		if !ok || call.spread {
+			return
+		}
+		if call.qualifier != "" {
+			if _, imported := imports[call.qualifier]; !imported {
+				// A method call on a value such as @c.Render()
+				return
+			}
+		}
+		component := s.resolveCall(docPath, imports, call.qualifier, call.name)
+		if component == nil {
+			return
+		}
+
+		want := len(component.Parameters)
+		variadic := want > 0 && strings.HasPrefix(component.Parameters[want-1].Type, "...")
+		if call.args == want || variadic && call.args >= want-1 {
+			return
+		}
+
+		expected := strconv.Itoa(want)
+		if variadic {
+			expected = "at least " + strconv.Itoa(want-1)
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    expressionRange(text, expression),
+			Severity: SeverityError,
+			Code:     "argument-count",
+			Source:   diagnosticSource,
+			Message: fmt.Sprintf("%s takes %s argument%s but is called with %d: %s",
+				component.Name, expected, plural(want), call.args, signature(component)),
+		})
+	}
+
+	walkNodes(template.Children, func(node templparser.Node) {
+		switch n := node.(type) {
+		case *templparser.TemplElementExpression:
+			check(n.Expression)
+		case *templparser.CallTemplateExpression:
+			check(n.Expression)
+		}
+	})
+	return diagnostics
+}
+
+// checkComponent reports the validator's findings and the dependency
+// cycles of the component a template declares
+func (s *Server) checkComponent(text, docPath string, template *templparser.HTMLTemplate) []Diagnostic {
+	component := s.declaredComponent(docPath, template)
+	if component == nil {
+		return nil
+	}
+	declaration := expressionRange(text, template.Expression)
+
+	var diagnostics []Diagnostic
+	if s.options.Validate != nil {
+		errs, warnings := s.options.Validate(component)
+		for _, message := range errs {
+			diagnostics = append(diagnostics, Diagnostic{
+				Range: declaration, Severity: SeverityError, Code: "validate", Source: diagnosticSource, Message: message,
+			})
+		}
+		for _, message := range warnings {
+			diagnostics = append(diagnostics, Diagnostic{
+				Range: declaration, Severity: SeverityWarning, Code: "validate", Source: diagnosticSource, Message: message,
+			})
+		}
+	}
+
+	for _, cycle := range s.registry.DetectCircularDependencies() {
+		for _, id := range cycle {
+			if id != component.ID {
+				continue
+			}
+			diagnostics = append(diagnostics, Diagnostic{
+				Range:    declaration,
+				Severity: SeverityError,
+				Code:     "circular-dependency",
+				Source:   diagnosticSource,
+				Message:  "Circular dependency: " + strings.Join(cycle, " -> "),
+			})
+			break
+		}
+	}
+	return diagnostics
+}
+
+// declaredComponent finds the registered component a template declares
+func (s *Server) declaredComponent(docPath string, template *templparser.HTMLTemplate) *types.ComponentInfo {
+	name := templateName(template.Expression.Value)
+	if name == "" {
+		return nil
+	}
+	for _, component := range s.registry.GetAll() {
+		if component.Name != name {
+			continue
+		}
+		if absPath, err := filepath.Abs(component.FilePath); err == nil && absPath == docPath {
+			return component
+		}
+	}
+	return nil
+}
+
+// checkAccessibility runs the accessibility rules over the HTML a template
+// renders. Expressions are replaced by placeholders, so only the static
+// structure of the template is checked.
+func (s *Server) checkAccessibility(ctx context.Context, text string, template *templparser.HTMLTemplate) []Diagnostic {
+	if s.accessibility == nil {
+		return nil
+	}
+
+	var skeleton strings.Builder
+	writeSkeleton(&skeleton, template.Children)
+	if skeleton.Len() == 0 {
+		return nil
+	}
+
+	report, err := s.accessibility.Analyze(ctx, skeleton.String(), accessibility.AuditConfiguration{
+		WCAGLevel:    accessibility.WCAGLevelAA,
+		ExcludeRules: pageRules,
+	})
+	if err != nil {
+		return nil
+	}
+
+	var diagnostics []Diagnostic
+	for _, violation := range report.Violations {
+		// Violations without a position are about the elements the HTML
+		// parser adds around the component
+		tag := openingTag(violation.Context.HTMLContext)
+		match := positionPattern.FindStringSubmatch(tag)
+		if match == nil || strings.Contains(tag, spreadAttribute) {
+			continue
+		}
+		start, err := strconv.Atoi(match[1])
+		if err != nil || start > len(text) {
+			continue
+		}
+
+		end := scanTagName(text, start)
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    Range{Start: positionAt(text, start), End: positionAt(text, end)},
+			Severity: accessibilitySeverity(violation.Severity),
+			Code:     violation.Rule,
+			Source:   diagnosticSource,
+			Message:  violation.Message + ": " + violation.Description,
+		})
+	}
+	return diagnostics
+}
+
+// writeSkeleton writes the HTML a template renders with every expression
+// replaced by a placeholder. Each element carries its source offset.
+func writeSkeleton(b *strings.Builder, nodes []templparser.Node) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *templparser.Element:
+			fmt.Fprintf(b, "<%s %s=\"%d\"", n.Name, positionAttribute, n.NameRange.From.Index)
+			writeAttributes(b, n.Attributes)
+			b.WriteString(">")
+			writeSkeleton(b, n.Children)
+			if !voidElements[n.Name] {
+				fmt.Fprintf(b, "</%s>", n.Name)
+			}
+		case *templparser.Text:
+			b.WriteString(n.Value)
+		case *templparser.Whitespace:
+			b.WriteString(" ")
+		case *templparser.StringExpression:
+			b.WriteString("text")
+		case *templparser.CallTemplateExpression, *templparser.ChildrenExpression:
+			b.WriteString("content")
+		case *templparser.TemplElementExpression:
+			b.WriteString("content")
+			writeSkeleton(b, n.Children)
+		case *templparser.IfExpression:
+			writeSkeleton(b, n.Then)
+			for _, elseIf := range n.ElseIfs {
+				writeSkeleton(b, elseIf.Then)
+			}
+			writeSkeleton(b, n.Else)
+		case *templparser.SwitchExpression:
+			for _, c := range n.Cases {
+				writeSkeleton(b, c.Children)
+			}
+		case *templparser.ForExpression:
+			writeSkeleton(b, n.Children)
+		}
+	}
+}
+
+// writeAttributes writes the attributes of a skeleton element. Attributes
+// set by expressions get placeholder values, and both branches of
+// conditional attributes are included.
+func writeAttributes(b *strings.Builder, attributes []templparser.Attribute) {
+	for _, attribute := range attributes {
+		switch a := attribute.(type) {
+		case *templparser.ConstantAttribute:
+			writeAttribute(b, a.Key, html.EscapeString(a.Value))
+		case *templparser.ExpressionAttribute:
+			writeAttribute(b, a.Key, "value")
+		case *templparser.BoolConstantAttribute:
+			writeAttribute(b, a.Key, "")
+		case *templparser.BoolExpressionAttribute:
+			writeAttribute(b, a.Key, "")
+		case *templparser.SpreadAttributes:
+			b.WriteString(" " + spreadAttribute)
+		case *templparser.ConditionalAttribute:
+			writeAttributes(b, a.Then)
+			writeAttributes(b, a.Else)
+		}
+	}
+}
+
+func writeAttribute(b *strings.Builder, key templparser.AttributeKey, value string) {
+	constant, ok := key.(templparser.ConstantAttributeKey)
+	if !ok || constant.Name == positionAttribute {
+		return
+	}
+	fmt.Fprintf(b, " %s=\"%s\"", constant.Name, value)
+}
+
+// walkNodes calls fn for every node of a template, depth first
+func walkNodes(nodes []templparser.Node, fn func(templparser.Node)) {
+	for _, node := range nodes {
+		fn(node)
+		switch n := node.(type) {
+		case *templparser.Element:
+			walkNodes(n.Children, fn)
+		case *templparser.TemplElementExpression:
+			walkNodes(n.Children, fn)
+		case *templparser.IfExpression:
+			walkNodes(n.Then, fn)
+			for _, elseIf := range n.ElseIfs {
+				walkNodes(elseIf.Then, fn)
+			}
+			walkNodes(n.Else, fn)
+		case *templparser.SwitchExpression:
+			for _, c := range n.Cases {
+				walkNodes(c.Children, fn)
+			}
+		case *templparser.ForExpression:
+			walkNodes(n.Children, fn)
+		}
+	}
+}
+
+// call is a parsed templ call expression such as Child(x), pkg.Child(x)
+// or List[string](items)
+type call struct {
+	qualifier string
+	name      string
+	args      int
+	// spread reports a final argument spread with ..., which makes the
+	// argument count unknown
+	spread bool
+}
+
+func parseCall(expression string) (call, bool) {
+	expr, err := parser.ParseExpr(strings.TrimSpace(expression))
+	if err != nil {
+		return call{}, false
+	}
+
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return call{}, false
+	}
+	result := call{args: len(callExpr.Args), spread: callExpr.Ellipsis.IsValid()}
+
+	fun := callExpr.Fun
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+
+	switch f := fun.(type) {
+	case *ast.Ident:
+		result.name = f.Name
+		return result, true
+	case *ast.SelectorExpr:
+		if pkg, ok := f.X.(*ast.Ident); ok {
+			result.qualifier = pkg.Name
+			result.name = f.Sel.Name
+			return result, true
+		}
+	}
+
+	return call{}, false
+}
+
+// expressionRange converts the source range of a templ expression
+func expressionRange(text string, expression templparser.Expression) Range {
+	return Range{
+		Start: positionAt(text, int(expression.Range.From.Index)),
+		End:   positionAt(text, int(expression.Range.To.Index)),
+	}
+}
+
+// openingTag returns the opening tag of an element's outer HTML
+func openingTag(outerHTML string) string {
+	if end := strings.IndexByte(outerHTML, '>'); end >= 0 {
+		return outerHTML[:end]
+	}
+	return outerHTML
+}
+
+// scanTagName returns the end of the tag name starting at byte offset start
+func scanTagName(text string, start int) int {
+	end := start
+	for end < len(text) && (isIdentByte(text[end]) || text[end] == '-' || text[end] == ':') {
+		end++
+	}
+	return end
+}
+
+// accessibilitySeverity maps a violation severity to a diagnostic severity
+func accessibilitySeverity(severity accessibility.ViolationSeverity) int {
+	switch severity {
+	case accessibility.SeverityError:
+		return SeverityError
+	case accessibility.SeverityWarning:
+		return SeverityWarning
+	default:
+		return SeverityInformation
+	}
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
@@ -0,0 +1,175 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	templparser "github.com/a-h/templ/parser/v2"
+)
+
+// document is an open text document
+type document struct {
+	uri  string
+	text string
+	// file is the last successful parse of the document, kept so imports
+	// still resolve while the text is being edited into a new valid state
+	file *templparser.TemplateFile
+}
+
+// documents tracks the text of the documents open in the editor
+type documents struct {
+	mu   sync.RWMutex
+	docs map[string]*document
+}
+
+func newDocuments() *documents {
+	return &documents{docs: make(map[string]*document)}
+}
+
+// open records an opened document
+func (d *documents) open(item TextDocumentItem) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.docs[item.URI] = &document{uri: item.URI, text: item.Text}
+}
+
+// change applies content changes to an open document
+func (d *documents) change(uri string, changes []TextDocumentContentChangeEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	doc, exists := d.docs[uri]
+	if !exists {
+		return
+	}
+	for _, change := range changes {
+		if change.Range == nil {
+			doc.text = change.Text
+			continue
+		}
+		start := offsetAt(doc.text, change.Range.Start)
+		end := offsetAt(doc.text, change.Range.End)
+		if end < start {
+			start, end = end, start
+		}
+		doc.text = doc.text[:start] + change.Text + doc.text[end:]
+	}
+}
+
+// close forgets a closed document
+func (d *documents) close(uri string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.docs, uri)
+}
+
+// snapshot returns the text and last successful parse of an open document
+func (d *documents) snapshot(uri string) (string, *templparser.TemplateFile, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	doc, exists := d.docs[uri]
+	if !exists {
+		return "", nil, false
+	}
+	return doc.text, doc.file, true
+}
+
+// parsed records the parse of a document's text, unless the document has
+// changed since
+func (d *documents) parsed(uri, text string, file *templparser.TemplateFile) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if doc, exists := d.docs[uri]; exists && doc.text == text {
+		doc.file = file
+	}
+}
+
+// uris lists the open documents
+func (d *documents) uris() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	uris := make([]string, 0, len(d.docs))
+	for uri := range d.docs {
+		uris = append(uris, uri)
+	}
+	return uris
+}
+
+// offsetAt converts an LSP position, counted in UTF-16 code units, to a
+// byte offset in text. Positions past the end of a line or the text are
+// clamped.
+func offsetAt(text string, pos Position) int {
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		next := strings.IndexByte(text[offset:], '\n')
+		if next < 0 {
+			return len(text)
+		}
+		offset += next + 1
+	}
+
+	for units := 0; units < pos.Character && offset < len(text); {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		if r == '\n' {
+			break
+		}
+		units += utf16.RuneLen(r)
+		offset += size
+	}
+	return offset
+}
+
+// positionAt converts a byte offset in text to an LSP position
+func positionAt(text string, offset int) Position {
+	if offset > len(text) {
+		offset = len(text)
+	}
+
+	pos := Position{}
+	lineStart := 0
+	for i := 0; i < offset; i++ {
+		if text[i] == '\n' {
+			pos.Line++
+			lineStart = i + 1
+		}
+	}
+	for _, r := range text[lineStart:offset] {
+		pos.Character += utf16.RuneLen(r)
+	}
+	return pos
+}
+
+// uriToPath converts a file URI to a file path
+func uriToPath(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return ""
+	}
+	path := parsed.Path
+	// file:///C:/dir on Windows
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path)
+}
+
+// pathToURI converts a file path to a file URI
+func pathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,49 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocuments_Change(t *testing.T) {
+	docs := newDocuments()
+	docs.open(TextDocumentItem{URI: "file:///a.templ", Text: "héllo 🌍\nworld"})
+
+	// Positions count UTF-16 code units: the emoji is two of them
+	docs.change("file:///a.templ", []TextDocumentContentChangeEvent{
+		{Range: &Range{Start: Position{Line: 0, Character: 8}, End: Position{Line: 1, Character: 0}}, Text: "!\n"},
+		{Range: &Range{Start: Position{Line: 0, Character: 1}, End: Position{Line: 0, Character: 2}}, Text: "e"},
+	})
+	text, _, found := docs.snapshot("file:///a.templ")
+	assert.True(t, found)
+	assert.Equal(t, "hello 🌍!\nworld", text)
+
+	docs.change("file:///a.templ", []TextDocumentContentChangeEvent{{Text: "replaced"}})
+	text, _, _ = docs.snapshot("file:///a.templ")
+	assert.Equal(t, "replaced", text)
+
+	docs.close("file:///a.templ")
+	_, _, found = docs.snapshot("file:///a.templ")
+	assert.False(t, found)
+}
+
+func TestPositions(t *testing.T) {
+	text := "a🌍b\ncd"
+
+	assert.Equal(t, 0, offsetAt(text, Position{}))
+	assert.Equal(t, 5, offsetAt(text, Position{Line: 0, Character: 3}))
+	assert.Equal(t, 7, offsetAt(text, Position{Line: 1, Character: 0}))
+	assert.Equal(t, 6, offsetAt(text, Position{Line: 0, Character: 99}), "clamped to the end of the line")
+	assert.Equal(t, len(text), offsetAt(text, Position{Line: 5}))
+
+	assert.Equal(t, Position{Line: 0, Character: 3}, positionAt(text, 5))
+	assert.Equal(t, Position{Line: 1, Character: 1}, positionAt(text, 8))
+}
+
+func TestURIs(t *testing.T) {
+	assert.Equal(t, "/src/app/button.templ", uriToPath("file:///src/app/button.templ"))
+	assert.Equal(t, "/src/my app/x.templ", uriToPath("file:///src/my%20app/x.templ"))
+	assert.Equal(t, "", uriToPath("untitled:Untitled-1"))
+	assert.Equal(t, "file:///src/my%20app/x.templ", pathToURI("/src/my app/x.templ"))
+}
@@ -0,0 +1,479 @@
+package lsp
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	templparser "github.com/a-h/templ/parser/v2"
+
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// maxCallLookback bounds how far before the cursor a call is searched for
+const maxCallLookback = 4096
+
+// callSite is an @Component(...) call found around the cursor
+type callSite struct {
+	// qualifier is the package alias of a call such as @ui.Button(), empty
+	// for calls to components in the same package
+	qualifier string
+	// name is the component name
+	name string
+	// nameStart and nameEnd are the byte offsets of the qualified name
+	nameStart int
+	nameEnd   int
+	// inArgs reports whether the cursor is inside the call's argument list
+	inArgs bool
+	// argIndex is the index of the argument under the cursor
+	argIndex int
+}
+
+// findCall finds the @Component call whose name or argument list contains
+// the byte offset
+func findCall(text string, offset int) (callSite, bool) {
+	if offset > len(text) {
+		offset = len(text)
+	}
+	floor := offset - maxCallLookback
+	if floor < 0 {
+		floor = 0
+	}
+
+	// Arguments are Go expressions and can't contain templ calls, so only
+	// the nearest @ that starts a call can enclose the cursor. Earlier
+	// candidates are tried when the nearest @ is inside a string literal.
+	for at := strings.LastIndexByte(text[floor:offset], '@'); at >= 0; at = strings.LastIndexByte(text[floor:floor+at], '@') {
+		site, ok := parseCallAt(text, floor+at, offset)
+		if ok {
+			return site, true
+		}
+	}
+	return callSite{}, false
+}
+
+// parseCallAt parses a call starting with the @ at byte offset at and
+// reports whether the cursor offset is on its name or in its arguments
+func parseCallAt(text string, at, offset int) (callSite, bool) {
+	if at > 0 && isIdentByte(text[at-1]) {
+		// An e-mail address or similar text, not a call
+		return callSite{}, false
+	}
+
+	site := callSite{nameStart: at + 1}
+	end := scanIdent(text, site.nameStart)
+	site.name = text[site.nameStart:end]
+	if end < len(text) && text[end] == '.' && site.name != "" {
+		qualifiedEnd := scanIdent(text, end+1)
+		site.qualifier = site.name
+		site.name = text[end+1 : qualifiedEnd]
+		end = qualifiedEnd
+	}
+	site.nameEnd = end
+
+	if offset <= site.nameEnd {
+		return site, offset >= site.nameStart
+	}
+	if site.name == "" {
+		return callSite{}, false
+	}
+
+	// Skip type arguments of generic components
+	open := site.nameEnd
+	if open < len(text) && text[open] == '[' {
+		closing := strings.IndexByte(text[open:], ']')
+		if closing < 0 {
+			return callSite{}, false
+		}
+		open += closing + 1
+	}
+	if open >= len(text) || text[open] != '(' || offset <= open {
+		return callSite{}, false
+	}
+
+	// Count the arguments before the cursor, stopping if the call closes
+	args := text[open+1 : offset]
+	fset := token.NewFileSet()
+	file := fset.AddFile("", -1, len(args))
+	var s scanner.Scanner
+	s.Init(file, []byte(args), nil, 0)
+
+	depth := 0
+	for {
+		_, tok, _ := s.Scan()
+		switch tok {
+		case token.EOF:
+			site.inArgs = true
+			return site, true
+		case token.LPAREN, token.LBRACK, token.LBRACE:
+			depth++
+		case token.RPAREN, token.RBRACK, token.RBRACE:
+			depth--
+			if depth < 0 {
+				return callSite{}, false
+			}
+		case token.COMMA:
+			if depth == 0 {
+				site.argIndex++
+			}
+		}
+	}
+}
+
+// scanIdent returns the end of the identifier starting at byte offset start
+func scanIdent(text string, start int) int {
+	end := start
+	for end < len(text) && isIdentByte(text[end]) {
+		end++
+	}
+	return end
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+// resolveCall finds the component called by a call site in the document at
+// path, using the document's imports to resolve qualified calls
+func (s *Server) resolveCall(docPath string, imports map[string]string, qualifier, name string) *types.ComponentInfo {
+	for _, component := range s.components(docPath, imports, qualifier) {
+		if component.Name == name {
+			return component
+		}
+	}
+	return nil
+}
+
+// components lists the templ components callable from the document at path
+// with the given qualifier: those in the document's own package when the
+// qualifier is empty, otherwise those in the package imported under it
+func (s *Server) components(docPath string, imports map[string]string, qualifier string) []*types.ComponentInfo {
+	var importPath string
+	if qualifier != "" {
+		var imported bool
+		if importPath, imported = imports[qualifier]; !imported {
+			return nil
+		}
+	}
+	docDir := filepath.Dir(docPath)
+
+	var components []*types.ComponentInfo
+	for _, component := range s.registry.GetAll() {
+		if component.Kind != "" && component.Kind != types.ComponentKindTempl {
+			continue
+		}
+		if qualifier == "" {
+			if componentDir(component) != docDir {
+				continue
+			}
+		} else if component.ImportPath != importPath {
+			continue
+		}
+		components = append(components, component)
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].Name < components[j].Name
+	})
+	return components
+}
+
+// completion lists the components or parameters to complete at a position
+func (s *Server) completion(uri string, pos Position) []CompletionItem {
+	text, file, found := s.docs.snapshot(uri)
+	if !found {
+		return nil
+	}
+	offset := offsetAt(text, pos)
+	site, ok := findCall(text, offset)
+	if !ok {
+		return nil
+	}
+	docPath := uriToPath(uri)
+	imports := fileImports(file)
+
+	if site.inArgs {
+		component := s.resolveCall(docPath, imports, site.qualifier, site.name)
+		if component == nil {
+			return nil
+		}
+		param, ok := parameterAt(component, site.argIndex)
+		if !ok {
+			return nil
+		}
+		return []CompletionItem{{
+			Label:         param.Name,
+			Kind:          completionKindField,
+			Detail:        param.Type,
+			Documentation: markdown(param.Description),
+			SortText:      "0",
+			InsertText:    param.Name,
+		}}
+	}
+
+	// Completing the name: with "ui." typed only the components of ui are
+	// offered, otherwise local components and those of imported packages
+	typed := text[site.nameStart:offset]
+	if dot := strings.IndexByte(typed, '.'); dot >= 0 {
+		var items []CompletionItem
+		for _, component := range s.components(docPath, imports, typed[:dot]) {
+			items = append(items, componentCompletion(component, ""))
+		}
+		return items
+	}
+
+	var items []CompletionItem
+	for _, component := range s.components(docPath, imports, "") {
+		items = append(items, componentCompletion(component, ""))
+	}
+	aliases := make([]string, 0, len(imports))
+	for alias := range imports {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		for _, component := range s.components(docPath, imports, alias) {
+			items = append(items, componentCompletion(component, alias))
+		}
+	}
+	return items
+}
+
+// componentCompletion completes a component call, inserting a placeholder
+// for each parameter and a children block for components that render them
+func componentCompletion(component *types.ComponentInfo, qualifier string) CompletionItem {
+	label := component.Name
+	if qualifier != "" {
+		label = qualifier + "." + component.Name
+	}
+
+	placeholders := make([]string, len(component.Parameters))
+	for i, param := range component.Parameters {
+		placeholders[i] = fmt.Sprintf("${%d:%s}", i+1, snippetEscape(param.Name))
+	}
+	insert := label + "(" + strings.Join(placeholders, ", ") + ")"
+	if component.AcceptsChildren {
+		insert += " {\n\t$0\n}"
+	}
+
+	return CompletionItem{
+		Label:            label,
+		Kind:             completionKindFunction,
+		Detail:           signature(component),
+		Documentation:    markdown(component.Description),
+		SortText:         "0" + label,
+		FilterText:       label,
+		InsertText:       insert,
+		InsertTextFormat: insertTextFormatSnippet,
+	}
+}
+
+// hover describes the component whose call name is under the cursor
+func (s *Server) hover(uri string, pos Position) *Hover {
+	text, file, found := s.docs.snapshot(uri)
+	if !found {
+		return nil
+	}
+	site, ok := findCall(text, offsetAt(text, pos))
+	if !ok || site.inArgs {
+		return nil
+	}
+	component := s.resolveCall(uriToPath(uri), fileImports(file), site.qualifier, site.name)
+	if component == nil {
+		return nil
+	}
+
+	nameRange := Range{Start: positionAt(text, site.nameStart), End: positionAt(text, site.nameEnd)}
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: describe(component)},
+		Range:    &nameRange,
+	}
+}
+
+// definition locates the declaration of the component whose call name is
+// under the cursor
+func (s *Server) definition(uri string, pos Position) *Location {
+	text, file, found := s.docs.snapshot(uri)
+	if !found {
+		return nil
+	}
+	site, ok := findCall(text, offsetAt(text, pos))
+	if !ok || site.inArgs {
+		return nil
+	}
+	component := s.resolveCall(uriToPath(uri), fileImports(file), site.qualifier, site.name)
+	if component == nil {
+		return nil
+	}
+
+	declaration := Position{}
+	if component.Line > 0 {
+		declaration.Line = component.Line - 1
+	}
+	if component.Column > 0 {
+		declaration.Character = component.Column - 1
+	}
+	return &Location{
+		URI:   pathToURI(component.FilePath),
+		Range: Range{Start: declaration, End: declaration},
+	}
+}
+
+// parameterAt returns the parameter receiving the argument at index,
+// taking variadic parameters into account
+func parameterAt(component *types.ComponentInfo, index int) (types.ParameterInfo, bool) {
+	params := component.Parameters
+	if len(params) == 0 {
+		return types.ParameterInfo{}, false
+	}
+	if index < len(params) {
+		return params[index], true
+	}
+	if last := params[len(params)-1]; strings.HasPrefix(last.Type, "...") {
+		return last, true
+	}
+	return types.ParameterInfo{}, false
+}
+
+// signature formats a component's templ declaration
+func signature(component *types.ComponentInfo) string {
+	var b strings.Builder
+	b.WriteString("templ ")
+	if component.Receiver != nil {
+		fmt.Fprintf(&b, "(%s %s) ", component.Receiver.Name, component.Receiver.Type)
+	}
+	b.WriteString(component.Name)
+	if len(component.TypeParameters) > 0 {
+		b.WriteString("[" + joinParams(component.TypeParameters) + "]")
+	}
+	b.WriteString("(" + joinParams(component.Parameters) + ")")
+	return b.String()
+}
+
+func joinParams(params []types.ParameterInfo) string {
+	parts := make([]string, len(params))
+	for i, param := range params {
+		parts[i] = strings.TrimSpace(param.Name + " " + param.Type)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describe documents a component in Markdown: its signature, doc comment,
+// props and where it is declared
+func describe(component *types.ComponentInfo) string {
+	var b strings.Builder
+	b.WriteString("```go\n" + signature(component) + "\n```\n")
+	if component.Description != "" {
+		b.WriteString("\n" + component.Description + "\n")
+	}
+
+	if len(component.Parameters) > 0 {
+		b.WriteString("\n| Prop | Type | Default | Description |\n|---|---|---|---|\n")
+		for _, param := range component.Parameters {
+			defaultValue := ""
+			if param.Default != nil {
+				defaultValue = fmt.Sprintf("`%v`", param.Default)
+			} else if param.Optional {
+				defaultValue = "optional"
+			}
+			fmt.Fprintf(&b, "| `%s` | `%s` | %s | %s |\n",
+				param.Name, param.Type, defaultValue, tableEscape(param.Description))
+		}
+	}
+
+	location := filepath.ToSlash(component.FilePath)
+	if component.Line > 0 {
+		location += ":" + strconv.Itoa(component.Line)
+	}
+	b.WriteString("\nDefined in `" + location + "`")
+	return b.String()
+}
+
+// markdown wraps documentation, returning nil when there is none
+func markdown(value string) *MarkupContent {
+	if value == "" {
+		return nil
+	}
+	return &MarkupContent{Kind: "markdown", Value: value}
+}
+
+func snippetEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `$`, `\$`, `}`, `\}`).Replace(s)
+}
+
+func tableEscape(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\n", " "), "|", `\|`)
+}
+
+// componentDir returns the absolute directory of a component's file
+func componentDir(component *types.ComponentInfo) string {
+	absPath, err := filepath.Abs(component.FilePath)
+	if err != nil {
+		return ""
+	}
+	return filepath.Dir(absPath)
+}
+
+// fileImports maps import aliases to import paths for a templ file.
+// Unaliased imports use the last path element as their alias.
+func fileImports(tf *templparser.TemplateFile) map[string]string {
+	imports := make(map[string]string)
+	if tf == nil {
+		return imports
+	}
+
+	for _, node := range tf.Nodes {
+		goExpr, ok := node.(*templparser.TemplateFileGoExpression)
+		if !ok {
+			continue
+		}
+
+		file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+goExpr.Expression.Value, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+
+		for _, imp := range file.Imports {
+			importPath, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				continue
+			}
+
+			alias := path.Base(importPath)
+			if imp.Name != nil {
+				alias = imp.Name.Name
+			}
+			if alias == "_" || alias == "." {
+				continue
+			}
+
+			imports[alias] = importPath
+		}
+	}
+
+	return imports
+}
+
+// templateName extracts the declared name from a templ signature such as
+// "Button(text string)" or "(c Card) Render()"
+func templateName(signature string) string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\nfunc "+signature+" {}\n", parser.SkipObjectResolution)
+	if err != nil {
+		return ""
+	}
+
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name != nil {
+			return fn.Name.Name
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,98 @@
+package lsp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindCall(t *testing.T) {
+	tests := []struct {
+		name string
+		// text marks the cursor with |
+		text     string
+		found    bool
+		expected callSite
+	}{
+		{
+			name:     "name",
+			text:     "<div>@Butt|</div>",
+			found:    true,
+			expected: callSite{name: "Butt", nameStart: 6, nameEnd: 10},
+		},
+		{
+			name:     "empty name",
+			text:     "@|",
+			found:    true,
+			expected: callSite{nameStart: 1, nameEnd: 1},
+		},
+		{
+			name:     "qualified name",
+			text:     "@ui.Car|d(x)",
+			found:    true,
+			expected: callSite{qualifier: "ui", name: "Card", nameStart: 1, nameEnd: 8},
+		},
+		{
+			name:     "first argument",
+			text:     "@Card(|",
+			found:    true,
+			expected: callSite{name: "Card", nameStart: 1, nameEnd: 5, inArgs: true},
+		},
+		{
+			name:     "later argument",
+			text:     `@Card("a, b", f(x, y), |)`,
+			found:    true,
+			expected: callSite{name: "Card", nameStart: 1, nameEnd: 5, inArgs: true, argIndex: 2},
+		},
+		{
+			name:     "generic component",
+			text:     "@List[string](items, |",
+			found:    true,
+			expected: callSite{name: "List", nameStart: 1, nameEnd: 5, inArgs: true, argIndex: 1},
+		},
+		{
+			name:     "at sign in an argument",
+			text:     `@Link("a@b.c", |`,
+			found:    true,
+			expected: callSite{name: "Link", nameStart: 1, nameEnd: 5, inArgs: true, argIndex: 1},
+		},
+		{name: "after a closed call", text: "@Card(x) <p>|</p>"},
+		{name: "e-mail address", text: "mail me@exam|ple.com"},
+		{name: "no call", text: "<p>text|</p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset := strings.Index(tt.text, "|")
+			text := strings.Replace(tt.text, "|", "", 1)
+
+			site, found := findCall(text, offset)
+			assert.Equal(t, tt.found, found)
+			if tt.found {
+				assert.Equal(t, tt.expected, site)
+			}
+		})
+	}
+}
+
+func TestParseCall(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   call
+		ok         bool
+	}{
+		{expression: "Button()", expected: call{name: "Button"}, ok: true},
+		{expression: `ui.Card("a", b)`, expected: call{qualifier: "ui", name: "Card", args: 2}, ok: true},
+		{expression: "List[string](items...)", expected: call{name: "List", args: 1, spread: true}, ok: true},
+		{expression: "icon", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			got, ok := parseCall(tt.expression)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -0,0 +1,497 @@
+// Package lsp implements templar's language server. It adds component
+// completion, hover, go-to-definition and diagnostics for templ files and
+// proxies everything else to templ's own language server.
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/conneroisu/templar/internal/accessibility"
+	"github.com/conneroisu/templar/internal/logging"
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// diagnosticsDelay debounces diagnostics while a document is being edited
+const diagnosticsDelay = 200 * time.Millisecond
+
+// Options configures the language server
+type Options struct {
+	// TemplCommand runs templ's language server, which handles everything
+	// templar does not. Defaults to "templ lsp".
+	TemplCommand []string
+	// Rescan updates the registry from a saved file
+	Rescan func(path string) error
+	// Validate checks a component, returning errors and warnings that are
+	// published as diagnostics on its declaration
+	Validate func(component *types.ComponentInfo) (errs []string, warnings []string)
+	// DisableAccessibility turns off the accessibility diagnostics
+	DisableAccessibility bool
+}
+
+// Server is a templar language server
+type Server struct {
+	registry      *registry.ComponentRegistry
+	options       Options
+	docs          *documents
+	accessibility *accessibility.DefaultAccessibilityEngine
+
+	client *conn
+
+	mu sync.Mutex
+	// upstream talks to templ's language server, nil when it isn't running
+	upstream *conn
+	// pending holds the requests forwarded to templ, by ID, until answered
+	pending map[string]pendingRequest
+	// templDiagnostics and ownDiagnostics are the latest diagnostics of each
+	// document from templ and from templar; the editor is sent their union
+	templDiagnostics map[string][]Diagnostic
+	ownDiagnostics   map[string][]Diagnostic
+	timers           map[string]*time.Timer
+}
+
+// NewServer creates a language server answering from the components in reg
+func NewServer(reg *registry.ComponentRegistry, options Options) *Server {
+	if len(options.TemplCommand) == 0 {
+		options.TemplCommand = []string{"templ", "lsp"}
+	}
+
+	s := &Server{
+		registry:         reg,
+		options:          options,
+		docs:             newDocuments(),
+		pending:          make(map[string]pendingRequest),
+		templDiagnostics: make(map[string][]Diagnostic),
+		ownDiagnostics:   make(map[string][]Diagnostic),
+		timers:           make(map[string]*time.Timer),
+	}
+
+	if !options.DisableAccessibility {
+		// The engine logs every analysis; only errors reach stderr
+		logger := logging.NewLogger(&logging.LoggerConfig{
+			Level:     logging.LevelError,
+			Format:    "text",
+			Component: "lsp",
+			Output:    os.Stderr,
+		})
+		engine := accessibility.NewDefaultAccessibilityEngine(logger)
+		if err := engine.Initialize(context.Background(), accessibility.EngineConfig{}); err == nil {
+			s.accessibility = engine
+		}
+	}
+
+	return s
+}
+
+// pendingRequest is a request forwarded to templ
+type pendingRequest struct {
+	request *message
+	// handler, if not nil, rewrites templ's response before it reaches the
+	// editor
+	handler func(response *message) *message
+}
+
+// Serve speaks LSP over in and out until the editor exits. templ's
+// language server is started to handle everything templar does not; if it
+// can't be started or stops, templar's features are served on their own.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.options.TemplCommand[0], s.options.TemplCommand[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create templ stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create templ stdout pipe: %w", err)
+	}
+
+	var upstream *conn
+	if err := cmd.Start(); err != nil {
+		log.Printf("Warning: failed to start %v, serving templar features only: %v", s.options.TemplCommand, err)
+	} else {
+		upstream = newConn(stdout, stdin)
+		defer func() {
+			stdin.Close()
+			cancel()
+			cmd.Wait()
+		}()
+	}
+
+	return s.serve(ctx, newConn(in, out), upstream)
+}
+
+// serve dispatches messages between the editor and templ. upstream is nil
+// when templ isn't running.
+func (s *Server) serve(ctx context.Context, client, upstream *conn) error {
+	s.client = client
+	s.upstream = upstream
+
+	done := make(chan error, 1)
+	if upstream != nil {
+		go func() {
+			for {
+				msg, err := upstream.read()
+				if err != nil {
+					s.upstreamStopped(err)
+					return
+				}
+				s.handleUpstream(msg)
+			}
+		}()
+	}
+
+	go func() {
+		for {
+			msg, err := client.read()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					err = nil
+				}
+				done <- err
+				return
+			}
+			if msg.Method == methodExit {
+				s.forward(msg, nil)
+				done <- nil
+				return
+			}
+			s.handleClient(ctx, msg)
+		}
+	}()
+
+	select {
+	case err := <-done:
+		s.stopTimers()
+		return err
+	case <-ctx.Done():
+		s.stopTimers()
+		return nil
+	}
+}
+
+// handleClient handles a message from the editor
+func (s *Server) handleClient(ctx context.Context, msg *message) {
+	switch msg.Method {
+	case methodInitialize:
+		s.forward(msg, func(response *message) *message {
+			if response.Error == nil {
+				response.Result = augmentInitializeResult(response.Result)
+			}
+			return response
+		})
+		return
+
+	case methodDidOpen:
+		var params DidOpenTextDocumentParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			s.docs.open(params.TextDocument)
+			s.scheduleDiagnostics(ctx, params.TextDocument.URI, 0)
+		}
+
+	case methodDidChange:
+		var params DidChangeTextDocumentParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			s.docs.change(params.TextDocument.URI, params.ContentChanges)
+			s.scheduleDiagnostics(ctx, params.TextDocument.URI, diagnosticsDelay)
+		}
+
+	case methodDidSave:
+		var params DidSaveTextDocumentParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			s.rescan(ctx, params.TextDocument.URI)
+		}
+
+	case methodDidClose:
+		var params DidCloseTextDocumentParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			s.docs.close(params.TextDocument.URI)
+			s.clearDiagnostics(params.TextDocument.URI)
+		}
+
+	case methodCompletion:
+		var params TextDocumentPositionParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			if items := s.completion(params.TextDocument.URI, params.Position); len(items) > 0 {
+				s.answerCompletion(msg, items)
+				return
+			}
+		}
+
+	case methodHover:
+		var params TextDocumentPositionParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			if hover := s.hover(params.TextDocument.URI, params.Position); hover != nil {
+				s.client.reply(msg.ID, hover)
+				return
+			}
+		}
+
+	case methodDefinition:
+		var params TextDocumentPositionParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			if location := s.definition(params.TextDocument.URI, params.Position); location != nil {
+				s.client.reply(msg.ID, location)
+				return
+			}
+		}
+	}
+
+	s.forward(msg, nil)
+}
+
+// handleUpstream handles a message from templ
+func (s *Server) handleUpstream(msg *message) {
+	if msg.isResponse() {
+		s.mu.Lock()
+		pending, found := s.pending[string(*msg.ID)]
+		delete(s.pending, string(*msg.ID))
+		s.mu.Unlock()
+		if found && pending.handler != nil {
+			msg = pending.handler(msg)
+		}
+	}
+
+	if msg.Method == methodPublishDiagnostics {
+		var params PublishDiagnosticsParams
+		if json.Unmarshal(msg.Params, &params) == nil {
+			s.mu.Lock()
+			s.templDiagnostics[params.URI] = params.Diagnostics
+			s.mu.Unlock()
+			s.publishDiagnostics(params.URI)
+			return
+		}
+	}
+
+	if err := s.client.write(msg); err != nil {
+		log.Printf("Warning: failed to write to editor: %v", err)
+	}
+}
+
+// forward sends a message from the editor to templ, or answers it without
+// templ when templ isn't running. handler, if not nil, rewrites the response.
+func (s *Server) forward(msg *message, handler func(*message) *message) {
+	s.mu.Lock()
+	upstream := s.upstream
+	if upstream != nil && msg.isRequest() {
+		s.pending[string(*msg.ID)] = pendingRequest{request: msg, handler: handler}
+	}
+	s.mu.Unlock()
+
+	if upstream == nil {
+		if msg.isRequest() {
+			s.answerStandalone(msg, handler)
+		}
+		return
+	}
+	if err := upstream.write(msg); err != nil {
+		log.Printf("Warning: failed to write to templ lsp: %v", err)
+	}
+}
+
+// upstreamStopped switches to serving templar's features on their own
+// when templ stops, answering the requests templ left unanswered
+func (s *Server) upstreamStopped(err error) {
+	s.mu.Lock()
+	s.upstream = nil
+	pending := s.pending
+	s.pending = make(map[string]pendingRequest)
+	s.mu.Unlock()
+
+	log.Printf("Warning: templ lsp stopped, serving templar features only: %v", err)
+	for _, p := range pending {
+		s.answerStandalone(p.request, p.handler)
+	}
+}
+
+// answerCompletion answers a completion request with templar's items
+// followed by templ's
+func (s *Server) answerCompletion(msg *message, items []CompletionItem) {
+	s.forward(msg, func(response *message) *message {
+		list := CompletionList{Items: items}
+		if response.Error == nil && len(response.Result) > 0 {
+			var templList CompletionList
+			var templItems []CompletionItem
+			if json.Unmarshal(response.Result, &templList) == nil && templList.Items != nil {
+				list.IsIncomplete = templList.IsIncomplete
+				templItems = templList.Items
+			} else {
+				json.Unmarshal(response.Result, &templItems)
+			}
+			list.Items = append(list.Items, templItems...)
+		}
+
+		result, err := json.Marshal(list)
+		if err != nil {
+			return response
+		}
+		return &message{ID: response.ID, Result: result}
+	})
+}
+
+// answerStandalone answers a request as if templ had no result for it
+func (s *Server) answerStandalone(msg *message, handler func(*message) *message) {
+	if handler != nil {
+		if err := s.client.write(handler(&message{ID: msg.ID, Result: json.RawMessage("null")})); err != nil {
+			log.Printf("Warning: failed to write to editor: %v", err)
+		}
+		return
+	}
+
+	switch msg.Method {
+	case methodCompletion:
+		s.client.reply(msg.ID, CompletionList{Items: []CompletionItem{}})
+	case methodShutdown, methodHover, methodDefinition:
+		s.client.reply(msg.ID, nil)
+	default:
+		s.client.replyError(msg.ID, codeMethodNotFound, "method not supported: "+msg.Method)
+	}
+}
+
+// rescan updates the registry from a saved document and refreshes the
+// diagnostics of every open document, which may call its components
+func (s *Server) rescan(ctx context.Context, uri string) {
+	if s.options.Rescan != nil {
+		if path := uriToPath(uri); path != "" {
+			if err := s.options.Rescan(path); err != nil {
+				log.Printf("Warning: failed to rescan %s: %v", path, err)
+			}
+		}
+	}
+	for _, open := range s.docs.uris() {
+		s.scheduleDiagnostics(ctx, open, 0)
+	}
+}
+
+// scheduleDiagnostics recomputes and publishes templar's diagnostics for a
+// document after delay, replacing any pending recomputation
+func (s *Server) scheduleDiagnostics(ctx context.Context, uri string, delay time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if timer, exists := s.timers[uri]; exists {
+		timer.Stop()
+	}
+	s.timers[uri] = time.AfterFunc(delay, func() {
+		text, _, found := s.docs.snapshot(uri)
+		if !found {
+			return
+		}
+		diagnostics := s.diagnose(ctx, uri, text)
+
+		s.mu.Lock()
+		s.ownDiagnostics[uri] = diagnostics
+		s.mu.Unlock()
+		s.publishDiagnostics(uri)
+	})
+}
+
+// clearDiagnostics forgets templar's diagnostics for a closed document
+func (s *Server) clearDiagnostics(uri string) {
+	s.mu.Lock()
+	if timer, exists := s.timers[uri]; exists {
+		timer.Stop()
+		delete(s.timers, uri)
+	}
+	hadDiagnostics := len(s.ownDiagnostics[uri]) > 0
+	delete(s.ownDiagnostics, uri)
+	s.mu.Unlock()
+
+	if hadDiagnostics {
+		s.publishDiagnostics(uri)
+	}
+}
+
+// publishDiagnostics sends the editor templ's and templar's diagnostics
+// for a document
+func (s *Server) publishDiagnostics(uri string) {
+	s.mu.Lock()
+	diagnostics := make([]Diagnostic, 0, len(s.templDiagnostics[uri])+len(s.ownDiagnostics[uri]))
+	diagnostics = append(diagnostics, s.templDiagnostics[uri]...)
+	diagnostics = append(diagnostics, s.ownDiagnostics[uri]...)
+	s.mu.Unlock()
+
+	err := s.client.notify(methodPublishDiagnostics, PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+	if err != nil {
+		log.Printf("Warning: failed to publish diagnostics: %v", err)
+	}
+}
+
+func (s *Server) stopTimers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for uri, timer := range s.timers {
+		timer.Stop()
+		delete(s.timers, uri)
+	}
+}
+
+// capabilities adds templar's features to the server capabilities templ
+// declared, or declares them on their own when templ isn't running
+func capabilities(templCapabilities map[string]interface{}) map[string]interface{} {
+	if templCapabilities == nil {
+		templCapabilities = map[string]interface{}{
+			"textDocumentSync": map[string]interface{}{"openClose": true, "change": 1, "save": true},
+		}
+	}
+
+	completion, _ := templCapabilities["completionProvider"].(map[string]interface{})
+	if completion == nil {
+		completion = make(map[string]interface{})
+	}
+	triggers, _ := completion["triggerCharacters"].([]interface{})
+	for _, trigger := range []string{"@", "(", ","} {
+		if !containsValue(triggers, trigger) {
+			triggers = append(triggers, trigger)
+		}
+	}
+	completion["triggerCharacters"] = triggers
+	templCapabilities["completionProvider"] = completion
+
+	templCapabilities["hoverProvider"] = true
+	templCapabilities["definitionProvider"] = true
+	return templCapabilities
+}
+
+// augmentInitializeResult adds templar's capabilities to templ's
+// initialize result, which is null when templ isn't running
+func augmentInitializeResult(result json.RawMessage) json.RawMessage {
+	var initializeResult map[string]interface{}
+	if err := json.Unmarshal(result, &initializeResult); err != nil {
+		return result
+	}
+	if initializeResult == nil {
+		initializeResult = make(map[string]interface{})
+	}
+
+	templCapabilities, _ := initializeResult["capabilities"].(map[string]interface{})
+	initializeResult["capabilities"] = capabilities(templCapabilities)
+
+	augmented, err := json.Marshal(initializeResult)
+	if err != nil {
+		return result
+	}
+	return augmented
+}
+
+func containsValue(values []interface{}, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,413 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/scanner"
+	"github.com/conneroisu/templar/internal/types"
+)
+
+const buttonTempl = `package ui
+
+// Button renders a clickable button
+templ Button(text string, variant string) {
+	<button class={ variant }>{ text }</button>
+}
+`
+
+const pageTempl = `package ui
+
+templ Page() {
+	<main>
+		@Button("Save")
+		<img src="/logo.png"/>
+	</main>
+}
+`
+
+// session is an editor talking to a language server whose templ is faked
+type session struct {
+	t        *testing.T
+	editor   *conn
+	messages chan *message
+	nextID   int
+}
+
+// fakeTempl answers like templ's language server: it declares its own
+// completion triggers, completes and hovers with fixed results and reports
+// one diagnostic for every opened document
+func fakeTempl(t *testing.T, c *conn) {
+	for {
+		msg, err := c.read()
+		if err != nil {
+			return
+		}
+		switch msg.Method {
+		case methodInitialize:
+			c.reply(msg.ID, map[string]interface{}{
+				"capabilities": map[string]interface{}{
+					"textDocumentSync":   1,
+					"completionProvider": map[string]interface{}{"triggerCharacters": []string{"{", "<"}},
+				},
+			})
+		case methodCompletion:
+			c.reply(msg.ID, CompletionList{Items: []CompletionItem{{Label: "fromTempl"}}})
+		case methodHover:
+			c.reply(msg.ID, Hover{Contents: MarkupContent{Kind: "markdown", Value: "templ hover"}})
+		case methodDefinition:
+			c.reply(msg.ID, nil)
+		case methodDidOpen:
+			var params DidOpenTextDocumentParams
+			require.NoError(t, json.Unmarshal(msg.Params, &params))
+			c.notify(methodPublishDiagnostics, PublishDiagnosticsParams{
+				URI:         params.TextDocument.URI,
+				Diagnostics: []Diagnostic{{Message: "templ says", Source: "templ"}},
+			})
+		}
+	}
+}
+
+// stoppingTempl exits as soon as it is asked anything
+func stoppingTempl(t *testing.T, c *conn) {
+	c.read()
+}
+
+// newSession starts a language server whose templ is faked by templ, or
+// that runs without templ if templ is nil
+func newSession(t *testing.T, templ func(*testing.T, *conn)) *session {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("button.templ", []byte(buttonTempl), 0644))
+	require.NoError(t, os.WriteFile("page.templ", []byte(pageTempl), 0644))
+
+	reg := registry.NewComponentRegistry()
+	componentScanner := scanner.NewComponentScanner(reg)
+	require.NoError(t, componentScanner.ScanFile("button.templ"))
+	require.NoError(t, componentScanner.ScanFile("page.templ"))
+
+	server := NewServer(reg, Options{
+		Rescan: componentScanner.ScanFile,
+		Validate: func(component *types.ComponentInfo) ([]string, []string) {
+			if component.Name == "Page" {
+				return nil, []string{"Page is validated"}
+			}
+			return nil, nil
+		},
+	})
+
+	editorIn, serverOut := io.Pipe()
+	serverIn, editorOut := io.Pipe()
+	var upstream *conn
+	if templ != nil {
+		templIn, proxyOut := io.Pipe()
+		proxyIn, templOut := io.Pipe()
+		upstream = newConn(proxyIn, proxyOut)
+		go func() {
+			templ(t, newConn(templIn, templOut))
+			templOut.Close()
+			templIn.Close()
+		}()
+		t.Cleanup(func() {
+			proxyOut.Close()
+			templOut.Close()
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		server.serve(ctx, newConn(serverIn, serverOut), upstream)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		editorOut.Close()
+		<-served
+		serverOut.Close()
+	})
+
+	s := &session{t: t, editor: newConn(editorIn, editorOut), messages: make(chan *message, 64)}
+	go func() {
+		for {
+			msg, err := s.editor.read()
+			if err != nil {
+				return
+			}
+			s.messages <- msg
+		}
+	}()
+	return s
+}
+
+// request sends a request and returns its result, skipping notifications
+func (s *session) request(method string, params interface{}, result interface{}) {
+	s.nextID++
+	id := json.RawMessage(strings.TrimSpace(string(mustMarshal(s.t, s.nextID))))
+	data := mustMarshal(s.t, params)
+	require.NoError(s.t, s.editor.write(&message{ID: &id, Method: method, Params: data}))
+
+	for {
+		msg := s.next()
+		if !msg.isResponse() || string(*msg.ID) != string(id) {
+			continue
+		}
+		require.Nil(s.t, msg.Error)
+		if result != nil {
+			require.NoError(s.t, json.Unmarshal(msg.Result, result))
+		}
+		return
+	}
+}
+
+func (s *session) notify(method string, params interface{}) {
+	require.NoError(s.t, s.editor.notify(method, params))
+}
+
+// diagnostics waits for diagnostics of a document satisfying done
+func (s *session) diagnostics(uri string, done func([]Diagnostic) bool) []Diagnostic {
+	for {
+		msg := s.next()
+		if msg.Method != methodPublishDiagnostics {
+			continue
+		}
+		var params PublishDiagnosticsParams
+		require.NoError(s.t, json.Unmarshal(msg.Params, &params))
+		if params.URI == uri && done(params.Diagnostics) {
+			return params.Diagnostics
+		}
+	}
+}
+
+func (s *session) next() *message {
+	select {
+	case msg := <-s.messages:
+		return msg
+	case <-time.After(10 * time.Second):
+		s.t.Fatal("timed out waiting for the language server")
+		return nil
+	}
+}
+
+// open opens a file, returning its URI
+func (s *session) open(name string) string {
+	text, err := os.ReadFile(name)
+	require.NoError(s.t, err)
+	uri := pathToURI(name)
+	s.notify(methodDidOpen, DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "templ", Version: 1, Text: string(text)},
+	})
+	return uri
+}
+
+func mustMarshal(t *testing.T, v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
+// positionOf returns the position of the first occurrence of marker in a
+// document, plus offset bytes
+func positionOf(t *testing.T, text, marker string, offset int) Position {
+	index := strings.Index(text, marker)
+	require.GreaterOrEqual(t, index, 0, "marker %q not found", marker)
+	return positionAt(text, index+offset)
+}
+
+func hasCode(diagnostics []Diagnostic, code string) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestServer_Initialize(t *testing.T) {
+	s := newSession(t, fakeTempl)
+
+	var result struct {
+		Capabilities map[string]interface{} `json:"capabilities"`
+	}
+	s.request(methodInitialize, map[string]interface{}{"capabilities": map[string]interface{}{}}, &result)
+
+	// templ's capabilities are kept and templar's added
+	assert.EqualValues(t, 1, result.Capabilities["textDocumentSync"])
+	assert.Equal(t, true, result.Capabilities["hoverProvider"])
+	assert.Equal(t, true, result.Capabilities["definitionProvider"])
+	completion := result.Capabilities["completionProvider"].(map[string]interface{})
+	assert.ElementsMatch(t, []interface{}{"{", "<", "@", "(", ","}, completion["triggerCharacters"])
+}
+
+func TestServer_Completion(t *testing.T) {
+	s := newSession(t, fakeTempl)
+	s.request(methodInitialize, map[string]interface{}{}, nil)
+	uri := s.open("page.templ")
+
+	// Component names, followed by templ's items
+	var list CompletionList
+	s.request(methodCompletion, TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, pageTempl, "@Button", 3),
+	}, &list)
+	require.GreaterOrEqual(t, len(list.Items), 2)
+	labels := make([]string, len(list.Items))
+	for i, item := range list.Items {
+		labels[i] = item.Label
+	}
+	assert.Contains(t, labels, "Button")
+	assert.Contains(t, labels, "fromTempl")
+	for _, item := range list.Items {
+		if item.Label == "Button" {
+			assert.Equal(t, "Button(${1:text}, ${2:variant})", item.InsertText)
+			assert.Equal(t, insertTextFormatSnippet, item.InsertTextFormat)
+			assert.Equal(t, "templ Button(text string, variant string)", item.Detail)
+		}
+	}
+
+	// Parameters inside the argument list
+	list = CompletionList{}
+	s.request(methodCompletion, TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, pageTempl, `"Save"`, len(`"Save"`)),
+	}, &list)
+	require.NotEmpty(t, list.Items)
+	assert.Equal(t, "text", list.Items[0].Label)
+	assert.Equal(t, "string", list.Items[0].Detail)
+}
+
+func TestServer_HoverAndDefinition(t *testing.T) {
+	s := newSession(t, fakeTempl)
+	s.request(methodInitialize, map[string]interface{}{}, nil)
+	uri := s.open("page.templ")
+	onButton := TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, pageTempl, "@Button", 2),
+	}
+
+	var hover Hover
+	s.request(methodHover, onButton, &hover)
+	assert.Contains(t, hover.Contents.Value, "templ Button(text string, variant string)")
+	assert.Contains(t, hover.Contents.Value, "| `variant` | `string` |")
+	assert.Contains(t, hover.Contents.Value, "Defined in `button.templ:4`")
+
+	var location Location
+	s.request(methodDefinition, onButton, &location)
+	assert.Equal(t, pathToURI("button.templ"), location.URI)
+	assert.Equal(t, 3, location.Range.Start.Line)
+
+	// Anywhere else, templ answers
+	hover = Hover{}
+	s.request(methodHover, TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, pageTempl, "<main>", 2),
+	}, &hover)
+	assert.Equal(t, "templ hover", hover.Contents.Value)
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	s := newSession(t, fakeTempl)
+	s.request(methodInitialize, map[string]interface{}{}, nil)
+	uri := s.open("page.templ")
+
+	diagnostics := s.diagnostics(uri, func(diagnostics []Diagnostic) bool {
+		return hasCode(diagnostics, "argument-count") && hasCode(diagnostics, "")
+	})
+
+	byCode := make(map[string]Diagnostic)
+	for _, diagnostic := range diagnostics {
+		byCode[diagnostic.Code] = diagnostic
+	}
+	assert.Contains(t, byCode, "", "templ's diagnostics are kept")
+	assert.Equal(t, "templ says", byCode[""].Message)
+
+	assert.Equal(t, SeverityError, byCode["argument-count"].Severity)
+	assert.Contains(t, byCode["argument-count"].Message, "Button takes 2 arguments but is called with 1")
+	assert.Equal(t, positionOf(t, pageTempl, `Button("Save")`, 0), byCode["argument-count"].Range.Start)
+
+	assert.Contains(t, byCode, "missing-alt-text")
+	assert.Equal(t, positionOf(t, pageTempl, "img", 0), byCode["missing-alt-text"].Range.Start)
+
+	assert.NotContains(t, byCode, "missing-lang-attribute", "the page wrapping the component isn't checked")
+
+	assert.Equal(t, "Page is validated", byCode["validate"].Message)
+	assert.Equal(t, SeverityWarning, byCode["validate"].Severity)
+
+	// Fixing the call clears its diagnostic while the file is edited
+	fixed := strings.Replace(pageTempl, `@Button("Save")`, `@Button("Save", "primary")`, 1)
+	s.notify(methodDidChange, DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: uri},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: fixed}},
+	})
+	s.diagnostics(uri, func(diagnostics []Diagnostic) bool {
+		return !hasCode(diagnostics, "argument-count") && hasCode(diagnostics, "missing-alt-text")
+	})
+}
+
+func TestServer_Rescan(t *testing.T) {
+	s := newSession(t, fakeTempl)
+	s.request(methodInitialize, map[string]interface{}{}, nil)
+	uri := s.open("page.templ")
+	s.diagnostics(uri, func(diagnostics []Diagnostic) bool {
+		return hasCode(diagnostics, "argument-count")
+	})
+
+	// Saving a component with one parameter updates the calls to it
+	require.NoError(t, os.WriteFile("button.templ", []byte(strings.Replace(buttonTempl, ", variant string", "", 1)), 0644))
+	s.notify(methodDidSave, DidSaveTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: pathToURI("button.templ")}})
+	s.diagnostics(uri, func(diagnostics []Diagnostic) bool {
+		return !hasCode(diagnostics, "argument-count")
+	})
+}
+
+func TestServer_Standalone(t *testing.T) {
+	for name, templ := range map[string]func(*testing.T, *conn){
+		"without templ":     nil,
+		"after templ stops": stoppingTempl,
+	} {
+		t.Run(name, func(t *testing.T) {
+			testStandalone(t, newSession(t, templ))
+		})
+	}
+}
+
+func testStandalone(t *testing.T, s *session) {
+
+	var result struct {
+		Capabilities map[string]interface{} `json:"capabilities"`
+	}
+	s.request(methodInitialize, map[string]interface{}{}, &result)
+	assert.Equal(t, true, result.Capabilities["hoverProvider"])
+	assert.NotNil(t, result.Capabilities["textDocumentSync"])
+
+	uri := s.open(filepath.Join(".", "page.templ"))
+	s.diagnostics(uri, func(diagnostics []Diagnostic) bool {
+		return hasCode(diagnostics, "argument-count")
+	})
+
+	var hover *Hover
+	s.request(methodHover, TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, pageTempl, "<main>", 2),
+	}, &hover)
+	assert.Nil(t, hover)
+
+	var list CompletionList
+	s.request(methodCompletion, TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     positionOf(t, pageTempl, "@Button", 3),
+	}, &list)
+	require.NotEmpty(t, list.Items)
+	assert.Equal(t, "Button", list.Items[0].Label)
+
+	s.request(methodShutdown, nil, nil)
+}
@@ -0,0 +1,415 @@
+package mockdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// DefaultVariant is the fixture variant applied to every render of a
+// component; named variants match story names and are merged over it
+const DefaultVariant = "default"
+
+// fixtureExtensions are the file extensions loaded as fixtures, in the order
+// tried when a $ref omits the extension
+var fixtureExtensions = []string{".json", ".yaml", ".yml"}
+
+// Fixtures serves prop fixtures from the directory named by preview.mock_data.
+//
+// Files directly inside the directory declare component fixtures keyed by
+// component (fully qualified ID, package-qualified name or bare name) and
+// then by variant:
+//
+//	ui.Button:
+//	  default:
+//	    text: Save
+//	  Danger:
+//	    variant: danger
+//	    author: {$ref: shared/user}
+//
+// Any fixture file, typically one in a subdirectory, can be referenced with
+// {"$ref": "path/without/extension"} and an optional "#/key/path" fragment.
+// Keys next to $ref are merged over the referenced value. Files are reread
+// when they change, so edits apply to the next render.
+type Fixtures struct {
+	dir   string
+	mutex sync.Mutex
+	files map[string]*fixtureFile
+}
+
+// fixtureFile caches a decoded fixture file
+type fixtureFile struct {
+	modTime time.Time
+	size    int64
+	data    interface{}
+	err     error
+}
+
+// NewFixtures creates a fixture store reading from dir
+func NewFixtures(dir string) *Fixtures {
+	return &Fixtures{
+		dir:   dir,
+		files: make(map[string]*fixtureFile),
+	}
+}
+
+// LoadFixtures creates a fixture store for dir and reads every fixture file,
+// reporting a missing directory or invalid files. The store is returned
+// even on error so later lookups pick up fixed files.
+func LoadFixtures(dir string) (*Fixtures, error) {
+	fixtures := NewFixtures(dir)
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fixtures, fmt.Errorf("reading mock data directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fixtures, fmt.Errorf("mock data path %s is not a directory", dir)
+	}
+
+	fixtures.mutex.Lock()
+	defer fixtures.mutex.Unlock()
+
+	return fixtures, fixtures.refreshLocked()
+}
+
+// Dir returns the fixture directory
+func (f *Fixtures) Dir() string {
+	if f == nil {
+		return ""
+	}
+	return f.dir
+}
+
+// Props returns the fixture props of a component variant: the default
+// variant merged with the named one, with every $ref resolved. It returns
+// nil when the component has no fixtures. A nil store has no fixtures.
+func (f *Fixtures) Props(component *types.ComponentInfo, variant string) (map[string]interface{}, error) {
+	if f == nil || component == nil {
+		return nil, nil
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if err := f.refreshLocked(); err != nil {
+		return nil, err
+	}
+
+	// Less specific keys first so qualified fixtures override bare names
+	keys := []string{component.Name}
+	if component.Package != "" {
+		keys = append(keys, component.Package+"."+component.Name)
+	}
+	if component.ID != "" && component.ID != keys[len(keys)-1] {
+		keys = append(keys, component.ID)
+	}
+
+	variants := []string{DefaultVariant}
+	if variant != "" && variant != DefaultVariant {
+		variants = append(variants, variant)
+	}
+
+	var props map[string]interface{}
+	for _, name := range f.componentFilesLocked() {
+		document, ok := f.files[name].data.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, key := range keys {
+			entry, ok := document[key]
+			if !ok {
+				continue
+			}
+			variantProps, ok := entry.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("fixture %s: %s must map variant names to props", name, key)
+			}
+
+			for _, variantName := range variants {
+				value, ok := variantProps[variantName]
+				if !ok {
+					continue
+				}
+				resolved, err := f.resolveLocked(value, []string{name})
+				if err != nil {
+					return nil, fmt.Errorf("fixture %s: %s.%s: %w", name, key, variantName, err)
+				}
+				object, ok := resolved.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("fixture %s: %s.%s must be an object of props", name, key, variantName)
+				}
+				props = MergeProps(props, object)
+			}
+		}
+	}
+
+	return props, nil
+}
+
+// Apply merges the fixture props of a component variant over generated
+// values. Objects are merged key by key, any other fixture value replaces
+// the generated one.
+func (f *Fixtures) Apply(component *types.ComponentInfo, variant string, generated map[string]interface{}) (map[string]interface{}, error) {
+	props, err := f.Props(component, variant)
+	if err != nil {
+		return generated, err
+	}
+	if props == nil {
+		return generated, nil
+	}
+	return MergeProps(generated, props), nil
+}
+
+// MergeProps returns base with overlay merged over it. Nested objects are
+// merged recursively; neither argument is modified.
+func MergeProps(base, overlay map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overlay))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overlay {
+		baseObject, baseIsObject := merged[key].(map[string]interface{})
+		overlayObject, overlayIsObject := value.(map[string]interface{})
+		if baseIsObject && overlayIsObject {
+			merged[key] = MergeProps(baseObject, overlayObject)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// componentFilesLocked lists the fixture files directly inside the fixture
+// directory in name order; files in subdirectories are only used via $ref
+func (f *Fixtures) componentFilesLocked() []string {
+	names := make([]string, 0, len(f.files))
+	for name := range f.files {
+		if !strings.Contains(name, "/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// refreshLocked rereads changed fixture files and forgets deleted ones,
+// returning the first decoding error
+func (f *Fixtures) refreshLocked() error {
+	seen := make(map[string]bool, len(f.files))
+
+	err := filepath.WalkDir(f.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == f.dir && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !isFixtureFile(path) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(f.dir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		seen[name] = true
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if cached, ok := f.files[name]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+			return nil
+		}
+
+		data, decodeErr := decodeFixtureFile(path)
+		f.files[name] = &fixtureFile{modTime: info.ModTime(), size: info.Size(), data: data, err: decodeErr}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("reading mock data directory: %w", err)
+	}
+
+	for name := range f.files {
+		if !seen[name] {
+			delete(f.files, name)
+		}
+	}
+
+	names := make([]string, 0, len(f.files))
+	for name := range f.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := f.files[name].err; err != nil {
+			return fmt.Errorf("fixture %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// resolveLocked replaces every {"$ref": ...} object in value with the value
+// it references. stack holds the references being resolved, to report cycles.
+func (f *Fixtures) resolveLocked(value interface{}, stack []string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		ref, hasRef := v["$ref"]
+		if !hasRef {
+			resolved := make(map[string]interface{}, len(v))
+			for key, item := range v {
+				r, err := f.resolveLocked(item, stack)
+				if err != nil {
+					return nil, err
+				}
+				resolved[key] = r
+			}
+			return resolved, nil
+		}
+
+		refName, ok := ref.(string)
+		if !ok {
+			return nil, fmt.Errorf("$ref must be a string")
+		}
+		for _, active := range stack[1:] {
+			if active == refName {
+				return nil, fmt.Errorf("circular $ref: %s -> %s", strings.Join(stack[1:], " -> "), refName)
+			}
+		}
+
+		target, err := f.lookupRefLocked(refName)
+		if err != nil {
+			return nil, err
+		}
+		target, err = f.resolveLocked(target, append(stack, refName))
+		if err != nil {
+			return nil, err
+		}
+
+		// Keys next to $ref override the referenced object
+		if len(v) == 1 {
+			return target, nil
+		}
+		targetObject, ok := target.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("$ref %s is not an object, so it cannot be combined with other keys", refName)
+		}
+		overrides := make(map[string]interface{}, len(v)-1)
+		for key, item := range v {
+			if key != "$ref" {
+				overrides[key] = item
+			}
+		}
+		resolvedOverrides, err := f.resolveLocked(overrides, stack)
+		if err != nil {
+			return nil, err
+		}
+		return MergeProps(targetObject, resolvedOverrides.(map[string]interface{})), nil
+
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			r, err := f.resolveLocked(item, stack)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = r
+		}
+		return resolved, nil
+	}
+
+	return value, nil
+}
+
+// lookupRefLocked returns the value a $ref names: a fixture file relative to
+// the fixture directory, optionally followed by a #/key/path fragment whose
+// segments are object keys or list indexes
+func (f *Fixtures) lookupRefLocked(ref string) (interface{}, error) {
+	path, fragment, _ := strings.Cut(ref, "#")
+
+	name := strings.TrimPrefix(filepath.ToSlash(filepath.Clean(filepath.FromSlash(path))), "./")
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") || filepath.IsAbs(path) {
+		return nil, fmt.Errorf("$ref %s must name a file inside the mock data directory", ref)
+	}
+
+	file, ok := f.files[name]
+	if !ok {
+		for _, ext := range fixtureExtensions {
+			if file, ok = f.files[name+ext]; ok {
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("$ref %s: no fixture file %s{%s}", ref, name, strings.Join(fixtureExtensions, ","))
+	}
+
+	value := file.data
+	for _, key := range strings.Split(strings.Trim(fragment, "/"), "/") {
+		if key == "" {
+			continue
+		}
+		switch container := value.(type) {
+		case map[string]interface{}:
+			if value, ok = container[key]; !ok {
+				return nil, fmt.Errorf("$ref %s: key %s not found", ref, key)
+			}
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(container) {
+				return nil, fmt.Errorf("$ref %s: index %s out of range", ref, key)
+			}
+			value = container[index]
+		default:
+			return nil, fmt.Errorf("$ref %s: cannot select %s from a scalar", ref, key)
+		}
+	}
+
+	return value, nil
+}
+
+func isFixtureFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, fixtureExt := range fixtureExtensions {
+		if ext == fixtureExt {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeFixtureFile decodes a JSON or YAML fixture file into plain maps,
+// slices and scalars
+func decodeFixtureFile(path string) (interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var data interface{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(content, &data)
+	} else {
+		err = yaml.Unmarshal(content, &data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
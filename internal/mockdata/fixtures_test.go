@@ -0,0 +1,197 @@
+package mockdata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/templar/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fixtureButton = &types.ComponentInfo{ID: "example.com/app/ui.Button", Name: "Button", Package: "ui"}
+
+// writeFixtures writes files into a fresh fixture directory
+func writeFixtures(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestFixtures_Props(t *testing.T) {
+	dir := writeFixtures(t, map[string]string{
+		"buttons.yaml": `
+Button:
+  default: {text: Bare, size: small}
+ui.Button:
+  default: {text: Save}
+  Danger:
+    variant: danger
+    author: {$ref: shared/user, role: admin}
+`,
+		"cards.json": `{"example.com/app/ui.Button": {"Danger": {"size": "large"}}}`,
+		"shared/user.json": `{"name": "Alice", "role": "viewer", "address": {"city": "Oslo"}}`,
+	})
+
+	fixtures, err := LoadFixtures(dir)
+	require.NoError(t, err)
+
+	props, err := fixtures.Props(fixtureButton, DefaultVariant)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"text": "Save", "size": "small"}, props)
+
+	props, err = fixtures.Props(fixtureButton, "Danger")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"text":    "Save",
+		"size":    "large",
+		"variant": "danger",
+		"author": map[string]interface{}{
+			"name":    "Alice",
+			"role":    "admin",
+			"address": map[string]interface{}{"city": "Oslo"},
+		},
+	}, props)
+
+	props, err = fixtures.Props(&types.ComponentInfo{Name: "Badge", Package: "ui"}, DefaultVariant)
+	require.NoError(t, err)
+	assert.Nil(t, props)
+
+	var none *Fixtures
+	props, err = none.Props(fixtureButton, DefaultVariant)
+	require.NoError(t, err)
+	assert.Nil(t, props)
+}
+
+func TestFixtures_Apply(t *testing.T) {
+	dir := writeFixtures(t, map[string]string{
+		"fixtures.yaml": "Button:\n  default:\n    text: Save\n    style: {color: red}\n",
+	})
+
+	generated := map[string]interface{}{
+		"text":     "Sample text",
+		"disabled": true,
+		"style":    map[string]interface{}{"color": "blue", "size": "md"},
+	}
+	props, err := NewFixtures(dir).Apply(fixtureButton, DefaultVariant, generated)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"text":     "Save",
+		"disabled": true,
+		"style":    map[string]interface{}{"color": "red", "size": "md"},
+	}, props)
+
+	// The generated values are left untouched
+	assert.Equal(t, "Sample text", generated["text"])
+}
+
+func TestFixtures_Refs(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    map[string]interface{}
+		message string
+	}{
+		{
+			name: "fragment without extension",
+			files: map[string]string{
+				"fixtures.json":     `{"Button": {"default": {"user": {"$ref": "shared/users#/admins/0"}}}}`,
+				"shared/users.yaml": "admins:\n  - name: Root\n",
+			},
+			want: map[string]interface{}{"user": map[string]interface{}{"name": "Root"}},
+		},
+		{
+			name: "nested refs",
+			files: map[string]string{
+				"fixtures.json":    `{"Button": {"default": {"team": {"$ref": "shared/team.json"}}}}`,
+				"shared/team.json": `{"lead": {"$ref": "shared/user"}}`,
+				"shared/user.json": `{"name": "Alice"}`,
+			},
+			want: map[string]interface{}{"team": map[string]interface{}{"lead": map[string]interface{}{"name": "Alice"}}},
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"fixtures.json": `{"Button": {"default": {"a": {"$ref": "shared/a"}}}}`,
+				"shared/a.json": `{"b": {"$ref": "shared/b"}}`,
+				"shared/b.json": `{"a": {"$ref": "shared/a"}}`,
+			},
+			message: "circular $ref: shared/a -> shared/b -> shared/a",
+		},
+		{
+			name:    "outside the directory",
+			files:   map[string]string{"fixtures.json": `{"Button": {"default": {"a": {"$ref": "../secrets"}}}}`},
+			message: "must name a file inside the mock data directory",
+		},
+		{
+			name:    "missing file",
+			files:   map[string]string{"fixtures.json": `{"Button": {"default": {"a": {"$ref": "shared/missing"}}}}`},
+			message: "no fixture file shared/missing",
+		},
+		{
+			name: "missing key",
+			files: map[string]string{
+				"fixtures.json":    `{"Button": {"default": {"a": {"$ref": "shared/user#/email"}}}}`,
+				"shared/user.json": `{"name": "Alice"}`,
+			},
+			message: "key email not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props, err := NewFixtures(writeFixtures(t, tt.files)).Props(fixtureButton, DefaultVariant)
+			if tt.message != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.message)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, props)
+		})
+	}
+}
+
+func TestFixtures_Reload(t *testing.T) {
+	dir := writeFixtures(t, map[string]string{"fixtures.json": `{"Button": {"default": {"text": "Save"}}}`})
+	fixtures, err := LoadFixtures(dir)
+	require.NoError(t, err)
+
+	props, err := fixtures.Props(fixtureButton, DefaultVariant)
+	require.NoError(t, err)
+	assert.Equal(t, "Save", props["text"])
+
+	// Edits apply to the next lookup
+	path := filepath.Join(dir, "fixtures.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"Button": {"default": {"text": "Submit"}}}`), 0644))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, future, future))
+
+	props, err = fixtures.Props(fixtureButton, DefaultVariant)
+	require.NoError(t, err)
+	assert.Equal(t, "Submit", props["text"])
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"Button": `), 0644))
+	require.NoError(t, os.Chtimes(path, future.Add(time.Minute), future.Add(time.Minute)))
+	_, err = fixtures.Props(fixtureButton, DefaultVariant)
+	assert.ErrorContains(t, err, "fixture fixtures.json")
+
+	require.NoError(t, os.Remove(path))
+	props, err = fixtures.Props(fixtureButton, DefaultVariant)
+	require.NoError(t, err)
+	assert.Nil(t, props)
+}
+
+func TestLoadFixtures_MissingDirectory(t *testing.T) {
+	fixtures, err := LoadFixtures(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+	assert.NotNil(t, fixtures)
+}
@@ -0,0 +1,347 @@
+package monitoring
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/conneroisu/templar/internal/interfaces"
+)
+
+// metricTypeUnknown is the type of collected metrics that cannot be exposed
+// with their own type, such as pre-flattened histograms
+const metricTypeUnknown MetricType = "unknown"
+
+// Content types of the metric exposition formats
+const (
+	contentTypeJSON        = "application/json"
+	contentTypePrometheus  = "text/plain; version=0.0.4; charset=utf-8"
+	contentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// invalidMetricNameChars and invalidLabelNameChars match the characters
+// the exposition formats do not allow in metric and label names
+var (
+	invalidMetricNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+	invalidLabelNameChars  = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// metricFamily holds the series of one metric name
+type metricFamily struct {
+	name   string
+	typ    MetricType
+	help   string
+	series []metricSeries
+}
+
+// metricSeries is one labelled series of a metric family
+type metricSeries struct {
+	labels    map[string]string
+	value     float64
+	histogram *histogramSnapshot
+}
+
+// histogramSnapshot holds the cumulative bucket counts of a histogram,
+// ordered by upper bound
+type histogramSnapshot struct {
+	bounds []float64
+	counts []int64
+	count  int64
+	sum    float64
+}
+
+// ExportMetrics renders the current metrics in the given format
+func (mc *MetricsCollector) ExportMetrics(format interfaces.MetricFormat) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch format {
+	case interfaces.MetricFormatJSON:
+		if err := json.NewEncoder(&buf).Encode(map[string]interface{}{
+			"timestamp": time.Now(),
+			"metrics":   mc.GatherMetrics(),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to encode metrics: %w", err)
+		}
+	case interfaces.MetricFormatPrometheus:
+		writeExposition(&buf, mc.gatherFamilies(), false)
+	case interfaces.MetricFormatOpenMetrics:
+		writeExposition(&buf, mc.gatherFamilies(), true)
+	default:
+		return nil, fmt.Errorf("unsupported metric format: %s", format)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// gatherFamilies collects all current metrics grouped by name, sorted by
+// name and labels so that the output is stable between scrapes
+func (mc *MetricsCollector) gatherFamilies() []*metricFamily {
+	mc.mutex.RLock()
+	defer mc.mutex.RUnlock()
+
+	byName := make(map[string]*metricFamily)
+	add := func(metric *Metric, series metricSeries) {
+		name := sanitizeMetricName(metric.Name)
+		family, exists := byName[name]
+		if !exists {
+			help := metric.Help
+			if help == "" {
+				help = mc.help[metric.Name]
+			}
+			family = &metricFamily{name: name, typ: metric.Type, help: help}
+			byName[name] = family
+		}
+
+		// A name recorded as two types cannot be exposed as both
+		if family.typ != metric.Type {
+			return
+		}
+		family.series = append(family.series, series)
+	}
+
+	for key, counter := range mc.counters {
+		if metric, exists := mc.metrics[key]; exists {
+			add(metric, metricSeries{labels: metric.Labels, value: float64(atomic.LoadInt64(counter))})
+		}
+	}
+
+	for key, gauge := range mc.gauges {
+		if metric, exists := mc.metrics[key]; exists {
+			add(metric, metricSeries{labels: metric.Labels, value: *gauge})
+		}
+	}
+
+	for key, hist := range mc.histograms {
+		if metric, exists := mc.metrics[key]; exists {
+			add(metric, metricSeries{labels: metric.Labels, histogram: hist.snapshot()})
+		}
+	}
+
+	for _, collector := range mc.collectors {
+		for _, metric := range collector.Collect() {
+			// Collectors report histograms and summaries as plain samples
+			if metric.Type != MetricTypeCounter && metric.Type != MetricTypeGauge {
+				metric.Type = metricTypeUnknown
+			}
+			add(&metric, metricSeries{labels: metric.Labels, value: metric.Value})
+		}
+	}
+
+	families := make([]*metricFamily, 0, len(byName))
+	for _, family := range byName {
+		sort.Slice(family.series, func(i, j int) bool {
+			return formatLabels(family.series[i].labels, "", "") < formatLabels(family.series[j].labels, "", "")
+		})
+		families = append(families, family)
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name < families[j].name
+	})
+
+	return families
+}
+
+// snapshot returns the cumulative bucket counts of the histogram
+func (h *Histogram) snapshot() *histogramSnapshot {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	snapshot := &histogramSnapshot{
+		bounds: make([]float64, 0, len(h.buckets)),
+		count:  h.count,
+		sum:    h.sum,
+	}
+	for bound := range h.buckets {
+		snapshot.bounds = append(snapshot.bounds, bound)
+	}
+	sort.Float64s(snapshot.bounds)
+
+	// Observe counts a value in every bucket it fits, so the counts are
+	// already cumulative
+	snapshot.counts = make([]int64, len(snapshot.bounds))
+	for i, bound := range snapshot.bounds {
+		snapshot.counts[i] = h.buckets[bound]
+	}
+
+	return snapshot
+}
+
+// writeExposition writes metric families in the Prometheus text format, or
+// in the OpenMetrics text format if openMetrics is set
+func writeExposition(w io.Writer, families []*metricFamily, openMetrics bool) {
+	for _, family := range families {
+		name := family.name
+		typ := string(family.typ)
+		switch {
+		case openMetrics && family.typ == MetricTypeCounter:
+			// OpenMetrics names counter families without the suffix of
+			// their samples
+			name = strings.TrimSuffix(name, "_total")
+		case !openMetrics && family.typ == metricTypeUnknown:
+			typ = "untyped"
+		}
+
+		if family.help != "" {
+			fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(family.help, openMetrics))
+		}
+		fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+
+		for _, series := range family.series {
+			switch {
+			case series.histogram != nil:
+				hist := series.histogram
+				for i, bound := range hist.bounds {
+					writeSample(w, name+"_bucket", formatLabels(series.labels, "le", formatFloat(bound)), float64(hist.counts[i]))
+				}
+				writeSample(w, name+"_bucket", formatLabels(series.labels, "le", "+Inf"), float64(hist.count))
+				writeSample(w, name+"_sum", formatLabels(series.labels, "", ""), hist.sum)
+				writeSample(w, name+"_count", formatLabels(series.labels, "", ""), float64(hist.count))
+			case openMetrics && family.typ == MetricTypeCounter:
+				writeSample(w, name+"_total", formatLabels(series.labels, "", ""), series.value)
+			default:
+				writeSample(w, name, formatLabels(series.labels, "", ""), series.value)
+			}
+		}
+	}
+
+	if openMetrics {
+		fmt.Fprint(w, "# EOF\n")
+	}
+}
+
+// writeSample writes one sample line
+func writeSample(w io.Writer, name, labels string, value float64) {
+	fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(value))
+}
+
+// formatLabels renders a label set as {name="value",...}, sorted by name.
+// extraName adds a label such as a histogram bucket's "le", which takes
+// precedence over a label of the same name.
+func formatLabels(labels map[string]string, extraName, extraValue string) string {
+	if len(labels) == 0 && extraName == "" {
+		return ""
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		if sanitizeLabelName(name) != extraName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names)+1)
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, sanitizeLabelName(name), escapeLabelValue(labels[name])))
+	}
+	if extraName != "" {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extraName, escapeLabelValue(extraValue)))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// sanitizeMetricName replaces characters not allowed in metric names
+func sanitizeMetricName(name string) string {
+	name = invalidMetricNameChars.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// sanitizeLabelName replaces characters not allowed in label names
+func sanitizeLabelName(name string) string {
+	name = invalidLabelNameChars.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// escapeLabelValue escapes backslashes, double quotes and line feeds
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// escapeHelp escapes backslashes and line feeds in help text, and double
+// quotes as well in OpenMetrics
+func escapeHelp(help string, openMetrics bool) string {
+	if openMetrics {
+		return escapeLabelValue(help)
+	}
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+// formatFloat renders a sample value or bucket bound
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+// negotiateMetricFormat picks the format to serve for an Accept header.
+// JSON is served unless a text format is explicitly preferred, so clients
+// that do not ask for a format keep getting JSON.
+func negotiateMetricFormat(accept string) interfaces.MetricFormat {
+	format, best := interfaces.MetricFormatJSON, 0.0
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		var candidate interfaces.MetricFormat
+		switch mediaType {
+		case "application/openmetrics-text":
+			candidate = interfaces.MetricFormatOpenMetrics
+		case "text/plain":
+			candidate = interfaces.MetricFormatPrometheus
+		case "application/json":
+			candidate = interfaces.MetricFormatJSON
+		default:
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > best {
+			format, best = candidate, quality
+		}
+	}
+
+	return format
+}
+
+// metricContentType returns the content type of a metric format
+func metricContentType(format interfaces.MetricFormat) string {
+	switch format {
+	case interfaces.MetricFormatPrometheus:
+		return contentTypePrometheus
+	case interfaces.MetricFormatOpenMetrics:
+		return contentTypeOpenMetrics
+	default:
+		return contentTypeJSON
+	}
+}
@@ -0,0 +1,137 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/conneroisu/templar/internal/interfaces"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusExposition(t *testing.T) {
+	collector := NewMetricsCollector("templar", "")
+	app := NewApplicationMetrics(collector)
+	collector.RegisterCollector(app)
+
+	app.ComponentScanned("templ")
+	app.ComponentScanned("templ")
+	app.CacheOperation("get", true)
+	app.BuildDuration("Button", 0.02e9)
+	app.BuildDuration("Button", 0.3e9)
+	app.BuildDuration("Button", 20e9)
+	collector.Gauge("queue_depth", 3, map[string]string{"path": "C:\\\"quoted\"\nline"})
+
+	data, err := collector.ExportMetrics(interfaces.MetricFormatPrometheus)
+	require.NoError(t, err)
+	output := string(data)
+
+	assert.Contains(t, output, "# HELP templar_components_scanned_total Total number of components discovered by the scanner, by component type\n")
+	assert.Contains(t, output, "# TYPE templar_components_scanned_total counter\n")
+	assert.Contains(t, output, "templar_components_scanned_total{type=\"templ\"} 2\n")
+	assert.Contains(t, output, "templar_cache_operations_total{operation=\"get\",result=\"hit\"} 1\n")
+
+	// Buckets are cumulative and end with +Inf
+	assert.Contains(t, output, "# TYPE templar_build_duration_seconds histogram\n")
+	assert.Contains(t, output, "templar_build_duration_seconds_bucket{component=\"Button\",le=\"0.01\"} 0\n")
+	assert.Contains(t, output, "templar_build_duration_seconds_bucket{component=\"Button\",le=\"0.025\"} 1\n")
+	assert.Contains(t, output, "templar_build_duration_seconds_bucket{component=\"Button\",le=\"0.5\"} 2\n")
+	assert.Contains(t, output, "templar_build_duration_seconds_bucket{component=\"Button\",le=\"10\"} 2\n")
+	assert.Contains(t, output, "templar_build_duration_seconds_bucket{component=\"Button\",le=\"+Inf\"} 3\n")
+	assert.Contains(t, output, "templar_build_duration_seconds_sum{component=\"Button\"} 20.32\n")
+	assert.Contains(t, output, "templar_build_duration_seconds_count{component=\"Button\"} 3\n")
+
+	assert.Contains(t, output, `templar_queue_depth{path="C:\\\"quoted\"\nline"} 3`+"\n")
+	assert.Contains(t, output, "# TYPE templar_uptime_seconds gauge\n")
+	assert.NotContains(t, output, "# EOF")
+
+	// Each family is introduced once, before its samples
+	assert.Equal(t, 1, strings.Count(output, "# TYPE templar_build_duration_seconds "))
+	assert.Less(t, strings.Index(output, "# TYPE templar_build_duration_seconds "), strings.Index(output, "templar_build_duration_seconds_bucket"))
+
+	// Output is stable between scrapes
+	again, err := collector.ExportMetrics(interfaces.MetricFormatPrometheus)
+	require.NoError(t, err)
+	assert.Equal(t, removeUptime(output), removeUptime(string(again)))
+}
+
+func TestOpenMetricsExposition(t *testing.T) {
+	collector := NewMetricsCollector("templar", "")
+	app := NewApplicationMetrics(collector)
+	app.WebSocketConnection("opened")
+
+	data, err := collector.ExportMetrics(interfaces.MetricFormatOpenMetrics)
+	require.NoError(t, err)
+	output := string(data)
+
+	assert.Contains(t, output, "# TYPE templar_websocket_connections counter\n")
+	assert.Contains(t, output, "templar_websocket_connections_total{action=\"opened\"} 1\n")
+	assert.True(t, strings.HasSuffix(output, "# EOF\n"))
+}
+
+func TestMetricsKeyIsStable(t *testing.T) {
+	collector := NewMetricsCollector("test", "")
+	for i := 0; i < 20; i++ {
+		collector.Counter("requests_total", map[string]string{"a": "1", "b": "2", "c": "3", "d": "4"})
+	}
+
+	metrics := collector.GatherMetrics()
+	require.Len(t, metrics, 1)
+	assert.Equal(t, 20.0, metrics[0].Value)
+}
+
+func TestMetricsContentNegotiation(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", contentTypeJSON},
+		{"*/*", contentTypeJSON},
+		{"application/json", contentTypeJSON},
+		{"text/plain", contentTypePrometheus},
+		{"text/plain;version=0.0.4;q=0.5,*/*;q=0.1", contentTypePrometheus},
+		{"application/openmetrics-text;version=1.0.0,application/openmetrics-text;version=0.0.1;q=0.75,text/plain;version=0.0.4;q=0.5,*/*;q=0.1", contentTypeOpenMetrics},
+		{"text/plain;q=0.2,application/json", contentTypeJSON},
+	}
+
+	monitor := &Monitor{metrics: NewMetricsCollector("templar", "")}
+	monitor.metrics.Counter("requests_total", nil)
+	handler := monitor.createMetricsHandler()
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.contentType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+
+			if tt.contentType == contentTypeJSON {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Contains(t, body, "metrics")
+			} else {
+				assert.Contains(t, rec.Body.String(), "templar_requests_total")
+			}
+		})
+	}
+}
+
+// removeUptime drops the uptime sample, which changes between scrapes
+func removeUptime(output string) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(line, "templar_uptime_seconds ") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -40,6 +41,7 @@ type MetricsCollector struct {
 	counters    map[string]*int64
 	gauges      map[string]*float64
 	histograms  map[string]*Histogram
+	help        map[string]string
 	mutex       sync.RWMutex
 	prefix      string
 	enabled     bool
@@ -76,6 +78,7 @@ func NewMetricsCollector(prefix string, outputPath string) *MetricsCollector {
 		counters:    make(map[string]*int64),
 		gauges:      make(map[string]*float64),
 		histograms:  make(map[string]*Histogram),
+		help:        make(map[string]string),
 		prefix:      prefix,
 		enabled:     true,
 		outputPath:  outputPath,
@@ -250,6 +253,13 @@ func (mc *MetricsCollector) TimerContext(ctx context.Context, name string, label
 	}
 }
 
+// Describe sets the help text exposed for a metric
+func (mc *MetricsCollector) Describe(name, help string) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	mc.help[mc.getFullName(name)] = help
+}
+
 // RegisterCollector adds a custom metric collector
 func (mc *MetricsCollector) RegisterCollector(collector MetricCollector) {
 	mc.mutex.Lock()
@@ -331,8 +341,9 @@ func (mc *MetricsCollector) GatherMetrics() []Metric {
 				metricCopy.Name = metricCopy.Name + "_bucket"
 				metricCopy.Value = float64(count)
 				metricCopy.Timestamp = time.Now()
-				if metricCopy.Labels == nil {
-					metricCopy.Labels = make(map[string]string)
+				metricCopy.Labels = make(map[string]string, len(metric.Labels)+1)
+				for k, v := range metric.Labels {
+					metricCopy.Labels[k] = v
 				}
 				metricCopy.Labels["le"] = fmt.Sprintf("%.3f", bucket)
 				allMetrics = append(allMetrics, metricCopy)
@@ -392,13 +403,18 @@ func (mc *MetricsCollector) getFullName(name string) string {
 	return mc.prefix + "_" + name
 }
 
-// getKey generates a unique key for a metric with labels
+// getKey generates a unique key for a metric with labels. Labels are sorted
+// so that a label set always maps to the same series.
 func (mc *MetricsCollector) getKey(name string, labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
 	key := name
-	if labels != nil {
-		for k, v := range labels {
-			key += fmt.Sprintf("_%s_%s", k, v)
-		}
+	for _, k := range names {
+		key += fmt.Sprintf("_%s_%s", k, labels[k])
 	}
 	return key
 }
@@ -462,8 +478,41 @@ type ApplicationMetrics struct {
 	collector *MetricsCollector
 }
 
+// Names of the application metrics, before the collector's prefix. They
+// are scraped by dashboards and alerts and must not change.
+const (
+	MetricComponentsScanned    = "components_scanned_total"
+	MetricComponentsBuilt      = "components_built_total"
+	MetricBuildDuration        = "build_duration_seconds"
+	MetricHTTPRequests         = "http_requests_total"
+	MetricWebSocketConnections = "websocket_connections_total"
+	MetricWebSocketMessages    = "websocket_messages_total"
+	MetricFileWatcherEvents    = "file_watcher_events_total"
+	MetricCacheOperations      = "cache_operations_total"
+	MetricErrors               = "errors_total"
+	MetricUptime               = "uptime_seconds"
+)
+
+// applicationMetricHelp is the help text exposed for the application metrics
+var applicationMetricHelp = map[string]string{
+	MetricComponentsScanned:    "Total number of components discovered by the scanner, by component type",
+	MetricComponentsBuilt:      "Total number of component builds, by component and status",
+	MetricBuildDuration:        "Duration of component builds in seconds",
+	MetricHTTPRequests:         "Total number of HTTP requests, by method, path and status",
+	MetricWebSocketConnections: "Total number of WebSocket connection events, by action",
+	MetricWebSocketMessages:    "Total number of WebSocket messages, by type",
+	MetricFileWatcherEvents:    "Total number of file watcher events, by type",
+	MetricCacheOperations:      "Total number of cache operations, by operation and result",
+	MetricErrors:               "Total number of errors, by category and component",
+	MetricUptime:               "Application uptime in seconds",
+}
+
 // NewApplicationMetrics creates application metrics collector
 func NewApplicationMetrics(collector *MetricsCollector) *ApplicationMetrics {
+	for name, help := range applicationMetricHelp {
+		collector.Describe(name, help)
+	}
+
 	return &ApplicationMetrics{
 		collector: collector,
 	}
@@ -471,7 +520,7 @@ func NewApplicationMetrics(collector *MetricsCollector) *ApplicationMetrics {
 
 // ComponentScanned increments component scan counter
 func (am *ApplicationMetrics) ComponentScanned(componentType string) {
-	am.collector.Counter("components_scanned_total", map[string]string{
+	am.collector.Counter(MetricComponentsScanned, map[string]string{
 		"type": componentType,
 	})
 }
@@ -483,7 +532,7 @@ func (am *ApplicationMetrics) ComponentBuilt(componentName string, success bool)
 		status = "error"
 	}
 
-	am.collector.Counter("components_built_total", map[string]string{
+	am.collector.Counter(MetricComponentsBuilt, map[string]string{
 		"component": componentName,
 		"status":    status,
 	})
@@ -491,14 +540,14 @@ func (am *ApplicationMetrics) ComponentBuilt(componentName string, success bool)
 
 // BuildDuration records build duration
 func (am *ApplicationMetrics) BuildDuration(componentName string, duration time.Duration) {
-	am.collector.Histogram("build_duration_seconds", duration.Seconds(), map[string]string{
+	am.collector.Histogram(MetricBuildDuration, duration.Seconds(), map[string]string{
 		"component": componentName,
 	})
 }
 
 // ServerRequest increments server request counter
 func (am *ApplicationMetrics) ServerRequest(method, path string, statusCode int) {
-	am.collector.Counter("http_requests_total", map[string]string{
+	am.collector.Counter(MetricHTTPRequests, map[string]string{
 		"method": method,
 		"path":   path,
 		"status": fmt.Sprintf("%d", statusCode),
@@ -507,21 +556,21 @@ func (am *ApplicationMetrics) ServerRequest(method, path string, statusCode int)
 
 // WebSocketConnection tracks WebSocket connections
 func (am *ApplicationMetrics) WebSocketConnection(action string) {
-	am.collector.Counter("websocket_connections_total", map[string]string{
+	am.collector.Counter(MetricWebSocketConnections, map[string]string{
 		"action": action, // "opened", "closed", "error"
 	})
 }
 
 // WebSocketMessage tracks WebSocket messages
 func (am *ApplicationMetrics) WebSocketMessage(messageType string) {
-	am.collector.Counter("websocket_messages_total", map[string]string{
+	am.collector.Counter(MetricWebSocketMessages, map[string]string{
 		"type": messageType,
 	})
 }
 
 // FileWatcherEvent tracks file watcher events
 func (am *ApplicationMetrics) FileWatcherEvent(eventType string) {
-	am.collector.Counter("file_watcher_events_total", map[string]string{
+	am.collector.Counter(MetricFileWatcherEvents, map[string]string{
 		"type": eventType,
 	})
 }
@@ -533,7 +582,7 @@ func (am *ApplicationMetrics) CacheOperation(operation string, hit bool) {
 		hitStr = "hit"
 	}
 
-	am.collector.Counter("cache_operations_total", map[string]string{
+	am.collector.Counter(MetricCacheOperations, map[string]string{
 		"operation": operation,
 		"result":    hitStr,
 	})
@@ -541,7 +590,7 @@ func (am *ApplicationMetrics) CacheOperation(operation string, hit bool) {
 
 // ErrorOccurred tracks errors by category and component
 func (am *ApplicationMetrics) ErrorOccurred(category, component string) {
-	am.collector.Counter("errors_total", map[string]string{
+	am.collector.Counter(MetricErrors, map[string]string{
 		"category":  category,
 		"component": component,
 	})
@@ -557,11 +606,11 @@ func (am *ApplicationMetrics) Collect() []Metric {
 	// This method can be used to export additional computed metrics
 	return []Metric{
 		{
-			Name:      am.collector.getFullName("uptime_seconds"),
+			Name:      am.collector.getFullName(MetricUptime),
 			Type:      MetricTypeGauge,
 			Value:     time.Since(startTime).Seconds(),
 			Timestamp: time.Now(),
-			Help:      "Application uptime in seconds",
+			Help:      applicationMetricHelp[MetricUptime],
 		},
 	}
 }
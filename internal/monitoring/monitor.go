@@ -417,17 +417,19 @@ func (m *Monitor) createMetricsHandler() http.HandlerFunc {
 			return
 		}
 
-		metrics := m.metrics.GatherMetrics()
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-
-		if err := json.NewEncoder(w).Encode(map[string]interface{}{
-			"timestamp": time.Now(),
-			"metrics":   metrics,
-		}); err != nil {
+		// Scrapers ask for a text format; everyone else gets JSON
+		format := negotiateMetricFormat(r.Header.Get("Accept"))
+		data, err := m.metrics.ExportMetrics(format)
+		if err != nil {
 			m.logger.Error(context.Background(), err, "Failed to encode metrics")
+			http.Error(w, "Failed to encode metrics", http.StatusInternalServerError)
+			return
 		}
+
+		w.Header().Set("Content-Type", metricContentType(format))
+		w.Header().Set("Vary", "Accept")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(data)
 	}
 }
 
@@ -0,0 +1,489 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// defaultExternalTimeout bounds calls to external plugins whose settings
+// don't configure a timeout
+const defaultExternalTimeout = 30 * time.Second
+
+// healthCheckTimeout bounds the health call of an external plugin
+const healthCheckTimeout = 5 * time.Second
+
+// retryBackoff is the wait before the first retry of a failed call, doubled
+// on each further retry up to maxRetryBackoff
+const (
+	retryBackoff    = 100 * time.Millisecond
+	maxRetryBackoff = 2 * time.Second
+)
+
+// ExternalPlugin runs a plugin described by a manifest as a child process,
+// forwarding the hooks of the interfaces it declares over JSON-RPC. Calls are
+// bounded by PluginSettings.Timeout; calls that time out or hit a crashed
+// process are retried up to PluginSettings.MaxRetries times on a restarted
+// process.
+type ExternalPlugin struct {
+	manifest Manifest
+
+	mu          sync.Mutex
+	process     *pluginProcess
+	config      PluginConfig
+	initialized bool
+	lastError   string
+
+	calls    int64
+	failures int64
+	restarts int64
+}
+
+var (
+	_ ComponentPlugin = (*ExternalPlugin)(nil)
+	_ BuildPlugin     = (*ExternalPlugin)(nil)
+	_ ServerPlugin    = (*ExternalPlugin)(nil)
+	_ WatcherPlugin   = (*ExternalPlugin)(nil)
+	_ usageReporter   = (*ExternalPlugin)(nil)
+)
+
+// NewExternalPlugin creates an external plugin for a validated manifest. The
+// process starts when the plugin is initialized.
+func NewExternalPlugin(manifest Manifest) *ExternalPlugin {
+	return &ExternalPlugin{manifest: manifest}
+}
+
+// Manifest returns the manifest the plugin was created from
+func (p *ExternalPlugin) Manifest() Manifest {
+	return p.manifest
+}
+
+// Name implements Plugin
+func (p *ExternalPlugin) Name() string {
+	return p.manifest.Name
+}
+
+// Version implements Plugin
+func (p *ExternalPlugin) Version() string {
+	return p.manifest.Version
+}
+
+// Description implements Plugin
+func (p *ExternalPlugin) Description() string {
+	return p.manifest.Description
+}
+
+// Interfaces returns the plugin interfaces declared in the manifest. The
+// plugin managers only hand an external plugin the hooks it declares.
+func (p *ExternalPlugin) Interfaces() []string {
+	return p.manifest.Interfaces
+}
+
+// Initialize implements Plugin by starting the plugin process. Initializing a
+// running plugin again is a no-op.
+func (p *ExternalPlugin) Initialize(ctx context.Context, config PluginConfig) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.config = config
+	p.initialized = true
+	_, err := p.ensureProcessLocked(ctx)
+	return err
+}
+
+// Shutdown implements Plugin by asking the plugin process to exit
+func (p *ExternalPlugin) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.initialized = false
+	process := p.process
+	p.process = nil
+	if process == nil || !process.running() {
+		return nil
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, callTimeout(p.config.Settings))
+	err := process.call(callCtx, MethodShutdown, nil, nil)
+	cancel()
+	process.stop()
+
+	if err != nil && !errors.Is(err, errProcessExited) {
+		return fmt.Errorf("plugin %s: %w", p.manifest.Name, err)
+	}
+	return nil
+}
+
+// Health implements Plugin. Crashed plugins are degraded until their next
+// call restarts them.
+func (p *ExternalPlugin) Health() PluginHealth {
+	p.mu.Lock()
+	process := p.process
+	initialized := p.initialized
+	lastError := p.lastError
+	settings := p.config.Settings
+	p.mu.Unlock()
+
+	health := PluginHealth{
+		Status:    HealthStatusHealthy,
+		LastCheck: time.Now(),
+		Metrics: map[string]interface{}{
+			"calls":    atomic.LoadInt64(&p.calls),
+			"failures": atomic.LoadInt64(&p.failures),
+			"restarts": atomic.LoadInt64(&p.restarts),
+		},
+	}
+
+	switch {
+	case !initialized:
+		health.Status = HealthStatusUnknown
+		return health
+	case process == nil || !process.running():
+		health.Status = HealthStatusDegraded
+		health.Error = lastError
+		return health
+	}
+	health.Metrics["pid"] = process.cmd.Process.Pid
+
+	var result struct {
+		Status HealthStatus `json:"status"`
+		Error  string       `json:"error,omitempty"`
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), min(callTimeout(settings), healthCheckTimeout))
+	defer cancel()
+
+	var rpcErr *RPCError
+	err := process.call(ctx, MethodHealth, nil, &result)
+	switch {
+	case errors.As(err, &rpcErr) && rpcErr.Code == rpcErrorMethodNotFound:
+		// Plugins need not implement health checks
+	case err != nil:
+		health.Status = HealthStatusUnhealthy
+		health.Error = err.Error()
+	case result.Status != "":
+		health.Status = result.Status
+		health.Error = result.Error
+	}
+	return health
+}
+
+// HandleComponent implements ComponentPlugin
+func (p *ExternalPlugin) HandleComponent(ctx context.Context, component *types.ComponentInfo) (*types.ComponentInfo, error) {
+	var result struct {
+		Component *types.ComponentInfo `json:"component"`
+	}
+	params := map[string]interface{}{"component": component}
+	if err := p.call(ctx, MethodHandleComponent, params, &result); err != nil {
+		return nil, err
+	}
+	if result.Component == nil {
+		return component, nil
+	}
+	return result.Component, nil
+}
+
+// SupportedExtensions implements ComponentPlugin
+func (p *ExternalPlugin) SupportedExtensions() []string {
+	return p.manifest.Extensions
+}
+
+// Priority implements ComponentPlugin
+func (p *ExternalPlugin) Priority() int {
+	return p.manifest.Priority
+}
+
+// PreBuild implements BuildPlugin
+func (p *ExternalPlugin) PreBuild(ctx context.Context, components []*types.ComponentInfo) error {
+	params := map[string]interface{}{"components": components}
+	return p.call(ctx, MethodPreBuild, params, nil)
+}
+
+// PostBuild implements BuildPlugin
+func (p *ExternalPlugin) PostBuild(ctx context.Context, components []*types.ComponentInfo, buildResult BuildResult) error {
+	params := map[string]interface{}{"components": components, "result": buildResult}
+	return p.call(ctx, MethodPostBuild, params, nil)
+}
+
+// TransformBuildCommand implements BuildPlugin
+func (p *ExternalPlugin) TransformBuildCommand(ctx context.Context, command []string) ([]string, error) {
+	var result struct {
+		Command []string `json:"command"`
+	}
+	params := map[string]interface{}{"command": command}
+	if err := p.call(ctx, MethodTransformCommand, params, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Command) == 0 {
+		return command, nil
+	}
+	return result.Command, nil
+}
+
+// RegisterRoutes implements ServerPlugin by forwarding requests to the
+// routes listed in the manifest to the plugin
+func (p *ExternalPlugin) RegisterRoutes(router Router) error {
+	for _, route := range p.manifest.Routes {
+		handler := p.routeHandler()
+		switch route.Method {
+		case "GET":
+			router.GET(route.Path, handler)
+		case "POST":
+			router.POST(route.Path, handler)
+		case "PUT":
+			router.PUT(route.Path, handler)
+		case "DELETE":
+			router.DELETE(route.Path, handler)
+		}
+	}
+	return nil
+}
+
+// routeHandler forwards an HTTP request to the plugin and writes its answer
+func (p *ExternalPlugin) routeHandler() HandlerFunc {
+	return func(c Context) error {
+		body, err := c.Body()
+		if err != nil {
+			return err
+		}
+
+		var result struct {
+			Status int         `json:"status"`
+			Body   string      `json:"body"`
+			JSON   interface{} `json:"json"`
+		}
+		params := map[string]interface{}{
+			"method": c.Method(),
+			"path":   c.Path(),
+			"body":   string(body),
+		}
+		if err := p.call(c.Context(), MethodServerRequest, params, &result); err != nil {
+			return c.String(http.StatusBadGateway, err.Error())
+		}
+
+		if result.Status == 0 {
+			result.Status = http.StatusOK
+		}
+		if result.JSON != nil {
+			return c.JSON(result.Status, result.JSON)
+		}
+		return c.String(result.Status, result.Body)
+	}
+}
+
+// Middleware implements ServerPlugin. External plugins can't wrap handlers.
+func (p *ExternalPlugin) Middleware() []MiddlewareFunc {
+	return nil
+}
+
+// WebSocketHandler implements ServerPlugin by forwarding each received
+// message to the plugin and sending back the messages it answers with
+func (p *ExternalPlugin) WebSocketHandler(ctx context.Context, conn WebSocketConnection) error {
+	for {
+		data, err := conn.Receive()
+		if err != nil {
+			return err
+		}
+
+		var result struct {
+			Messages []string `json:"messages"`
+		}
+		params := map[string]interface{}{
+			"remote_addr": conn.RemoteAddr(),
+			"data":        string(data),
+		}
+		if err := p.call(ctx, MethodWebSocketMessage, params, &result); err != nil {
+			return err
+		}
+
+		for _, message := range result.Messages {
+			if err := conn.Send([]byte(message)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// WatchPatterns implements WatcherPlugin
+func (p *ExternalPlugin) WatchPatterns() []string {
+	return p.manifest.WatchPatterns
+}
+
+// HandleFileChange implements WatcherPlugin
+func (p *ExternalPlugin) HandleFileChange(ctx context.Context, event FileChangeEvent) error {
+	params := map[string]interface{}{"event": event}
+	return p.call(ctx, MethodFileChange, params, nil)
+}
+
+// ShouldIgnore implements WatcherPlugin. Changes are not ignored when the
+// plugin fails to answer.
+func (p *ExternalPlugin) ShouldIgnore(filePath string) bool {
+	var result struct {
+		Ignore bool `json:"ignore"`
+	}
+	params := map[string]interface{}{"path": filePath}
+	if err := p.call(context.Background(), MethodShouldIgnore, params, &result); err != nil {
+		return false
+	}
+	return result.Ignore
+}
+
+// call calls a method of the plugin, restarting the process and retrying
+// when it crashes or doesn't answer in time. Errors returned by the plugin
+// itself are not retried.
+func (p *ExternalPlugin) call(ctx context.Context, method string, params, result interface{}) error {
+	atomic.AddInt64(&p.calls, 1)
+
+	settings := p.settings()
+	attempts := max(settings.MaxRetries, 0) + 1
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 && !waitToRetry(ctx, attempt) {
+			break
+		}
+
+		var process *pluginProcess
+		process, err = p.ensureProcess(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, callTimeout(settings))
+		err = process.call(callCtx, method, params, result)
+		cancel()
+
+		var rpcErr *RPCError
+		if err == nil || errors.As(err, &rpcErr) || ctx.Err() != nil {
+			break
+		}
+
+		// The process crashed or hangs; a hung process is killed so that
+		// the next attempt starts a fresh one
+		if process.running() {
+			_ = process.cmd.Process.Kill()
+			<-process.exited
+		}
+		p.recordError(err)
+	}
+
+	if err != nil {
+		atomic.AddInt64(&p.failures, 1)
+		return fmt.Errorf("plugin %s: %s: %w", p.manifest.Name, method, err)
+	}
+	return nil
+}
+
+// waitToRetry waits before a retry, doubling the wait on each attempt. It
+// returns false when ctx is done first.
+func waitToRetry(ctx context.Context, attempt int) bool {
+	backoff := min(retryBackoff<<(attempt-1), maxRetryBackoff)
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// ensureProcess returns the running plugin process, restarting it if it
+// exited
+func (p *ExternalPlugin) ensureProcess(ctx context.Context) (*pluginProcess, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.initialized {
+		return nil, fmt.Errorf("plugin %s is not initialized", p.manifest.Name)
+	}
+	return p.ensureProcessLocked(ctx)
+}
+
+// ensureProcessLocked starts and initializes the plugin process unless it is
+// running. The caller must hold p.mu.
+func (p *ExternalPlugin) ensureProcessLocked(ctx context.Context) (*pluginProcess, error) {
+	if p.process != nil {
+		if p.process.running() {
+			return p.process, nil
+		}
+		atomic.AddInt64(&p.restarts, 1)
+		p.process = nil
+	}
+
+	process, err := startPluginProcess(p.manifest, p.config.Settings.ResourceLimits)
+	if err != nil {
+		p.lastError = err.Error()
+		return nil, err
+	}
+
+	var result struct {
+		ProtocolVersion int `json:"protocol_version"`
+	}
+	params := map[string]interface{}{
+		"protocol_version": ProtocolVersion,
+		"name":             p.manifest.Name,
+		"config":           p.config.Config,
+		"log_level":        p.config.Settings.LogLevel,
+	}
+	callCtx, cancel := context.WithTimeout(ctx, callTimeout(p.config.Settings))
+	err = process.call(callCtx, MethodInitialize, params, &result)
+	cancel()
+	if err == nil && result.ProtocolVersion != ProtocolVersion {
+		err = fmt.Errorf("plugin speaks protocol version %d, expected %d", result.ProtocolVersion, ProtocolVersion)
+	}
+	if err != nil {
+		_ = process.cmd.Process.Kill()
+		<-process.exited
+		p.lastError = err.Error()
+		return nil, fmt.Errorf("initializing plugin %s: %w", p.manifest.Name, err)
+	}
+
+	p.process = process
+	return process, nil
+}
+
+// resourceUsage implements usageReporter with the usage of the running
+// plugin process
+func (p *ExternalPlugin) resourceUsage() (processUsage, bool) {
+	p.mu.Lock()
+	process := p.process
+	p.mu.Unlock()
+
+	if process == nil || !process.running() {
+		return processUsage{}, false
+	}
+	usage, err := readProcessUsage(process.cmd.Process.Pid)
+	return usage, err == nil
+}
+
+// recordError remembers the last transport error for health reports
+func (p *ExternalPlugin) recordError(err error) {
+	p.mu.Lock()
+	p.lastError = err.Error()
+	p.mu.Unlock()
+}
+
+// settings returns the plugin settings it was initialized with
+func (p *ExternalPlugin) settings() PluginSettings {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.config.Settings
+}
+
+// callTimeout returns the time a single call may take
+func callTimeout(settings PluginSettings) time.Duration {
+	if settings.Timeout > 0 {
+		return settings.Timeout
+	}
+	return defaultExternalTimeout
+}
@@ -0,0 +1,357 @@
+package plugins
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/templar/internal/config"
+	"github.com/conneroisu/templar/internal/errors"
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExternalPluginProcess is not a test: the external plugin tests run the
+// test binary with it as the plugin process
+func TestExternalPluginProcess(t *testing.T) {
+	mode := os.Getenv("TEMPLAR_TEST_PLUGIN")
+	if mode == "" {
+		t.Skip("only runs as an external plugin process")
+	}
+	state := os.Getenv("TEMPLAR_TEST_PLUGIN_STATE")
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	encoder := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		var request struct {
+			ID     uint64          `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			os.Exit(2)
+		}
+
+		var params map[string]interface{}
+		_ = json.Unmarshal(request.Params, &params)
+
+		var result interface{}
+		var rpcErr *RPCError
+		switch request.Method {
+		case MethodInitialize:
+			result = map[string]interface{}{"protocol_version": ProtocolVersion}
+		case MethodShutdown:
+			_ = encoder.Encode(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": nil})
+			os.Exit(0)
+		case MethodHandleComponent:
+			switch mode {
+			case "crash":
+				// Crash on the first call of the test only
+				marker := filepath.Join(state, "crashed")
+				if _, err := os.Stat(marker); err != nil {
+					_ = os.WriteFile(marker, nil, 0644)
+					os.Exit(3)
+				}
+			case "hang":
+				time.Sleep(time.Hour)
+			}
+			component := params["component"].(map[string]interface{})
+			component["Metadata"] = map[string]interface{}{"external": true}
+			result = map[string]interface{}{"component": component}
+		case MethodTransformCommand:
+			command := params["command"].([]interface{})
+			result = map[string]interface{}{"command": append(command, "-v")}
+		case MethodShouldIgnore:
+			result = map[string]interface{}{"ignore": strings.HasSuffix(params["path"].(string), ".tmp")}
+		case MethodServerRequest:
+			if mode == "hang" {
+				time.Sleep(time.Hour)
+			}
+			result = map[string]interface{}{"status": 201, "json": map[string]interface{}{"path": params["path"]}}
+		case MethodPreBuild:
+			rpcErr = &RPCError{Code: 1, Message: "pre-build refused"}
+		default:
+			rpcErr = &RPCError{Code: rpcErrorMethodNotFound, Message: "method not found"}
+		}
+
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": result}
+		if rpcErr != nil {
+			response["error"] = rpcErr
+		}
+		_ = encoder.Encode(response)
+	}
+	os.Exit(0)
+}
+
+// testManifest returns a manifest running the test binary as a plugin in a mode
+func testManifest(t *testing.T, mode string) Manifest {
+	executable, err := filepath.Abs(os.Args[0])
+	require.NoError(t, err)
+
+	return Manifest{
+		Name:       "external-" + mode,
+		Version:    "1.0.0",
+		Protocol:   ProtocolVersion,
+		Command:    executable,
+		Args:       []string{"-test.run=^TestExternalPluginProcess$"},
+		Env:        map[string]string{"TEMPLAR_TEST_PLUGIN": mode, "TEMPLAR_TEST_PLUGIN_STATE": t.TempDir()},
+		Interfaces: []string{"ComponentPlugin", "BuildPlugin", "WatcherPlugin", "ServerPlugin"},
+		Routes:     []ManifestRoute{{Method: "GET", Path: "/external"}},
+	}
+}
+
+func testPluginConfig(timeout time.Duration, maxRetries int) PluginConfig {
+	return PluginConfig{
+		Enabled:  true,
+		Settings: PluginSettings{Timeout: timeout, MaxRetries: maxRetries},
+	}
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ManifestFileName)
+
+	write := func(manifest string) {
+		require.NoError(t, os.WriteFile(path, []byte(manifest), 0644))
+	}
+
+	write(`{"name": "lint", "protocol": 1, "command": "./bin/lint", "interfaces": ["ComponentPlugin"]}`)
+	manifest, err := LoadManifest(path)
+	require.NoError(t, err)
+	assert.Equal(t, "lint", manifest.Name)
+	assert.Equal(t, filepath.Join(dir, "bin", "lint"), manifest.CommandPath())
+
+	manifest.Command = "lint"
+	assert.Equal(t, "lint", manifest.CommandPath(), "bare commands are looked up in PATH")
+
+	invalid := map[string]string{
+		"missing name":     `{"protocol": 1, "command": "lint"}`,
+		"missing command":  `{"name": "lint", "protocol": 1}`,
+		"unknown protocol": `{"name": "lint", "protocol": 2, "command": "lint"}`,
+		"unknown iface":    `{"name": "lint", "protocol": 1, "command": "lint", "interfaces": ["Linter"]}`,
+		"bad route":        `{"name": "lint", "protocol": 1, "command": "lint", "routes": [{"method": "PATCH", "path": "/lint"}]}`,
+		"malformed":        `{"name":`,
+	}
+	for name, manifest := range invalid {
+		write(manifest)
+		_, err := LoadManifest(path)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestExternalPlugin(t *testing.T) {
+	ctx := context.Background()
+	plugin := NewExternalPlugin(testManifest(t, "normal"))
+	assert.Equal(t, HealthStatusUnknown, plugin.Health().Status)
+
+	require.NoError(t, plugin.Initialize(ctx, testPluginConfig(5*time.Second, 1)))
+	defer plugin.Shutdown(ctx)
+	assert.Equal(t, HealthStatusHealthy, plugin.Health().Status)
+
+	component, err := plugin.HandleComponent(ctx, &types.ComponentInfo{Name: "Button"})
+	require.NoError(t, err)
+	assert.Equal(t, "Button", component.Name)
+	assert.Equal(t, true, component.Metadata["external"])
+
+	command, err := plugin.TransformBuildCommand(ctx, []string{"templ", "generate"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"templ", "generate", "-v"}, command)
+
+	assert.True(t, plugin.ShouldIgnore("component.tmp"))
+	assert.False(t, plugin.ShouldIgnore("component.templ"))
+
+	// Plugin errors are returned without retries or restarts
+	err = plugin.PreBuild(ctx, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pre-build refused")
+
+	// Hooks the plugin doesn't implement report method not found
+	assert.Error(t, plugin.HandleFileChange(ctx, FileChangeEvent{Path: "a.templ"}))
+
+	health := plugin.Health()
+	assert.Equal(t, int64(0), health.Metrics["restarts"])
+	assert.Equal(t, int64(2), health.Metrics["failures"])
+
+	require.NoError(t, plugin.Shutdown(ctx))
+	assert.Equal(t, HealthStatusUnknown, plugin.Health().Status)
+}
+
+func TestExternalPluginRestartsAfterCrash(t *testing.T) {
+	ctx := context.Background()
+	plugin := NewExternalPlugin(testManifest(t, "crash"))
+	require.NoError(t, plugin.Initialize(ctx, testPluginConfig(5*time.Second, 1)))
+	defer plugin.Shutdown(ctx)
+
+	component, err := plugin.HandleComponent(ctx, &types.ComponentInfo{Name: "Card"})
+	require.NoError(t, err, "the call is retried on a restarted process")
+	assert.Equal(t, true, component.Metadata["external"])
+
+	health := plugin.Health()
+	assert.Equal(t, HealthStatusHealthy, health.Status)
+	assert.Equal(t, int64(1), health.Metrics["restarts"])
+}
+
+func TestExternalPluginTimeout(t *testing.T) {
+	ctx := context.Background()
+	plugin := NewExternalPlugin(testManifest(t, "hang"))
+	require.NoError(t, plugin.Initialize(ctx, testPluginConfig(time.Second, 1)))
+	defer plugin.Shutdown(ctx)
+
+	start := time.Now()
+	_, err := plugin.HandleComponent(ctx, &types.ComponentInfo{Name: "Card"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 10*time.Second)
+
+	// Hung processes are killed, so the plugin is degraded until restarted
+	health := plugin.Health()
+	assert.Equal(t, HealthStatusDegraded, health.Status)
+	assert.Equal(t, int64(1), health.Metrics["restarts"])
+	assert.Equal(t, int64(1), health.Metrics["failures"])
+
+	// The next call restarts the process
+	command, err := plugin.TransformBuildCommand(ctx, []string{"templ"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"templ", "-v"}, command)
+}
+
+func TestDiscoverExternalPlugins(t *testing.T) {
+	ctx := context.Background()
+
+	manifest := testManifest(t, "normal")
+	manifest.Interfaces = []string{"ComponentPlugin"}
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	for _, name := range []string{"enabled", "disabled"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0755))
+		named := strings.Replace(string(data), manifest.Name, name, 1)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name, ManifestFileName), []byte(named), 0644))
+	}
+
+	cfg := &config.PluginsConfig{
+		Enabled:        []string{"enabled"},
+		DiscoveryPaths: []string{dir},
+		Configurations: make(map[string]config.PluginConfigMap),
+	}
+	logger := &MockLogger{}
+	epm := NewEnhancedPluginManager(cfg, logger, errors.NewErrorHandler(logger, nil), registry.NewComponentRegistry())
+	buildAdapter := NewBuildPipelineAdapter()
+	epm.SetIntegrations(buildAdapter, NewServerAdapter(), NewWatcherAdapter())
+
+	require.NoError(t, epm.Initialize(ctx))
+	defer epm.Shutdown(ctx)
+
+	info := epm.GetPluginInfo()
+	require.Contains(t, info, "enabled")
+	assert.Equal(t, "file", info["enabled"].Source)
+	assert.Equal(t, filepath.Join(dir, "enabled", ManifestFileName), info["enabled"].Path)
+	assert.Equal(t, []string{"Plugin", "ComponentPlugin"}, info["enabled"].Interfaces)
+
+	// External plugins only load when enabled in the configuration
+	assert.Equal(t, PluginStateEnabled, epm.GetPluginState("enabled"))
+	assert.Equal(t, PluginStateDiscovered, epm.GetPluginState("disabled"))
+
+	// Only the declared hooks are used
+	assert.Empty(t, buildAdapter.preHooks)
+
+	component, err := epm.ProcessComponent(ctx, &types.ComponentInfo{Name: "Card"})
+	require.NoError(t, err)
+	assert.Equal(t, true, component.Metadata["external"])
+}
+
+// routeContext is the request context of a plugin route in tests
+type routeContext struct {
+	ctx    context.Context
+	status int
+	body   string
+}
+
+func (c *routeContext) Method() string           { return http.MethodGet }
+func (c *routeContext) Path() string             { return "/external" }
+func (c *routeContext) Param(key string) string  { return "" }
+func (c *routeContext) Query(key string) string  { return "" }
+func (c *routeContext) Header(key string) string { return "" }
+func (c *routeContext) Body() ([]byte, error)    { return nil, nil }
+func (c *routeContext) JSON(code int, data interface{}) error {
+	return c.String(code, fmt.Sprint(data))
+}
+func (c *routeContext) File(filePath string) error          { return nil }
+func (c *routeContext) Redirect(code int, url string) error { return nil }
+func (c *routeContext) Set(key string, value interface{})   {}
+func (c *routeContext) Get(key string) interface{}          { return nil }
+func (c *routeContext) Context() context.Context            { return c.ctx }
+func (c *routeContext) String(code int, data string) error {
+	c.status, c.body = code, data
+	return nil
+}
+
+func TestExternalPluginRouteCanceled(t *testing.T) {
+	ctx := context.Background()
+	plugin := NewExternalPlugin(testManifest(t, "hang"))
+	require.NoError(t, plugin.Initialize(ctx, testPluginConfig(3*time.Second, 0)))
+	defer plugin.Shutdown(ctx)
+
+	// A client disconnecting cancels the call to the plugin before it times out
+	requestCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	c := &routeContext{ctx: requestCtx}
+
+	start := time.Now()
+	require.NoError(t, plugin.routeHandler()(c))
+	assert.Equal(t, http.StatusBadGateway, c.status)
+	assert.Contains(t, c.body, context.DeadlineExceeded.Error())
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestPluginProcessDropsUnexpectedResponses(t *testing.T) {
+	reader, writer := io.Pipe()
+	process := &pluginProcess{
+		cmd:     exec.Command("true"),
+		stderr:  &outputTail{limit: pluginStderrLimit},
+		limiter: newProcessLimiter("test", ResourceLimits{}),
+		pending: make(map[uint64]chan rpcResponse),
+		exited:  make(chan struct{}),
+	}
+	first := make(chan rpcResponse, 1)
+	process.pending[1] = first
+	go process.readResponses(reader)
+
+	// Repeated answers and answers to unknown calls are dropped without
+	// blocking the reader
+	for _, id := range []int{1, 1, 1, 7} {
+		_, err := fmt.Fprintf(writer, "{\"jsonrpc\":\"2.0\",\"id\":%d,\"result\":%d}\n", id, id)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, uint64(1), (<-first).ID)
+
+	second := make(chan rpcResponse, 1)
+	process.mu.Lock()
+	process.pending[2] = second
+	process.mu.Unlock()
+	_, err := fmt.Fprintf(writer, "{\"jsonrpc\":\"2.0\",\"id\":2,\"result\":2}\n")
+	require.NoError(t, err)
+
+	select {
+	case response := <-second:
+		assert.Equal(t, uint64(2), response.ID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the reader is blocked")
+	}
+
+	require.NoError(t, writer.Close())
+	<-process.exited
+}
@@ -0,0 +1,195 @@
+//go:build linux
+
+package plugins
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// cgroupRoot is where the unified cgroup hierarchy is mounted
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupCPUPeriod is the period of the cpu.max quota, in microseconds
+const cgroupCPUPeriod = 100000
+
+// clockTicks is the unit of the CPU times in /proc/<pid>/stat
+const clockTicks = 100
+
+// cgroupSequence numbers the cgroups created by this process
+var cgroupSequence atomic.Int64
+
+// unsafeCgroupChars are replaced in plugin names used in cgroup names
+var unsafeCgroupChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// processLimiter applies the resource limits of a plugin to its process.
+// Open files are limited with RLIMIT_NOFILE. Memory and CPU are limited
+// through a cgroup when the cgroup v2 hierarchy is delegated to templar;
+// otherwise they are only monitored, as address space rlimits break
+// runtimes such as Go and V8 that reserve far more than they use.
+type processLimiter struct {
+	name      string
+	limits    ResourceLimits
+	cgroupDir string
+	cgroupFD  *os.File
+}
+
+// newProcessLimiter creates the limiter of a plugin process
+func newProcessLimiter(name string, limits ResourceLimits) *processLimiter {
+	return &processLimiter{name: name, limits: limits}
+}
+
+// prepare places the command in a new cgroup enforcing the memory and CPU
+// limits, if one can be created
+func (l *processLimiter) prepare(cmd *exec.Cmd) {
+	if l.limits.MaxMemoryMB <= 0 && l.limits.MaxCPUPercent <= 0 {
+		return
+	}
+
+	parent, err := ownCgroup()
+	if err != nil {
+		return
+	}
+
+	// Fails when the controllers are already enabled or templar may not
+	// enable them, which the files of the new cgroup reveal
+	_ = os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("+memory +cpu"), 0)
+
+	name := fmt.Sprintf("templar-plugin-%s-%d-%d",
+		unsafeCgroupChars.ReplaceAllString(l.name, "_"), os.Getpid(), cgroupSequence.Add(1))
+	dir := filepath.Join(parent, name)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return
+	}
+
+	var settings []string
+	if l.limits.MaxMemoryMB > 0 {
+		settings = append(settings, "memory.max", strconv.Itoa(l.limits.MaxMemoryMB<<20))
+	}
+	if l.limits.MaxCPUPercent > 0 {
+		quota := max(int(l.limits.MaxCPUPercent/100*cgroupCPUPeriod), 1000)
+		settings = append(settings, "cpu.max", fmt.Sprintf("%d %d", quota, cgroupCPUPeriod))
+	}
+	for i := 0; i < len(settings); i += 2 {
+		if err := os.WriteFile(filepath.Join(dir, settings[i]), []byte(settings[i+1]), 0); err != nil {
+			_ = os.Remove(dir)
+			return
+		}
+	}
+
+	fd, err := os.Open(dir)
+	if err != nil {
+		_ = os.Remove(dir)
+		return
+	}
+
+	l.cgroupDir = dir
+	l.cgroupFD = fd
+	cmd.SysProcAttr = &syscall.SysProcAttr{UseCgroupFD: true, CgroupFD: int(fd.Fd())}
+}
+
+// usesCgroup reports whether prepare placed the command in a cgroup
+func (l *processLimiter) usesCgroup() bool {
+	return l.cgroupDir != ""
+}
+
+// apply sets the resource limits of the started process
+func (l *processLimiter) apply(pid int) error {
+	if l.cgroupFD != nil {
+		_ = l.cgroupFD.Close()
+		l.cgroupFD = nil
+	}
+
+	if l.limits.MaxFileDescriptors > 0 {
+		limit := uint64(l.limits.MaxFileDescriptors)
+		if err := unix.Prlimit(pid, unix.RLIMIT_NOFILE, &unix.Rlimit{Cur: limit, Max: limit}, nil); err != nil {
+			return fmt.Errorf("limiting open files: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// release removes the cgroup of an exited process
+func (l *processLimiter) release() {
+	if l.cgroupFD != nil {
+		_ = l.cgroupFD.Close()
+		l.cgroupFD = nil
+	}
+	if l.cgroupDir != "" {
+		_ = os.Remove(l.cgroupDir)
+		l.cgroupDir = ""
+	}
+}
+
+// ownCgroup returns the directory of the cgroup v2 templar runs in
+func ownCgroup() (string, error) {
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		return "", fmt.Errorf("cgroup v2 is not mounted at %s", cgroupRoot)
+	}
+
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			return filepath.Join(cgroupRoot, path), nil
+		}
+	}
+	return "", fmt.Errorf("no cgroup v2 membership")
+}
+
+// readProcessUsage reads the resource usage of a process from /proc
+func readProcessUsage(pid int) (processUsage, error) {
+	var usage processUsage
+	procDir := filepath.Join("/proc", strconv.Itoa(pid))
+
+	status, err := os.ReadFile(filepath.Join(procDir, "status"))
+	if err != nil {
+		return usage, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(status))
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "VmRSS:"); ok {
+			kb, _ := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 64)
+			usage.MemoryMB = kb / 1024
+			break
+		}
+	}
+
+	// utime and stime are the 14th and 15th fields, counted after the
+	// command name, which may contain spaces
+	stat, err := os.ReadFile(filepath.Join(procDir, "stat"))
+	if err != nil {
+		return usage, err
+	}
+	if end := bytes.LastIndexByte(stat, ')'); end >= 0 {
+		fields := strings.Fields(string(stat[end+1:]))
+		if len(fields) > 12 {
+			utime, _ := strconv.ParseInt(fields[11], 10, 64)
+			stime, _ := strconv.ParseInt(fields[12], 10, 64)
+			usage.CPUTime = time.Duration(utime+stime) * time.Second / clockTicks
+		}
+	}
+
+	fds, err := os.ReadDir(filepath.Join(procDir, "fd"))
+	if err != nil {
+		return usage, err
+	}
+	usage.FileDescriptors = len(fds)
+
+	return usage, nil
+}
@@ -0,0 +1,35 @@
+//go:build linux
+
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalPluginProcessLimits(t *testing.T) {
+	ctx := context.Background()
+	plugin := NewExternalPlugin(testManifest(t, "normal"))
+
+	config := testPluginConfig(5*time.Second, 0)
+	config.Settings.ResourceLimits.MaxFileDescriptors = 64
+	require.NoError(t, plugin.Initialize(ctx, config))
+	defer plugin.Shutdown(ctx)
+
+	pid := plugin.process.cmd.Process.Pid
+	limits, err := os.ReadFile(fmt.Sprintf("/proc/%d/limits", pid))
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`Max open files\s+64\s+64`), string(limits))
+
+	usage, ok := plugin.resourceUsage()
+	require.True(t, ok)
+	assert.Greater(t, usage.MemoryMB, 0.0)
+	assert.Greater(t, usage.FileDescriptors, 0)
+}
@@ -0,0 +1,39 @@
+//go:build !linux
+
+package plugins
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// processLimiter applies the resource limits of a plugin to its process.
+// Operating system limits are only applied on Linux; elsewhere limits are
+// not enforced for plugin processes.
+type processLimiter struct{}
+
+// newProcessLimiter creates the limiter of a plugin process
+func newProcessLimiter(name string, limits ResourceLimits) *processLimiter {
+	return &processLimiter{}
+}
+
+// prepare is a no-op outside Linux
+func (l *processLimiter) prepare(cmd *exec.Cmd) {}
+
+// usesCgroup reports whether prepare placed the command in a cgroup
+func (l *processLimiter) usesCgroup() bool {
+	return false
+}
+
+// apply is a no-op outside Linux
+func (l *processLimiter) apply(pid int) error {
+	return nil
+}
+
+// release is a no-op outside Linux
+func (l *processLimiter) release() {}
+
+// readProcessUsage is only supported on Linux
+func readProcessUsage(pid int) (processUsage, error) {
+	return processUsage{}, errors.New("process usage is only measured on Linux")
+}
@@ -42,6 +42,9 @@ type EnhancedPluginManager struct {
 	loadedPlugins  map[string]LoadedPlugin
 
 	mu sync.RWMutex
+
+	// limitMu serializes disabling plugins over their resource limits
+	limitMu sync.Mutex
 }
 
 // PluginState represents the current state of a plugin
@@ -131,6 +134,8 @@ func NewEnhancedPluginManager(
 		manager.enabledPlugins[name] = false
 	}
 
+	baseManager.onLimitExceeded = manager.disableExceeded
+
 	return manager
 }
 
@@ -205,8 +210,16 @@ func (epm *EnhancedPluginManager) registerPlugin(ctx context.Context, plugin Plu
 		Interfaces:  epm.getPluginInterfaces(plugin),
 	}
 
+	// External plugins are described by their manifest
+	if ep, ok := plugin.(*ExternalPlugin); ok {
+		manifest := ep.Manifest()
+		info.Author = manifest.Author
+		info.License = manifest.License
+		info.Path = filepath.Join(manifest.Dir, ManifestFileName)
+	}
+
 	// Add plugin-specific info
-	if cp, ok := plugin.(ComponentPlugin); ok {
+	if cp, ok := plugin.(ComponentPlugin); ok && declaresInterface(plugin, "ComponentPlugin") {
 		info.Extensions = cp.SupportedExtensions()
 		info.Priority = cp.Priority()
 	}
@@ -277,25 +290,26 @@ func (epm *EnhancedPluginManager) integratePlugin(plugin Plugin) error {
 	name := plugin.Name()
 
 	// Integrate with build pipeline
-	if bp, ok := plugin.(BuildPlugin); ok && epm.buildPipeline != nil {
-		if err := epm.buildPipeline.RegisterPreBuildHook(bp); err != nil {
+	if bp, ok := plugin.(BuildPlugin); ok && declaresInterface(plugin, "BuildPlugin") && epm.buildPipeline != nil {
+		guarded := guardedBuildPlugin{BuildPlugin: bp, manager: epm.PluginManager}
+		if err := epm.buildPipeline.RegisterPreBuildHook(guarded); err != nil {
 			return fmt.Errorf("failed to register build pre-hook for %s: %w", name, err)
 		}
-		if err := epm.buildPipeline.RegisterPostBuildHook(bp); err != nil {
+		if err := epm.buildPipeline.RegisterPostBuildHook(guarded); err != nil {
 			return fmt.Errorf("failed to register build post-hook for %s: %w", name, err)
 		}
 	}
 
 	// Integrate with server
-	if sp, ok := plugin.(ServerPlugin); ok && epm.server != nil {
+	if sp, ok := plugin.(ServerPlugin); ok && declaresInterface(plugin, "ServerPlugin") && epm.server != nil {
 		if err := epm.server.RegisterPlugin(sp); err != nil {
 			return fmt.Errorf("failed to register server plugin %s: %w", name, err)
 		}
 	}
 
 	// Integrate with file watcher
-	if wp, ok := plugin.(WatcherPlugin); ok && epm.watcher != nil {
-		if err := epm.watcher.RegisterPlugin(wp); err != nil {
+	if wp, ok := plugin.(WatcherPlugin); ok && declaresInterface(plugin, "WatcherPlugin") && epm.watcher != nil {
+		if err := epm.watcher.RegisterPlugin(guardedWatcherPlugin{WatcherPlugin: wp, manager: epm.PluginManager}); err != nil {
 			return fmt.Errorf("failed to register watcher plugin %s: %w", name, err)
 		}
 	}
@@ -337,16 +351,16 @@ func (epm *EnhancedPluginManager) getPluginInterfaces(plugin Plugin) []string {
 
 	interfaces = append(interfaces, "Plugin")
 
-	if _, ok := plugin.(ComponentPlugin); ok {
+	if _, ok := plugin.(ComponentPlugin); ok && declaresInterface(plugin, "ComponentPlugin") {
 		interfaces = append(interfaces, "ComponentPlugin")
 	}
-	if _, ok := plugin.(BuildPlugin); ok {
+	if _, ok := plugin.(BuildPlugin); ok && declaresInterface(plugin, "BuildPlugin") {
 		interfaces = append(interfaces, "BuildPlugin")
 	}
-	if _, ok := plugin.(ServerPlugin); ok {
+	if _, ok := plugin.(ServerPlugin); ok && declaresInterface(plugin, "ServerPlugin") {
 		interfaces = append(interfaces, "ServerPlugin")
 	}
-	if _, ok := plugin.(WatcherPlugin); ok {
+	if _, ok := plugin.(WatcherPlugin); ok && declaresInterface(plugin, "WatcherPlugin") {
 		interfaces = append(interfaces, "WatcherPlugin")
 	}
 
@@ -380,22 +394,42 @@ func (epm *EnhancedPluginManager) discoverPluginsInPath(ctx context.Context, pat
 		return nil // Path doesn't exist, skip
 	}
 
-	// Walk the directory looking for plugin files
+	// Walk the directory looking for plugin manifests
 	return filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Look for .so files (Go plugins) or plugin manifest files
-		if strings.HasSuffix(filePath, ".so") || strings.HasSuffix(filePath, "plugin.json") {
-			// TODO: Implement external plugin loading
-			epm.logger.Info(ctx, "Found potential plugin file", "path", filePath)
+		switch {
+		case !info.IsDir() && info.Name() == ManifestFileName:
+			epm.discoverExternalPlugin(ctx, filePath)
+		case strings.HasSuffix(filePath, ".so"):
+			epm.logger.Warn(ctx, nil, "Go plugins are not supported, describe the plugin in a plugin.json manifest instead", "path", filePath)
 		}
 
 		return nil
 	})
 }
 
+// discoverExternalPlugin registers the external plugin described by a
+// manifest. It is started only if enabled in the configuration.
+func (epm *EnhancedPluginManager) discoverExternalPlugin(ctx context.Context, manifestPath string) {
+	manifest, err := LoadManifest(manifestPath)
+	if err != nil {
+		epm.logger.Error(ctx, err, "Invalid plugin manifest", "path", manifestPath)
+		return
+	}
+
+	if _, exists := epm.discoveredPlugins[manifest.Name]; exists {
+		epm.logger.Warn(ctx, nil, "Plugin already registered, ignoring manifest", "plugin", manifest.Name, "path", manifestPath)
+		return
+	}
+
+	if err := epm.registerPlugin(ctx, NewExternalPlugin(manifest), "file"); err != nil {
+		epm.logger.Error(ctx, err, "Failed to load external plugin", "plugin", manifest.Name)
+	}
+}
+
 // loadEnabledPlugins loads all plugins that should be enabled
 func (epm *EnhancedPluginManager) loadEnabledPlugins(ctx context.Context) error {
 	var errors []error
@@ -446,8 +480,12 @@ func (epm *EnhancedPluginManager) EnablePlugin(ctx context.Context, name string)
 		return fmt.Errorf("runtime enabling of builtin plugins not yet implemented")
 	}
 
-	// TODO: Load external plugin
-	return fmt.Errorf("runtime enabling of external plugins not yet implemented")
+	// External plugins are started again from their manifest
+	manifest, err := LoadManifest(info.Path)
+	if err != nil {
+		return fmt.Errorf("failed to load plugin %s: %w", name, err)
+	}
+	return epm.loadPlugin(ctx, NewExternalPlugin(manifest), info)
 }
 
 // DisablePlugin disables a plugin at runtime
@@ -541,17 +579,93 @@ func (epm *EnhancedPluginManager) ProcessComponent(ctx context.Context, componen
 
 	result := component
 	for _, plugin := range plugins {
-		var err error
-		result, err = plugin.HandleComponent(ctx, result)
+		if epm.exceededLimits(plugin.Name()) {
+			continue
+		}
+
+		var processed *types.ComponentInfo
+		err := epm.runHook(ctx, plugin, "HandleComponent", func(ctx context.Context) error {
+			var err error
+			processed, err = plugin.HandleComponent(ctx, result)
+			return err
+		})
 		if err != nil {
 			return nil, fmt.Errorf("plugin %s failed to process component %s: %w",
 				plugin.Name(), component.Name, err)
 		}
+		result = processed
 	}
 
 	return result, nil
 }
 
+// CheckHealth checks the health and resource usage of all loaded plugins,
+// disabling those over their resource limits, and returns the loaded
+// plugins with their current health
+func (epm *EnhancedPluginManager) CheckHealth(ctx context.Context) map[string]LoadedPlugin {
+	epm.PluginManager.checkAllPluginHealth()
+
+	epm.PluginManager.mu.RLock()
+	healthChecks := make(map[string]PluginHealth, len(epm.PluginManager.healthChecks))
+	for name, health := range epm.PluginManager.healthChecks {
+		healthChecks[name] = health
+	}
+	exceeded := make(map[string]LimitViolation)
+	for name, guard := range epm.PluginManager.guards {
+		if violation := guard.exceeded(); violation != nil {
+			exceeded[name] = *violation
+		}
+	}
+	epm.PluginManager.mu.RUnlock()
+
+	// Disable plugins over their limits before reporting on them
+	for name, violation := range exceeded {
+		epm.disableExceeded(name, violation)
+	}
+
+	epm.mu.Lock()
+	for name, health := range healthChecks {
+		if loaded, exists := epm.loadedPlugins[name]; exists && loaded.State == PluginStateEnabled {
+			loaded.Health = health
+			epm.loadedPlugins[name] = loaded
+		}
+	}
+	epm.mu.Unlock()
+
+	return epm.GetLoadedPlugins()
+}
+
+// disableExceeded disables a plugin that exceeded its resource limits,
+// keeping the violation as its health
+func (epm *EnhancedPluginManager) disableExceeded(name string, violation LimitViolation) {
+	epm.limitMu.Lock()
+	defer epm.limitMu.Unlock()
+
+	epm.mu.RLock()
+	loaded, exists := epm.loadedPlugins[name]
+	epm.mu.RUnlock()
+	if !exists || loaded.State != PluginStateEnabled {
+		return
+	}
+
+	ctx := context.Background()
+	epm.logger.Warn(ctx, nil, "Disabling plugin that exceeded its resource limits", "plugin", name, "reason", violation.String())
+	if err := epm.DisablePlugin(ctx, name); err != nil {
+		epm.logger.Error(ctx, err, "Failed to disable plugin", "plugin", name)
+	}
+
+	epm.mu.Lock()
+	loaded = epm.loadedPlugins[name]
+	loaded.Health = PluginHealth{
+		Status:    HealthStatusUnhealthy,
+		LastCheck: time.Now(),
+		Error:     violation.String(),
+		Metrics:   map[string]interface{}{"limit_violation": violation},
+	}
+	epm.loadedPlugins[name] = loaded
+	epm.mu.Unlock()
+}
+
 // getComponentPluginsByPriority returns component plugins sorted by priority
 func (epm *EnhancedPluginManager) getComponentPluginsByPriority() []ComponentPlugin {
 	epm.mu.RLock()
@@ -560,7 +674,7 @@ func (epm *EnhancedPluginManager) getComponentPluginsByPriority() []ComponentPlu
 	var plugins []ComponentPlugin
 	for _, loaded := range epm.loadedPlugins {
 		if loaded.State == PluginStateEnabled {
-			if cp, ok := loaded.Instance.(ComponentPlugin); ok {
+			if cp, ok := loaded.Instance.(ComponentPlugin); ok && declaresInterface(cp, "ComponentPlugin") {
 				plugins = append(plugins, cp)
 			}
 		}
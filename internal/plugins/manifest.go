@@ -0,0 +1,135 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestFileName is the name of the file describing an external plugin
+const ManifestFileName = "plugin.json"
+
+// Manifest describes an external plugin that runs as a child process
+type Manifest struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Author      string `json:"author,omitempty"`
+	License     string `json:"license,omitempty"`
+
+	// Protocol is the version of the plugin protocol the command speaks
+	Protocol int `json:"protocol"`
+
+	// Command starts the plugin. Paths containing a separator are relative
+	// to the manifest's directory, bare names are looked up in PATH.
+	Command string            `json:"command"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+
+	// Interfaces lists the plugin interfaces whose hooks the plugin handles
+	Interfaces []string `json:"interfaces"`
+
+	// ComponentPlugin capabilities
+	Extensions []string `json:"extensions,omitempty"`
+	Priority   int      `json:"priority,omitempty"`
+
+	// WatcherPlugin capabilities
+	WatchPatterns []string `json:"watch_patterns,omitempty"`
+
+	// ServerPlugin capabilities
+	Routes []ManifestRoute `json:"routes,omitempty"`
+
+	// Dir is the directory the manifest was loaded from
+	Dir string `json:"-"`
+}
+
+// ManifestRoute is an HTTP route served by an external plugin
+type ManifestRoute struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// pluginInterfaces are the interfaces external plugins may declare
+var pluginInterfaces = map[string]bool{
+	"ComponentPlugin": true,
+	"BuildPlugin":     true,
+	"ServerPlugin":    true,
+	"WatcherPlugin":   true,
+}
+
+// LoadManifest reads and validates a plugin manifest
+func LoadManifest(path string) (Manifest, error) {
+	var manifest Manifest
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return manifest, fmt.Errorf("reading plugin manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("parsing plugin manifest %s: %w", path, err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return manifest, err
+	}
+	manifest.Dir = filepath.Dir(absPath)
+
+	if err := manifest.Validate(); err != nil {
+		return manifest, fmt.Errorf("invalid plugin manifest %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// Validate checks that the manifest describes a plugin this version of
+// templar can run
+func (m Manifest) Validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if m.Command == "" {
+		return fmt.Errorf("command is required")
+	}
+	if m.Protocol != ProtocolVersion {
+		return fmt.Errorf("unsupported protocol version %d, expected %d", m.Protocol, ProtocolVersion)
+	}
+
+	for _, name := range m.Interfaces {
+		if !pluginInterfaces[name] {
+			return fmt.Errorf("unknown interface %q", name)
+		}
+	}
+
+	for _, route := range m.Routes {
+		switch route.Method {
+		case "GET", "POST", "PUT", "DELETE":
+		default:
+			return fmt.Errorf("route %s: unsupported method %q", route.Path, route.Method)
+		}
+		if !strings.HasPrefix(route.Path, "/") {
+			return fmt.Errorf("route path %q must start with /", route.Path)
+		}
+	}
+
+	return nil
+}
+
+// CommandPath returns the command to execute
+func (m Manifest) CommandPath() string {
+	if m.Dir != "" && !filepath.IsAbs(m.Command) && strings.ContainsRune(m.Command, filepath.Separator) {
+		return filepath.Join(m.Dir, m.Command)
+	}
+	return m.Command
+}
+
+// declares reports whether the manifest lists an interface
+func (m Manifest) declares(iface string) bool {
+	for _, name := range m.Interfaces {
+		if name == iface {
+			return true
+		}
+	}
+	return false
+}
@@ -86,6 +86,28 @@ type WatcherPlugin interface {
 	ShouldIgnore(filePath string) bool
 }
 
+// interfaceDeclarer is implemented by plugins that implement every plugin
+// interface in Go but only handle the hooks of the interfaces they declare,
+// such as external plugins
+type interfaceDeclarer interface {
+	Interfaces() []string
+}
+
+// declaresInterface reports whether a plugin handles the hooks of a plugin
+// interface it implements
+func declaresInterface(plugin Plugin, name string) bool {
+	declarer, ok := plugin.(interfaceDeclarer)
+	if !ok {
+		return true
+	}
+	for _, iface := range declarer.Interfaces() {
+		if iface == name {
+			return true
+		}
+	}
+	return false
+}
+
 // PluginConfig contains configuration for a plugin
 type PluginConfig struct {
 	// Name of the plugin
@@ -205,6 +227,10 @@ type Context interface {
 	// Additional context
 	Set(key string, value interface{})
 	Get(key string) interface{}
+
+	// Context returns the request's context, which is canceled when the
+	// client disconnects
+	Context() context.Context
 }
 
 // MiddlewareFunc represents HTTP middleware
@@ -259,10 +285,15 @@ type PluginManager struct {
 	watcherPlugins    []WatcherPlugin
 	configs           map[string]PluginConfig
 	healthChecks      map[string]PluginHealth
+	guards            map[string]*resourceGuard
 	mu                sync.RWMutex
 	ctx               context.Context
 	cancel            context.CancelFunc
 	healthCheckTicker *time.Ticker
+
+	// onLimitExceeded disables plugins exceeding their resource limits in
+	// place of the default of shutting them down and skipping them
+	onLimitExceeded func(name string, violation LimitViolation)
 }
 
 // NewPluginManager creates a new plugin manager
@@ -273,6 +304,7 @@ func NewPluginManager() *PluginManager {
 		plugins:      make(map[string]Plugin),
 		configs:      make(map[string]PluginConfig),
 		healthChecks: make(map[string]PluginHealth),
+		guards:       make(map[string]*resourceGuard),
 		ctx:          ctx,
 		cancel:       cancel,
 	}
@@ -296,18 +328,22 @@ func (pm *PluginManager) RegisterPlugin(plugin Plugin, config PluginConfig) erro
 	// Store the plugin
 	pm.plugins[name] = plugin
 	pm.configs[name] = config
+	pm.guards[name] = newResourceGuard(name, config.Settings, func(name string, violation LimitViolation) {
+		// Violations may be found while pm.mu is held
+		go pm.limitExceeded(name, violation)
+	})
 
 	// Categorize the plugin
-	if cp, ok := plugin.(ComponentPlugin); ok {
+	if cp, ok := plugin.(ComponentPlugin); ok && declaresInterface(plugin, "ComponentPlugin") {
 		pm.componentPlugins = append(pm.componentPlugins, cp)
 	}
-	if bp, ok := plugin.(BuildPlugin); ok {
+	if bp, ok := plugin.(BuildPlugin); ok && declaresInterface(plugin, "BuildPlugin") {
 		pm.buildPlugins = append(pm.buildPlugins, bp)
 	}
-	if sp, ok := plugin.(ServerPlugin); ok {
+	if sp, ok := plugin.(ServerPlugin); ok && declaresInterface(plugin, "ServerPlugin") {
 		pm.serverPlugins = append(pm.serverPlugins, sp)
 	}
-	if wp, ok := plugin.(WatcherPlugin); ok {
+	if wp, ok := plugin.(WatcherPlugin); ok && declaresInterface(plugin, "WatcherPlugin") {
 		pm.watcherPlugins = append(pm.watcherPlugins, wp)
 	}
 
@@ -336,6 +372,7 @@ func (pm *PluginManager) UnregisterPlugin(name string) error {
 	delete(pm.plugins, name)
 	delete(pm.configs, name)
 	delete(pm.healthChecks, name)
+	delete(pm.guards, name)
 
 	// Remove from categorized lists
 	pm.componentPlugins = removeComponentPlugin(pm.componentPlugins, plugin)
@@ -408,16 +445,75 @@ func (pm *PluginManager) ProcessComponent(ctx context.Context, component *types.
 
 	result := component
 	for _, plugin := range plugins {
-		var err error
-		result, err = plugin.HandleComponent(ctx, result)
+		if pm.exceededLimits(plugin.Name()) {
+			continue
+		}
+
+		var processed *types.ComponentInfo
+		err := pm.runHook(ctx, plugin, "HandleComponent", func(ctx context.Context) error {
+			var err error
+			processed, err = plugin.HandleComponent(ctx, result)
+			return err
+		})
 		if err != nil {
 			return nil, fmt.Errorf("plugin %s failed to process component: %w", plugin.Name(), err)
 		}
+		result = processed
 	}
 
 	return result, nil
 }
 
+// runHook calls a hook of a plugin under its resource guard
+func (pm *PluginManager) runHook(ctx context.Context, plugin Plugin, hook string, fn func(ctx context.Context) error) error {
+	pm.mu.RLock()
+	guard := pm.guards[plugin.Name()]
+	pm.mu.RUnlock()
+
+	if guard == nil {
+		return fn(ctx)
+	}
+	return guard.run(ctx, plugin, hook, fn)
+}
+
+// exceededLimits reports whether a plugin exceeded its resource limits, in
+// which case its hooks are no longer called
+func (pm *PluginManager) exceededLimits(name string) bool {
+	pm.mu.RLock()
+	guard := pm.guards[name]
+	pm.mu.RUnlock()
+
+	return guard != nil && guard.exceeded() != nil
+}
+
+// limitExceeded disables a plugin that exceeded its resource limits. Unless
+// onLimitExceeded is set, the plugin is shut down and skipped from then on.
+func (pm *PluginManager) limitExceeded(name string, violation LimitViolation) {
+	pm.mu.Lock()
+	plugin, exists := pm.plugins[name]
+	if exists {
+		health := pm.healthChecks[name]
+		health.Status = HealthStatusUnhealthy
+		health.Error = violation.String()
+		health.LastCheck = time.Now()
+		pm.healthChecks[name] = health
+	}
+	handler := pm.onLimitExceeded
+	pm.mu.Unlock()
+
+	if !exists {
+		return
+	}
+	if handler != nil {
+		handler(name, violation)
+		return
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_ = plugin.Shutdown(shutdownCtx)
+}
+
 // StartHealthChecks starts periodic health checks for all plugins
 func (pm *PluginManager) StartHealthChecks(interval time.Duration) {
 	pm.mu.Lock()
@@ -441,14 +537,20 @@ func (pm *PluginManager) StartHealthChecks(interval time.Duration) {
 	}()
 }
 
-// checkAllPluginHealth performs health checks on all plugins
+// checkAllPluginHealth performs health checks on all plugins, including
+// their resource usage
 func (pm *PluginManager) checkAllPluginHealth() {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
+	goroutines := pluginGoroutines()
 	for name, plugin := range pm.plugins {
 		health := plugin.Health()
 		health.LastCheck = time.Now()
+		if guard, ok := pm.guards[name]; ok {
+			guard.check(plugin, goroutines)
+			guard.annotate(&health, goroutines)
+		}
 		pm.healthChecks[name] = health
 	}
 }
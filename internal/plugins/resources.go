@@ -0,0 +1,395 @@
+package plugins
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// pluginLabel is the profiler label attributing goroutines to the plugin
+// whose hook started them
+const pluginLabel = "templar_plugin"
+
+// maxConsecutiveTimeouts is the number of hook calls in a row that may time
+// out before the plugin is disabled. A single slow call, such as one hitting
+// a cold cache, only counts in the hook statistics.
+const maxConsecutiveTimeouts = 2
+
+// LimitViolation describes a plugin exceeding one of its ResourceLimits
+type LimitViolation struct {
+	// Limit is the exceeded limit, named as in the configuration
+	Limit string `json:"limit"`
+
+	// Value is the measured usage
+	Value float64 `json:"value"`
+
+	// Max is the configured limit
+	Max float64 `json:"max"`
+
+	// Hook is the hook that exceeded the limit, if any
+	Hook string `json:"hook,omitempty"`
+}
+
+// String describes the violation for health reports
+func (v LimitViolation) String() string {
+	if v.Hook != "" {
+		return fmt.Sprintf("resource limit exceeded: %s %g > %g in %s", v.Limit, v.Value, v.Max, v.Hook)
+	}
+	return fmt.Sprintf("resource limit exceeded: %s %g > %g", v.Limit, v.Value, v.Max)
+}
+
+// processUsage is the resource usage of an out-of-process plugin
+type processUsage struct {
+	MemoryMB        float64
+	CPUTime         time.Duration
+	FileDescriptors int
+}
+
+// usageReporter is implemented by plugins running in their own process,
+// whose usage is measured by the operating system
+type usageReporter interface {
+	resourceUsage() (processUsage, bool)
+}
+
+// hookStats accumulates the latency of a plugin hook
+type hookStats struct {
+	Calls    int64
+	Timeouts int64
+	Total    time.Duration
+	Max      time.Duration
+}
+
+// resourceGuard enforces the resource limits of one plugin. Hooks of
+// in-process plugins run under a profiler label so that the goroutines they
+// leave behind can be counted, with the plugin's timeout applied through
+// their context. Out-of-process plugins are measured through the operating
+// system.
+type resourceGuard struct {
+	name     string
+	settings PluginSettings
+
+	// onViolation is called once, when the plugin first exceeds a limit
+	onViolation func(name string, violation LimitViolation)
+
+	mu        sync.Mutex
+	hooks     map[string]*hookStats
+	violation *LimitViolation
+	// timeouts counts the hook calls that timed out since the last call
+	// that completed
+	timeouts int
+
+	// CPU time of the plugin process at the last sample
+	lastCPU      time.Duration
+	lastSampleAt time.Time
+}
+
+// newResourceGuard creates the guard of a plugin
+func newResourceGuard(name string, settings PluginSettings, onViolation func(name string, violation LimitViolation)) *resourceGuard {
+	return &resourceGuard{
+		name:        name,
+		settings:    settings,
+		onViolation: onViolation,
+		hooks:       make(map[string]*hookStats),
+	}
+}
+
+// run calls a hook of the plugin, recording its latency. For in-process
+// plugins the call is bounded by the plugin's timeout and the goroutines it
+// leaves running are counted against MaxGoroutines.
+func (g *resourceGuard) run(ctx context.Context, plugin Plugin, hook string, fn func(ctx context.Context) error) error {
+	if _, external := plugin.(usageReporter); external {
+		// External plugins bound their calls themselves
+		start := time.Now()
+		err := fn(ctx)
+		g.record(hook, time.Since(start), false)
+		return err
+	}
+
+	timeout := g.settings.Timeout
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	labels := pprof.Labels(pluginLabel, g.name)
+	before := runtime.NumGoroutine()
+	start := time.Now()
+
+	// Without a timeout the hook runs on the calling goroutine; with one it
+	// runs on its own so that the caller can give up on it
+	var err error
+	if timeout <= 0 {
+		pprof.Do(ctx, labels, func(ctx context.Context) {
+			err = fn(ctx)
+		})
+	} else {
+		done := make(chan error, 1)
+		go pprof.Do(ctx, labels, func(ctx context.Context) {
+			done <- fn(ctx)
+		})
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			return g.abandon(ctx, hook, start)
+		}
+	}
+	g.record(hook, time.Since(start), false)
+	g.mu.Lock()
+	g.timeouts = 0
+	g.mu.Unlock()
+
+	// Counting labelled goroutines takes a goroutine profile, so it is only
+	// done when the process-wide count grew past the limit
+	if limit := g.settings.ResourceLimits.MaxGoroutines; limit > 0 && runtime.NumGoroutine()-before > limit {
+		if count := pluginGoroutines()[g.name]; count > limit {
+			g.violate(LimitViolation{
+				Limit: "max_goroutines",
+				Value: float64(count),
+				Max:   float64(limit),
+				Hook:  hook,
+			})
+		}
+	}
+	return err
+}
+
+// abandon records a hook call given up on because its context ended. Hitting
+// the plugin's timeout is a violation once more than maxConsecutiveTimeouts
+// calls in a row did.
+func (g *resourceGuard) abandon(ctx context.Context, hook string, start time.Time) error {
+	timedOut := ctx.Err() == context.DeadlineExceeded
+	g.record(hook, time.Since(start), timedOut)
+
+	g.mu.Lock()
+	timeouts := g.timeouts
+	g.mu.Unlock()
+	if timeouts > maxConsecutiveTimeouts {
+		g.violate(LimitViolation{
+			Limit: "consecutive_timeouts",
+			Value: float64(timeouts),
+			Max:   maxConsecutiveTimeouts,
+			Hook:  hook,
+		})
+	}
+	return fmt.Errorf("plugin %s: %s: %w", g.name, hook, ctx.Err())
+}
+
+// check measures the plugin's current usage against its limits. goroutines
+// holds the goroutine counts of pluginGoroutines.
+func (g *resourceGuard) check(plugin Plugin, goroutines map[string]int) {
+	limits := g.settings.ResourceLimits
+
+	reporter, external := plugin.(usageReporter)
+	if !external {
+		if limits.MaxGoroutines > 0 && goroutines[g.name] > limits.MaxGoroutines {
+			g.violate(LimitViolation{
+				Limit: "max_goroutines",
+				Value: float64(goroutines[g.name]),
+				Max:   float64(limits.MaxGoroutines),
+			})
+		}
+		return
+	}
+
+	usage, ok := reporter.resourceUsage()
+	if !ok {
+		return
+	}
+
+	g.mu.Lock()
+	now := time.Now()
+	var cpuPercent float64
+	if !g.lastSampleAt.IsZero() && usage.CPUTime >= g.lastCPU {
+		cpuPercent = float64(usage.CPUTime-g.lastCPU) / float64(now.Sub(g.lastSampleAt)) * 100
+	}
+	g.lastCPU = usage.CPUTime
+	g.lastSampleAt = now
+	g.mu.Unlock()
+
+	switch {
+	case limits.MaxMemoryMB > 0 && usage.MemoryMB > float64(limits.MaxMemoryMB):
+		g.violate(LimitViolation{Limit: "max_memory_mb", Value: usage.MemoryMB, Max: float64(limits.MaxMemoryMB)})
+	case limits.MaxCPUPercent > 0 && cpuPercent > limits.MaxCPUPercent:
+		g.violate(LimitViolation{Limit: "max_cpu_percent", Value: cpuPercent, Max: limits.MaxCPUPercent})
+	case limits.MaxFileDescriptors > 0 && usage.FileDescriptors > limits.MaxFileDescriptors:
+		g.violate(LimitViolation{
+			Limit: "max_file_descriptors",
+			Value: float64(usage.FileDescriptors),
+			Max:   float64(limits.MaxFileDescriptors),
+		})
+	}
+}
+
+// record adds a hook call to the latency statistics
+func (g *resourceGuard) record(hook string, elapsed time.Duration, timedOut bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	stats, ok := g.hooks[hook]
+	if !ok {
+		stats = &hookStats{}
+		g.hooks[hook] = stats
+	}
+	stats.Calls++
+	stats.Total += elapsed
+	stats.Max = max(stats.Max, elapsed)
+	if timedOut {
+		stats.Timeouts++
+		g.timeouts++
+	}
+}
+
+// violate records a violation and reports it to onViolation. Only the first
+// violation is kept, as the plugin is disabled for it.
+func (g *resourceGuard) violate(violation LimitViolation) {
+	g.mu.Lock()
+	first := g.violation == nil
+	if first {
+		g.violation = &violation
+	}
+	g.mu.Unlock()
+
+	if first && g.onViolation != nil {
+		g.onViolation(g.name, violation)
+	}
+}
+
+// exceeded returns the violation the plugin was disabled for, if any
+func (g *resourceGuard) exceeded() *LimitViolation {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.violation
+}
+
+// annotate adds the hook latencies to a health report, marking the plugin
+// unhealthy if it exceeded a limit
+func (g *resourceGuard) annotate(health *PluginHealth, goroutines map[string]int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if health.Metrics == nil {
+		health.Metrics = make(map[string]interface{})
+	}
+
+	hooks := make(map[string]interface{}, len(g.hooks))
+	for hook, stats := range g.hooks {
+		hooks[hook] = map[string]interface{}{
+			"calls":    stats.Calls,
+			"timeouts": stats.Timeouts,
+			"avg_ms":   float64(stats.Total.Microseconds()) / float64(stats.Calls) / 1000,
+			"max_ms":   float64(stats.Max.Microseconds()) / 1000,
+		}
+	}
+	health.Metrics["hooks"] = hooks
+	if goroutines != nil {
+		health.Metrics["goroutines"] = goroutines[g.name]
+	}
+
+	if g.violation != nil {
+		health.Status = HealthStatusUnhealthy
+		health.Error = g.violation.String()
+		health.Metrics["limit_violation"] = *g.violation
+	}
+}
+
+// pluginGoroutines counts the running goroutines started by the hooks of
+// each plugin
+func pluginGoroutines() map[string]int {
+	var profile bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&profile, 1); err != nil {
+		return nil
+	}
+	return parseGoroutineLabels(&profile)
+}
+
+// parseGoroutineLabels counts goroutines by plugin label in a goroutine
+// profile written with debug level 1, where each stack is introduced by a
+// "<count> @ <addresses>" line followed by its labels
+func parseGoroutineLabels(profile *bytes.Buffer) map[string]int {
+	counts := make(map[string]int)
+
+	count := 0
+	scanner := bufio.NewScanner(profile)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if n, _, ok := strings.Cut(line, " @ "); ok {
+			count, _ = strconv.Atoi(n)
+			continue
+		}
+
+		labels, ok := strings.CutPrefix(line, "# labels: ")
+		if !ok {
+			continue
+		}
+		var values map[string]string
+		if json.Unmarshal([]byte(labels), &values) == nil {
+			if name, ok := values[pluginLabel]; ok {
+				counts[name] += count
+			}
+		}
+	}
+
+	return counts
+}
+
+// guardedBuildPlugin runs the hooks of a build plugin under its resource
+// guard, for integrations calling them outside the plugin manager
+type guardedBuildPlugin struct {
+	BuildPlugin
+	manager *PluginManager
+}
+
+// PreBuild implements BuildPlugin
+func (p guardedBuildPlugin) PreBuild(ctx context.Context, components []*types.ComponentInfo) error {
+	return p.manager.runHook(ctx, p.BuildPlugin, "PreBuild", func(ctx context.Context) error {
+		return p.BuildPlugin.PreBuild(ctx, components)
+	})
+}
+
+// PostBuild implements BuildPlugin
+func (p guardedBuildPlugin) PostBuild(ctx context.Context, components []*types.ComponentInfo, buildResult BuildResult) error {
+	return p.manager.runHook(ctx, p.BuildPlugin, "PostBuild", func(ctx context.Context) error {
+		return p.BuildPlugin.PostBuild(ctx, components, buildResult)
+	})
+}
+
+// TransformBuildCommand implements BuildPlugin
+func (p guardedBuildPlugin) TransformBuildCommand(ctx context.Context, command []string) ([]string, error) {
+	var transformed []string
+	err := p.manager.runHook(ctx, p.BuildPlugin, "TransformBuildCommand", func(ctx context.Context) error {
+		var err error
+		transformed, err = p.BuildPlugin.TransformBuildCommand(ctx, command)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transformed, nil
+}
+
+// guardedWatcherPlugin runs the hooks of a watcher plugin under its
+// resource guard, for integrations calling them outside the plugin manager
+type guardedWatcherPlugin struct {
+	WatcherPlugin
+	manager *PluginManager
+}
+
+// HandleFileChange implements WatcherPlugin
+func (p guardedWatcherPlugin) HandleFileChange(ctx context.Context, event FileChangeEvent) error {
+	return p.manager.runHook(ctx, p.WatcherPlugin, "HandleFileChange", func(ctx context.Context) error {
+		return p.WatcherPlugin.HandleFileChange(ctx, event)
+	})
+}
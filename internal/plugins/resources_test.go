@@ -0,0 +1,199 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/conneroisu/templar/internal/config"
+	"github.com/conneroisu/templar/internal/errors"
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hookPlugin is a component plugin running a function as its hook
+type hookPlugin struct {
+	MockPlugin
+	handle   func(ctx context.Context) error
+	shutdown atomic.Bool
+}
+
+func (hp *hookPlugin) Shutdown(ctx context.Context) error {
+	hp.shutdown.Store(true)
+	return nil
+}
+
+func (hp *hookPlugin) HandleComponent(ctx context.Context, component *types.ComponentInfo) (*types.ComponentInfo, error) {
+	if err := hp.handle(ctx); err != nil {
+		return nil, err
+	}
+	return component, nil
+}
+
+func (hp *hookPlugin) SupportedExtensions() []string { return []string{".templ"} }
+func (hp *hookPlugin) Priority() int                 { return 0 }
+
+// usagePlugin is a plugin reporting the usage of a process
+type usagePlugin struct {
+	MockPlugin
+	usage processUsage
+}
+
+func (up *usagePlugin) resourceUsage() (processUsage, bool) { return up.usage, true }
+
+func TestResourceGuardTimeout(t *testing.T) {
+	pm := NewPluginManager()
+	defer pm.Shutdown()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	var slow atomic.Bool
+	plugin := &hookPlugin{
+		MockPlugin: MockPlugin{name: "slow", health: PluginHealth{Status: HealthStatusHealthy}},
+		handle: func(ctx context.Context) error {
+			if slow.Load() {
+				<-release
+			}
+			return nil
+		},
+	}
+	config := PluginConfig{Name: "slow", Enabled: true, Settings: PluginSettings{Timeout: 50 * time.Millisecond}}
+	require.NoError(t, pm.RegisterPlugin(plugin, config))
+
+	component := &types.ComponentInfo{Name: "Card"}
+	hookStats := func() map[string]interface{} {
+		pm.checkAllPluginHealth()
+		hooks := pm.healthChecks["slow"].Metrics["hooks"].(map[string]interface{})
+		return hooks["HandleComponent"].(map[string]interface{})
+	}
+
+	// Occasional timeouts are only recorded in the hook statistics
+	for i := 0; i < 2*maxConsecutiveTimeouts; i++ {
+		slow.Store(i%2 == 0)
+		_, err := pm.ProcessComponent(context.Background(), component)
+		if i%2 == 0 {
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+	assert.False(t, plugin.shutdown.Load())
+	assert.Equal(t, int64(maxConsecutiveTimeouts), hookStats()["timeouts"])
+	assert.NotEqual(t, HealthStatusUnhealthy, pm.healthChecks["slow"].Status)
+
+	// Timing out more than maxConsecutiveTimeouts times in a row disables it
+	slow.Store(true)
+	for i := 0; i <= maxConsecutiveTimeouts; i++ {
+		_, err := pm.ProcessComponent(context.Background(), component)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+
+	// The plugin is shut down and skipped from then on
+	assert.Eventually(t, plugin.shutdown.Load, time.Second, 10*time.Millisecond)
+
+	result, err := pm.ProcessComponent(context.Background(), component)
+	require.NoError(t, err)
+	assert.Same(t, component, result)
+
+	assert.Equal(t, int64(2*maxConsecutiveTimeouts+1), hookStats()["timeouts"])
+	health := pm.healthChecks["slow"]
+	assert.Equal(t, HealthStatusUnhealthy, health.Status)
+	assert.Contains(t, health.Error, "consecutive_timeouts 3 > 2")
+	assert.Contains(t, health.Error, "HandleComponent")
+}
+
+func TestResourceGuardGoroutines(t *testing.T) {
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	// The default limits allow 10 goroutines per plugin
+	plugin := &hookPlugin{
+		MockPlugin: MockPlugin{name: "leaky", health: PluginHealth{Status: HealthStatusHealthy}},
+		handle: func(ctx context.Context) error {
+			for i := 0; i < 20; i++ {
+				go func() { <-release }()
+			}
+			return nil
+		},
+	}
+
+	cfg := &config.PluginsConfig{Configurations: make(map[string]config.PluginConfigMap)}
+	logger := &MockLogger{}
+	epm := NewEnhancedPluginManager(cfg, logger, errors.NewErrorHandler(logger, nil), registry.NewComponentRegistry())
+	require.NoError(t, epm.SetBuiltinPlugins([]Plugin{plugin}))
+	defer epm.Shutdown(ctx)
+
+	_, err := epm.ProcessComponent(ctx, &types.ComponentInfo{Name: "Card"})
+	require.NoError(t, err)
+
+	loaded := epm.CheckHealth(ctx)["leaky"]
+	assert.Equal(t, PluginStateDisabled, loaded.State)
+	assert.Equal(t, HealthStatusUnhealthy, loaded.Health.Status)
+	assert.Contains(t, loaded.Health.Error, "max_goroutines")
+	assert.Equal(t, PluginStateDisabled, epm.GetPluginState("leaky"))
+}
+
+func TestResourceGuardProcessUsage(t *testing.T) {
+	limits := ResourceLimits{MaxMemoryMB: 100, MaxCPUPercent: 50, MaxFileDescriptors: 10}
+
+	var violations []LimitViolation
+	newGuard := func() *resourceGuard {
+		return newResourceGuard("external", PluginSettings{ResourceLimits: limits}, func(name string, violation LimitViolation) {
+			violations = append(violations, violation)
+		})
+	}
+
+	plugin := &usagePlugin{usage: processUsage{MemoryMB: 20, FileDescriptors: 5}}
+	guard := newGuard()
+	guard.check(plugin, nil)
+	assert.Nil(t, guard.exceeded())
+
+	plugin.usage.MemoryMB = 150
+	guard.check(plugin, nil)
+	require.NotNil(t, guard.exceeded())
+	assert.Equal(t, "max_memory_mb", guard.exceeded().Limit)
+
+	// Only the first violation is reported
+	plugin.usage.FileDescriptors = 50
+	guard.check(plugin, nil)
+	assert.Len(t, violations, 1)
+
+	// CPU usage is measured between checks
+	plugin.usage = processUsage{MemoryMB: 20}
+	guard = newGuard()
+	guard.check(plugin, nil)
+	guard.lastSampleAt = time.Now().Add(-time.Second)
+	plugin.usage.CPUTime = 900 * time.Millisecond
+	guard.check(plugin, nil)
+	require.NotNil(t, guard.exceeded())
+	assert.Equal(t, "max_cpu_percent", guard.exceeded().Limit)
+
+	health := PluginHealth{Status: HealthStatusHealthy}
+	guard.annotate(&health, nil)
+	assert.Equal(t, HealthStatusUnhealthy, health.Status)
+	assert.Contains(t, health.Error, "max_cpu_percent")
+}
+
+func TestParseGoroutineLabels(t *testing.T) {
+	profile := bytes.NewBufferString(`goroutine profile: total 6
+3 @ 0x47d82a 0x480985
+# labels: {"templar_plugin":"tailwind"}
+#	0x480984	time.Sleep+0x164	/usr/local/go/src/runtime/time.go:368
+
+2 @ 0x47d82a 0x480985
+# labels: {"templar_plugin":"tailwind", "other":"label"}
+#	0x480984	time.Sleep+0x164	/usr/local/go/src/runtime/time.go:368
+
+1 @ 0x47d82a 0x480985
+#	0x480984	time.Sleep+0x164	/usr/local/go/src/runtime/time.go:368
+`)
+	assert.Equal(t, map[string]int{"tailwind": 5}, parseGoroutineLabels(profile))
+}
@@ -0,0 +1,304 @@
+package plugins
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// ProtocolVersion is the version of the JSON-RPC protocol spoken with
+// external plugins. Plugins declare the version they implement in their
+// manifest and confirm it when initialized.
+const ProtocolVersion = 1
+
+// Methods of the external plugin protocol. Each maps onto a hook of the
+// plugin interfaces.
+const (
+	MethodInitialize          = "initialize"
+	MethodShutdown            = "shutdown"
+	MethodHealth              = "health"
+	MethodHandleComponent     = "component.handle"
+	MethodPreBuild            = "build.pre_build"
+	MethodPostBuild           = "build.post_build"
+	MethodTransformCommand    = "build.transform_command"
+	MethodFileChange          = "watcher.file_change"
+	MethodShouldIgnore        = "watcher.should_ignore"
+	MethodServerRequest       = "server.request"
+	MethodWebSocketMessage    = "server.websocket_message"
+	rpcErrorMethodNotFound    = -32601
+	pluginStderrLimit         = 16 * 1024
+	pluginShutdownGracePeriod = 2 * time.Second
+)
+
+// errProcessExited is returned for calls an external plugin did not answer
+// before its process exited
+var errProcessExited = errors.New("plugin process exited")
+
+// rpcRequest is a JSON-RPC 2.0 request, sent as one line on the plugin's stdin
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      uint64      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response, read as one line from the
+// plugin's stdout
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError is an error an external plugin answered a call with
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements error
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// pluginProcess is a running external plugin. Calls are multiplexed by
+// request ID, so several may be in flight at once.
+type pluginProcess struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stderr  *outputTail
+	limiter *processLimiter
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan rpcResponse
+
+	// exited is closed once the process has been reaped; err says why
+	exited chan struct{}
+	err    error
+}
+
+// startPluginProcess starts the command of a manifest with the resource
+// limits of the plugin applied
+func startPluginProcess(manifest Manifest, limits ResourceLimits) (*pluginProcess, error) {
+	process, err := launchPluginProcess(manifest, limits, true)
+	if err != nil && process != nil {
+		// Kernels before 5.7 can't start processes in a cgroup
+		process.limiter.release()
+		process, err = launchPluginProcess(manifest, limits, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := process.limiter.apply(process.cmd.Process.Pid); err != nil {
+		_ = process.cmd.Process.Kill()
+		<-process.exited
+		return nil, fmt.Errorf("applying resource limits to plugin %s: %w", manifest.Name, err)
+	}
+	return process, nil
+}
+
+// launchPluginProcess starts the command of a manifest, placing it in a
+// cgroup if requested and possible. When starting in a cgroup fails, the
+// unstarted process is returned with the error so that its cgroup can be
+// released.
+func launchPluginProcess(manifest Manifest, limits ResourceLimits, cgroup bool) (*pluginProcess, error) {
+	cmd := exec.Command(manifest.CommandPath(), manifest.Args...)
+	cmd.Env = os.Environ()
+	for key, value := range manifest.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Env = append(cmd.Env, fmt.Sprintf("TEMPLAR_PLUGIN_PROTOCOL=%d", ProtocolVersion))
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("creating plugin stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("creating plugin stdout: %w", err)
+	}
+	stderr := &outputTail{limit: pluginStderrLimit}
+	cmd.Stderr = stderr
+
+	limiter := newProcessLimiter(manifest.Name, limits)
+	if cgroup {
+		limiter.prepare(cmd)
+	}
+
+	process := &pluginProcess{
+		cmd:     cmd,
+		stdin:   stdin,
+		stderr:  stderr,
+		limiter: limiter,
+		pending: make(map[uint64]chan rpcResponse),
+		exited:  make(chan struct{}),
+	}
+
+	if err := cmd.Start(); err != nil {
+		err = fmt.Errorf("starting plugin %s: %w", manifest.Name, err)
+		if limiter.usesCgroup() {
+			return process, err
+		}
+		return nil, err
+	}
+
+	go process.readResponses(stdout)
+	return process, nil
+}
+
+// call sends a request and decodes the result of its response into result,
+// which may be nil
+func (p *pluginProcess) call(ctx context.Context, method string, params, result interface{}) error {
+	responses := make(chan rpcResponse, 1)
+
+	p.mu.Lock()
+	p.nextID++
+	id := p.nextID
+	p.pending[id] = responses
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, id)
+		p.mu.Unlock()
+	}()
+
+	payload, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("encoding %s request: %w", method, err)
+	}
+
+	p.writeMu.Lock()
+	_, err = p.stdin.Write(append(payload, '\n'))
+	p.writeMu.Unlock()
+	if err != nil {
+		return p.exitError()
+	}
+
+	select {
+	case response := <-responses:
+		if response.Error != nil {
+			return response.Error
+		}
+		if result == nil || len(response.Result) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(response.Result, result); err != nil {
+			return fmt.Errorf("decoding %s result: %w", method, err)
+		}
+		return nil
+	case <-p.exited:
+		return p.exitError()
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", method, ctx.Err())
+	}
+}
+
+// readResponses hands the responses read from stdout to their callers until
+// the process exits. Each call takes one response; repeated responses and
+// responses to calls that gave up are dropped, so that a misbehaving plugin
+// can never block the reader.
+func (p *pluginProcess) readResponses(stdout io.Reader) {
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var response rpcResponse
+			if json.Unmarshal(line, &response) == nil {
+				p.mu.Lock()
+				responses, ok := p.pending[response.ID]
+				delete(p.pending, response.ID)
+				p.mu.Unlock()
+				if ok {
+					select {
+					case responses <- response:
+					default:
+					}
+				}
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	err := p.cmd.Wait()
+	p.limiter.release()
+	if err == nil {
+		err = errProcessExited
+	} else {
+		err = fmt.Errorf("%w: %v", errProcessExited, err)
+	}
+	p.err = err
+	close(p.exited)
+}
+
+// running reports whether the process has not exited
+func (p *pluginProcess) running() bool {
+	select {
+	case <-p.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// exitError describes why the process exited, with the end of its stderr
+func (p *pluginProcess) exitError() error {
+	<-p.exited
+	if stderr := p.stderr.String(); stderr != "" {
+		return fmt.Errorf("%w\n%s", p.err, stderr)
+	}
+	return p.err
+}
+
+// stop closes the process's stdin, killing it if it does not exit in time
+func (p *pluginProcess) stop() {
+	_ = p.stdin.Close()
+	select {
+	case <-p.exited:
+	case <-time.After(pluginShutdownGracePeriod):
+		if p.cmd.Process != nil {
+			_ = p.cmd.Process.Kill()
+		}
+		<-p.exited
+	}
+}
+
+// outputTail keeps the last limit bytes written to it
+type outputTail struct {
+	mu    sync.Mutex
+	buf   []byte
+	limit int
+}
+
+// Write implements io.Writer
+func (t *outputTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.limit {
+		t.buf = t.buf[len(t.buf)-t.limit:]
+	}
+	return len(p), nil
+}
+
+// String returns the kept output
+func (t *outputTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return string(t.buf)
+}
@@ -26,7 +26,7 @@ import (
 // - Dependency analysis and circular dependency detection
 // - Security hardening through input sanitization
 type ComponentRegistry struct {
-	// components stores all registered component information indexed by component name
+	// components stores all registered component information indexed by component ID
 	components map[string]*types.ComponentInfo
 	// mutex protects concurrent access to components and watchers
 	mutex sync.RWMutex
@@ -34,6 +34,8 @@ type ComponentRegistry struct {
 	watchers []chan types.ComponentEvent
 	// dependencyAnalyzer analyzes component dependencies and detects circular references
 	dependencyAnalyzer *DependencyAnalyzer
+	// modules resolves component directories to Go import paths
+	modules *moduleResolver
 }
 
 // NewComponentRegistry creates a new component registry with dependency analysis enabled.
@@ -46,6 +48,7 @@ func NewComponentRegistry() *ComponentRegistry {
 	registry := &ComponentRegistry{
 		components: make(map[string]*types.ComponentInfo),
 		watchers:   make([]chan types.ComponentEvent, 0),
+		modules:    newModuleResolver(),
 	}
 
 	// Initialize dependency analyzer
@@ -58,37 +61,40 @@ func NewComponentRegistry() *ComponentRegistry {
 //
 // The method performs:
 // 1. Input sanitization to prevent security vulnerabilities
-// 2. Component registration or update based on existing state
-// 3. Dependency analysis for the registered component
-// 4. Event notification to all watchers
+// 2. Assignment of the fully qualified component ID (import path + name)
+// 3. Component registration or update based on existing state
+// 4. Dependency analysis for the registered component
+// 5. Event notification to all watchers
 //
 // The operation is thread-safe and non-blocking for event notifications.
 func (r *ComponentRegistry) Register(component *types.ComponentInfo) {
 	// Validate and sanitize component data
 	component = r.sanitizeComponent(component)
+	r.assignID(component)
 
 	r.mutex.Lock()
 
 	eventType := types.EventTypeAdded
-	if _, exists := r.components[component.Name]; exists {
+	if _, exists := r.components[component.ID]; exists {
 		eventType = types.EventTypeUpdated
 	}
 
-	r.components[component.Name] = component
+	r.components[component.ID] = component
 	r.mutex.Unlock()
 
 	// Analyze dependencies for the component
 	if r.dependencyAnalyzer != nil {
-		deps, err := r.dependencyAnalyzer.AnalyzeComponent(component)
+		result, err := r.dependencyAnalyzer.analyze(component)
 		if err == nil {
 			// Sanitize dependencies to prevent path traversal
-			sanitizedDeps := make([]string, len(deps))
-			for i, dep := range deps {
-				sanitizedDeps[i] = sanitizeFilePath(dep)
+			sanitizedDeps := make([]string, len(result.dependencies))
+			for i, dep := range result.dependencies {
+				sanitizedDeps[i] = sanitizeComponentID(dep)
 			}
 
 			r.mutex.Lock()
 			component.Dependencies = sanitizedDeps
+			component.AcceptsChildren = result.acceptsChildren
 			r.mutex.Unlock()
 		}
 	}
@@ -111,13 +117,42 @@ func (r *ComponentRegistry) Register(component *types.ComponentInfo) {
 	r.mutex.RUnlock()
 }
 
-// Get retrieves a component by name
+// assignID fills in the component's import path and fully qualified ID.
+// The import path is derived from the enclosing Go module when the scanner
+// did not provide one.
+func (r *ComponentRegistry) assignID(component *types.ComponentInfo) {
+	if component == nil {
+		return
+	}
+
+	if component.ImportPath == "" && component.FilePath != "" {
+		if absPath, err := filepath.Abs(component.FilePath); err == nil {
+			component.ImportPath = sanitizeComponentID(r.modules.importPath(filepath.Dir(absPath)))
+		}
+	}
+
+	if component.ID == "" {
+		component.ID = sanitizeComponentID(componentID(component))
+	}
+}
+
+// Get retrieves a component by fully qualified ID or by an unambiguous
+// name. Use Resolve to find out why a lookup failed.
 func (r *ComponentRegistry) Get(name string) (*types.ComponentInfo, bool) {
+	component, err := r.Resolve(name)
+	return component, err == nil
+}
+
+// Resolve looks up a component by its fully qualified ID (for example
+// "github.com/acme/app/ui.Button") or by a shorter form: the bare name
+// ("Button"), the package-qualified name ("ui.Button") or any import path
+// suffix ("app/ui.Button"). Shorter forms must match exactly one component;
+// otherwise an *AmbiguousComponentError listing the candidates is returned.
+func (r *ComponentRegistry) Resolve(name string) (*types.ComponentInfo, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	component, exists := r.components[name]
-	return component, exists
+	return r.resolveLocked(name)
 }
 
 // GetAll returns all registered components
@@ -132,29 +167,29 @@ func (r *ComponentRegistry) GetAll() []*types.ComponentInfo {
 	return result
 }
 
-// GetAllMap returns all registered components as a map
+// GetAllMap returns all registered components as a map keyed by component ID
 func (r *ComponentRegistry) GetAllMap() map[string]*types.ComponentInfo {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	result := make(map[string]*types.ComponentInfo)
-	for name, component := range r.components {
-		result[name] = component
+	for id, component := range r.components {
+		result[id] = component
 	}
 	return result
 }
 
-// Remove removes a component from the registry
+// Remove removes a component from the registry by ID or unambiguous name
 func (r *ComponentRegistry) Remove(name string) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	component, exists := r.components[name]
-	if !exists {
+	component, err := r.resolveLocked(name)
+	if err != nil {
 		return
 	}
 
-	delete(r.components, name)
+	delete(r.components, component.ID)
 
 	// Notify watchers
 	event := types.ComponentEvent{
@@ -228,20 +263,36 @@ func (r *ComponentRegistry) sanitizeComponent(component *types.ComponentInfo) *t
 	// Sanitize package name
 	sanitized.Package = sanitizeIdentifier(sanitized.Package)
 
+	// Sanitize qualified identity - import paths may contain slashes and dashes
+	sanitized.ImportPath = sanitizeComponentID(sanitized.ImportPath)
+	sanitized.ID = sanitizeComponentID(sanitized.ID)
+
 	// Sanitize file path - remove control characters
 	sanitized.FilePath = sanitizeFilePath(sanitized.FilePath)
 
 	// Sanitize parameters
 	for i := range sanitized.Parameters {
-		sanitized.Parameters[i].Name = sanitizeIdentifier(sanitized.Parameters[i].Name)
-		sanitized.Parameters[i].Type = sanitizeIdentifier(sanitized.Parameters[i].Type)
+		sanitized.Parameters[i].Name = sanitizeParameterName(sanitized.Parameters[i].Name)
+		sanitized.Parameters[i].Type = sanitizeTypeExpression(sanitized.Parameters[i].Type)
+	}
+
+	// Sanitize receiver and type parameters of method and generic templates
+	if sanitized.Receiver != nil {
+		receiver := *sanitized.Receiver
+		receiver.Name = sanitizeParameterName(receiver.Name)
+		receiver.Type = sanitizeTypeExpression(receiver.Type)
+		sanitized.Receiver = &receiver
+	}
+	for i := range sanitized.TypeParameters {
+		sanitized.TypeParameters[i].Name = sanitizeParameterName(sanitized.TypeParameters[i].Name)
+		sanitized.TypeParameters[i].Type = sanitizeTypeExpression(sanitized.TypeParameters[i].Type)
 	}
 
 	// Sanitize dependencies to prevent path traversal
 	if sanitized.Dependencies != nil {
 		sanitizedDeps := make([]string, len(sanitized.Dependencies))
 		for i, dep := range sanitized.Dependencies {
-			sanitizedDeps[i] = sanitizeFilePath(dep)
+			sanitizedDeps[i] = sanitizeComponentID(dep)
 		}
 		sanitized.Dependencies = sanitizedDeps
 	}
@@ -275,6 +326,42 @@ func sanitizeIdentifier(identifier string) string {
 	return cleanedId
 }
 
+// sanitizeParameterName keeps only the characters of a Go identifier.
+// Parameter names are prop keys and never reach the file system, so they are
+// not subject to the system path checks of sanitizeIdentifier, which would
+// otherwise rename common parameters such as "variant".
+func sanitizeParameterName(name string) string {
+	var cleaned []rune
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			cleaned = append(cleaned, r)
+		}
+	}
+	return string(cleaned)
+}
+
+// sanitizeTypeExpression removes characters that cannot appear in a Go type
+// expression while keeping composite types such as []*Item, map[string]int
+// and func(string) error intact.
+func sanitizeTypeExpression(typeExpr string) string {
+	var cleaned []rune
+	for _, r := range typeExpr {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+			strings.ContainsRune("_.*[](){},~| ", r) {
+			cleaned = append(cleaned, r)
+		}
+	}
+
+	cleanedType := strings.TrimSpace(string(cleaned))
+
+	// Function types that mention exec are rejected outright
+	if strings.Contains(cleanedType, "func(") && strings.Contains(cleanedType, "exec") {
+		return sanitizeIdentifier(cleanedType)
+	}
+
+	return cleanedType
+}
+
 // sanitizeFilePath removes control characters and prevents path traversal attacks
 func sanitizeFilePath(path string) string {
 	var cleaned []rune
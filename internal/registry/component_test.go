@@ -1,12 +1,15 @@
 package registry
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/conneroisu/templar/internal/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewComponentRegistry(t *testing.T) {
@@ -23,6 +26,7 @@ func TestComponentRegistry_Add(t *testing.T) {
 	registry := NewComponentRegistry()
 
 	component := &types.ComponentInfo{
+		ID:       "main.TestComponent",
 		Name:     "TestComponent",
 		FilePath: "/path/to/component.templ",
 		Package:  "main",
@@ -52,6 +56,7 @@ func TestComponentRegistry_Update(t *testing.T) {
 
 	// Add initial component
 	component := &types.ComponentInfo{
+		ID:       "main.TestComponent",
 		Name:     "TestComponent",
 		FilePath: "/path/to/component.templ",
 		Package:  "main",
@@ -63,6 +68,7 @@ func TestComponentRegistry_Update(t *testing.T) {
 
 	// Update component
 	updatedComponent := &types.ComponentInfo{
+		ID:       "main.TestComponent",
 		Name:     "TestComponent",
 		FilePath: "/path/to/component.templ",
 		Package:  "main",
@@ -88,6 +94,7 @@ func TestComponentRegistry_Remove(t *testing.T) {
 
 	// Add component
 	component := &types.ComponentInfo{
+		ID:         "main.TestComponent",
 		Name:       "TestComponent",
 		FilePath:   "/path/to/component.templ",
 		Package:    "main",
@@ -166,6 +173,7 @@ func TestComponentRegistry_Watch(t *testing.T) {
 
 	// Add a component and check if event is received
 	component := &types.ComponentInfo{
+		ID:         "main.TestComponent",
 		Name:       "TestComponent",
 		FilePath:   "/path/to/component.templ",
 		Package:    "main",
@@ -233,6 +241,7 @@ func TestComponentRegistry_EventTypes(t *testing.T) {
 	watcher := registry.Watch()
 
 	component := &types.ComponentInfo{
+		ID:         "main.TestComponent",
 		Name:       "TestComponent",
 		FilePath:   "/path/to/component.templ",
 		Package:    "main",
@@ -255,6 +264,7 @@ func TestComponentRegistry_EventTypes(t *testing.T) {
 
 	// Test Update event
 	updatedComponent := &types.ComponentInfo{
+		ID:       "main.TestComponent",
 		Name:     "TestComponent",
 		FilePath: "/path/to/component.templ",
 		Package:  "main",
@@ -333,6 +343,68 @@ func TestComponentRegistry_ConcurrentAccess(t *testing.T) {
 	}
 }
 
+func TestComponentRegistry_QualifiedIDs(t *testing.T) {
+	root := writeDependencyFixture(t)
+	registry := NewComponentRegistry()
+
+	registry.Register(&types.ComponentInfo{Name: "Button", Package: "ui", FilePath: filepath.Join(root, "ui/button.templ")})
+	registry.Register(&types.ComponentInfo{Name: "Button", Package: "marketing", FilePath: filepath.Join(root, "marketing/button.templ")})
+	registry.Register(&types.ComponentInfo{
+		Name:     "Render",
+		Package:  "ui",
+		FilePath: filepath.Join(root, "ui/card.templ"),
+		Receiver: &types.ParameterInfo{Name: "c", Type: "*Card"},
+	})
+	registry.Register(&types.ComponentInfo{Name: "Alert", Package: "feedback", FilePath: "/path/to/alert.templ"})
+
+	assert.Equal(t, 4, registry.Count())
+
+	all := registry.GetAllMap()
+	assert.Contains(t, all, "example.com/app/ui.Button")
+	assert.Contains(t, all, "example.com/app/marketing.Button")
+	assert.Contains(t, all, "example.com/app/ui.Card.Render")
+	assert.Contains(t, all, "feedback.Alert")
+	assert.Equal(t, "example.com/app/ui", all["example.com/app/ui.Button"].ImportPath)
+
+	tests := []struct {
+		name     string
+		lookup   string
+		expected string
+	}{
+		{"full ID", "example.com/app/ui.Button", "example.com/app/ui.Button"},
+		{"package qualified", "marketing.Button", "example.com/app/marketing.Button"},
+		{"import path suffix", "app/ui.Button", "example.com/app/ui.Button"},
+		{"method template", "Card.Render", "example.com/app/ui.Card.Render"},
+		{"unambiguous bare name", "Alert", "feedback.Alert"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component, err := registry.Resolve(tt.lookup)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, component.ID)
+		})
+	}
+
+	_, err := registry.Resolve("Button")
+	var ambiguous *AmbiguousComponentError
+	require.ErrorAs(t, err, &ambiguous)
+	assert.Equal(t, []string{"example.com/app/marketing.Button", "example.com/app/ui.Button"}, ambiguous.Candidates)
+	assert.Contains(t, err.Error(), "ambiguous")
+
+	_, exists := registry.Get("Button")
+	assert.False(t, exists)
+
+	_, err = registry.Resolve("Missing")
+	assert.True(t, errors.Is(err, ErrComponentNotFound))
+
+	// Removing one of the Buttons makes the bare name unambiguous again
+	registry.Remove("marketing.Button")
+	component, exists := registry.Get("Button")
+	require.True(t, exists)
+	assert.Equal(t, "example.com/app/ui.Button", component.ID)
+}
+
 func TestComponentInfo_Basic(t *testing.T) {
 	component := &types.ComponentInfo{
 		Name:     "TestComponent",
@@ -359,3 +431,23 @@ func TestParameterInfo_Basic(t *testing.T) {
 	assert.Equal(t, "title", param.Name)
 	assert.Equal(t, "string", param.Type)
 }
+
+func TestComponentRegistry_KeepsParameterNames(t *testing.T) {
+	registry := NewComponentRegistry()
+	registry.Register(&types.ComponentInfo{
+		Name:     "Badge",
+		FilePath: "/path/to/badge.templ",
+		Package:  "ui",
+		Parameters: []types.ParameterInfo{
+			{Name: "variant", Type: "Variant"},
+			{Name: "binding", Type: "string"},
+			{Name: "bad;name", Type: "string"},
+		},
+	})
+
+	component, exists := registry.Get("ui.Badge")
+	require.True(t, exists)
+	assert.Equal(t, "variant", component.Parameters[0].Name)
+	assert.Equal(t, "binding", component.Parameters[1].Name)
+	assert.Equal(t, "badname", component.Parameters[2].Name)
+}
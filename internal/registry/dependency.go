@@ -5,96 +5,122 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
-	"regexp"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+
+	templparser "github.com/a-h/templ/parser/v2"
+	"github.com/a-h/templ/parser/v2/visitor"
 
 	"github.com/conneroisu/templar/internal/types"
 )
 
-// DependencyAnalyzer analyzes component dependencies
+// maxParsedFiles bounds the number of parsed templ files kept by the analyzer
+const maxParsedFiles = 512
+
+// DependencyAnalyzer analyzes component dependencies by walking the templ AST
+// of each component and resolving @Child(...), @pkg.Child(...) and
+// { children... } composition against the registry.
 type DependencyAnalyzer struct {
 	registry *ComponentRegistry
+	// mu protects the parse cache
+	mu sync.Mutex
+	// parsedFiles caches parsed templ files keyed by path and content hash
+	parsedFiles map[string]*templparser.TemplateFile
+	// modules resolves import paths to directories inside the module
+	modules *moduleResolver
+}
+
+// componentDependencies is the result of analyzing a single component
+type componentDependencies struct {
+	// dependencies lists the IDs of components rendered by the component
+	dependencies []string
+	// acceptsChildren reports whether the component renders { children... }
+	acceptsChildren bool
+}
+
+// templateReference is a component call found in a template body
+type templateReference struct {
+	// qualifier is the package alias for @pkg.Child calls, empty for local calls
+	qualifier string
+	// name is the called component name
+	name string
 }
 
 // NewDependencyAnalyzer creates a new dependency analyzer
 func NewDependencyAnalyzer(registry *ComponentRegistry) *DependencyAnalyzer {
+	modules := newModuleResolver()
+	if registry != nil && registry.modules != nil {
+		modules = registry.modules
+	}
+
 	return &DependencyAnalyzer{
-		registry: registry,
+		registry:    registry,
+		parsedFiles: make(map[string]*templparser.TemplateFile),
+		modules:     modules,
 	}
 }
 
 // AnalyzeComponent analyzes dependencies for a single component
 func (da *DependencyAnalyzer) AnalyzeComponent(component *types.ComponentInfo) ([]string, error) {
-	dependencies := make([]string, 0)
+	result, err := da.analyze(component)
+	if err != nil {
+		return make([]string, 0), err
+	}
+	return result.dependencies, nil
+}
 
-	// Read and parse the component file
-	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, component.FilePath, nil, parser.ParseComments)
+// analyze parses the component's templ file and resolves every component
+// reference in its body to a registered component.
+func (da *DependencyAnalyzer) analyze(component *types.ComponentInfo) (*componentDependencies, error) {
+	tf, err := da.parseFile(component.FilePath, component.Hash)
 	if err != nil {
-		return dependencies, fmt.Errorf("failed to parse file %s: %w", component.FilePath, err)
+		return nil, fmt.Errorf("failed to parse file %s: %w", component.FilePath, err)
 	}
 
-	// Find templ component calls in the AST
-	visitor := &dependencyVisitor{
-		registry:     da.registry,
-		dependencies: make(map[string]bool),
-		currentComp:  component.Name,
+	template := findTemplate(tf, component)
+	if template == nil {
+		return nil, fmt.Errorf("component %s not found in %s", component.Name, component.FilePath)
 	}
 
-	ast.Walk(visitor, node)
+	refs, acceptsChildren := collectReferences(template.Children)
 
-	// Convert map to slice
-	for dep := range visitor.dependencies {
-		dependencies = append(dependencies, dep)
+	componentDir := ""
+	if absPath, err := filepath.Abs(component.FilePath); err == nil {
+		componentDir = filepath.Dir(absPath)
 	}
 
-	return dependencies, nil
+	deps := da.resolveReferences(refs, templateImports(tf), componentDir, component)
+
+	return &componentDependencies{
+		dependencies:    deps,
+		acceptsChildren: acceptsChildren,
+	}, nil
 }
 
-// AnalyzeComponentFromContent analyzes dependencies from raw content
+// AnalyzeComponentFromContent analyzes dependencies from raw templ content.
+// References are resolved by name only since the content has no location.
 func (da *DependencyAnalyzer) AnalyzeComponentFromContent(content, componentName string) []string {
-	dependencies := make(map[string]bool)
-
-	// Pattern to match component calls in templ syntax
-	// Matches: @ComponentName(...) and ComponentName(...)
-	patterns := []*regexp.Regexp{
-		regexp.MustCompile(`@([A-Z][a-zA-Z0-9_]*)\s*\(`),        // @ComponentName(
-		regexp.MustCompile(`\s([A-Z][a-zA-Z0-9_]*)\s*\(`),       // ComponentName(
-		regexp.MustCompile(`{!\s*([A-Z][a-zA-Z0-9_]*)\s*\(`),    // {! ComponentName(
-		regexp.MustCompile(`templ\s+([A-Z][a-zA-Z0-9_]*)\s*\(`), // templ ComponentName(
+	tf, err := templparser.ParseString(content)
+	if err != nil {
+		return make([]string, 0)
 	}
 
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		// Skip comment lines
-		if strings.HasPrefix(strings.TrimSpace(line), "//") {
+	var refs []templateReference
+	for _, node := range tf.Nodes {
+		template, ok := node.(*templparser.HTMLTemplate)
+		if !ok || templateName(template.Expression.Value) != componentName {
 			continue
 		}
-
-		for _, pattern := range patterns {
-			matches := pattern.FindAllStringSubmatch(line, -1)
-			for _, match := range matches {
-				if len(match) > 1 {
-					dep := match[1]
-					// Don't include self-references
-					if dep != componentName {
-						// Check if this component exists in registry
-						if _, exists := da.registry.Get(dep); exists {
-							dependencies[dep] = true
-						}
-					}
-				}
-			}
-		}
-	}
-
-	// Convert to slice
-	result := make([]string, 0, len(dependencies))
-	for dep := range dependencies {
-		result = append(result, dep)
+		refs, _ = collectReferences(template.Children)
 	}
 
-	return result
+	return da.resolveReferences(refs, templateImports(tf), "", &types.ComponentInfo{Name: componentName})
 }
 
 // UpdateAllDependencies updates dependencies for all components
@@ -102,16 +128,23 @@ func (da *DependencyAnalyzer) UpdateAllDependencies() error {
 	components := da.registry.GetAll()
 
 	for _, component := range components {
-		deps, err := da.AnalyzeComponent(component)
+		result, err := da.analyze(component)
 		if err != nil {
 			// Log error but continue with other components
 			continue
 		}
 
+		// Sanitize dependencies to prevent path traversal
+		deps := make([]string, len(result.dependencies))
+		for i, dep := range result.dependencies {
+			deps[i] = sanitizeComponentID(dep)
+		}
+
 		// Update component dependencies
 		da.registry.mutex.Lock()
-		if existing := da.registry.components[component.Name]; existing != nil {
+		if existing := da.registry.components[component.ID]; existing != nil {
 			existing.Dependencies = deps
+			existing.AcceptsChildren = result.acceptsChildren
 		}
 		da.registry.mutex.Unlock()
 	}
@@ -119,16 +152,272 @@ func (da *DependencyAnalyzer) UpdateAllDependencies() error {
 	return nil
 }
 
-// GetDependents returns components that depend on the given component
+// parseFile parses a templ file, reusing the previous parse when the content hash is unchanged
+func (da *DependencyAnalyzer) parseFile(filePath, hash string) (*templparser.TemplateFile, error) {
+	cacheKey := filePath + ":" + hash
+
+	if hash != "" {
+		da.mu.Lock()
+		tf, ok := da.parsedFiles[cacheKey]
+		da.mu.Unlock()
+		if ok {
+			return tf, nil
+		}
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	tf, err := templparser.ParseString(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	if hash != "" {
+		da.mu.Lock()
+		if len(da.parsedFiles) >= maxParsedFiles {
+			da.parsedFiles = make(map[string]*templparser.TemplateFile)
+		}
+		da.parsedFiles[cacheKey] = tf
+		da.mu.Unlock()
+	}
+
+	return tf, nil
+}
+
+// findTemplate locates the templ declaration for a component within a parsed file
+func findTemplate(tf *templparser.TemplateFile, component *types.ComponentInfo) *templparser.HTMLTemplate {
+	var fallback *templparser.HTMLTemplate
+
+	for _, node := range tf.Nodes {
+		template, ok := node.(*templparser.HTMLTemplate)
+		if !ok || templateName(template.Expression.Value) != component.Name {
+			continue
+		}
+
+		// Prefer the declaration at the recorded position when several
+		// receivers declare templates with the same name
+		if component.Line == 0 || int(template.Range.From.Line)+1 == component.Line {
+			return template
+		}
+		if fallback == nil {
+			fallback = template
+		}
+	}
+
+	return fallback
+}
+
+// templateName extracts the declared name from a templ signature such as
+// "Button(text string)" or "(c Card) Render()"
+func templateName(signature string) string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\nfunc "+signature+" {}\n", parser.SkipObjectResolution)
+	if err != nil {
+		return ""
+	}
+
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name != nil {
+			return fn.Name.Name
+		}
+	}
+
+	return ""
+}
+
+// templateImports maps import aliases to import paths for a templ file.
+// Unaliased imports use the last path element as their alias.
+func templateImports(tf *templparser.TemplateFile) map[string]string {
+	imports := make(map[string]string)
+
+	for _, node := range tf.Nodes {
+		goExpr, ok := node.(*templparser.TemplateFileGoExpression)
+		if !ok {
+			continue
+		}
+
+		file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+goExpr.Expression.Value, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+
+		for _, imp := range file.Imports {
+			importPath, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				continue
+			}
+
+			alias := path.Base(importPath)
+			if imp.Name != nil {
+				alias = imp.Name.Name
+			}
+			if alias == "_" || alias == "." {
+				continue
+			}
+
+			imports[alias] = importPath
+		}
+	}
+
+	return imports
+}
+
+// collectReferences walks template nodes and returns every @Component call
+// along with whether the template renders { children... }
+func collectReferences(nodes []templparser.Node) ([]templateReference, bool) {
+	var refs []templateReference
+	acceptsChildren := false
+
+	v := visitor.New()
+	v.TemplElementExpression = func(n *templparser.TemplElementExpression) error {
+		if ref, ok := parseReference(n.Expression.Value); ok {
+			refs = append(refs, ref)
+		}
+		for _, child := range n.Children {
+			if err := child.Visit(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	v.CallTemplateExpression = func(n *templparser.CallTemplateExpression) error {
+		if ref, ok := parseReference(n.Expression.Value); ok {
+			refs = append(refs, ref)
+		}
+		return nil
+	}
+	v.ChildrenExpression = func(n *templparser.ChildrenExpression) error {
+		acceptsChildren = true
+		return nil
+	}
+
+	for _, node := range nodes {
+		if err := node.Visit(v); err != nil {
+			break
+		}
+	}
+
+	return refs, acceptsChildren
+}
+
+// parseReference converts a templ call expression such as Child(x),
+// pkg.Child(x) or List[string](items) into a component reference
+func parseReference(expression string) (templateReference, bool) {
+	expr, err := parser.ParseExpr(strings.TrimSpace(expression))
+	if err != nil {
+		return templateReference{}, false
+	}
+
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return templateReference{}, false
+	}
+
+	fun := call.Fun
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return templateReference{name: f.Name}, true
+	case *ast.SelectorExpr:
+		if pkg, ok := f.X.(*ast.Ident); ok {
+			return templateReference{qualifier: pkg.Name, name: f.Sel.Name}, true
+		}
+	}
+
+	return templateReference{}, false
+}
+
+// resolveReferences maps template references to component IDs. Local calls
+// resolve to components in the same package directory; qualified calls are
+// resolved through the file's imports and the enclosing Go module.
+func (da *DependencyAnalyzer) resolveReferences(refs []templateReference, imports map[string]string, componentDir string, component *types.ComponentInfo) []string {
+	dependencies := make([]string, 0)
+	if len(refs) == 0 {
+		return dependencies
+	}
+
+	registered := da.registry.GetAll()
+	seen := make(map[string]bool)
+
+	for _, ref := range refs {
+		targetDir := componentDir
+		targetPackage := ""
+
+		if ref.qualifier != "" {
+			importPath, ok := imports[ref.qualifier]
+			if !ok {
+				// Not a package - a method call on a value such as @c.Render()
+				continue
+			}
+			targetDir = da.modules.resolveImportDir(importPath, componentDir)
+			targetPackage = path.Base(importPath)
+		}
+
+		for _, candidate := range registered {
+			if candidate.Name != ref.name {
+				continue
+			}
+			if candidate.Kind != "" && candidate.Kind != types.ComponentKindTempl {
+				continue
+			}
+
+			if targetDir != "" {
+				if componentPackageDir(candidate) != targetDir {
+					continue
+				}
+			} else if targetPackage != "" && candidate.Package != targetPackage {
+				continue
+			}
+
+			// Don't include self-references
+			if candidate.Name == component.Name && componentPackageDir(candidate) == componentDir {
+				continue
+			}
+
+			if !seen[candidate.ID] {
+				seen[candidate.ID] = true
+				dependencies = append(dependencies, candidate.ID)
+			}
+		}
+	}
+
+	sort.Strings(dependencies)
+	return dependencies
+}
+
+// componentPackageDir returns the absolute directory of a component's package
+func componentPackageDir(component *types.ComponentInfo) string {
+	absPath, err := filepath.Abs(component.FilePath)
+	if err != nil {
+		return ""
+	}
+	return filepath.Dir(absPath)
+}
+
+// GetDependents returns components that depend on the given component,
+// identified by ID or unambiguous name
 func (da *DependencyAnalyzer) GetDependents(componentName string) []*types.ComponentInfo {
 	var dependents []*types.ComponentInfo
 
 	da.registry.mutex.RLock()
 	defer da.registry.mutex.RUnlock()
 
+	id := componentName
+	if target, err := da.registry.resolveLocked(componentName); err == nil {
+		id = target.ID
+	}
+
 	for _, component := range da.registry.components {
 		for _, dep := range component.Dependencies {
-			if dep == componentName {
+			if dep == id {
 				dependents = append(dependents, component)
 				break
 			}
@@ -138,6 +427,95 @@ func (da *DependencyAnalyzer) GetDependents(componentName string) []*types.Compo
 	return dependents
 }
 
+// GetTransitiveDependents returns the components depending on any of the
+// given components, identified by ID or unambiguous name, either directly or
+// through other components. The given components are not included.
+func (da *DependencyAnalyzer) GetTransitiveDependents(componentNames ...string) []*types.ComponentInfo {
+	da.registry.mutex.RLock()
+	defer da.registry.mutex.RUnlock()
+
+	dependents := make(map[string][]string)
+	for id, component := range da.registry.components {
+		for _, dep := range component.Dependencies {
+			dependents[dep] = append(dependents[dep], id)
+		}
+	}
+
+	seen := make(map[string]bool)
+	queue := make([]string, 0, len(componentNames))
+	for _, name := range componentNames {
+		id := name
+		if target, err := da.registry.resolveLocked(name); err == nil {
+			id = target.ID
+		}
+		if !seen[id] {
+			seen[id] = true
+			queue = append(queue, id)
+		}
+	}
+
+	var result []*types.ComponentInfo
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, dependent := range dependents[id] {
+			if seen[dependent] {
+				continue
+			}
+			seen[dependent] = true
+			queue = append(queue, dependent)
+			result = append(result, da.registry.components[dependent])
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// ComponentsForFile returns the components a change to a file affects
+// directly: those declared in a .templ file, or for any other Go file those
+// of its package and those importing it. Their dependents are not included.
+func (da *DependencyAnalyzer) ComponentsForFile(filePath string) []*types.ComponentInfo {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		absPath = filepath.Clean(filePath)
+	}
+	dir := filepath.Dir(absPath)
+	isTempl := filepath.Ext(absPath) == ".templ"
+
+	importPath := ""
+	if !isTempl {
+		importPath = da.modules.importPath(dir)
+	}
+
+	da.registry.mutex.RLock()
+	defer da.registry.mutex.RUnlock()
+
+	var affected []*types.ComponentInfo
+	for _, component := range da.registry.components {
+		componentPath, err := filepath.Abs(component.FilePath)
+		if err != nil {
+			continue
+		}
+
+		if isTempl {
+			if componentPath == absPath {
+				affected = append(affected, component)
+			}
+			continue
+		}
+
+		// Types and helpers are used by the package's own components and by
+		// components importing the package
+		if filepath.Dir(componentPath) == dir || (importPath != "" && slices.Contains(component.Imports, importPath)) {
+			affected = append(affected, component)
+		}
+	}
+
+	sort.Slice(affected, func(i, j int) bool { return affected[i].ID < affected[j].ID })
+	return affected
+}
+
 // GetDependencyGraph returns the full dependency graph
 func (da *DependencyAnalyzer) GetDependencyGraph() map[string][]string {
 	graph := make(map[string][]string)
@@ -153,93 +531,79 @@ func (da *DependencyAnalyzer) GetDependencyGraph() map[string][]string {
 	return graph
 }
 
-// DetectCircularDependencies detects circular dependencies in the graph
+// DetectCircularDependencies detects circular dependencies in the graph.
+// Each cycle is reported once, starting from its lexically smallest component
+// and closed by repeating that component at the end.
 func (da *DependencyAnalyzer) DetectCircularDependencies() [][]string {
 	cycles := make([][]string, 0)
 	graph := da.GetDependencyGraph()
 
+	components := make([]string, 0, len(graph))
+	for component := range graph {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
 	visited := make(map[string]bool)
 	recStack := make(map[string]bool)
-	path := make([]string, 0)
+	seen := make(map[string]bool)
 
-	for component := range graph {
+	for _, component := range components {
 		if !visited[component] {
-			if cycle := da.detectCycleDFS(component, graph, visited, recStack, path); cycle != nil {
-				cycles = append(cycles, cycle)
-			}
+			da.detectCycleDFS(component, graph, visited, recStack, nil, seen, &cycles)
 		}
 	}
 
 	return cycles
 }
 
-// detectCycleDFS performs DFS to detect cycles
-func (da *DependencyAnalyzer) detectCycleDFS(component string, graph map[string][]string, visited, recStack map[string]bool, path []string) []string {
+// detectCycleDFS performs DFS to detect cycles, recording every distinct cycle found
+func (da *DependencyAnalyzer) detectCycleDFS(component string, graph map[string][]string, visited, recStack map[string]bool, path []string, seen map[string]bool, cycles *[][]string) {
 	visited[component] = true
 	recStack[component] = true
 	path = append(path, component)
 
-	for _, dep := range graph[component] {
+	deps := append([]string(nil), graph[component]...)
+	sort.Strings(deps)
+
+	for _, dep := range deps {
 		if !visited[dep] {
-			if cycle := da.detectCycleDFS(dep, graph, visited, recStack, path); cycle != nil {
-				return cycle
-			}
-		} else if recStack[dep] {
-			// Found cycle - extract the cycle from path
-			cycleStart := -1
-			for i, p := range path {
-				if p == dep {
-					cycleStart = i
-					break
-				}
+			da.detectCycleDFS(dep, graph, visited, recStack, path, seen, cycles)
+			continue
+		}
+		if !recStack[dep] {
+			continue
+		}
+
+		// Found cycle - extract the cycle from path
+		for i, p := range path {
+			if p != dep {
+				continue
 			}
-			if cycleStart >= 0 {
-				cycle := make([]string, len(path)-cycleStart+1)
-				copy(cycle, path[cycleStart:])
-				cycle[len(cycle)-1] = dep // Close the cycle
-				return cycle
+			cycle := canonicalCycle(path[i:])
+			key := strings.Join(cycle, "\x00")
+			if !seen[key] {
+				seen[key] = true
+				*cycles = append(*cycles, cycle)
 			}
+			break
 		}
 	}
 
 	recStack[component] = false
-	return nil
 }
 
-// dependencyVisitor implements ast.Visitor for dependency analysis
-type dependencyVisitor struct {
-	registry     *ComponentRegistry
-	dependencies map[string]bool
-	currentComp  string
-}
-
-// Visit visits AST nodes to find component calls
-func (v *dependencyVisitor) Visit(node ast.Node) ast.Visitor {
-	switch n := node.(type) {
-	case *ast.CallExpr:
-		// Look for function calls that might be component calls
-		if ident, ok := n.Fun.(*ast.Ident); ok {
-			// Check if this looks like a component name (starts with uppercase)
-			if len(ident.Name) > 0 && ident.Name[0] >= 'A' && ident.Name[0] <= 'Z' {
-				// Don't include self-references
-				if ident.Name != v.currentComp {
-					// Check if this component exists in registry
-					if _, exists := v.registry.Get(ident.Name); exists {
-						v.dependencies[ident.Name] = true
-					}
-				}
-			}
-		}
-	case *ast.SelectorExpr:
-		// Handle package.ComponentName calls
-		if len(n.Sel.Name) > 0 && n.Sel.Name[0] >= 'A' && n.Sel.Name[0] <= 'Z' {
-			if n.Sel.Name != v.currentComp {
-				if _, exists := v.registry.Get(n.Sel.Name); exists {
-					v.dependencies[n.Sel.Name] = true
-				}
-			}
+// canonicalCycle rotates a cycle to start at its smallest member and closes it
+func canonicalCycle(members []string) []string {
+	start := 0
+	for i, member := range members {
+		if member < members[start] {
+			start = i
 		}
 	}
 
-	return v
+	cycle := make([]string, 0, len(members)+1)
+	cycle = append(cycle, members[start:]...)
+	cycle = append(cycle, members[:start]...)
+	return append(cycle, cycle[0])
 }
@@ -23,6 +23,23 @@ func (r *ComponentRegistry) GetDependents(componentName string) []*types.Compone
 	return r.dependencyAnalyzer.GetDependents(componentName)
 }
 
+// GetTransitiveDependents returns the components depending on any of the
+// given components, directly or through other components
+func (r *ComponentRegistry) GetTransitiveDependents(componentNames ...string) []*types.ComponentInfo {
+	if r.dependencyAnalyzer == nil {
+		return nil
+	}
+	return r.dependencyAnalyzer.GetTransitiveDependents(componentNames...)
+}
+
+// ComponentsForFile returns the components a change to a file directly affects
+func (r *ComponentRegistry) ComponentsForFile(filePath string) []*types.ComponentInfo {
+	if r.dependencyAnalyzer == nil {
+		return nil
+	}
+	return r.dependencyAnalyzer.ComponentsForFile(filePath)
+}
+
 // GetDependencyGraph returns the full dependency graph
 func (r *ComponentRegistry) GetDependencyGraph() map[string][]string {
 	if r.dependencyAnalyzer == nil {
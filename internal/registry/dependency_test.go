@@ -0,0 +1,188 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// writeDependencyFixture writes a small module with templ components spread
+// across packages and returns its root directory relative to the test.
+func writeDependencyFixture(t *testing.T) string {
+	t.Helper()
+
+	root, err := os.MkdirTemp(".", "deptest")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.24\n",
+		"ui/button.templ": `package ui
+
+templ Button(text string) {
+	<button>{ text }</button>
+}
+`,
+		"layout/shell.templ": `package layout
+
+templ Shell(title string) {
+	<main>{ children... }</main>
+}
+`,
+		"pages/home.templ": `package pages
+
+import (
+	"example.com/app/ui"
+	shell "example.com/app/layout"
+)
+
+templ Home(c Card) {
+	@shell.Shell("Home") {
+		@ui.Button("Click")
+	}
+	@c.Render()
+	@Footer()
+	<p>Button(not a component call)</p>
+}
+
+templ Footer() {
+	<footer></footer>
+}
+
+templ Ping() {
+	@Pong()
+}
+
+templ Pong() {
+	@Ping()
+}
+`,
+		"app/app.templ": `package app
+
+import "example.com/app/pages"
+
+templ App() {
+	@pages.Home(nil)
+}
+`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	return root
+}
+
+func TestDependencyAnalyzer_ResolvesTemplComposition(t *testing.T) {
+	root := writeDependencyFixture(t)
+	reg := NewComponentRegistry()
+
+	components := []*types.ComponentInfo{
+		{Name: "Button", Package: "ui", FilePath: filepath.Join(root, "ui/button.templ"), Kind: types.ComponentKindTempl},
+		{Name: "Shell", Package: "layout", FilePath: filepath.Join(root, "layout/shell.templ"), Kind: types.ComponentKindTempl},
+		{Name: "Home", Package: "pages", FilePath: filepath.Join(root, "pages/home.templ"), Kind: types.ComponentKindTempl},
+		{Name: "Footer", Package: "pages", FilePath: filepath.Join(root, "pages/home.templ"), Kind: types.ComponentKindTempl},
+		{Name: "Ping", Package: "pages", FilePath: filepath.Join(root, "pages/home.templ"), Kind: types.ComponentKindTempl},
+		{Name: "Pong", Package: "pages", FilePath: filepath.Join(root, "pages/home.templ"), Kind: types.ComponentKindTempl},
+	}
+	for _, component := range components {
+		reg.Register(component)
+	}
+	require.NoError(t, reg.UpdateAllDependencies())
+
+	home, exists := reg.Get("Home")
+	require.True(t, exists)
+	assert.Equal(t, "example.com/app/pages.Home", home.ID)
+	assert.Equal(t, []string{
+		"example.com/app/layout.Shell",
+		"example.com/app/pages.Footer",
+		"example.com/app/ui.Button",
+	}, home.Dependencies)
+	assert.False(t, home.AcceptsChildren)
+
+	shell, exists := reg.Get("Shell")
+	require.True(t, exists)
+	assert.Empty(t, shell.Dependencies)
+	assert.True(t, shell.AcceptsChildren)
+
+	dependents := reg.GetDependents("Button")
+	require.Len(t, dependents, 1)
+	assert.Equal(t, "Home", dependents[0].Name)
+
+	graph := reg.GetDependencyGraph()
+	assert.Equal(t, []string{"example.com/app/pages.Pong"}, graph["example.com/app/pages.Ping"])
+
+	cycles := reg.DetectCircularDependencies()
+	assert.Equal(t, [][]string{{
+		"example.com/app/pages.Ping",
+		"example.com/app/pages.Pong",
+		"example.com/app/pages.Ping",
+	}}, cycles)
+}
+
+func TestDependencyAnalyzer_ChangePropagation(t *testing.T) {
+	root := writeDependencyFixture(t)
+	reg := NewComponentRegistry()
+
+	components := []*types.ComponentInfo{
+		{Name: "Button", Package: "ui", FilePath: filepath.Join(root, "ui/button.templ"), Kind: types.ComponentKindTempl},
+		{Name: "Shell", Package: "layout", FilePath: filepath.Join(root, "layout/shell.templ"), Kind: types.ComponentKindTempl},
+		{Name: "Home", Package: "pages", FilePath: filepath.Join(root, "pages/home.templ"), Kind: types.ComponentKindTempl,
+			Imports: []string{"example.com/app/ui", "example.com/app/layout"}},
+		{Name: "Footer", Package: "pages", FilePath: filepath.Join(root, "pages/home.templ"), Kind: types.ComponentKindTempl},
+		{Name: "Ping", Package: "pages", FilePath: filepath.Join(root, "pages/home.templ"), Kind: types.ComponentKindTempl},
+		{Name: "Pong", Package: "pages", FilePath: filepath.Join(root, "pages/home.templ"), Kind: types.ComponentKindTempl},
+		{Name: "App", Package: "app", FilePath: filepath.Join(root, "app/app.templ"), Kind: types.ComponentKindTempl,
+			Imports: []string{"example.com/app/pages"}},
+	}
+	for _, component := range components {
+		reg.Register(component)
+	}
+	require.NoError(t, reg.UpdateAllDependencies())
+
+	ids := func(components []*types.ComponentInfo) []string {
+		result := make([]string, 0, len(components))
+		for _, component := range components {
+			result = append(result, component.ID)
+		}
+		return result
+	}
+
+	// Dependents are found through every level, and cycles end
+	assert.Equal(t, []string{"example.com/app/app.App", "example.com/app/pages.Home"}, ids(reg.GetTransitiveDependents("Button")))
+	assert.Equal(t, []string{"example.com/app/pages.Ping"}, ids(reg.GetTransitiveDependents("example.com/app/pages.Pong")))
+	assert.Empty(t, reg.GetTransitiveDependents("App"))
+
+	// Templ files map to the components declared in them
+	assert.Equal(t, []string{"example.com/app/layout.Shell"}, ids(reg.ComponentsForFile(filepath.Join(root, "layout/shell.templ"))))
+
+	// Go files map to the components of their package and those importing it
+	assert.Equal(t, []string{"example.com/app/pages.Home", "example.com/app/ui.Button"}, ids(reg.ComponentsForFile(filepath.Join(root, "ui/variants.go"))))
+	assert.Equal(t, []string{"example.com/app/app.App"}, ids(reg.ComponentsForFile(filepath.Join(root, "app/helpers.go"))))
+	assert.Empty(t, reg.ComponentsForFile(filepath.Join(root, "internal/db/db.go")))
+}
+
+func TestDependencyAnalyzer_AnalyzeComponentFromContent(t *testing.T) {
+	reg := NewComponentRegistry()
+	reg.Register(&types.ComponentInfo{ID: "example.com/app/components.Card", Name: "Card", Package: "components", FilePath: "missing/card.templ"})
+
+	analyzer := reg.GetDependencyAnalyzer()
+	content := `package components
+
+templ Page() {
+	<div>Card(text only)</div>
+	@Card()
+	@Unknown()
+}
+`
+
+	assert.Equal(t, []string{"example.com/app/components.Card"}, analyzer.AnalyzeComponentFromContent(content, "Page"))
+}
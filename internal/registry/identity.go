@@ -0,0 +1,262 @@
+package registry
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// ErrComponentNotFound is returned by Resolve when no component matches a name or ID
+var ErrComponentNotFound = errors.New("component not found")
+
+// AmbiguousComponentError is returned by Resolve when a bare or partially
+// qualified name matches components in more than one package.
+type AmbiguousComponentError struct {
+	// Name is the name that was looked up
+	Name string
+	// Candidates lists the fully qualified IDs of every matching component
+	Candidates []string
+}
+
+// Error implements the error interface
+func (e *AmbiguousComponentError) Error() string {
+	return fmt.Sprintf("component name %q is ambiguous, use a qualified ID: %s",
+		e.Name, strings.Join(e.Candidates, ", "))
+}
+
+// moduleInfo describes the Go module containing a directory
+type moduleInfo struct {
+	// root is the absolute directory containing go.mod
+	root string
+	// path is the module path declared in go.mod
+	path string
+}
+
+// moduleResolver maps directories to the Go module that contains them,
+// caching go.mod lookups per directory.
+type moduleResolver struct {
+	// mu protects modules
+	mu sync.Mutex
+	// modules caches the module for each directory looked up
+	modules map[string]moduleInfo
+}
+
+// newModuleResolver creates an empty module resolver
+func newModuleResolver() *moduleResolver {
+	return &moduleResolver{
+		modules: make(map[string]moduleInfo),
+	}
+}
+
+// findModule walks up from dir to the nearest go.mod and reads its module path
+func (m *moduleResolver) findModule(dir string) (moduleInfo, bool) {
+	m.mu.Lock()
+	module, ok := m.modules[dir]
+	m.mu.Unlock()
+	if ok {
+		return module, module.root != ""
+	}
+
+	for current := dir; ; current = filepath.Dir(current) {
+		if modulePath := readModulePath(filepath.Join(current, "go.mod")); modulePath != "" {
+			module = moduleInfo{root: current, path: modulePath}
+			break
+		}
+		if parent := filepath.Dir(current); parent == current {
+			break
+		}
+	}
+
+	m.mu.Lock()
+	m.modules[dir] = module
+	m.mu.Unlock()
+
+	return module, module.root != ""
+}
+
+// importPath returns the Go import path of the package in dir, or an empty
+// string when dir is not inside a module.
+func (m *moduleResolver) importPath(dir string) string {
+	if dir == "" {
+		return ""
+	}
+
+	module, ok := m.findModule(dir)
+	if !ok {
+		return ""
+	}
+
+	rel, err := filepath.Rel(module.root, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	if rel == "." {
+		return module.path
+	}
+
+	return module.path + "/" + filepath.ToSlash(rel)
+}
+
+// resolveImportDir maps an import path to a directory inside the module that
+// contains fromDir. An empty string is returned for imports outside the module.
+func (m *moduleResolver) resolveImportDir(importPath, fromDir string) string {
+	if fromDir == "" {
+		return ""
+	}
+
+	module, ok := m.findModule(fromDir)
+	if !ok {
+		return ""
+	}
+
+	if importPath == module.path {
+		return module.root
+	}
+	if rel, ok := strings.CutPrefix(importPath, module.path+"/"); ok {
+		return filepath.Join(module.root, filepath.FromSlash(rel))
+	}
+
+	return ""
+}
+
+// readModulePath returns the module path declared in a go.mod file
+func readModulePath(goModPath string) string {
+	file, err := os.Open(goModPath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if modulePath, ok := strings.CutPrefix(line, "module"); ok {
+			modulePath = strings.Trim(strings.TrimSpace(modulePath), `"`)
+			if modulePath != "" {
+				return modulePath
+			}
+		}
+	}
+
+	return ""
+}
+
+// localName returns the package-local name of a component: the template name,
+// prefixed with the receiver type for method templates (e.g. "Card.Render").
+func localName(component *types.ComponentInfo) string {
+	if component.Receiver == nil {
+		return component.Name
+	}
+
+	recvType := strings.TrimLeft(component.Receiver.Type, "*")
+	if i := strings.IndexByte(recvType, '['); i >= 0 {
+		recvType = recvType[:i]
+	}
+	if recvType == "" {
+		return component.Name
+	}
+
+	return recvType + "." + component.Name
+}
+
+// componentID builds the fully qualified ID of a component from its import
+// path and local name, falling back to the package name when the import path
+// is unknown.
+func componentID(component *types.ComponentInfo) string {
+	switch {
+	case component.ImportPath != "":
+		return component.ImportPath + "." + localName(component)
+	case component.Package != "":
+		return component.Package + "." + localName(component)
+	default:
+		return localName(component)
+	}
+}
+
+// sanitizeComponentID removes characters that cannot appear in a component ID
+// (an import path followed by a dotted name) and strips traversal sequences.
+func sanitizeComponentID(id string) string {
+	var cleaned []rune
+	for _, r := range id {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+			strings.ContainsRune("_.-~/", r) {
+			cleaned = append(cleaned, r)
+		}
+	}
+
+	cleanedID := string(cleaned)
+	for strings.Contains(cleanedID, "..") {
+		cleanedID = strings.ReplaceAll(cleanedID, "..", ".")
+	}
+	for strings.Contains(cleanedID, "//") {
+		cleanedID = strings.ReplaceAll(cleanedID, "//", "/")
+	}
+
+	return strings.Trim(cleanedID, "/.")
+}
+
+// matchesName reports whether name refers to the component. A name matches
+// the full ID, the bare local name ("Button"), the package-qualified name
+// ("ui.Button") or any import path suffix ("components/ui.Button").
+func matchesName(component *types.ComponentInfo, name string) bool {
+	id := component.ID
+	if id == "" {
+		id = componentID(component)
+	}
+	if id == name {
+		return true
+	}
+
+	local := localName(component)
+	if name == local {
+		return true
+	}
+
+	packageName := component.Package
+	if component.ImportPath != "" {
+		packageName = path.Base(component.ImportPath)
+	}
+	if packageName != "" && name == packageName+"."+local {
+		return true
+	}
+
+	return strings.HasSuffix(id, "/"+name) && strings.HasSuffix(name, "."+local)
+}
+
+// resolveLocked finds the component referred to by name. The caller must hold
+// at least a read lock on the registry.
+func (r *ComponentRegistry) resolveLocked(name string) (*types.ComponentInfo, error) {
+	if component, exists := r.components[name]; exists {
+		return component, nil
+	}
+
+	var matches []*types.ComponentInfo
+	for _, component := range r.components {
+		if matchesName(component, name) {
+			matches = append(matches, component)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrComponentNotFound, name)
+	case 1:
+		return matches[0], nil
+	}
+
+	candidates := make([]string, len(matches))
+	for i, match := range matches {
+		candidates[i] = match.ID
+	}
+	sort.Strings(candidates)
+
+	return nil, &AmbiguousComponentError{Name: name, Candidates: candidates}
+}
@@ -0,0 +1,843 @@
+package renderer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	gotypes "go/types"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/conneroisu/templar/internal/cache"
+	"github.com/conneroisu/templar/internal/interfaces"
+	"github.com/conneroisu/templar/internal/tracing"
+	"github.com/conneroisu/templar/internal/types"
+)
+
+const (
+	// hostBinaryName is the file name of the compiled render host
+	hostBinaryName = "templar-render-host"
+	// hostModulePath is the module path of the generated render host
+	hostModulePath = "templar.local/renderhost"
+	// defaultHostRenderTimeout bounds a single render request
+	defaultHostRenderTimeout = 30 * time.Second
+	// defaultHostBuildTimeout bounds templ generate and go build of the host
+	defaultHostBuildTimeout = 5 * time.Minute
+	// hostStderrLimit is the amount of host stderr kept for error reports
+	hostStderrLimit = 16 * 1024
+)
+
+// errHostUnavailable reports that no render host binary could be built, so
+// components must be rendered without it
+var errHostUnavailable = errors.New("render host unavailable")
+
+// RenderHost renders components through a long-lived process compiled from
+// the project's own component packages.
+//
+// The host is generated as a small Go module joined to the project modules
+// through a go.work file, so it imports components exactly as application
+// code would. It is built once, kept running, and fed render requests as
+// newline-delimited JSON over stdin/stdout. The binary is only rebuilt after
+// Invalidate is called (on Go or templ changes) or when the set of renderable
+// components changes, which keeps repeat renders in the millisecond range.
+//
+// Packages that fail to generate or compile are left out of the binary so
+// that the other components keep rendering, and when a rebuild fails
+// altogether the last good binary keeps serving.
+type RenderHost struct {
+	registry interfaces.ComponentRegistry
+	// dir is the absolute directory holding the generated host module
+	dir string
+	// mu serializes builds and render requests
+	mu sync.Mutex
+	// process is the running host, nil until the first render
+	process *hostProcess
+	// stale is set when source changes require a rebuild
+	stale bool
+	// signature identifies the component set the current binary was built for
+	signature string
+	// served holds the IDs of components compiled into the current binary
+	served map[string]bool
+	// failed holds the build errors of packages left out of the current
+	// binary, by import path
+	failed map[string]error
+	// buildErr is the error of the last rebuild when it failed altogether
+	buildErr error
+	// paramTypes caches the signatures of component functions by ID
+	// for prop validation; it is reset on every rebuild
+	paramTypes map[string]*gotypes.Signature
+	// nextID numbers render requests
+	nextID uint64
+	// renderTimeout bounds a single render request
+	renderTimeout time.Duration
+	// buildTimeout bounds a rebuild of the host
+	buildTimeout time.Duration
+	// cache keeps generated code and rendered HTML across runs; nil when
+	// disabled
+	cache *cache.DiskCache
+	// binaryHash identifies the current binary in render cache keys
+	binaryHash string
+}
+
+// hostProcess is a running render host
+type hostProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	stderr *tailBuffer
+	// exited is closed once the process has been reaped
+	exited chan struct{}
+}
+
+// hostRequest is a render request sent to the host
+type hostRequest struct {
+	ID        uint64                 `json:"id"`
+	Component string                 `json:"component"`
+	Props     map[string]interface{} `json:"props"`
+	// Children is HTML passed to the component as its { children... }
+	Children string `json:"children,omitempty"`
+	// Nonce is the CSP nonce exposed to the component via templ.GetNonce
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// hostResponse is the host's answer to a render request
+type hostResponse struct {
+	ID    uint64 `json:"id"`
+	HTML  string `json:"html"`
+	Error string `json:"error,omitempty"`
+}
+
+// NewRenderHost creates a render host that keeps its generated module in dir.
+// Nothing is built or started until the first render.
+func NewRenderHost(registry interfaces.ComponentRegistry, dir string) *RenderHost {
+	if absDir, err := filepath.Abs(dir); err == nil {
+		dir = absDir
+	}
+
+	return &RenderHost{
+		registry:      registry,
+		dir:           dir,
+		stale:         true,
+		served:        make(map[string]bool),
+		paramTypes:    make(map[string]*gotypes.Signature),
+		renderTimeout: defaultHostRenderTimeout,
+		buildTimeout:  defaultHostBuildTimeout,
+	}
+}
+
+// Supports reports whether the component can be rendered by the host: it must
+// be an exported, non-generic templ function in an importable package.
+func (h *RenderHost) Supports(component *types.ComponentInfo) bool {
+	if component == nil || component.ImportPath == "" || component.ID == "" {
+		return false
+	}
+	if component.Package == "main" || !component.IsExported {
+		return false
+	}
+	if component.Kind != "" && component.Kind != types.ComponentKindTempl {
+		return false
+	}
+	return component.Receiver == nil && len(component.TypeParameters) == 0
+}
+
+// BuildError returns the error of the last rebuild: the error that stopped
+// it, or the errors of the packages it left out. It is nil when every
+// package built.
+func (h *RenderHost) BuildError() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.buildErr != nil {
+		return h.buildErr
+	}
+	return joinPackageErrors(h.failed)
+}
+
+// joinPackageErrors joins the build errors of packages in import path order
+func joinPackageErrors(failed map[string]error) error {
+	importPaths := make([]string, 0, len(failed))
+	for importPath := range failed {
+		importPaths = append(importPaths, importPath)
+	}
+	sort.Strings(importPaths)
+	errs := make([]error, len(importPaths))
+	for i, importPath := range importPaths {
+		errs[i] = failed[importPath]
+	}
+	return errors.Join(errs...)
+}
+
+// Invalidate marks the host binary as out of date. The next render rebuilds it.
+func (h *RenderHost) Invalidate() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.stale = true
+}
+
+// SetDiskCache makes the host restore generated code for unchanged templ
+// files from a disk cache and keep the HTML it renders there. Renders are
+// cached per binary, component, props and children; renders with a CSP nonce
+// are not cached.
+func (h *RenderHost) SetDiskCache(diskCache *cache.DiskCache) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.cache = diskCache
+}
+
+// Owns reports whether path belongs to the host's generated module, so that
+// file watchers can ignore the host's own writes.
+func (h *RenderHost) Owns(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	return absPath == h.dir || strings.HasPrefix(absPath, h.dir+string(filepath.Separator))
+}
+
+// Render renders a component with the given props, building and starting the
+// host first if needed. Props are checked against the component's parameter
+// types and decoded into them by the host; a prop that does not match its
+// type yields a *PropError. Missing props leave parameters at their zero value.
+func (h *RenderHost) Render(ctx context.Context, component *types.ComponentInfo, props map[string]interface{}) (string, error) {
+	return h.render(ctx, hostRequest{Component: component.ID, Props: props}, component)
+}
+
+// RenderWithChildren renders a component, typically a layout, with children
+// as the HTML of its { children... } and nonce as the CSP nonce returned by
+// templ.GetNonce
+func (h *RenderHost) RenderWithChildren(ctx context.Context, component *types.ComponentInfo, props map[string]interface{}, children, nonce string) (string, error) {
+	return h.render(ctx, hostRequest{Component: component.ID, Props: props, Children: children, Nonce: nonce}, component)
+}
+
+// render checks the props of request against the component and sends it to
+// the host
+func (h *RenderHost) render(ctx context.Context, request hostRequest, component *types.ComponentInfo) (string, error) {
+	if !h.Supports(component) {
+		return "", fmt.Errorf("component %s cannot be rendered by the render host", component.ID)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.ensureBuilt(ctx); err != nil {
+		return "", err
+	}
+	if !h.served[component.ID] {
+		if err := h.failed[component.ImportPath]; err != nil {
+			return "", err
+		}
+		if h.buildErr != nil {
+			return "", fmt.Errorf("component %s is not in the last render host build: %w", component.ID, h.buildErr)
+		}
+		return "", fmt.Errorf("component %s has parameter types the render host cannot express", component.ID)
+	}
+
+	sig, err := h.loadParamTypes(ctx, component)
+	if err != nil {
+		return "", err
+	}
+	request.Props, err = checkProps(sig, request.Props)
+	if err != nil {
+		return "", err
+	}
+
+	if request.Props == nil {
+		request.Props = map[string]interface{}{}
+	}
+
+	cacheKey := h.renderCacheKey(request)
+	if cacheKey != "" {
+		if html, ok := h.cache.Get(cache.NamespaceRender, cacheKey); ok {
+			return string(html), nil
+		}
+	}
+
+	if err := h.ensureRunning(); err != nil {
+		return "", err
+	}
+
+	h.nextID++
+	request.ID = h.nextID
+
+	response, err := h.roundTrip(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	if response.Error != "" {
+		return "", fmt.Errorf("rendering %s: %s", component.ID, response.Error)
+	}
+
+	if cacheKey != "" {
+		// A failed write only costs a render next time
+		_ = h.cache.Set(cache.NamespaceRender, cacheKey, []byte(response.HTML))
+	}
+	return response.HTML, nil
+}
+
+// renderCacheKey returns the disk cache key of a render request, or "" when
+// it is not cached. The key covers the binary, so any change to the
+// component sources yields new keys.
+func (h *RenderHost) renderCacheKey(request hostRequest) string {
+	if h.cache == nil || h.binaryHash == "" || request.Nonce != "" {
+		return ""
+	}
+	props, err := json.Marshal(request.Props)
+	if err != nil {
+		return ""
+	}
+	return cache.Key(h.binaryHash, request.Component, string(props), request.Children)
+}
+
+// Close stops the host process
+func (h *RenderHost) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.stopLocked()
+	return nil
+}
+
+// hostComponents returns the registered components the host can serve, ordered by ID
+func (h *RenderHost) hostComponents() []*types.ComponentInfo {
+	var components []*types.ComponentInfo
+	for _, component := range h.registry.GetAll() {
+		if h.Supports(component) {
+			components = append(components, component)
+		}
+	}
+	sortComponentsByID(components)
+	return components
+}
+
+// componentSignature identifies a component set by IDs and parameter types
+func componentSignature(components []*types.ComponentInfo) string {
+	var sb strings.Builder
+	for _, component := range components {
+		sb.WriteString(component.ID)
+		for _, param := range component.Parameters {
+			sb.WriteString(" " + param.Name + ":" + param.Type)
+		}
+		sb.WriteString(" [" + strings.Join(component.Imports, ",") + "]\n")
+	}
+	return sb.String()
+}
+
+// ensureBuilt rebuilds the host binary when it is stale or the component
+// set changed. When the rebuild fails, the last good binary keeps serving;
+// without one, an error wrapping errHostUnavailable is returned.
+func (h *RenderHost) ensureBuilt(ctx context.Context) error {
+	components := h.hostComponents()
+	signature := componentSignature(components)
+
+	if !h.stale && signature == h.signature {
+		if h.buildErr != nil {
+			// A failed build is retried once sources change, not on every render
+			return h.lastBuild()
+		}
+		if h.hasBinary() {
+			return nil
+		}
+	}
+
+	if len(components) == 0 {
+		return fmt.Errorf("no components available for the render host")
+	}
+
+	_, span := tracing.Start(ctx, "renderer.host_build")
+	span.SetAttribute("components", len(components))
+	defer span.End()
+
+	buildCtx, cancel := context.WithTimeout(ctx, h.buildTimeout)
+	defer cancel()
+
+	served, failed, err := h.build(buildCtx, components)
+	h.signature = signature
+	h.stale = false
+	if err != nil {
+		span.RecordError(err)
+		h.buildErr = err
+		if len(h.served) > 0 {
+			log.Printf("Render host rebuild failed, serving the last build: %v", err)
+		}
+		return h.lastBuild()
+	}
+	for importPath, err := range failed {
+		log.Printf("Render host left out package %s: %v", importPath, err)
+	}
+
+	// The old process serves the previous binary
+	h.stopLocked()
+
+	h.binaryHash = ""
+	if h.cache != nil {
+		h.binaryHash, err = fileHash(filepath.Join(h.dir, hostBinaryName))
+		if err != nil {
+			return err
+		}
+	}
+
+	h.served = served
+	h.failed = failed
+	h.buildErr = nil
+	h.paramTypes = make(map[string]*gotypes.Signature)
+	return nil
+}
+
+// lastBuild keeps the last good binary serving after a failed rebuild, or
+// reports the host unavailable when there is none
+func (h *RenderHost) lastBuild() error {
+	if len(h.served) > 0 && h.hasBinary() {
+		return nil
+	}
+	return fmt.Errorf("%w: %v", errHostUnavailable, h.buildErr)
+}
+
+// hasBinary reports whether a host binary has been built
+func (h *RenderHost) hasBinary() bool {
+	_, err := os.Stat(filepath.Join(h.dir, hostBinaryName))
+	return err == nil
+}
+
+// build generates the host module, runs templ generate for the component
+// packages and compiles the host binary. Packages that fail to generate or
+// compile are left out and returned with their errors by import path.
+func (h *RenderHost) build(ctx context.Context, components []*types.ComponentInfo) (map[string]bool, map[string]error, error) {
+	if _, err := exec.LookPath("go"); err != nil {
+		return nil, nil, fmt.Errorf("go command not found: %w", err)
+	}
+
+	modules, goVersion, err := componentModules(components)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	failed, err := h.generateTemplCode(ctx, components)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := os.MkdirAll(h.dir, 0750); err != nil {
+		return nil, nil, fmt.Errorf("creating render host directory: %w", err)
+	}
+
+	goMod := fmt.Sprintf("module %s\n\ngo %s\n", hostModulePath, goVersion)
+
+	var goWork strings.Builder
+	fmt.Fprintf(&goWork, "go %s\n\nuse (\n\t.\n", goVersion)
+	for _, root := range modules {
+		fmt.Fprintf(&goWork, "\t%s\n", strconv.Quote(root))
+	}
+	goWork.WriteString(")\n")
+
+	for name, content := range map[string]string{"go.mod": goMod, "go.work": goWork.String()} {
+		if err := writeFileIfChanged(filepath.Join(h.dir, name), []byte(content)); err != nil {
+			return nil, nil, fmt.Errorf("writing render host %s: %w", name, err)
+		}
+	}
+
+	for {
+		components = withoutPackages(components, failed)
+		if len(components) == 0 {
+			return nil, failed, fmt.Errorf("no component package could be built: %w", joinPackageErrors(failed))
+		}
+
+		source, servedIDs, _, err := generateHostProgram(components)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := writeFileIfChanged(filepath.Join(h.dir, "main.go"), []byte(source)); err != nil {
+			return nil, nil, fmt.Errorf("writing render host main.go: %w", err)
+		}
+
+		output, err := h.goBuild(ctx, "-o", hostBinaryName, ".")
+		if err == nil {
+			served := make(map[string]bool, len(servedIDs))
+			for _, id := range servedIDs {
+				served[id] = true
+			}
+			return served, failed, nil
+		}
+
+		// Find the packages that broke the build and try again without them
+		broken := h.brokenPackages(ctx, components)
+		if len(broken) == 0 {
+			return nil, failed, fmt.Errorf("building render host: %w\nOutput: %s", err, output)
+		}
+		for importPath, err := range broken {
+			failed[importPath] = err
+		}
+	}
+}
+
+// goBuild runs go build in the host module
+func (h *RenderHost) goBuild(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "go", append([]string{"build"}, args...)...)
+	cmd.Dir = h.dir
+	cmd.Env = hostBuildEnv(filepath.Join(h.dir, "go.work"))
+	return cmd.CombinedOutput()
+}
+
+// brokenPackages compiles the component packages one by one, returning the
+// build errors of those that do not compile by import path
+func (h *RenderHost) brokenPackages(ctx context.Context, components []*types.ComponentInfo) map[string]error {
+	broken := make(map[string]error)
+	seen := make(map[string]bool)
+	for _, component := range components {
+		if seen[component.ImportPath] {
+			continue
+		}
+		seen[component.ImportPath] = true
+
+		if output, err := h.goBuild(ctx, component.ImportPath); err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			broken[component.ImportPath] = fmt.Errorf("package %s does not compile: %w\nOutput: %s", component.ImportPath, err, output)
+		}
+	}
+	return broken
+}
+
+// withoutPackages returns the components outside the given packages
+func withoutPackages(components []*types.ComponentInfo, importPaths map[string]error) []*types.ComponentInfo {
+	if len(importPaths) == 0 {
+		return components
+	}
+	kept := make([]*types.ComponentInfo, 0, len(components))
+	for _, component := range components {
+		if _, ok := importPaths[component.ImportPath]; !ok {
+			kept = append(kept, component)
+		}
+	}
+	return kept
+}
+
+// generateTemplCode runs templ generate once per component package
+// directory, returning the errors of the packages it failed for by import
+// path
+func (h *RenderHost) generateTemplCode(ctx context.Context, components []*types.ComponentInfo) (map[string]error, error) {
+	if _, err := exec.LookPath("templ"); err != nil {
+		return nil, fmt.Errorf("templ command not found: %w", err)
+	}
+
+	failed := make(map[string]error)
+	seen := make(map[string]bool)
+	for _, component := range components {
+		dir := filepath.Dir(component.FilePath)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		templFiles, _ := filepath.Glob(filepath.Join(dir, "*.templ"))
+		if h.restoreGenerated(templFiles) {
+			continue
+		}
+
+		cmd := exec.CommandContext(ctx, "templ", "generate", "-path", dir)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			failed[component.ImportPath] = fmt.Errorf("templ generate failed in directory %s: %w\nOutput: %s", dir, err, output)
+			continue
+		}
+
+		if h.cache != nil {
+			for _, templFile := range templFiles {
+				_ = h.cache.StoreGenerated(templFile)
+			}
+		}
+	}
+
+	return failed, nil
+}
+
+// restoreGenerated restores the generated code of templ files from the disk
+// cache, reporting whether all of them were cached
+func (h *RenderHost) restoreGenerated(templFiles []string) bool {
+	if h.cache == nil || len(templFiles) == 0 {
+		return false
+	}
+	for _, templFile := range templFiles {
+		if !h.cache.RestoreGenerated(templFile) {
+			return false
+		}
+	}
+	return true
+}
+
+// ensureRunning starts the host process if it is not running
+func (h *RenderHost) ensureRunning() error {
+	if h.process != nil {
+		select {
+		case <-h.process.exited:
+			h.process = nil
+		default:
+			return nil
+		}
+	}
+
+	cmd := exec.Command(filepath.Join(h.dir, hostBinaryName))
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("creating render host stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("creating render host stdout: %w", err)
+	}
+	stderr := &tailBuffer{limit: hostStderrLimit}
+	cmd.Stderr = stderr
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting render host: %w", err)
+	}
+
+	process := &hostProcess{
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReader(stdout),
+		stderr: stderr,
+		exited: make(chan struct{}),
+	}
+	go func() {
+		_ = cmd.Wait()
+		close(process.exited)
+	}()
+
+	h.process = process
+	return nil
+}
+
+// roundTrip sends a request and waits for its response, killing the host if
+// it does not answer in time
+func (h *RenderHost) roundTrip(ctx context.Context, request hostRequest) (*hostResponse, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("encoding render request: %w", err)
+	}
+
+	process := h.process
+	if _, err := process.stdin.Write(append(payload, '\n')); err != nil {
+		h.stopLocked()
+		return nil, fmt.Errorf("sending render request: %w", err)
+	}
+
+	type result struct {
+		line []byte
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		line, err := process.stdout.ReadBytes('\n')
+		results <- result{line: line, err: err}
+	}()
+
+	timer := time.NewTimer(h.renderTimeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-results:
+		if res.err != nil {
+			h.stopLocked()
+			return nil, fmt.Errorf("render host exited: %w\n%s", res.err, process.stderr.String())
+		}
+
+		var response hostResponse
+		if err := json.Unmarshal(res.line, &response); err != nil {
+			h.stopLocked()
+			return nil, fmt.Errorf("decoding render response: %w", err)
+		}
+		if response.ID != request.ID {
+			h.stopLocked()
+			return nil, fmt.Errorf("render host answered request %d, expected %d", response.ID, request.ID)
+		}
+		return &response, nil
+
+	case <-timer.C:
+		h.stopLocked()
+		return nil, fmt.Errorf("render of %s timed out after %v", request.Component, h.renderTimeout)
+
+	case <-ctx.Done():
+		h.stopLocked()
+		return nil, ctx.Err()
+	}
+}
+
+// stopLocked terminates the host process. The caller must hold h.mu.
+func (h *RenderHost) stopLocked() {
+	if h.process == nil {
+		return
+	}
+
+	process := h.process
+	h.process = nil
+
+	_ = process.stdin.Close()
+	select {
+	case <-process.exited:
+	case <-time.After(time.Second):
+		if process.cmd.Process != nil {
+			_ = process.cmd.Process.Kill()
+		}
+		<-process.exited
+	}
+}
+
+// componentModules returns the module roots containing the components and the
+// highest go version they declare, used for the host's go.work file
+func componentModules(components []*types.ComponentInfo) ([]string, string, error) {
+	seen := make(map[string]bool)
+	var roots []string
+	goVersion := "1.21"
+
+	for _, component := range components {
+		absPath, err := filepath.Abs(component.FilePath)
+		if err != nil {
+			return nil, "", fmt.Errorf("resolving %s: %w", component.FilePath, err)
+		}
+
+		root, version := findModuleRoot(filepath.Dir(absPath))
+		if root == "" {
+			return nil, "", fmt.Errorf("no go.mod found for component %s", component.ID)
+		}
+		if seen[root] {
+			continue
+		}
+		seen[root] = true
+		roots = append(roots, root)
+
+		if compareGoVersions(version, goVersion) > 0 {
+			goVersion = version
+		}
+	}
+
+	return roots, goVersion, nil
+}
+
+// findModuleRoot walks up from dir to the nearest go.mod, returning its
+// directory and declared go version
+func findModuleRoot(dir string) (string, string) {
+	for current := dir; ; current = filepath.Dir(current) {
+		content, err := os.ReadFile(filepath.Join(current, "go.mod"))
+		if err == nil {
+			version := ""
+			for _, line := range strings.Split(string(content), "\n") {
+				if v, ok := strings.CutPrefix(strings.TrimSpace(line), "go "); ok {
+					version = strings.TrimSpace(v)
+					break
+				}
+			}
+			return current, version
+		}
+		if parent := filepath.Dir(current); parent == current {
+			return "", ""
+		}
+	}
+}
+
+// compareGoVersions compares dotted go versions such as "1.24" and "1.24.4"
+func compareGoVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// hostBuildEnv returns the environment for building the host in workspace
+// mode. -mod flags are incompatible with workspaces and are dropped.
+func hostBuildEnv(goWork string) []string {
+	env := make([]string, 0, len(os.Environ())+2)
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, "GOWORK=") || strings.HasPrefix(kv, "GOFLAGS=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+
+	var flags []string
+	for _, flag := range strings.Fields(os.Getenv("GOFLAGS")) {
+		if !strings.HasPrefix(flag, "-mod=") {
+			flags = append(flags, flag)
+		}
+	}
+
+	return append(env, "GOWORK="+goWork, "GOFLAGS="+strings.Join(flags, " "))
+}
+
+// writeFileIfChanged writes content to path unless it already holds it, so
+// that rebuilds do not produce spurious file watcher events
+func writeFileIfChanged(path string, content []byte) error {
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, content) {
+		return nil
+	}
+	return os.WriteFile(path, content, 0600)
+}
+
+// fileHash returns the SHA-256 of a file's content
+func fileHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// tailBuffer keeps the last limit bytes written to it
+type tailBuffer struct {
+	mu    sync.Mutex
+	buf   []byte
+	limit int
+}
+
+// Write implements io.Writer
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.limit {
+		t.buf = t.buf[len(t.buf)-t.limit:]
+	}
+	return len(p), nil
+}
+
+// String returns the buffered output
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return string(t.buf)
+}
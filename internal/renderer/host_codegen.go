@@ -0,0 +1,492 @@
+package renderer
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	gotypes "go/types"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// hostImports assigns collision-free aliases to the packages imported by the
+// generated render host
+type hostImports struct {
+	// aliases maps import paths to their alias in the generated file
+	aliases map[string]string
+	// paths lists imported paths in the order they were first used
+	paths []string
+}
+
+// newHostImports creates an empty import set
+func newHostImports() *hostImports {
+	return &hostImports{aliases: make(map[string]string)}
+}
+
+// alias returns the alias for importPath, adding the import on first use
+func (hi *hostImports) alias(importPath string) string {
+	if alias, ok := hi.aliases[importPath]; ok {
+		return alias
+	}
+
+	alias := fmt.Sprintf("p%d", len(hi.paths))
+	hi.aliases[importPath] = alias
+	hi.paths = append(hi.paths, importPath)
+	return alias
+}
+
+// hostImport is an aliased import in the generated host program
+type hostImport struct {
+	Alias string
+	Path  string
+}
+
+// hostParam is a decoded component parameter in the generated host program
+type hostParam struct {
+	// Name is the JSON prop name, empty for blank parameters
+	Name string
+	// Var is the local variable holding the decoded value
+	Var string
+	// Type is the package-qualified Go type of the variable
+	Type string
+}
+
+// hostComponent is a component entry in the generated dispatch table
+type hostComponent struct {
+	ID     string
+	Call   string
+	Params []hostParam
+	Args   string
+}
+
+// hostProgram is the data used to generate the render host's main.go
+type hostProgram struct {
+	Imports    []hostImport
+	Components []hostComponent
+}
+
+// versionSuffix matches major version suffixes such as "v2" in import paths
+var versionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// importName returns the package name conventionally used for an import
+// path, e.g. "gopkg.in/yaml.v3" is imported as yaml and
+// "github.com/jackc/pgx/v5" as pgx.
+func importName(importPath string) string {
+	name := path.Base(importPath)
+	if versionSuffix.MatchString(name) {
+		name = path.Base(path.Dir(importPath))
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+	return strings.ReplaceAll(name, "-", "")
+}
+
+// generateHostProgram generates the source of a render host serving the
+// given components. Components whose parameter types cannot be expressed
+// outside their package are returned as skipped so that callers can fall
+// back to standalone rendering for them.
+func generateHostProgram(components []*types.ComponentInfo) (string, []string, []string, error) {
+	imports := newHostImports()
+	program := hostProgram{}
+	var served, skipped []string
+
+	for _, component := range components {
+		entry, err := hostEntry(component, imports)
+		if err != nil {
+			skipped = append(skipped, component.ID)
+			continue
+		}
+		program.Components = append(program.Components, *entry)
+		served = append(served, component.ID)
+	}
+
+	for _, importPath := range imports.paths {
+		program.Imports = append(program.Imports, hostImport{
+			Alias: imports.aliases[importPath],
+			Path:  importPath,
+		})
+	}
+
+	tmpl, err := template.New("host").Parse(hostProgramTemplate)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, program); err != nil {
+		return "", nil, nil, fmt.Errorf("generating render host: %w", err)
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("formatting render host: %w", err)
+	}
+
+	return string(source), served, skipped, nil
+}
+
+// hostEntry builds the dispatch table entry for a single component
+func hostEntry(component *types.ComponentInfo, imports *hostImports) (*hostComponent, error) {
+	fileImports := make(map[string]string, len(component.Imports))
+	for _, importPath := range component.Imports {
+		fileImports[importName(importPath)] = importPath
+	}
+
+	// Parameter types are declared as slices for variadic parameters
+	typeExprs := make([]string, len(component.Parameters))
+	variadic := make([]bool, len(component.Parameters))
+	for i, param := range component.Parameters {
+		typeExpr := strings.TrimSpace(param.Type)
+		if rest, ok := strings.CutPrefix(typeExpr, "..."); ok {
+			if i != len(component.Parameters)-1 {
+				return nil, fmt.Errorf("variadic parameter %s must be last", param.Name)
+			}
+			variadic[i] = true
+			typeExpr = "[]" + rest
+		}
+		typeExprs[i] = typeExpr
+
+		// Validate against a scratch import set first so that a skipped
+		// component does not leave unused imports behind
+		if _, err := qualifyType(typeExpr, component.ImportPath, fileImports, newHostImports()); err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", param.Name, err)
+		}
+	}
+
+	entry := &hostComponent{
+		ID:   component.ID,
+		Call: imports.alias(component.ImportPath) + "." + component.Name,
+	}
+
+	args := make([]string, len(component.Parameters))
+	for i, param := range component.Parameters {
+		finalType, err := qualifyType(typeExprs[i], component.ImportPath, fileImports, imports)
+		if err != nil {
+			return nil, err
+		}
+
+		name := param.Name
+		if name == "_" {
+			name = ""
+		}
+
+		variable := fmt.Sprintf("v%d", i)
+		entry.Params = append(entry.Params, hostParam{Name: name, Var: variable, Type: finalType})
+
+		args[i] = variable
+		if variadic[i] {
+			args[i] += "..."
+		}
+	}
+	entry.Args = strings.Join(args, ", ")
+
+	return entry, nil
+}
+
+// qualifyType rewrites a type expression written inside a component's package
+// so that it can be used from the render host: local type names are qualified
+// with the component package and package selectors are mapped to aliased
+// imports.
+func qualifyType(typeExpr, packagePath string, fileImports map[string]string, imports *hostImports) (string, error) {
+	expr, err := parser.ParseExpr(typeExpr)
+	if err != nil {
+		return "", fmt.Errorf("parsing type %q: %w", typeExpr, err)
+	}
+
+	q := &typeQualifier{packagePath: packagePath, fileImports: fileImports, imports: imports}
+	rewritten, err := q.rewrite(expr)
+	if err != nil {
+		return "", fmt.Errorf("type %q: %w", typeExpr, err)
+	}
+
+	return gotypes.ExprString(rewritten), nil
+}
+
+// typeQualifier rewrites identifiers in a type expression
+type typeQualifier struct {
+	packagePath string
+	fileImports map[string]string
+	imports     *hostImports
+}
+
+// rewrite returns expr with every type name qualified for use in the host
+func (q *typeQualifier) rewrite(expr ast.Expr) (ast.Expr, error) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if obj := gotypes.Universe.Lookup(e.Name); obj != nil {
+			if _, isType := obj.(*gotypes.TypeName); isType {
+				return e, nil
+			}
+		}
+		if q.packagePath == "" {
+			return nil, fmt.Errorf("unknown package for type %s", e.Name)
+		}
+		if !ast.IsExported(e.Name) {
+			return nil, fmt.Errorf("unexported type %s", e.Name)
+		}
+		return &ast.SelectorExpr{X: ast.NewIdent(q.imports.alias(q.packagePath)), Sel: e}, nil
+
+	case *ast.SelectorExpr:
+		pkg, ok := e.X.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("unsupported qualified type")
+		}
+		importPath, ok := q.fileImports[pkg.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown package %s", pkg.Name)
+		}
+		return &ast.SelectorExpr{X: ast.NewIdent(q.imports.alias(importPath)), Sel: e.Sel}, nil
+
+	case *ast.StarExpr:
+		x, err := q.rewrite(e.X)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.StarExpr{X: x}, nil
+
+	case *ast.ParenExpr:
+		return q.rewrite(e.X)
+
+	case *ast.ArrayType:
+		elt, err := q.rewrite(e.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ArrayType{Len: e.Len, Elt: elt}, nil
+
+	case *ast.MapType:
+		key, err := q.rewrite(e.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := q.rewrite(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.MapType{Key: key, Value: value}, nil
+
+	case *ast.ChanType:
+		value, err := q.rewrite(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.ChanType{Dir: e.Dir, Value: value}, nil
+
+	case *ast.Ellipsis:
+		elt, err := q.rewrite(e.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.Ellipsis{Elt: elt}, nil
+
+	case *ast.IndexExpr:
+		x, err := q.rewrite(e.X)
+		if err != nil {
+			return nil, err
+		}
+		index, err := q.rewrite(e.Index)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.IndexExpr{X: x, Index: index}, nil
+
+	case *ast.IndexListExpr:
+		x, err := q.rewrite(e.X)
+		if err != nil {
+			return nil, err
+		}
+		indices := make([]ast.Expr, len(e.Indices))
+		for i, index := range e.Indices {
+			if indices[i], err = q.rewrite(index); err != nil {
+				return nil, err
+			}
+		}
+		return &ast.IndexListExpr{X: x, Indices: indices}, nil
+
+	case *ast.FuncType:
+		params, err := q.rewriteFields(e.Params)
+		if err != nil {
+			return nil, err
+		}
+		results, err := q.rewriteFields(e.Results)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.FuncType{Params: params, Results: results}, nil
+
+	case *ast.StructType:
+		fields, err := q.rewriteFields(e.Fields)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.StructType{Fields: fields}, nil
+
+	case *ast.InterfaceType:
+		methods, err := q.rewriteFields(e.Methods)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.InterfaceType{Methods: methods}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported type expression %T", expr)
+}
+
+// rewriteFields qualifies the types of a field list, leaving names untouched
+func (q *typeQualifier) rewriteFields(fields *ast.FieldList) (*ast.FieldList, error) {
+	if fields == nil {
+		return nil, nil
+	}
+
+	rewritten := &ast.FieldList{}
+	for _, field := range fields.List {
+		fieldType, err := q.rewrite(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		rewritten.List = append(rewritten.List, &ast.Field{Names: field.Names, Type: fieldType, Tag: field.Tag})
+	}
+
+	return rewritten, nil
+}
+
+// sortComponentsByID orders components deterministically for code generation
+func sortComponentsByID(components []*types.ComponentInfo) {
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].ID < components[j].ID
+	})
+}
+
+// hostProgramTemplate is the main package of the render host. It reads one
+// JSON request per line from stdin and answers with one JSON response per
+// line on stdout. Anything the components print goes to stderr so that it
+// cannot corrupt the protocol.
+const hostProgramTemplate = `// Code generated by templar. DO NOT EDIT.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/a-h/templ"
+{{range .Imports}}
+	{{.Alias}} "{{.Path}}"
+{{- end}}
+)
+
+type request struct {
+	ID        uint64                     ` + "`json:\"id\"`" + `
+	Component string                     ` + "`json:\"component\"`" + `
+	Props     map[string]json.RawMessage ` + "`json:\"props\"`" + `
+	Children  string                     ` + "`json:\"children\"`" + `
+	Nonce     string                     ` + "`json:\"nonce\"`" + `
+}
+
+type response struct {
+	ID    uint64 ` + "`json:\"id\"`" + `
+	HTML  string ` + "`json:\"html\"`" + `
+	Error string ` + "`json:\"error,omitempty\"`" + `
+}
+
+var components = map[string]func(props map[string]json.RawMessage) (templ.Component, error){
+{{- range .Components}}
+	{{printf "%q" .ID}}: func(props map[string]json.RawMessage) (templ.Component, error) {
+{{- range .Params}}
+		var {{.Var}} {{.Type}}
+{{- if .Name}}
+		if err := decodeProp(props, {{printf "%q" .Name}}, &{{.Var}}); err != nil {
+			return nil, err
+		}
+{{- end}}
+{{- end}}
+		return {{.Call}}({{.Args}}), nil
+	},
+{{- end}}
+}
+
+func decodeProp(props map[string]json.RawMessage, name string, target interface{}) error {
+	raw, ok := props[name]
+	if !ok || len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		return fmt.Errorf("prop %s: %w", name, err)
+	}
+	return nil
+}
+
+func render(req request) (html string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic rendering %s: %v", req.Component, r)
+		}
+	}()
+
+	factory, ok := components[req.Component]
+	if !ok {
+		return "", fmt.Errorf("component %s is not served by this render host", req.Component)
+	}
+
+	component, err := factory(req.Props)
+	if err != nil {
+		return "", err
+	}
+
+	ctx := context.Background()
+	if req.Nonce != "" {
+		ctx = templ.WithNonce(ctx, req.Nonce)
+	}
+	if req.Children != "" {
+		ctx = templ.WithChildren(ctx, templ.Raw(req.Children))
+	}
+
+	var buf bytes.Buffer
+	if err := component.Render(ctx, &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func main() {
+	out := os.Stdout
+	os.Stdout = os.Stderr
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	encoder := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		var req request
+		var resp response
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp.Error = fmt.Sprintf("invalid request: %v", err)
+		} else {
+			resp.ID = req.ID
+			html, err := render(req)
+			if err != nil {
+				resp.Error = err.Error()
+			}
+			resp.HTML = html
+		}
+		if err := encoder.Encode(resp); err != nil {
+			fmt.Fprintf(os.Stderr, "render host: writing response: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+`
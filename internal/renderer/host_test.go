@@ -0,0 +1,352 @@
+package renderer
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/conneroisu/templar/internal/cache"
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/types"
+)
+
+func TestQualifyType(t *testing.T) {
+	fileImports := map[string]string{
+		"config": "example.com/app/config",
+		"time":   "time",
+	}
+
+	tests := []struct {
+		typeExpr string
+		expected string
+		wantErr  bool
+	}{
+		{"string", "string", false},
+		{"[]Item", "[]p0.Item", false},
+		{"map[string]*Item", "map[string]*p0.Item", false},
+		{"*config.Options", "*p1.Options", false},
+		{"time.Time", "p1.Time", false},
+		{"func(Item) error", "func(p0.Item) error", false},
+		{"struct{ Label string }", "struct{Label string}", false},
+		{"item", "", true},
+		{"unknown.Type", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typeExpr, func(t *testing.T) {
+			imports := newHostImports()
+			imports.alias("example.com/app/ui")
+
+			qualified, err := qualifyType(tt.typeExpr, "example.com/app/ui", fileImports, imports)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, qualified)
+		})
+	}
+}
+
+func TestGenerateHostProgram(t *testing.T) {
+	components := []*types.ComponentInfo{
+		{
+			ID:         "example.com/app/ui.Button",
+			Name:       "Button",
+			ImportPath: "example.com/app/ui",
+			Imports:    []string{"github.com/a-h/templ", "example.com/app/config"},
+			Parameters: []types.ParameterInfo{
+				{Name: "text", Type: "string"},
+				{Name: "opts", Type: "*config.Options"},
+				{Name: "classes", Type: "...string"},
+			},
+		},
+		{
+			ID:         "example.com/app/ui.Hidden",
+			Name:       "Hidden",
+			ImportPath: "example.com/app/ui",
+			Parameters: []types.ParameterInfo{{Name: "state", Type: "internalState"}},
+		},
+	}
+
+	source, served, skipped, err := generateHostProgram(components)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"example.com/app/ui.Button"}, served)
+	assert.Equal(t, []string{"example.com/app/ui.Hidden"}, skipped)
+
+	assert.Contains(t, source, `p0 "example.com/app/ui"`)
+	assert.Contains(t, source, `p1 "example.com/app/config"`)
+	assert.Contains(t, source, `"example.com/app/ui.Button": func(`)
+	assert.Contains(t, source, "var v1 *p1.Options")
+	assert.Contains(t, source, "var v2 []string")
+	assert.Contains(t, source, "return p0.Button(v0, v1, v2...), nil")
+	assert.NotContains(t, source, "Hidden(")
+}
+
+func TestRenderHost_InvalidateFiles(t *testing.T) {
+	reg := registry.NewComponentRegistry()
+	renderer := NewComponentRenderer(reg)
+
+	renderer.host.stale = false
+	renderer.InvalidateFiles([]string{"ui/button_templ.go", filepath.Join(renderer.host.dir, "main.go"), "README.md"})
+	assert.False(t, renderer.host.stale)
+
+	renderer.InvalidateFiles([]string{"ui/button.templ"})
+	assert.True(t, renderer.host.stale)
+
+	renderer.host.stale = false
+	renderer.InvalidateFiles([]string{"models/user.go"})
+	assert.True(t, renderer.host.stale)
+}
+
+// writeHostFixture writes a module with a templ component that the render
+// host can import, returning its root directory.
+func writeHostFixture(t *testing.T) string {
+	t.Helper()
+
+	root, err := os.MkdirTemp(".", "hosttest")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	goSum, err := os.ReadFile(filepath.Join("..", "..", "go.sum"))
+	require.NoError(t, err)
+
+	files := map[string]string{
+		"go.mod": "module example.com/hosttest\n\ngo 1.24\n\nrequire github.com/a-h/templ v0.3.906\n",
+		"go.sum": string(goSum),
+		"ui/types.go": `package ui
+
+type Link struct {
+	Label string ` + "`json:\"label\"`" + `
+	Href  string ` + "`json:\"href\"`" + `
+}
+`,
+		"ui/button.templ": `package ui
+
+templ Button(text string, links []Link) {
+	<button>{ text }</button>
+	for _, link := range links {
+		<a href={ templ.SafeURL(link.Href) }>{ link.Label }</a>
+	}
+}
+`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	return root
+}
+
+func TestRenderHost_RendersAndReusesProcess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping render host build in short mode")
+	}
+	for _, tool := range []string{"go", "templ"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s command not available", tool)
+		}
+	}
+
+	root := writeHostFixture(t)
+	reg := registry.NewComponentRegistry()
+	reg.Register(&types.ComponentInfo{
+		Name:       "Button",
+		Package:    "ui",
+		FilePath:   filepath.Join(root, "ui", "button.templ"),
+		IsExported: true,
+		Kind:       types.ComponentKindTempl,
+		Parameters: []types.ParameterInfo{
+			{Name: "text", Type: "string"},
+			{Name: "links", Type: "[]Link"},
+		},
+	})
+
+	component, exists := reg.Get("ui.Button")
+	require.True(t, exists)
+	require.Equal(t, "example.com/hosttest/ui.Button", component.ID)
+
+	host := NewRenderHost(reg, filepath.Join(root, ".host"))
+	defer host.Close()
+
+	props := map[string]interface{}{
+		"text":  "Save",
+		"links": []map[string]string{{"label": "Docs", "href": "/docs"}},
+	}
+
+	html, err := host.Render(context.Background(), component, props)
+	require.NoError(t, err)
+	assert.Contains(t, html, "<button>Save</button>")
+	assert.Contains(t, html, `<a href="/docs">Docs</a>`)
+
+	pid := host.process.cmd.Process.Pid
+
+	start := time.Now()
+	html, err = host.Render(context.Background(), component, map[string]interface{}{"text": "Again"})
+	require.NoError(t, err)
+	assert.Contains(t, html, "<button>Again</button>")
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.Equal(t, pid, host.process.cmd.Process.Pid, "repeat renders should reuse the running host")
+
+	_, err = host.Render(context.Background(), component, map[string]interface{}{"text": 42})
+	assert.ErrorContains(t, err, "prop text")
+
+	// A templ change followed by invalidation rebuilds the host
+	require.NoError(t, os.WriteFile(filepath.Join(root, "ui", "button.templ"), []byte(`package ui
+
+templ Button(text string, links []Link) {
+	<button class="primary">{ text }</button>
+}
+`), 0644))
+	host.Invalidate()
+
+	html, err = host.Render(context.Background(), component, map[string]interface{}{"text": "Changed"})
+	require.NoError(t, err)
+	assert.Contains(t, html, `<button class="primary">Changed</button>`)
+}
+
+func TestRenderHost_DiskCache(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping render host build in short mode")
+	}
+	for _, tool := range []string{"go", "templ"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s command not available", tool)
+		}
+	}
+
+	root := writeHostFixture(t)
+	reg := registry.NewComponentRegistry()
+	reg.Register(&types.ComponentInfo{
+		Name:       "Button",
+		Package:    "ui",
+		FilePath:   filepath.Join(root, "ui", "button.templ"),
+		IsExported: true,
+		Kind:       types.ComponentKindTempl,
+		Parameters: []types.ParameterInfo{
+			{Name: "text", Type: "string"},
+			{Name: "links", Type: "[]Link"},
+		},
+	})
+	component, exists := reg.Get("ui.Button")
+	require.True(t, exists)
+
+	diskCache, err := cache.Open(t.TempDir(), 0)
+	require.NoError(t, err)
+	props := map[string]interface{}{"text": "Save"}
+
+	host := NewRenderHost(reg, filepath.Join(root, ".host"))
+	host.SetDiskCache(diskCache)
+	html, err := host.Render(context.Background(), component, props)
+	require.NoError(t, err)
+	require.NoError(t, host.Close())
+
+	stats, err := diskCache.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Namespaces[cache.NamespaceGenerate].Entries)
+	assert.Equal(t, 1, stats.Namespaces[cache.NamespaceRender].Entries)
+
+	// After a restart, generated code is restored and the render is served
+	// without starting the host process
+	require.NoError(t, os.Remove(filepath.Join(root, "ui", "button_templ.go")))
+	restarted := NewRenderHost(reg, filepath.Join(root, ".host"))
+	restarted.SetDiskCache(diskCache)
+	defer restarted.Close()
+
+	cached, err := restarted.Render(context.Background(), component, props)
+	require.NoError(t, err)
+	assert.Equal(t, html, cached)
+	assert.Nil(t, restarted.process)
+	assert.FileExists(t, filepath.Join(root, "ui", "button_templ.go"))
+
+	// Renders with other props reach the host
+	html, err = restarted.Render(context.Background(), component, map[string]interface{}{"text": "Other"})
+	require.NoError(t, err)
+	assert.Contains(t, html, "<button>Other</button>")
+	assert.NotNil(t, restarted.process)
+}
+
+func TestRenderHost_BrokenPackage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping render host build in short mode")
+	}
+	for _, tool := range []string{"go", "templ"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s command not available", tool)
+		}
+	}
+
+	root := writeHostFixture(t)
+	// Generates fine but does not compile
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "broken"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "broken", "card.templ"), []byte(`package broken
+
+templ Card(title string) {
+	<div>{ undefinedTitle }</div>
+}
+`), 0644))
+
+	reg := registry.NewComponentRegistry()
+	reg.Register(&types.ComponentInfo{
+		Name:       "Button",
+		Package:    "ui",
+		FilePath:   filepath.Join(root, "ui", "button.templ"),
+		IsExported: true,
+		Kind:       types.ComponentKindTempl,
+		Parameters: []types.ParameterInfo{
+			{Name: "text", Type: "string"},
+			{Name: "links", Type: "[]Link"},
+		},
+	})
+	reg.Register(&types.ComponentInfo{
+		Name:       "Card",
+		Package:    "broken",
+		FilePath:   filepath.Join(root, "broken", "card.templ"),
+		IsExported: true,
+		Kind:       types.ComponentKindTempl,
+		Parameters: []types.ParameterInfo{{Name: "title", Type: "string"}},
+	})
+	button, exists := reg.Get("ui.Button")
+	require.True(t, exists)
+	card, exists := reg.Get("broken.Card")
+	require.True(t, exists)
+
+	host := NewRenderHost(reg, filepath.Join(root, ".host"))
+	defer host.Close()
+
+	// The broken package is left out and the other components still render
+	html, err := host.Render(context.Background(), button, map[string]interface{}{"text": "Save"})
+	require.NoError(t, err)
+	assert.Contains(t, html, "<button>Save</button>")
+
+	_, err = host.Render(context.Background(), card, map[string]interface{}{"title": "Hi"})
+	assert.ErrorContains(t, err, "example.com/hosttest/broken does not compile")
+	assert.NotErrorIs(t, err, errHostUnavailable)
+	assert.ErrorContains(t, host.BuildError(), "undefinedTitle")
+
+	// A rebuild that fails altogether keeps the last good binary serving
+	require.NoError(t, os.WriteFile(filepath.Join(root, "ui", "types.go"), []byte("package ui\n\ntype Link struct {\n"), 0644))
+	host.Invalidate()
+
+	html, err = host.Render(context.Background(), button, map[string]interface{}{"text": "Still"})
+	require.NoError(t, err)
+	assert.Contains(t, html, "<button>Still</button>")
+	assert.ErrorContains(t, host.BuildError(), "no component package could be built")
+
+	// Without a good binary the host is unavailable, so the renderer falls
+	// back to rendering components on their own
+	fresh := NewRenderHost(reg, filepath.Join(root, ".fresh-host"))
+	defer fresh.Close()
+	_, err = fresh.Render(context.Background(), button, map[string]interface{}{"text": "Save"})
+	assert.ErrorIs(t, err, errHostUnavailable)
+}
@@ -0,0 +1,378 @@
+package renderer
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/conneroisu/templar/internal/interfaces"
+	"github.com/conneroisu/templar/internal/mockdata"
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// SetWrapper sets the layout component previews are rendered inside, as
+// configured by preview.wrapper. It is either a component name or ID such as
+// "layouts.Preview", or the path of a .templ file declaring one component.
+// The layout receives the preview as { children... } and, when it declares a
+// string title parameter, the component name as title. An empty wrapper, or a
+// .templ path that does not exist, keeps the built-in layout.
+func (r *ComponentRenderer) SetWrapper(wrapper string) {
+	r.wrapper = strings.TrimSpace(wrapper)
+}
+
+// Wrapper returns the configured preview layout
+func (r *ComponentRenderer) Wrapper() string {
+	return r.wrapper
+}
+
+// WrapperFile returns the .templ file named by the configured wrapper, so that
+// callers can scan it when it lies outside the component scan paths. It is
+// empty when the wrapper names a component.
+func (r *ComponentRenderer) WrapperFile() string {
+	if !strings.HasSuffix(r.wrapper, ".templ") {
+		return ""
+	}
+	return r.wrapper
+}
+
+// resolveLayout looks up a layout component in the renderer's registry
+func (r *ComponentRenderer) resolveLayout(name string) (*types.ComponentInfo, error) {
+	return ResolveLayout(r.registry, name)
+}
+
+// ResolveLayout looks up a layout component by name, ID or the path of the
+// .templ file declaring it. It returns nil without an error for a .templ path
+// that does not exist.
+func ResolveLayout(registry interfaces.ComponentRegistry, name string) (*types.ComponentInfo, error) {
+	if !strings.HasSuffix(name, ".templ") {
+		layout, err := registry.Resolve(name)
+		if err != nil {
+			return nil, fmt.Errorf("resolving layout %s: %w", name, err)
+		}
+		return layout, nil
+	}
+
+	if _, err := os.Stat(name); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	absPath, err := filepath.Abs(name)
+	if err != nil {
+		return nil, fmt.Errorf("resolving layout %s: %w", name, err)
+	}
+
+	var declared []*types.ComponentInfo
+	for _, component := range registry.GetAll() {
+		if componentPath, err := filepath.Abs(component.FilePath); err == nil && componentPath == absPath {
+			declared = append(declared, component)
+		}
+	}
+
+	switch len(declared) {
+	case 0:
+		return nil, fmt.Errorf("layout file %s declares no scanned components", name)
+	case 1:
+		return declared[0], nil
+	}
+
+	names := make([]string, len(declared))
+	for i, component := range declared {
+		names[i] = component.Name
+	}
+	return nil, fmt.Errorf("layout file %s declares several components (%s), set preview.wrapper to one of them",
+		name, strings.Join(names, ", "))
+}
+
+// renderLayout renders html as the children of a layout component, passing
+// title to a string title parameter and nonce to templ.GetNonce. Fixtures
+// for the layout's default variant supply its other parameters.
+func (r *ComponentRenderer) renderLayout(layout *types.ComponentInfo, title, children, nonce string) (string, error) {
+	if !r.host.Supports(layout) {
+		return "", fmt.Errorf("layout %s must be an exported templ component in an importable package", layout.ID)
+	}
+
+	props, err := r.fixtures.Props(layout, mockdata.DefaultVariant)
+	if err != nil {
+		return "", fmt.Errorf("loading mock data fixtures: %w", err)
+	}
+	for _, param := range layout.Parameters {
+		if param.Name == "title" && param.Type == "string" {
+			props = mockdata.MergeProps(props, map[string]interface{}{"title": title})
+		}
+	}
+
+	return r.host.RenderWithChildren(context.Background(), layout, props, children, nonce)
+}
+
+// RenderWrapperPage renders a full preview page through the configured
+// wrapper, adding the CSP nonce and the live reload script. ok is false when
+// no wrapper is configured or its .templ file does not exist.
+func (r *ComponentRenderer) RenderWrapperPage(componentName, componentHTML, nonce string) (page string, ok bool, err error) {
+	page, ok, err = r.renderWrapper(componentName, componentHTML, nonce)
+	if !ok {
+		return "", ok, err
+	}
+	return injectLiveReload(page, nonce), true, nil
+}
+
+// RenderWrapperDocument renders a page through the configured wrapper without
+// the live reload script, for exported pages served without Templar. ok is
+// false when no wrapper is configured or its .templ file does not exist.
+func (r *ComponentRenderer) RenderWrapperDocument(componentName, componentHTML string) (page string, ok bool, err error) {
+	return r.renderWrapper(componentName, componentHTML, "")
+}
+
+// renderWrapper renders componentHTML as the children of the configured
+// wrapper, adding the CSP nonce to the wrapper's own tags
+func (r *ComponentRenderer) renderWrapper(componentName, componentHTML, nonce string) (page string, ok bool, err error) {
+	if r.wrapper == "" {
+		return "", false, nil
+	}
+
+	layout, err := r.resolveLayout(r.wrapper)
+	if err != nil || layout == nil {
+		return "", false, err
+	}
+
+	// The layout is rendered around a marker so that only its own tags get
+	// the nonce, never those of the previewed component
+	page, err = r.renderLayout(layout, componentName, childrenMarker, nonce)
+	if err != nil {
+		return "", false, fmt.Errorf("rendering preview wrapper %s: %w", layout.ID, err)
+	}
+	if !strings.Contains(page, childrenMarker) {
+		return "", false, fmt.Errorf("preview wrapper %s does not render { children... }", layout.ID)
+	}
+
+	return strings.Replace(addNonce(page, nonce), childrenMarker, componentHTML, 1), true, nil
+}
+
+// childrenMarker stands in for the previewed component while the wrapper
+// layout is post-processed
+const childrenMarker = "<!--templar:children-->"
+
+// inlineTagPattern matches opening script and style tags
+var inlineTagPattern = regexp.MustCompile(`(?i)<(script|style)(\s[^>]*)?>`)
+
+// noncePattern matches an existing nonce attribute
+var noncePattern = regexp.MustCompile(`(?i)\snonce\s*=`)
+
+// addNonce adds the CSP nonce to every script and style tag of a wrapper page
+// that does not already carry one, so layouts written without templ.GetNonce
+// keep working under the preview server's CSP
+func addNonce(page, nonce string) string {
+	if nonce == "" {
+		return page
+	}
+
+	nonceAttr := fmt.Sprintf(` nonce="%s"`, html.EscapeString(nonce))
+	return inlineTagPattern.ReplaceAllStringFunc(page, func(tag string) string {
+		if noncePattern.MatchString(tag) {
+			return tag
+		}
+		if strings.HasSuffix(tag, "/>") {
+			return tag[:len(tag)-2] + nonceAttr + "/>"
+		}
+		return tag[:len(tag)-1] + nonceAttr + ">"
+	})
+}
+
+// injectLiveReload inserts the live reload script before the closing body
+// tag of a page, or appends it when the page has none
+func injectLiveReload(page, nonce string) string {
+	script := liveReloadScript(nonce)
+
+	if i := strings.LastIndex(strings.ToLower(page), "</body>"); i >= 0 {
+		return page[:i] + script + "\n" + page[i:]
+	}
+	return page + "\n" + script
+}
+
+// StylesheetSwapScript defines swapStylesheets(href, hash), which reloads
+// the stylesheets linked from href on a css_update message. Each matching
+// <link> is replaced once its successor has loaded, so the page keeps its
+// scroll position and form state. When no link matches, as for a stylesheet
+// pulled in by @import, every same-origin stylesheet is reloaded.
+const StylesheetSwapScript = `
+        function swapStylesheets(href, hash) {
+            const target = new URL(href, window.location.href);
+            const links = Array.from(document.querySelectorAll('link[rel="stylesheet"]'));
+            let matched = links.filter(link => new URL(link.href).pathname === target.pathname);
+            if (matched.length === 0) {
+                matched = links.filter(link => new URL(link.href).origin === window.location.origin);
+            }
+            matched.forEach(link => {
+                const url = new URL(link.href);
+                url.searchParams.set('templar', hash);
+                const next = link.cloneNode();
+                next.href = url.toString();
+                next.onload = next.onerror = () => link.remove();
+                link.after(next);
+            });
+        }
+`
+
+// MarkPreview surrounds the rendered HTML of a component with the comments
+// the live reload script finds it by. component_update messages carry the
+// default preview of a component, so a story is marked to be reloaded
+// instead of morphed.
+func MarkPreview(componentID, story, html string) string {
+	marker := "templar-component:" + componentID
+	if story != "" {
+		marker = "templar-story:" + componentID
+	}
+	return "<!--" + marker + "-->" + html + "<!--/" + marker + "-->"
+}
+
+// morphScript defines morphComponent(message), which morphs the preview
+// marked by MarkPreview into the HTML of a component_update message
+const morphScript = `
+        function findMarker(text) {
+            const walker = document.createTreeWalker(document.body, NodeFilter.SHOW_COMMENT);
+            while (walker.nextNode()) {
+                if (walker.currentNode.nodeValue === text) {
+                    return walker.currentNode;
+                }
+            }
+            return null;
+        }
+
+        function morphComponent(message) {
+            const start = findMarker('templar-component:' + message.target);
+            if (!start) {
+                // Stories render with their own props, so they reload instead
+                if (findMarker('templar-story:' + message.target)) {
+                    window.location.reload();
+                }
+                return;
+            }
+            const end = findMarker('/templar-component:' + message.target);
+            const oldNodes = [];
+            for (let node = start.nextSibling; node && node !== end; node = node.nextSibling) {
+                oldNodes.push(node);
+            }
+
+            const template = document.createElement('template');
+            template.innerHTML = message.content;
+            const scrollX = window.scrollX, scrollY = window.scrollY;
+            morphChildren(start.parentNode, oldNodes, Array.from(template.content.childNodes), end);
+            window.scrollTo(scrollX, scrollY);
+        }
+
+        // morphChildren updates oldNodes, the children of parent before end,
+        // to match newNodes. Elements are kept where they still match, so
+        // focus and typed input survive; those with an id are matched by it.
+        function morphChildren(parent, oldNodes, newNodes, end) {
+            let i = 0;
+            for (const newNode of newNodes) {
+                if (newNode.id) {
+                    const j = oldNodes.findIndex((node, k) => k > i && node.id === newNode.id);
+                    if (j > i) {
+                        const moved = oldNodes.splice(j, 1)[0];
+                        parent.insertBefore(moved, oldNodes[i] || end);
+                        oldNodes.splice(i, 0, moved);
+                    }
+                }
+
+                const oldNode = oldNodes[i];
+                if (!oldNode) {
+                    parent.insertBefore(newNode, end);
+                } else if (oldNode.nodeType === newNode.nodeType && oldNode.nodeName === newNode.nodeName &&
+                    (oldNode.id || '') === (newNode.id || '')) {
+                    morphNode(oldNode, newNode);
+                } else {
+                    parent.replaceChild(newNode, oldNode);
+                    oldNodes[i] = newNode;
+                }
+                i++;
+            }
+            oldNodes.slice(i).forEach(node => node.remove());
+        }
+
+        // morphNode updates an element's attributes and children, or the text
+        // of other nodes. The value attributes of form fields only set their
+        // defaults, so what was typed in them stays.
+        function morphNode(from, to) {
+            if (from.nodeType !== Node.ELEMENT_NODE) {
+                if (from.nodeValue !== to.nodeValue) {
+                    from.nodeValue = to.nodeValue;
+                }
+                return;
+            }
+            for (const attr of Array.from(from.attributes)) {
+                if (!to.hasAttribute(attr.name)) {
+                    from.removeAttribute(attr.name);
+                }
+            }
+            for (const attr of Array.from(to.attributes)) {
+                if (from.getAttribute(attr.name) !== attr.value) {
+                    from.setAttribute(attr.name, attr.value);
+                }
+            }
+            morphChildren(from, Array.from(from.childNodes), Array.from(to.childNodes), null);
+        }
+`
+
+// errorOverlayScript defines showErrorOverlay(content), which shows the
+// error overlay of a build_error message over the page, and
+// hideErrorOverlay(). The overlay is rendered in a shadow root, with the
+// script's CSP nonce applied to its styles before they are attached.
+const errorOverlayScript = `
+        const overlayNonce = document.currentScript ? document.currentScript.nonce : '';
+        function showErrorOverlay(content) {
+            hideErrorOverlay();
+            const template = document.createElement('template');
+            template.innerHTML = content;
+            template.content.querySelectorAll('style').forEach(style => style.nonce = overlayNonce);
+            const host = document.createElement('templar-error-overlay');
+            const root = host.attachShadow({mode: 'open'});
+            root.appendChild(template.content);
+            root.querySelectorAll('[data-templar-close]').forEach(button => button.addEventListener('click', hideErrorOverlay));
+            (document.body || document.documentElement).appendChild(host);
+        }
+        function hideErrorOverlay() {
+            document.querySelectorAll('templar-error-overlay').forEach(overlay => overlay.remove());
+        }
+`
+
+// liveReloadScript returns the script reloading the preview on full_reload
+// messages from the server, its stylesheets on css_update messages, and
+// morphing the previewed component on component_update messages. A failed
+// morph reloads the page. Build errors are shown in an overlay until the
+// next successful build.
+func liveReloadScript(nonce string) string {
+	scriptNonce := ""
+	if nonce != "" {
+		scriptNonce = fmt.Sprintf(` nonce="%s"`, html.EscapeString(nonce))
+	}
+
+	return fmt.Sprintf(`<script%s>
+        // WebSocket connection for live reload
+        const ws = new WebSocket('ws://localhost:' + window.location.port + '/ws');
+        ws.onmessage = function(event) {
+            const message = JSON.parse(event.data);
+            if (message.type === 'full_reload') {
+                window.location.reload();
+            } else if (message.type === 'css_update') {
+                swapStylesheets(message.target, message.hash);
+            } else if (message.type === 'build_error') {
+                if (message.overlay) {
+                    showErrorOverlay(message.overlay);
+                }
+            } else if (message.type === 'build_success') {
+                hideErrorOverlay();
+            } else if (message.type === 'component_update') {
+                hideErrorOverlay();
+                try {
+                    morphComponent(message);
+                } catch (err) {
+                    console.error('Templar: morphing ' + message.target + ' failed', err);
+                    window.location.reload();
+                }
+            }
+        };%s%s%s    </script>`, scriptNonce, StylesheetSwapScript, morphScript, errorOverlayScript)
+}
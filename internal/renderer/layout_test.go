@@ -0,0 +1,127 @@
+package renderer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/types"
+)
+
+func TestAddNonce(t *testing.T) {
+	page := `<head><script src="/app.js"></script><script nonce="own">a()</script><style>b{}</style><link rel="stylesheet"/><script src="/x.js"/></head>`
+
+	assert.Equal(t,
+		`<head><script src="/app.js" nonce="n1"></script><script nonce="own">a()</script><style nonce="n1">b{}</style><link rel="stylesheet"/><script src="/x.js" nonce="n1"/></head>`,
+		addNonce(page, "n1"))
+	assert.Equal(t, page, addNonce(page, ""))
+}
+
+func TestInjectLiveReload(t *testing.T) {
+	page := injectLiveReload("<html><body><main></main></BODY></html>", "n1")
+	assert.True(t, strings.HasPrefix(page, "<html><body><main></main><script nonce=\"n1\">"))
+	assert.True(t, strings.HasSuffix(page, "</script>\n</BODY></html>"))
+	assert.Contains(t, page, "new WebSocket(")
+
+	fragment := injectLiveReload("<main></main>", "")
+	assert.True(t, strings.HasPrefix(fragment, "<main></main>\n<script>"))
+}
+
+func TestMarkPreview(t *testing.T) {
+	assert.Equal(t,
+		"<!--templar-component:ui.Button--><button>Save</button><!--/templar-component:ui.Button-->",
+		MarkPreview("ui.Button", "", "<button>Save</button>"))
+	assert.Equal(t,
+		"<!--templar-story:ui.Button--><button>Delete</button><!--/templar-story:ui.Button-->",
+		MarkPreview("ui.Button", "Danger", "<button>Delete</button>"))
+
+	// The live reload script finds the markers it morphs
+	script := liveReloadScript("")
+	assert.Contains(t, script, "'templar-component:' + message.target")
+	assert.Contains(t, script, "'templar-story:' + message.target")
+}
+
+func TestRenderComponentWithLayout_Wrapper(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping render host build in short mode")
+	}
+	for _, tool := range []string{"go", "templ"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s command not available", tool)
+		}
+	}
+
+	root := writeHostFixture(t)
+	layoutPath := filepath.Join(root, "layouts", "preview.templ")
+	require.NoError(t, os.MkdirAll(filepath.Dir(layoutPath), 0755))
+	require.NoError(t, os.WriteFile(layoutPath, []byte(`package layouts
+
+templ Preview(title string) {
+	<!DOCTYPE html>
+	<html>
+		<head>
+			<title>{ title }</title>
+			<link rel="stylesheet" href="/assets/app.css"/>
+			<script>window.theme = "dark"</script>
+			<script nonce={ templ.GetNonce(ctx) }>window.ready = true</script>
+		</head>
+		<body class="theme-dark">
+			{ children... }
+		</body>
+	</html>
+}
+
+templ Centered() {
+	<div class="centered">{ children... }</div>
+}
+`), 0644))
+
+	reg := registry.NewComponentRegistry()
+	for _, component := range []*types.ComponentInfo{
+		{Name: "Preview", Parameters: []types.ParameterInfo{{Name: "title", Type: "string"}}},
+		{Name: "Centered"},
+	} {
+		component.Package = "layouts"
+		component.FilePath = layoutPath
+		component.IsExported = true
+		component.Kind = types.ComponentKindTempl
+		reg.Register(component)
+	}
+
+	renderer := NewComponentRenderer(reg)
+	renderer.host = NewRenderHost(reg, filepath.Join(root, ".host"))
+	defer renderer.Close()
+
+	renderer.SetWrapper("layouts.Preview")
+	componentHTML := `<button>Save</button><script>component()</script>`
+	page := renderer.RenderComponentWithLayoutAndNonce("Button", componentHTML, "n0nce")
+
+	assert.Contains(t, page, "<title>Button</title>")
+	assert.Contains(t, page, `<body class="theme-dark">`+componentHTML)
+	assert.Contains(t, page, `<script nonce="n0nce">window.theme = "dark"</script>`)
+	assert.Contains(t, page, `<script nonce="n0nce">window.ready = true</script>`)
+	assert.Regexp(t, `<script nonce="n0nce">\s*// WebSocket connection for live reload[\s\S]*</script>\s*</body>`, page)
+	assert.NotContains(t, page, "Templar Preview")
+
+	// A story wrapper composes the story inside another layout
+	story := types.ComponentExample{Name: "Centered", Wrapper: "layouts.Centered"}
+	framed, err := renderer.PresentStory(&types.ComponentInfo{Name: "Button"}, story, "<button>Save</button>")
+	require.NoError(t, err)
+	assert.Equal(t, `<div class="templar-story" data-story="Centered"><div class="centered"><button>Save</button></div></div>`, framed)
+
+	// A .templ path declaring several components is ambiguous
+	renderer.SetWrapper(layoutPath)
+	_, _, err = renderer.RenderWrapperPage("Button", componentHTML, "")
+	assert.ErrorContains(t, err, "declares several components (")
+
+	// A missing wrapper file keeps the built-in layout
+	renderer.SetWrapper(filepath.Join(root, "missing.templ"))
+	page = renderer.RenderComponentWithLayoutAndNonce("Button", componentHTML, "")
+	assert.Contains(t, page, "Button - Templar Preview")
+}
@@ -0,0 +1,818 @@
+package renderer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	templparser "github.com/a-h/templ/parser/v2"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// PropError reports a prop that does not match the component's parameter type
+type PropError struct {
+	// Prop is the name of the top-level prop
+	Prop string
+	// Path locates the offending value inside the prop, e.g. "[0].href"
+	Path string
+	// Message describes the mismatch
+	Message string
+}
+
+// Error implements the error interface
+func (e *PropError) Error() string {
+	return fmt.Sprintf("prop %s%s: %s", e.Prop, e.Path, e.Message)
+}
+
+// overlayFile is the name of the Go file declaring stand-ins for the
+// components of a package while it is type-checked
+const overlayFile = "templar_params_overlay.go"
+
+// LoadParamTypes resolves the parameter types of templ components with
+// go/packages and returns the signature of each component's templ function
+// by component ID, and the reason it could not be resolved otherwise.
+// Components of the same package share one load. Templates are
+// type-checked through stand-in functions with the same signatures, so
+// packages whose *_templ.go files have not been generated yet still
+// resolve.
+func LoadParamTypes(ctx context.Context, components []*types.ComponentInfo) (map[string]*gotypes.Signature, map[string]error) {
+	return loadParamTypes(ctx, nil, components)
+}
+
+// loadParamTypes is LoadParamTypes with the environment of the go command,
+// nil for the current one
+func loadParamTypes(ctx context.Context, env []string, components []*types.ComponentInfo) (map[string]*gotypes.Signature, map[string]error) {
+	signatures := make(map[string]*gotypes.Signature)
+	errs := make(map[string]error)
+
+	byDir := make(map[string][]*types.ComponentInfo)
+	for _, component := range components {
+		if component.Kind != "" && component.Kind != types.ComponentKindTempl {
+			continue
+		}
+		dir, err := filepath.Abs(filepath.Dir(component.FilePath))
+		if err != nil {
+			errs[component.ID] = err
+			continue
+		}
+		byDir[dir] = append(byDir[dir], component)
+	}
+
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			for _, component := range byDir[dir] {
+				errs[component.ID] = err
+			}
+			continue
+		}
+		loadPackageParamTypes(ctx, env, dir, byDir[dir], signatures, errs)
+	}
+
+	return signatures, errs
+}
+
+// loadPackageParamTypes type-checks the package in dir with an overlay
+// declaring a stand-in function per component, and records the stand-ins'
+// signatures as the components'
+func loadPackageParamTypes(ctx context.Context, env []string, dir string, components []*types.ComponentInfo, signatures map[string]*gotypes.Signature, errs map[string]error) {
+	fail := func(err error) {
+		for _, component := range components {
+			errs[component.ID] = err
+		}
+	}
+
+	packageName, imports, err := templHeader(components)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	var src strings.Builder
+	src.WriteString("// Code generated by templar. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", packageName)
+	for _, spec := range imports {
+		fmt.Fprintf(&src, "import %s\n", spec)
+	}
+	for i, component := range components {
+		fmt.Fprintf(&src, "\nfunc %s%s(%s) {}\n", standIn(i), typeParamList(component.TypeParameters), paramList(component.Parameters))
+	}
+
+	overlay := filepath.Join(dir, overlayFile)
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    packages.NeedName | packages.NeedTypes | packages.NeedImports | packages.NeedSyntax | packages.NeedTypesSizes,
+		Dir:     dir,
+		Env:     env,
+		Overlay: map[string][]byte{overlay: []byte(src.String())},
+	}
+
+	loaded, err := packages.Load(cfg, ".")
+	if err != nil {
+		fail(fmt.Errorf("loading package in %s: %w", dir, err))
+		return
+	}
+	if len(loaded) != 1 || loaded[0].Types == nil {
+		fail(fmt.Errorf("loading package in %s: found %d packages", dir, len(loaded)))
+		return
+	}
+	pkg := loaded[0]
+
+	// Errors elsewhere in the package, such as calls to components whose
+	// code has not been generated, leave the parameter types intact
+	for i, component := range components {
+		fn, ok := pkg.Types.Scope().Lookup(standIn(i)).(*gotypes.Func)
+		if !ok {
+			errs[component.ID] = fmt.Errorf("type-checking %s: %s", component.Name, firstError(pkg))
+			continue
+		}
+
+		sig := fn.Type().(*gotypes.Signature)
+		if err := unresolvedParam(sig); err != nil {
+			if packageErr := firstError(pkg); packageErr != "" {
+				err = fmt.Errorf("%w (%s)", err, packageErr)
+			}
+			errs[component.ID] = err
+			continue
+		}
+		signatures[component.ID] = sig
+	}
+}
+
+// unresolvedParam reports the first parameter whose type did not resolve
+func unresolvedParam(sig *gotypes.Signature) error {
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		if param := params.At(i); !isResolved(param.Type()) {
+			return fmt.Errorf("parameter %s has type %s, which could not be resolved", param.Name(), param.Type())
+		}
+	}
+	return nil
+}
+
+// isResolved reports whether a type resolved completely
+func isResolved(t gotypes.Type) bool {
+	switch t := gotypes.Unalias(t).(type) {
+	case *gotypes.Basic:
+		return t.Kind() != gotypes.Invalid
+	case *gotypes.Pointer:
+		return isResolved(t.Elem())
+	case *gotypes.Slice:
+		return isResolved(t.Elem())
+	case *gotypes.Array:
+		return isResolved(t.Elem())
+	case *gotypes.Map:
+		return isResolved(t.Key()) && isResolved(t.Elem())
+	default:
+		return true
+	}
+}
+
+// standIn names the overlay function standing in for the i-th component
+func standIn(i int) string {
+	return fmt.Sprintf("templarParams%d", i)
+}
+
+// paramList renders parameters as a Go parameter list
+func paramList(params []types.ParameterInfo) string {
+	parts := make([]string, len(params))
+	for i, param := range params {
+		parts[i] = param.Name + " " + param.Type
+	}
+	return strings.Join(parts, ", ")
+}
+
+// typeParamList renders type parameters as a Go type parameter list
+func typeParamList(params []types.ParameterInfo) string {
+	if len(params) == 0 {
+		return ""
+	}
+	return "[" + paramList(params) + "]"
+}
+
+// firstError returns the first error reported for a package outside the
+// overlay, which otherwise only adds unused imports
+func firstError(pkg *packages.Package) string {
+	for _, err := range pkg.Errors {
+		if !strings.Contains(err.Pos, overlayFile) {
+			return err.Msg
+		}
+	}
+	return ""
+}
+
+// templHeader reads the package name and import declarations of the templ
+// files declaring the components
+func templHeader(components []*types.ComponentInfo) (string, []string, error) {
+	var packageName string
+	var imports []string
+	seenFiles := make(map[string]bool)
+	seenImports := make(map[string]bool)
+
+	for _, component := range components {
+		if seenFiles[component.FilePath] {
+			continue
+		}
+		seenFiles[component.FilePath] = true
+
+		content, err := os.ReadFile(component.FilePath)
+		if err != nil {
+			return "", nil, fmt.Errorf("reading %s: %w", component.FilePath, err)
+		}
+		tf, err := templparser.ParseString(string(content))
+		if err != nil {
+			return "", nil, fmt.Errorf("parsing %s: %w", component.FilePath, err)
+		}
+
+		if packageName == "" {
+			packageName = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tf.Package.Expression.Value), "package"))
+		}
+
+		for _, spec := range importSpecs(tf) {
+			if !seenImports[spec] {
+				seenImports[spec] = true
+				imports = append(imports, spec)
+			}
+		}
+	}
+
+	if packageName == "" {
+		return "", nil, fmt.Errorf("no package clause in %s", components[0].FilePath)
+	}
+	return packageName, imports, nil
+}
+
+// importSpecs returns the import declarations of a templ file as Go source,
+// e.g. `m "example.com/app/models"`
+func importSpecs(tf *templparser.TemplateFile) []string {
+	var specs []string
+	for _, node := range tf.Nodes {
+		goExpr, ok := node.(*templparser.TemplateFileGoExpression)
+		if !ok {
+			continue
+		}
+
+		file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+goExpr.Expression.Value, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+
+		for _, imp := range file.Imports {
+			spec := imp.Path.Value
+			if imp.Name != nil {
+				spec = imp.Name.Name + " " + spec
+			}
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// loadParamTypes returns the signature of the component's templ function,
+// loading the types of all components in its package on first use. The
+// package resolves through the host's go.work, exactly as the host binary
+// does.
+func (h *RenderHost) loadParamTypes(ctx context.Context, component *types.ComponentInfo) (*gotypes.Signature, error) {
+	if sig, ok := h.paramTypes[component.ID]; ok {
+		return sig, nil
+	}
+
+	dir := filepath.Dir(component.FilePath)
+	siblings := []*types.ComponentInfo{component}
+	for _, other := range h.registry.GetAll() {
+		if other.ID != component.ID && filepath.Dir(other.FilePath) == dir {
+			siblings = append(siblings, other)
+		}
+	}
+
+	signatures, errs := loadParamTypes(ctx, hostBuildEnv(filepath.Join(h.dir, "go.work")), siblings)
+	for id, sig := range signatures {
+		h.paramTypes[id] = sig
+	}
+
+	sig, ok := signatures[component.ID]
+	if !ok {
+		return nil, fmt.Errorf("loading parameter types of %s: %w", component.ID, errs[component.ID])
+	}
+	return sig, nil
+}
+
+// checkProps validates props against the parameters of a component function
+// and returns them in the form the host decodes: plain JSON values with enum
+// constant names replaced by their values. Missing props are left out so the
+// parameter keeps its zero value.
+func checkProps(sig *gotypes.Signature, props map[string]interface{}) (map[string]interface{}, error) {
+	params := sig.Params()
+	paramTypes := make(map[string]gotypes.Type, params.Len())
+	names := make([]string, 0, params.Len())
+	for i := 0; i < params.Len(); i++ {
+		param := params.At(i)
+		if param.Name() == "" || param.Name() == "_" {
+			continue
+		}
+		paramTypes[param.Name()] = param.Type()
+		names = append(names, param.Name())
+	}
+
+	values, err := normalizeProps(props)
+	if err != nil {
+		return nil, err
+	}
+
+	checked := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		paramType, ok := paramTypes[name]
+		if !ok {
+			return nil, &PropError{
+				Prop:    name,
+				Message: fmt.Sprintf("unknown prop, the component accepts: %s", strings.Join(names, ", ")),
+			}
+		}
+
+		converted, err := convertProp(paramType, value, "")
+		if err != nil {
+			if propErr, ok := err.(*PropError); ok {
+				propErr.Prop = name
+			}
+			return nil, err
+		}
+		checked[name] = converted
+	}
+
+	return checked, nil
+}
+
+// normalizeProps round-trips props through JSON so that values built in Go
+// and values decoded from requests share one representation, with numbers
+// kept as json.Number to preserve integer precision
+func normalizeProps(props map[string]interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(props)
+	if err != nil {
+		return nil, fmt.Errorf("encoding props: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	values := make(map[string]interface{})
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("decoding props: %w", err)
+	}
+	return values, nil
+}
+
+// convertProp checks a JSON value against a Go type, returning the value to
+// send to the host. JSON null is accepted everywhere and yields the zero value.
+func convertProp(t gotypes.Type, value interface{}, path string) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	t = gotypes.Unalias(t)
+
+	if named, ok := t.(*gotypes.Named); ok {
+		if isTimeType(named) {
+			s, ok := value.(string)
+			if !ok {
+				return nil, mismatch(path, "RFC 3339 timestamp string", value)
+			}
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return nil, &PropError{Path: path, Message: fmt.Sprintf("expected RFC 3339 timestamp, got %q", s)}
+			}
+			return value, nil
+		}
+
+		if hasUnmarshaler(named) {
+			// The type decodes itself, so any JSON value may be valid
+			return value, nil
+		}
+
+		if consts := enumConstants(named); len(consts) > 0 {
+			return convertEnum(named, consts, value, path)
+		}
+	}
+
+	switch u := t.Underlying().(type) {
+	case *gotypes.Basic:
+		return convertBasic(u, t, value, path)
+
+	case *gotypes.Pointer:
+		return convertProp(u.Elem(), value, path)
+
+	case *gotypes.Slice:
+		if basic, ok := u.Elem().Underlying().(*gotypes.Basic); ok && basic.Kind() == gotypes.Byte {
+			if _, ok := value.(string); ok {
+				// []byte is encoded as a base64 string
+				return value, nil
+			}
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, mismatch(path, typeString(t), value)
+		}
+		return convertItems(u.Elem(), items, path)
+
+	case *gotypes.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, mismatch(path, typeString(t), value)
+		}
+		if int64(len(items)) > u.Len() {
+			return nil, &PropError{Path: path, Message: fmt.Sprintf("expected at most %d items, got %d", u.Len(), len(items))}
+		}
+		return convertItems(u.Elem(), items, path)
+
+	case *gotypes.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, mismatch(path, typeString(t), value)
+		}
+		converted := make(map[string]interface{}, len(object))
+		for key, item := range object {
+			itemPath := path + "[" + strconv.Quote(key) + "]"
+			if err := checkMapKey(u.Key(), key, itemPath); err != nil {
+				return nil, err
+			}
+			convertedItem, err := convertProp(u.Elem(), item, itemPath)
+			if err != nil {
+				return nil, err
+			}
+			converted[key] = convertedItem
+		}
+		return converted, nil
+
+	case *gotypes.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, mismatch(path, typeString(t), value)
+		}
+		return convertStruct(u, t, object, path)
+
+	case *gotypes.Interface:
+		if u.Empty() {
+			return value, nil
+		}
+		return nil, &PropError{Path: path, Message: fmt.Sprintf("%s values cannot be supplied as JSON, use null", typeString(t))}
+
+	default:
+		return nil, &PropError{Path: path, Message: fmt.Sprintf("%s values cannot be supplied as JSON, use null", typeString(t))}
+	}
+}
+
+// convertItems converts the elements of a JSON array
+func convertItems(elem gotypes.Type, items []interface{}, path string) (interface{}, error) {
+	converted := make([]interface{}, len(items))
+	for i, item := range items {
+		convertedItem, err := convertProp(elem, item, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		converted[i] = convertedItem
+	}
+	return converted, nil
+}
+
+// convertBasic checks a JSON value against a boolean, string or numeric type
+func convertBasic(basic *gotypes.Basic, t gotypes.Type, value interface{}, path string) (interface{}, error) {
+	info := basic.Info()
+
+	switch {
+	case info&gotypes.IsBoolean != 0:
+		if _, ok := value.(bool); !ok {
+			return nil, mismatch(path, typeString(t), value)
+		}
+
+	case info&gotypes.IsString != 0:
+		if _, ok := value.(string); !ok {
+			return nil, mismatch(path, typeString(t), value)
+		}
+
+	case info&gotypes.IsInteger != 0:
+		number, ok := value.(json.Number)
+		if !ok {
+			return nil, mismatch(path, typeString(t), value)
+		}
+		bits := basicBits(basic.Kind())
+		var err error
+		if info&gotypes.IsUnsigned != 0 {
+			_, err = strconv.ParseUint(number.String(), 10, bits)
+		} else {
+			_, err = strconv.ParseInt(number.String(), 10, bits)
+		}
+		if err != nil {
+			return nil, &PropError{Path: path, Message: fmt.Sprintf("%s is not a valid %s", number, typeString(t))}
+		}
+
+	case info&gotypes.IsFloat != 0:
+		number, ok := value.(json.Number)
+		if !ok {
+			return nil, mismatch(path, typeString(t), value)
+		}
+		if _, err := number.Float64(); err != nil {
+			return nil, &PropError{Path: path, Message: fmt.Sprintf("%s is not a valid %s", number, typeString(t))}
+		}
+
+	default:
+		return nil, &PropError{Path: path, Message: fmt.Sprintf("%s values cannot be supplied as JSON, use null", typeString(t))}
+	}
+
+	return value, nil
+}
+
+// basicBits returns the bit size of an integer kind
+func basicBits(kind gotypes.BasicKind) int {
+	switch kind {
+	case gotypes.Int8, gotypes.Uint8:
+		return 8
+	case gotypes.Int16, gotypes.Uint16:
+		return 16
+	case gotypes.Int32, gotypes.Uint32:
+		return 32
+	default:
+		return 64
+	}
+}
+
+// checkMapKey checks that a JSON object key can be decoded into the map key type
+func checkMapKey(key gotypes.Type, name, path string) error {
+	if named, ok := key.(*gotypes.Named); ok && hasUnmarshaler(named) {
+		return nil
+	}
+
+	basic, ok := key.Underlying().(*gotypes.Basic)
+	if !ok {
+		return &PropError{Path: path, Message: fmt.Sprintf("map keys of type %s cannot be supplied as JSON", typeString(key))}
+	}
+
+	switch info := basic.Info(); {
+	case info&gotypes.IsString != 0:
+		return nil
+	case info&gotypes.IsInteger != 0:
+		var err error
+		if info&gotypes.IsUnsigned != 0 {
+			_, err = strconv.ParseUint(name, 10, basicBits(basic.Kind()))
+		} else {
+			_, err = strconv.ParseInt(name, 10, basicBits(basic.Kind()))
+		}
+		if err != nil {
+			return &PropError{Path: path, Message: fmt.Sprintf("key %q is not a valid %s", name, typeString(key))}
+		}
+		return nil
+	default:
+		return &PropError{Path: path, Message: fmt.Sprintf("map keys of type %s cannot be supplied as JSON", typeString(key))}
+	}
+}
+
+// convertStruct checks a JSON object against a struct type, following the
+// field naming rules of encoding/json
+func convertStruct(st *gotypes.Struct, t gotypes.Type, object map[string]interface{}, path string) (interface{}, error) {
+	fields := make(map[string]gotypes.Type)
+	collectJSONFields(st, fields, make(map[*gotypes.Struct]bool))
+
+	converted := make(map[string]interface{}, len(object))
+	for key, item := range object {
+		fieldType, ok := lookupJSONField(fields, key)
+		if !ok {
+			known := make([]string, 0, len(fields))
+			for name := range fields {
+				known = append(known, name)
+			}
+			sort.Strings(known)
+			return nil, &PropError{
+				Path:    path,
+				Message: fmt.Sprintf("unknown field %q in %s, known fields: %s", key, typeString(t), strings.Join(known, ", ")),
+			}
+		}
+
+		convertedItem, err := convertProp(fieldType, item, path+"."+key)
+		if err != nil {
+			return nil, err
+		}
+		converted[key] = convertedItem
+	}
+
+	return converted, nil
+}
+
+// collectJSONFields records the JSON name and type of each exported field,
+// promoting the fields of untagged embedded structs. Outer fields win.
+func collectJSONFields(st *gotypes.Struct, fields map[string]gotypes.Type, visited map[*gotypes.Struct]bool) {
+	if visited[st] {
+		return
+	}
+	visited[st] = true
+
+	var embedded []*gotypes.Struct
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i)).Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Embedded() && name == "" {
+			fieldType := field.Type()
+			if ptr, ok := fieldType.(*gotypes.Pointer); ok {
+				fieldType = ptr.Elem()
+			}
+			if inner, ok := fieldType.Underlying().(*gotypes.Struct); ok {
+				embedded = append(embedded, inner)
+				continue
+			}
+		}
+		if !field.Exported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name()
+		}
+		fields[name] = field.Type()
+	}
+
+	for _, inner := range embedded {
+		promoted := make(map[string]gotypes.Type)
+		collectJSONFields(inner, promoted, visited)
+		for name, fieldType := range promoted {
+			if _, exists := fields[name]; !exists {
+				fields[name] = fieldType
+			}
+		}
+	}
+}
+
+// lookupJSONField finds a field by exact name, then case-insensitively as
+// encoding/json does
+func lookupJSONField(fields map[string]gotypes.Type, key string) (gotypes.Type, bool) {
+	if fieldType, ok := fields[key]; ok {
+		return fieldType, true
+	}
+	for name, fieldType := range fields {
+		if strings.EqualFold(name, key) {
+			return fieldType, true
+		}
+	}
+	return nil, false
+}
+
+// enumConstants returns the package-level constants declared with a named
+// basic type, which the props treat as the type's allowed values
+func enumConstants(named *gotypes.Named) []*gotypes.Const {
+	if _, ok := named.Underlying().(*gotypes.Basic); !ok {
+		return nil
+	}
+	pkg := named.Obj().Pkg()
+	if pkg == nil {
+		return nil
+	}
+
+	var consts []*gotypes.Const
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if c, ok := scope.Lookup(name).(*gotypes.Const); ok && gotypes.Identical(c.Type(), named) {
+			consts = append(consts, c)
+		}
+	}
+	return consts
+}
+
+// convertEnum accepts either a constant name ("SizeLarge") or a constant value
+// for a named enum type, returning the constant's value
+func convertEnum(named *gotypes.Named, consts []*gotypes.Const, value interface{}, path string) (interface{}, error) {
+	if name, ok := value.(string); ok {
+		for _, c := range consts {
+			if c.Name() == name {
+				return constantJSON(c.Val()), nil
+			}
+		}
+	}
+
+	if literal, ok := constantFromJSON(value); ok {
+		for _, c := range consts {
+			if constant.Compare(c.Val(), token.EQL, literal) {
+				return value, nil
+			}
+		}
+	}
+
+	allowed := make([]string, len(consts))
+	for i, c := range consts {
+		allowed[i] = fmt.Sprintf("%s (%s)", c.Name(), c.Val().ExactString())
+	}
+	return nil, &PropError{
+		Path:    path,
+		Message: fmt.Sprintf("%s is not a valid %s, expected one of: %s", jsonValueString(value), typeString(named), strings.Join(allowed, ", ")),
+	}
+}
+
+// constantJSON converts a constant value to its JSON representation
+func constantJSON(value constant.Value) interface{} {
+	switch value.Kind() {
+	case constant.String:
+		return constant.StringVal(value)
+	case constant.Bool:
+		return constant.BoolVal(value)
+	default:
+		return json.Number(value.ExactString())
+	}
+}
+
+// constantFromJSON converts a JSON scalar to a constant for comparison
+func constantFromJSON(value interface{}) (constant.Value, bool) {
+	switch v := value.(type) {
+	case string:
+		return constant.MakeString(v), true
+	case bool:
+		return constant.MakeBool(v), true
+	case json.Number:
+		if c := constant.MakeFromLiteral(v.String(), token.INT, 0); c.Kind() != constant.Unknown {
+			return c, true
+		}
+		if c := constant.MakeFromLiteral(v.String(), token.FLOAT, 0); c.Kind() != constant.Unknown {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// isTimeType reports whether t is time.Time
+func isTimeType(named *gotypes.Named) bool {
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time"
+}
+
+// hasUnmarshaler reports whether a pointer to the type implements
+// json.Unmarshaler or encoding.TextUnmarshaler
+func hasUnmarshaler(named *gotypes.Named) bool {
+	methods := gotypes.NewMethodSet(gotypes.NewPointer(named))
+	for i := 0; i < methods.Len(); i++ {
+		switch methods.At(i).Obj().Name() {
+		case "UnmarshalJSON", "UnmarshalText":
+			return true
+		}
+	}
+	return false
+}
+
+// mismatch builds the error for a JSON value of the wrong kind
+func mismatch(path, expected string, value interface{}) *PropError {
+	return &PropError{Path: path, Message: fmt.Sprintf("expected %s, got %s", expected, jsonKind(value))}
+}
+
+// jsonKind names the kind of a decoded JSON value
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number, float64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// jsonValueString formats a JSON scalar for error messages
+func jsonValueString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	if number, ok := value.(json.Number); ok {
+		return number.String()
+	}
+	return jsonKind(value)
+}
+
+// typeString formats a type with package names rather than import paths
+func typeString(t gotypes.Type) string {
+	return gotypes.TypeString(t, func(pkg *gotypes.Package) string {
+		return pkg.Name()
+	})
+}
@@ -0,0 +1,195 @@
+package renderer
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// propsFixtureSource declares a component function using the parameter types
+// the playground has to decode
+const propsFixtureSource = `package ui
+
+import "time"
+
+type Variant string
+
+const (
+	VariantPrimary Variant = "primary"
+	VariantDanger  Variant = "danger"
+)
+
+type Size int
+
+const (
+	SizeSmall Size = iota
+	SizeLarge
+)
+
+type Base struct {
+	ID string ` + "`json:\"id\"`" + `
+}
+
+type Link struct {
+	Base
+	Label  string ` + "`json:\"label\"`" + `
+	Href   string ` + "`json:\"href\"`" + `
+	hidden bool
+}
+
+type Author struct {
+	Name  string
+	Email string ` + "`json:\"email,omitempty\"`" + `
+}
+
+type Component interface {
+	Render() error
+}
+
+func Card(title string, count int8, variant Variant, size Size, author *Author, links []Link, meta map[string]int, scores map[int]float64, published time.Time, extra any, child Component) {}
+`
+
+// propsFixtureSignature type-checks propsFixtureSource and returns the
+// signature of Card
+func propsFixtureSignature(t *testing.T) *gotypes.Signature {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "ui.go", propsFixtureSource, 0)
+	require.NoError(t, err)
+
+	conf := gotypes.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("example.com/app/ui", fset, []*ast.File{file}, nil)
+	require.NoError(t, err)
+
+	return pkg.Scope().Lookup("Card").Type().(*gotypes.Signature)
+}
+
+func TestCheckProps(t *testing.T) {
+	sig := propsFixtureSignature(t)
+
+	props := map[string]interface{}{
+		"title":     "Hello",
+		"count":     3,
+		"variant":   "VariantDanger",
+		"size":      "SizeLarge",
+		"author":    map[string]interface{}{"name": "Ada", "email": "ada@example.com"},
+		"links":     []interface{}{map[string]interface{}{"id": "docs", "label": "Docs", "href": "/docs"}},
+		"meta":      map[string]interface{}{"views": 10},
+		"scores":    map[string]interface{}{"1": 9.5},
+		"published": "2024-05-01T10:00:00Z",
+		"extra":     []interface{}{"anything", 1},
+		"child":     nil,
+	}
+
+	checked, err := checkProps(sig, props)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hello", checked["title"])
+	assert.Equal(t, "danger", checked["variant"], "enum names are replaced by their values")
+	assert.Equal(t, json.Number("1"), checked["size"])
+	assert.Equal(t, "2024-05-01T10:00:00Z", checked["published"])
+
+	// Enum values are accepted as well as names
+	checked, err = checkProps(sig, map[string]interface{}{"variant": "primary", "size": 0})
+	require.NoError(t, err)
+	assert.Equal(t, "primary", checked["variant"])
+	assert.Equal(t, json.Number("0"), checked["size"])
+}
+
+func TestCheckProps_Errors(t *testing.T) {
+	sig := propsFixtureSignature(t)
+
+	tests := []struct {
+		name    string
+		props   map[string]interface{}
+		prop    string
+		message string
+	}{
+		{
+			name:    "unknown prop",
+			props:   map[string]interface{}{"subtitle": "x"},
+			prop:    "prop subtitle",
+			message: "unknown prop, the component accepts: title, count",
+		},
+		{
+			name:    "wrong basic type",
+			props:   map[string]interface{}{"title": 42},
+			prop:    "prop title",
+			message: "expected string, got number",
+		},
+		{
+			name:    "integer out of range",
+			props:   map[string]interface{}{"count": 300},
+			prop:    "prop count",
+			message: "300 is not a valid int8",
+		},
+		{
+			name:    "invalid enum",
+			props:   map[string]interface{}{"variant": "ghost"},
+			prop:    "prop variant",
+			message: `"ghost" is not a valid ui.Variant, expected one of: VariantDanger ("danger"), VariantPrimary ("primary")`,
+		},
+		{
+			name:    "nested struct field",
+			props:   map[string]interface{}{"links": []interface{}{map[string]interface{}{"href": true}}},
+			prop:    "prop links[0].href",
+			message: "expected string, got boolean",
+		},
+		{
+			name:    "unknown struct field",
+			props:   map[string]interface{}{"author": map[string]interface{}{"nickname": "x"}},
+			prop:    "prop author",
+			message: `unknown field "nickname" in ui.Author, known fields: Name, email`,
+		},
+		{
+			name:    "struct given as string",
+			props:   map[string]interface{}{"links": []interface{}{"docs"}},
+			prop:    "prop links[0]",
+			message: "expected ui.Link, got string",
+		},
+		{
+			name:    "map value",
+			props:   map[string]interface{}{"meta": map[string]interface{}{"views": "many"}},
+			prop:    `prop meta["views"]`,
+			message: "expected int, got string",
+		},
+		{
+			name:    "map key",
+			props:   map[string]interface{}{"scores": map[string]interface{}{"first": 1}},
+			prop:    `prop scores["first"]`,
+			message: `key "first" is not a valid int`,
+		},
+		{
+			name:    "invalid timestamp",
+			props:   map[string]interface{}{"published": "yesterday"},
+			prop:    "prop published",
+			message: `expected RFC 3339 timestamp, got "yesterday"`,
+		},
+		{
+			name:    "interface value",
+			props:   map[string]interface{}{"child": map[string]interface{}{}},
+			prop:    "prop child",
+			message: "ui.Component values cannot be supplied as JSON, use null",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := checkProps(sig, tt.props)
+			require.Error(t, err)
+
+			var propErr *PropError
+			require.ErrorAs(t, err, &propErr)
+			assert.Contains(t, err.Error(), tt.prop+": ")
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
@@ -9,15 +9,22 @@
 package renderer
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
 
+	"github.com/conneroisu/templar/internal/cache"
 	"github.com/conneroisu/templar/internal/interfaces"
+	"github.com/conneroisu/templar/internal/mockdata"
+	"github.com/conneroisu/templar/internal/tracing"
 	"github.com/conneroisu/templar/internal/types"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -27,6 +34,12 @@ import (
 type ComponentRenderer struct {
 	registry interfaces.ComponentRegistry
 	workDir  string
+	// host renders components from their own packages in a persistent process
+	host *RenderHost
+	// fixtures supply props from preview.mock_data over generated mock data
+	fixtures *mockdata.Fixtures
+	// wrapper names the layout component previews are rendered inside
+	wrapper string
 }
 
 // NewComponentRenderer creates a new component renderer
@@ -40,23 +53,131 @@ func NewComponentRenderer(registry interfaces.ComponentRegistry) *ComponentRende
 	return &ComponentRenderer{
 		registry: registry,
 		workDir:  workDir,
+		host:     NewRenderHost(registry, filepath.Join(workDir, ".host")),
 	}
 }
 
-// RenderComponent renders a specific component with mock data
+// RenderComponent renders a specific component with mock data. The component
+// is looked up by fully qualified ID or by an unambiguous name.
 func (r *ComponentRenderer) RenderComponent(componentName string) (string, error) {
+	return r.RenderComponentWithContext(context.Background(), componentName)
+}
+
+// RenderComponentWithContext renders a component with mock data, recording
+// the render as a span of the trace ctx belongs to
+func (r *ComponentRenderer) RenderComponentWithContext(ctx context.Context, componentName string) (string, error) {
+	component, err := r.resolveComponent(componentName)
+	if err != nil {
+		return "", err
+	}
+
+	props, err := r.mockProps(component, mockdata.DefaultVariant)
+	if err != nil {
+		return "", err
+	}
+
+	return r.render(ctx, component, props)
+}
+
+// SetFixtures sets the prop fixtures merged over generated mock data. A nil
+// store disables fixtures.
+func (r *ComponentRenderer) SetFixtures(fixtures *mockdata.Fixtures) {
+	r.fixtures = fixtures
+}
+
+// SetDiskCache makes the renderer keep generated code and the HTML of
+// components it renders in a disk cache, which survives restarts
+func (r *ComponentRenderer) SetDiskCache(diskCache *cache.DiskCache) {
+	r.host.SetDiskCache(diskCache)
+}
+
+// Fixtures returns the prop fixtures in use, or nil
+func (r *ComponentRenderer) Fixtures() *mockdata.Fixtures {
+	if r == nil {
+		return nil
+	}
+	return r.fixtures
+}
+
+// RenderComponentWithProps renders a component with caller-supplied props,
+// given as decoded JSON values keyed by parameter name. Props are decoded into
+// the component's real parameter types, including structs, pointers, slices,
+// maps, time.Time (as RFC 3339 strings) and named enums (by constant name or
+// value). A prop that does not match its parameter yields a *PropError.
+func (r *ComponentRenderer) RenderComponentWithProps(componentName string, props map[string]interface{}) (string, error) {
+	component, err := r.resolveComponent(componentName)
+	if err != nil {
+		return "", err
+	}
+
+	return r.render(context.Background(), component, props)
+}
+
+// resolveComponent validates a component name or ID and looks it up
+func (r *ComponentRenderer) resolveComponent(componentName string) (*types.ComponentInfo, error) {
 	// Validate component name to prevent path traversal
-	if err := r.validateComponentName(componentName); err != nil {
-		return "", fmt.Errorf("invalid component name: %w", err)
+	if err := r.validateComponentID(componentName); err != nil {
+		return nil, fmt.Errorf("invalid component name: %w", err)
+	}
+
+	component, err := r.registry.Resolve(componentName)
+	if err != nil {
+		return nil, fmt.Errorf("resolving component %s: %w", componentName, err)
+	}
+
+	return component, nil
+}
+
+// render renders a resolved component with the given props
+func (r *ComponentRenderer) render(ctx context.Context, component *types.ComponentInfo, props map[string]interface{}) (html string, err error) {
+	ctx, span := tracing.Start(ctx, "renderer.render")
+	span.SetAttribute("component", component.Name)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	// Components in importable packages are served by the persistent render
+	// host, unless it cannot be built at all
+	if r.host.Supports(component) {
+		span.SetAttribute("render_host", true)
+		html, err = r.host.Render(ctx, component, props)
+		if !errors.Is(err, errHostUnavailable) {
+			return html, err
+		}
+		log.Printf("Rendering %s without the render host: %v", component.ID, err)
+		span.SetAttribute("render_host", false)
 	}
 
-	component, exists := r.registry.Get(componentName)
-	if !exists {
-		return "", fmt.Errorf("component %s not found", componentName)
+	for name := range props {
+		if !hasParameter(component, name) {
+			return "", &PropError{Prop: name, Message: "unknown prop"}
+		}
 	}
 
-	// Create a clean workspace for this component
-	componentWorkDir := filepath.Join(r.workDir, componentName)
+	return r.renderStandalone(component, props)
+}
+
+// hasParameter reports whether the component declares a parameter called name
+func hasParameter(component *types.ComponentInfo, name string) bool {
+	for _, param := range component.Parameters {
+		if param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// renderStandalone renders a component by copying its templ file into a
+// throwaway main package and running it. It is used for components the render
+// host cannot import, such as those declared in package main, and for all
+// components when the render host cannot be built.
+func (r *ComponentRenderer) renderStandalone(component *types.ComponentInfo, props map[string]interface{}) (string, error) {
+	componentName := component.ID
+
+	// Create a clean workspace for this component, one per qualified ID so
+	// that same-named components in different packages never collide
+	componentWorkDir := filepath.Join(r.workDir, workDirName(component))
 
 	// Validate the work directory path before operations
 	if err := r.validateWorkDir(componentWorkDir); err != nil {
@@ -71,11 +192,8 @@ func (r *ComponentRenderer) RenderComponent(componentName string) (string, error
 		return "", fmt.Errorf("failed to create component work directory %s: %w", componentWorkDir, err)
 	}
 
-	// Generate mock data for parameters
-	mockData := r.generateMockData(component)
-
 	// Create a Go file that renders the component
-	goCode, err := r.generateGoCode(component, mockData)
+	goCode, err := r.generateGoCode(component, props)
 	if err != nil {
 		return "", fmt.Errorf("generating Go code: %w", err)
 	}
@@ -106,6 +224,70 @@ func (r *ComponentRenderer) RenderComponent(componentName string) (string, error
 	return html, nil
 }
 
+// Invalidate marks the render host as out of date so that it is rebuilt
+// before the next render. Call it when Go or templ sources change.
+func (r *ComponentRenderer) Invalidate() {
+	if r.host == nil {
+		return
+	}
+	r.host.Invalidate()
+}
+
+// InvalidateFiles invalidates the render host if any of the changed paths is
+// a templ or hand-written Go source. Generated _templ.go files and the host's
+// own module are ignored since the host regenerates them itself.
+func (r *ComponentRenderer) InvalidateFiles(paths []string) {
+	if r.host == nil {
+		return
+	}
+	for _, path := range paths {
+		if r.host.Owns(path) || strings.HasSuffix(path, "_templ.go") {
+			continue
+		}
+		if ext := filepath.Ext(path); ext == ".templ" || ext == ".go" {
+			r.host.Invalidate()
+			return
+		}
+	}
+}
+
+// Close stops the render host process
+func (r *ComponentRenderer) Close() error {
+	if r.host == nil {
+		return nil
+	}
+	return r.host.Close()
+}
+
+// PreviewProps returns the props a component's default preview renders
+// with: generated mock data overridden by the default fixtures
+func (r *ComponentRenderer) PreviewProps(component *types.ComponentInfo) (map[string]interface{}, error) {
+	return r.mockProps(component, mockdata.DefaultVariant)
+}
+
+// mockProps converts generated mock data to render props and merges the
+// fixtures of the given variant over them. Only generated values whose shape
+// matches the parameter type are kept; other parameters are left at their
+// zero value rather than failing to decode.
+func (r *ComponentRenderer) mockProps(component *types.ComponentInfo, variant string) (map[string]interface{}, error) {
+	mockData := r.generateMockData(component)
+	props := make(map[string]interface{}, len(mockData))
+
+	for _, param := range component.Parameters {
+		switch param.Type {
+		case "string", "int", "int64", "int32", "bool", "[]string":
+			props[param.Name] = mockData[param.Name]
+		}
+	}
+
+	props, err := r.fixtures.Apply(component, variant, props)
+	if err != nil {
+		return nil, fmt.Errorf("loading mock data fixtures: %w", err)
+	}
+
+	return props, nil
+}
+
 // generateMockData creates mock data for component parameters
 func (r *ComponentRenderer) generateMockData(component *types.ComponentInfo) map[string]interface{} {
 	mockData := make(map[string]interface{})
@@ -152,7 +334,9 @@ func (r *ComponentRenderer) generateMockString(paramName string) string {
 	}
 }
 
-// generateGoCode creates Go code that renders the component
+// generateGoCode creates Go code that renders the component. Values with a
+// Go literal form are passed directly; otherwise the props are embedded as
+// JSON and decoded into the parameter types at run time.
 func (r *ComponentRenderer) generateGoCode(component *types.ComponentInfo, mockData map[string]interface{}) (string, error) {
 	tmplStr := `package main
 
@@ -196,7 +380,7 @@ func main() {
 
 		switch v := mockValue.(type) {
 		case string:
-			mockValueStr = fmt.Sprintf(`"%s"`, v)
+			mockValueStr = strconv.Quote(v)
 		case int:
 			mockValueStr = fmt.Sprintf("%d", v)
 		case bool:
@@ -205,12 +389,12 @@ func main() {
 			mockValueStr = fmt.Sprintf(`[]string{%s}`, strings.Join(func() []string {
 				var quoted []string
 				for _, s := range v {
-					quoted = append(quoted, fmt.Sprintf(`"%s"`, s))
+					quoted = append(quoted, strconv.Quote(s))
 				}
 				return quoted
 			}(), ", "))
 		default:
-			mockValueStr = fmt.Sprintf(`"%v"`, v)
+			return r.generatePropsGoCode(component, mockData)
 		}
 
 		templateData.Parameters = append(templateData.Parameters, struct {
@@ -230,6 +414,91 @@ func main() {
 	return buf.String(), nil
 }
 
+// generatePropsGoCode creates Go code that decodes JSON props into the
+// component's parameter types using reflection, so no type names need to be
+// spelled out in the generated program
+func (r *ComponentRenderer) generatePropsGoCode(component *types.ComponentInfo, props map[string]interface{}) (string, error) {
+	tmplStr := `package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+)
+
+func main() {
+	ctx := context.Background()
+	fn := reflect.ValueOf({{.ComponentName}})
+	names := []string{ {{- range $i, $name := .Parameters}}{{if $i}}, {{end}}{{printf "%q" $name}}{{end -}} }
+
+	props := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte({{printf "%q" .Props}}), &props); err != nil {
+		fmt.Fprintf(os.Stderr, "Error decoding props: %v\n", err)
+		os.Exit(1)
+	}
+
+	args := make([]reflect.Value, len(names))
+	for i, name := range names {
+		arg := reflect.New(fn.Type().In(i))
+		if raw, ok := props[name]; ok {
+			if err := json.Unmarshal(raw, arg.Interface()); err != nil {
+				fmt.Fprintf(os.Stderr, "Error decoding prop %s: %v\n", name, err)
+				os.Exit(1)
+			}
+		}
+		args[i] = arg.Elem()
+	}
+
+	var results []reflect.Value
+	if fn.Type().IsVariadic() {
+		results = fn.CallSlice(args)
+	} else {
+		results = fn.Call(args)
+	}
+
+	component := results[0].Interface().(interface {
+		Render(context.Context, io.Writer) error
+	})
+	if err := component.Render(ctx, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering component: %v\n", err)
+		os.Exit(1)
+	}
+}
+`
+
+	tmpl, err := template.New("go").Parse(tmplStr)
+	if err != nil {
+		return "", err
+	}
+
+	encodedProps, err := json.Marshal(props)
+	if err != nil {
+		return "", fmt.Errorf("encoding props: %w", err)
+	}
+
+	templateData := struct {
+		ComponentName string
+		Parameters    []string
+		Props         string
+	}{
+		ComponentName: component.Name,
+		Props:         string(encodedProps),
+	}
+	for _, param := range component.Parameters {
+		templateData.Parameters = append(templateData.Parameters, param.Name)
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, templateData); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
 // copyFile copies a file from src to dst
 // copyAndModifyTemplFile copies a templ file and modifies it to use main package
 func (r *ComponentRenderer) copyAndModifyTemplFile(src, dst string) error {
@@ -349,8 +618,18 @@ func (r *ComponentRenderer) RenderComponentWithLayout(componentName string, html
 	return r.RenderComponentWithLayoutAndNonce(componentName, html, "")
 }
 
-// RenderComponentWithLayoutAndNonce wraps component HTML in a full page layout with CSP nonce support
+// RenderComponentWithLayoutAndNonce wraps component HTML in a full page layout with CSP nonce support.
+// The configured preview wrapper is used when set; if it fails to render, the
+// built-in layout is used and the failure is logged.
 func (r *ComponentRenderer) RenderComponentWithLayoutAndNonce(componentName string, html string, nonce string) string {
+	page, ok, err := r.RenderWrapperPage(componentName, html, nonce)
+	if err != nil {
+		log.Printf("Preview wrapper %s failed, using the default layout: %v", r.wrapper, err)
+	}
+	if ok {
+		return page
+	}
+
 	// Generate nonce attributes for inline scripts and styles
 	scriptNonce := ""
 	styleNonce := ""
@@ -398,22 +677,31 @@ func (r *ComponentRenderer) RenderComponentWithLayoutAndNonce(componentName stri
         </div>
     </div>
     
-    <script%s>
-        // WebSocket connection for live reload
-        const ws = new WebSocket('ws://localhost:' + window.location.port + '/ws');
-        ws.onmessage = function(event) {
-            const message = JSON.parse(event.data);
-            if (message.type === 'full_reload') {
-                window.location.reload();
-            }
-        };
-    </script>
+    %s
 </body>
-</html>`, componentName, scriptNonce, styleNonce, componentName, html, scriptNonce)
+</html>`, componentName, scriptNonce, styleNonce, componentName, html, liveReloadScript(nonce))
+}
+
+// workDirName returns a single path element naming the work directory of a
+// component, derived from its qualified ID
+func workDirName(component *types.ComponentInfo) string {
+	id := component.ID
+	if id == "" {
+		id = component.Name
+	}
+
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, id)
 }
 
-// validateComponentName validates component name to prevent path traversal
-func (r *ComponentRenderer) validateComponentName(name string) error {
+// validateComponentID validates a component name or qualified ID to prevent
+// path traversal. Qualified IDs contain forward slashes from their import
+// path, e.g. "github.com/acme/app/ui.Button".
+func (r *ComponentRenderer) validateComponentID(name string) error {
 	// Clean the name
 	cleanName := filepath.Clean(name)
 
@@ -427,9 +715,9 @@ func (r *ComponentRenderer) validateComponentName(name string) error {
 		return fmt.Errorf("absolute path not allowed: %s", name)
 	}
 
-	// Reject names with path separators (should be simple component names)
-	if strings.ContainsRune(cleanName, os.PathSeparator) {
-		return fmt.Errorf("path separators not allowed in component name: %s", name)
+	// Reject backslashes, which never appear in import paths
+	if strings.ContainsRune(name, '\\') {
+		return fmt.Errorf("backslashes not allowed in component name: %s", name)
 	}
 
 	// Reject empty names
@@ -2,6 +2,7 @@ package renderer
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 	"time"
@@ -113,6 +114,115 @@ func TestGenerateGoCode(t *testing.T) {
 	assert.Contains(t, goCode, "component.Render(ctx, os.Stdout)")
 }
 
+func TestGenerateGoCode_StructuredProps(t *testing.T) {
+	reg := registry.NewComponentRegistry()
+	renderer := NewComponentRenderer(reg)
+
+	component := &types.ComponentInfo{
+		Name:    "Card",
+		Package: "main",
+		Parameters: []types.ParameterInfo{
+			{Name: "title", Type: "string"},
+			{Name: "links", Type: "[]Link"},
+		},
+	}
+
+	props := map[string]interface{}{
+		"title": "Docs",
+		"links": []interface{}{map[string]interface{}{"href": "/docs"}},
+	}
+
+	goCode, err := renderer.generateGoCode(component, props)
+	require.NoError(t, err)
+
+	assert.Contains(t, goCode, "fn := reflect.ValueOf(Card)")
+	assert.Contains(t, goCode, `names := []string{"title", "links"}`)
+	assert.Contains(t, goCode, `{\"links\":[{\"href\":\"/docs\"}],\"title\":\"Docs\"}`)
+	assert.Contains(t, goCode, "Error decoding prop %s")
+}
+
+// standaloneStub stands in for the generated templ code of the components
+// the standalone programs render
+const standaloneStub = `package main
+
+import (
+	"context"
+	"io"
+)
+
+type component string
+
+func (c component) Render(ctx context.Context, w io.Writer) error {
+	_, err := io.WriteString(w, string(c))
+	return err
+}
+
+type Link struct {
+	Href string ` + "`json:\"href\"`" + `
+}
+
+func Button(text string, disabled bool) component { return component(text) }
+
+func Card(title string, links []Link) component { return component(title + links[0].Href) }
+`
+
+func TestGenerateGoCode_Compiles(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+
+	reg := registry.NewComponentRegistry()
+	renderer := NewComponentRenderer(reg)
+
+	tests := []struct {
+		name      string
+		component *types.ComponentInfo
+		props     map[string]interface{}
+		expected  string
+	}{
+		{
+			name: "literal values",
+			component: &types.ComponentInfo{Name: "Button", Parameters: []types.ParameterInfo{
+				{Name: "text", Type: "string"},
+				{Name: "disabled", Type: "bool"},
+			}},
+			props:    map[string]interface{}{"text": "Click Me", "disabled": false},
+			expected: "Click Me",
+		},
+		{
+			name: "decoded props",
+			component: &types.ComponentInfo{Name: "Card", Parameters: []types.ParameterInfo{
+				{Name: "title", Type: "string"},
+				{Name: "links", Type: "[]Link"},
+			}},
+			props: map[string]interface{}{
+				"title": "Docs",
+				"links": []interface{}{map[string]interface{}{"href": "/docs"}},
+			},
+			expected: "Docs/docs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goCode, err := renderer.generateGoCode(tt.component, tt.props)
+			require.NoError(t, err)
+
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module standalone\n\ngo 1.21\n"), 0644))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(goCode), 0644))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "components.go"), []byte(standaloneStub), 0644))
+
+			cmd := exec.Command("go", "run", ".")
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=")
+			output, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(output))
+			assert.Equal(t, tt.expected, string(output))
+		})
+	}
+}
+
 func TestCopyAndModifyTemplFile(t *testing.T) {
 	reg := registry.NewComponentRegistry()
 	renderer := NewComponentRenderer(reg)
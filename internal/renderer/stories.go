@@ -0,0 +1,125 @@
+package renderer
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/conneroisu/templar/internal/mockdata"
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// StoryNotFoundError reports a story name a component does not declare
+type StoryNotFoundError struct {
+	Component string
+	Story     string
+	Available []string
+}
+
+func (e *StoryNotFoundError) Error() string {
+	if len(e.Available) == 0 {
+		return fmt.Sprintf("component %s has no stories", e.Component)
+	}
+	return fmt.Sprintf("component %s has no story %q, available stories: %s",
+		e.Component, e.Story, strings.Join(e.Available, ", "))
+}
+
+// FindStory returns the example of component named storyName
+func FindStory(component *types.ComponentInfo, storyName string) (types.ComponentExample, error) {
+	available := make([]string, 0, len(component.Examples))
+	for _, example := range component.Examples {
+		if example.Name == storyName {
+			return example, nil
+		}
+		available = append(available, example.Name)
+	}
+
+	return types.ComponentExample{}, &StoryNotFoundError{
+		Component: component.Name,
+		Story:     storyName,
+		Available: available,
+	}
+}
+
+// RenderStory renders a component with the props of one of its stories,
+// inside the story's wrapper and framed by its background and viewport
+func (r *ComponentRenderer) RenderStory(componentName, storyName string) (string, error) {
+	component, err := r.resolveComponent(componentName)
+	if err != nil {
+		return "", err
+	}
+
+	story, err := FindStory(component, storyName)
+	if err != nil {
+		return "", err
+	}
+
+	props, err := r.StoryProps(component, story)
+	if err != nil {
+		return "", err
+	}
+
+	rendered, err := r.render(context.Background(), component, props)
+	if err != nil {
+		return "", fmt.Errorf("rendering story %s: %w", story.Name, err)
+	}
+
+	return r.PresentStory(component, story, rendered)
+}
+
+// PresentStory places rendered story HTML inside the story's wrapper layout,
+// if it names one, and frames it with StoryFrame
+func (r *ComponentRenderer) PresentStory(component *types.ComponentInfo, story types.ComponentExample, rendered string) (string, error) {
+	if story.Wrapper != "" {
+		layout, err := r.resolveLayout(story.Wrapper)
+		if err == nil && layout == nil {
+			err = fmt.Errorf("layout file %s not found", story.Wrapper)
+		}
+		if err != nil {
+			return "", fmt.Errorf("story %s wrapper: %w", story.Name, err)
+		}
+
+		rendered, err = r.renderLayout(layout, component.Name, rendered, "")
+		if err != nil {
+			return "", fmt.Errorf("story %s wrapper %s: %w", story.Name, layout.ID, err)
+		}
+	}
+
+	return StoryFrame(story, rendered), nil
+}
+
+// StoryProps returns the props a story renders with: the component's
+// fixtures for the variant named after the story, overridden by the story's
+// own props
+func (r *ComponentRenderer) StoryProps(component *types.ComponentInfo, story types.ComponentExample) (map[string]interface{}, error) {
+	fixtureProps, err := r.fixtures.Props(component, story.Name)
+	if err != nil {
+		return nil, fmt.Errorf("loading mock data fixtures: %w", err)
+	}
+
+	return mockdata.MergeProps(fixtureProps, story.Props), nil
+}
+
+// StoryFrame wraps rendered story HTML in an element sized to the story's
+// viewport and painted with its background
+func StoryFrame(story types.ComponentExample, rendered string) string {
+	var style []string
+	if story.Background != "" {
+		style = append(style, "background: "+story.Background)
+	}
+	if story.Viewport.Width > 0 {
+		style = append(style, fmt.Sprintf("width: %dpx", story.Viewport.Width))
+	}
+	if story.Viewport.Height > 0 {
+		style = append(style, fmt.Sprintf("min-height: %dpx", story.Viewport.Height))
+	}
+
+	styleAttr := ""
+	if len(style) > 0 {
+		styleAttr = fmt.Sprintf(` style="%s"`, html.EscapeString(strings.Join(style, "; ")))
+	}
+
+	return fmt.Sprintf(`<div class="templar-story" data-story="%s"%s>%s</div>`,
+		html.EscapeString(story.Name), styleAttr, rendered)
+}
@@ -0,0 +1,44 @@
+package renderer
+
+import (
+	"testing"
+
+	"github.com/conneroisu/templar/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindStory(t *testing.T) {
+	component := &types.ComponentInfo{
+		Name: "Button",
+		Examples: []types.ComponentExample{
+			{Name: "Primary"},
+			{Name: "Danger"},
+		},
+	}
+
+	story, err := FindStory(component, "Danger")
+	require.NoError(t, err)
+	assert.Equal(t, "Danger", story.Name)
+
+	_, err = FindStory(component, "Ghost")
+	var notFound *StoryNotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.EqualError(t, err, `component Button has no story "Ghost", available stories: Primary, Danger`)
+
+	_, err = FindStory(&types.ComponentInfo{Name: "Badge"}, "Primary")
+	assert.EqualError(t, err, "component Badge has no stories")
+}
+
+func TestStoryFrame(t *testing.T) {
+	framed := StoryFrame(types.ComponentExample{
+		Name:       `Dark "mode"`,
+		Background: "#111827",
+		Viewport:   types.ExampleViewport{Width: 375, Height: 667},
+	}, "<button>Save</button>")
+
+	assert.Equal(t, `<div class="templar-story" data-story="Dark &#34;mode&#34;" style="background: #111827; width: 375px; min-height: 667px"><button>Save</button></div>`, framed)
+
+	plain := StoryFrame(types.ComponentExample{Name: "Primary"}, "<button>Save</button>")
+	assert.Equal(t, `<div class="templar-story" data-story="Primary"><button>Save</button></div>`, plain)
+}
@@ -1,8 +1,8 @@
 // Package scanner provides component discovery and analysis for templ templates.
 //
 // The scanner traverses file systems to find .templ files, parses them using
-// Go's AST parser to extract component metadata including parameters, dependencies,
-// and documentation. It integrates with the component registry to broadcast
+// the templ parser to extract component metadata including parameters, receivers,
+// doc comments and source positions. It integrates with the component registry to broadcast
 // change events and supports recursive directory scanning with exclude patterns.
 // The scanner maintains file hashes for change detection and provides both
 // single-file and batch scanning capabilities.
@@ -26,11 +26,14 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/conneroisu/templar/internal/cache"
 	"github.com/conneroisu/templar/internal/config"
 	"github.com/conneroisu/templar/internal/errors"
 	"github.com/conneroisu/templar/internal/interfaces"
 	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/tracing"
 	"github.com/conneroisu/templar/internal/types"
+	"github.com/conneroisu/templar/pkg/stories"
 )
 
 // crcTable is a pre-computed CRC32 Castagnoli table for faster hash generation
@@ -144,11 +147,11 @@ type ScanWorker struct {
 	stop chan struct{}
 }
 
-// ComponentScanner discovers and parses templ components using Go's AST parser.
+// ComponentScanner discovers and parses templ components using the templ parser.
 //
 // The scanner provides:
 // - Recursive directory traversal with exclude patterns
-// - AST-based component metadata extraction
+// - templ AST-based component metadata extraction
 // - Concurrent processing via worker pool
 // - Integration with component registry for event broadcasting
 // - File change detection using CRC32 hashing
@@ -168,6 +171,9 @@ type ComponentScanner struct {
 	bufferPool *BufferPool
 	// metadataCache caches parsed component metadata by file hash to avoid re-parsing unchanged files
 	metadataCache *MetadataCache
+	// diskCache keeps parsed component metadata across runs, keyed by file
+	// content; nil when disabled
+	diskCache *cache.DiskCache
 	// astParsingPool provides concurrent AST parsing to avoid blocking worker threads
 	astParsingPool *ASTParsingPool
 	// metrics tracks performance metrics during scanning operations
@@ -232,7 +238,11 @@ func (p *ASTParsingPool) worker() {
 	
 	for {
 		select {
-		case job := <-p.jobChan:
+		case job, ok := <-p.jobChan:
+			if !ok {
+				return
+			}
+
 			// Parse the AST
 			astFile, err := parser.ParseFile(job.fileSet, job.filePath, job.content, parser.ParseComments)
 			
@@ -651,6 +661,13 @@ func (s *ComponentScanner) Close() error {
 	return nil
 }
 
+// SetDiskCache makes the scanner keep parsed component metadata in a disk
+// cache, so unchanged files are not parsed again after a restart. It must be
+// called before scanning starts.
+func (s *ComponentScanner) SetDiskCache(diskCache *cache.DiskCache) {
+	s.diskCache = diskCache
+}
+
 // getCachedMetadata attempts to retrieve cached component metadata for a file
 func (s *ComponentScanner) getCachedMetadata(filePath, fileHash string) (*CachedComponentMetadata, bool) {
 	if s.metadataCache == nil {
@@ -700,6 +717,47 @@ func (s *ComponentScanner) setCachedMetadata(filePath, fileHash string, componen
 	s.metadataCache.Set(cacheKey, data)
 }
 
+// getDiskCachedMetadata looks up the component metadata of a file's content
+// in the disk cache, adding it to the in-memory cache when found
+func (s *ComponentScanner) getDiskCachedMetadata(filePath, fileHash string, content []byte) (*CachedComponentMetadata, bool) {
+	if s.diskCache == nil {
+		return nil, false
+	}
+
+	data, found := s.diskCache.Get(cache.NamespaceScan, cache.ContentKey(filePath, content))
+	if !found {
+		return nil, false
+	}
+
+	var metadata CachedComponentMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, false
+	}
+	metadata.FileHash = fileHash
+
+	s.setCachedMetadata(filePath, fileHash, metadata.Components)
+	return &metadata, true
+}
+
+// setDiskCachedMetadata stores the component metadata of a file's content in
+// the disk cache
+func (s *ComponentScanner) setDiskCachedMetadata(filePath string, content []byte, components []*types.ComponentInfo) {
+	if s.diskCache == nil {
+		return
+	}
+
+	data, err := json.Marshal(CachedComponentMetadata{
+		Components: components,
+		ParsedAt:   time.Now(),
+	})
+	if err != nil {
+		return
+	}
+
+	// A failed write only costs a parse on the next run
+	_ = s.diskCache.Set(cache.NamespaceScan, cache.ContentKey(filePath, content), data)
+}
+
 // ScanDirectory scans a directory for templ components using optimized worker pool with timeout support
 func (s *ComponentScanner) ScanDirectoryWithContext(ctx context.Context, dir string) error {
 	start := time.Now()
@@ -724,6 +782,14 @@ func (s *ComponentScanner) ScanDirectoryWithContext(ctx context.Context, dir str
 
 	// Process files using persistent worker pool with context (no goroutine creation overhead)
 	err = s.processBatchWithWorkerPoolWithContext(ctx, files)
+
+	// Re-resolve dependencies now that every component in the tree is registered,
+	// so references to components scanned later in the batch are not missed
+	if len(files) > 0 {
+		if depErr := s.registry.UpdateAllDependencies(); depErr != nil && err == nil {
+			err = depErr
+		}
+	}
 	
 	// Update metrics
 	if s.metrics != nil {
@@ -758,6 +824,42 @@ func (s *ComponentScanner) ScanDirectory(dir string) error {
 	return s.ScanDirectoryWithContext(ctx, dir)
 }
 
+// ReconcileDirectory rescans a directory and removes the registered
+// components under it that are no longer on disk: those whose file was
+// deleted, and those a successful rescan of their templ file did not find.
+// It recovers the registry from file events that were never delivered.
+func (s *ComponentScanner) ReconcileDirectory(dir string) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("resolving directory %s: %w", dir, err)
+	}
+
+	// Rescanned components are registered anew, so those still registered
+	// as before were not found
+	before := make(map[string]*types.ComponentInfo)
+	for _, component := range s.registry.GetAll() {
+		if path, err := filepath.Abs(component.FilePath); err == nil && strings.HasPrefix(path, absDir+string(filepath.Separator)) {
+			before[component.ID] = component
+		}
+	}
+
+	scanErr := s.ScanDirectory(dir)
+
+	current := s.registry.GetAllMap()
+	for id, component := range before {
+		if current[id] != component {
+			continue
+		}
+		_, statErr := os.Stat(component.FilePath)
+		missing := os.IsNotExist(statErr)
+		if missing || scanErr == nil && strings.HasSuffix(component.FilePath, ".templ") {
+			s.registry.Remove(id)
+		}
+	}
+
+	return scanErr
+}
+
 // processBatchWithWorkerPoolWithContext processes files using the persistent worker pool with optimized batching and context support
 func (s *ComponentScanner) processBatchWithWorkerPoolWithContext(ctx context.Context, files []string) error {
 	if len(files) == 0 {
@@ -860,8 +962,26 @@ func (s *ComponentScanner) ScanDirectoryParallel(dir string, workers int) error
 	return s.ScanDirectory(dir) // Use optimized version
 }
 
-// ScanFile scans a single file for templ components (optimized)
+// ScanFile scans a single file for templ components (optimized). Story
+// files rescan the templ file they belong to.
 func (s *ComponentScanner) ScanFile(path string) error {
+	return s.ScanFileWithContext(context.Background(), path)
+}
+
+// ScanFileWithContext scans a single file, recording the scan as a span of
+// the trace ctx belongs to
+func (s *ComponentScanner) ScanFileWithContext(ctx context.Context, path string) (err error) {
+	if templPath := stories.TemplFile(path); templPath != "" {
+		path = templPath
+	}
+
+	_, span := tracing.Start(ctx, "scanner.scan_file")
+	span.SetAttribute("file", path)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	return s.scanFileInternal(path)
 }
 
@@ -922,20 +1042,33 @@ func (s *ComponentScanner) scanFileInternal(path string) error {
 		_ = hashStrategy
 	}
 	
-	// Check cache first - avoid expensive parsing if metadata is cached
-	if cachedMetadata, found := s.getCachedMetadata(cleanPath, hash); found {
+	// Check cache first - avoid expensive parsing if metadata is cached,
+	// falling back to metadata cached on disk by earlier runs
+	cachedMetadata, found := s.getCachedMetadata(cleanPath, hash)
+	if !found {
+		cachedMetadata, found = s.getDiskCachedMetadata(cleanPath, hash, content)
+	}
+	if found {
 		// Track cache hit
 		if s.metrics != nil {
 			atomic.AddInt64(&s.metrics.CacheHits, 1)
 		}
 		
-		// Register all cached components with the registry
-		for _, component := range cachedMetadata.Components {
-			// Update file modification time to current scan time
+		// Update file modification time to current scan time
+		components := make([]*types.ComponentInfo, len(cachedMetadata.Components))
+		for i, component := range cachedMetadata.Components {
 			updatedComponent := *component
 			updatedComponent.LastMod = info.ModTime()
 			updatedComponent.Hash = hash
-			s.registry.Register(&updatedComponent)
+			components[i] = &updatedComponent
+		}
+
+		// Story files are not part of the templ file hash, so reload them
+		storyErr := s.attachFileStories(cleanPath, components)
+
+		// Register all cached components with the registry
+		for _, component := range components {
+			s.registry.Register(component)
 		}
 		
 		// Track components found
@@ -943,7 +1076,7 @@ func (s *ComponentScanner) scanFileInternal(path string) error {
 			atomic.AddInt64(&s.metrics.ComponentsFound, int64(len(cachedMetadata.Components)))
 		}
 		
-		return nil
+		return storyErr
 	}
 
 	// Track cache miss
@@ -951,22 +1084,25 @@ func (s *ComponentScanner) scanFileInternal(path string) error {
 		atomic.AddInt64(&s.metrics.CacheMisses, 1)
 	}
 
-	// Cache miss - perform parsing with async AST parsing to avoid blocking worker threads
+	// Cache miss - parse templ files with the templ parser and generated Go
+	// files with the Go AST parser
 	var components []*types.ComponentInfo
-	
-	// Use async AST parsing to avoid blocking the worker thread
-	astResultChan := s.astParsingPool.ParseAsync(cleanPath, content, s.fileSet)
-	
-	// Wait for AST parsing result (non-blocking for the worker thread)
-	astResult := <-astResultChan
-	
-	if astResult.err != nil {
-		// If AST parsing fails, try manual component extraction for .templ files
+
+	if strings.HasSuffix(cleanPath, ".templ") {
 		components, err = s.parseTemplFileWithComponents(cleanPath, content, hash, info.ModTime())
 		if err != nil {
 			return err
 		}
 	} else {
+		// Use async AST parsing to avoid blocking the worker thread
+		astResultChan := s.astParsingPool.ParseAsync(cleanPath, content, s.fileSet)
+
+		// Wait for AST parsing result (non-blocking for the worker thread)
+		astResult := <-astResultChan
+		if astResult.err != nil {
+			return fmt.Errorf("parsing Go file %s: %w", cleanPath, astResult.err)
+		}
+
 		// Extract components from AST
 		components, err = s.extractFromASTWithComponents(cleanPath, astResult.astFile, hash, info.ModTime())
 		if err != nil {
@@ -974,8 +1110,12 @@ func (s *ComponentScanner) scanFileInternal(path string) error {
 		}
 	}
 
-	// Cache the parsed components for future scans
+	// Cache the parsed components for future scans and runs
 	s.setCachedMetadata(cleanPath, hash, components)
+	s.setDiskCachedMetadata(cleanPath, content, components)
+
+	// A broken story file is reported without hiding the components themselves
+	storyErr := s.attachFileStories(cleanPath, components)
 
 	// Register all components with the registry
 	for _, component := range components {
@@ -987,7 +1127,16 @@ func (s *ComponentScanner) scanFileInternal(path string) error {
 		atomic.AddInt64(&s.metrics.ComponentsFound, int64(len(components)))
 	}
 
-	return nil
+	return storyErr
+}
+
+// attachFileStories loads the story files of a templ file into the Examples
+// of its components. Generated Go files carry no stories.
+func (s *ComponentScanner) attachFileStories(path string, components []*types.ComponentInfo) error {
+	if !strings.HasSuffix(path, ".templ") {
+		return nil
+	}
+	return attachStories(path, components)
 }
 
 // readFileStreaming removed - replaced by readFileStreamingOptimized
@@ -1028,55 +1177,6 @@ func (s *ComponentScanner) readFileStreamingOptimized(file *os.File, size int64,
 
 // Backward compatibility method removed - unused
 
-// parseTemplFileWithComponents extracts components from templ files and returns them
-func (s *ComponentScanner) parseTemplFileWithComponents(path string, content []byte, hash string, modTime time.Time) ([]*types.ComponentInfo, error) {
-	var components []*types.ComponentInfo
-	lines := strings.Split(string(content), "\n")
-	packageName := ""
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Extract package name
-		if strings.HasPrefix(line, "package ") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				packageName = sanitizeIdentifier(parts[1])
-			}
-		}
-
-		// Extract templ component declarations
-		if strings.HasPrefix(line, "templ ") {
-			// Extract component name from templ declaration
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				name := parts[1]
-				if idx := strings.Index(name, "("); idx != -1 {
-					name = name[:idx]
-				}
-
-				// Sanitize component name to prevent injection
-				name = sanitizeIdentifier(name)
-
-				component := &types.ComponentInfo{
-					Name:         name,
-					Package:      packageName,
-					FilePath:     path,
-					Parameters:   extractParameters(line),
-					Imports:      []string{},
-					LastMod:      modTime,
-					Hash:         hash,
-					Dependencies: []string{},
-				}
-
-				components = append(components, component)
-			}
-		}
-	}
-
-	return components, nil
-}
-
 // parseTemplFile provides backward compatibility - delegates to the new component-returning version
 func (s *ComponentScanner) parseTemplFile(path string, content []byte, hash string, modTime time.Time) error {
 	components, err := s.parseTemplFileWithComponents(path, content, hash, modTime)
@@ -1156,29 +1256,7 @@ func (s *ComponentScanner) isTemplComponent(fn *ast.FuncDecl) bool {
 }
 
 func (s *ComponentScanner) extractParametersFromFunc(fn *ast.FuncDecl) []types.ParameterInfo {
-	var params []types.ParameterInfo
-
-	if fn.Type.Params == nil {
-		return params
-	}
-
-	for _, param := range fn.Type.Params.List {
-		paramType := ""
-		if param.Type != nil {
-			paramType = s.typeToString(param.Type)
-		}
-
-		for _, name := range param.Names {
-			params = append(params, types.ParameterInfo{
-				Name:     name.Name,
-				Type:     paramType,
-				Optional: false,
-				Default:  nil,
-			})
-		}
-	}
-
-	return params
+	return fieldListToParameters(fn.Type.Params)
 }
 
 func (s *ComponentScanner) extractImports(astFile *ast.File) []string {
@@ -1186,78 +1264,29 @@ func (s *ComponentScanner) extractImports(astFile *ast.File) []string {
 
 	for _, imp := range astFile.Imports {
 		if imp.Path != nil {
-			imports = append(imports, imp.Path.Value)
+			if path, err := strconv.Unquote(imp.Path.Value); err == nil {
+				imports = append(imports, path)
+			}
 		}
 	}
 
 	return imports
 }
 
-func (s *ComponentScanner) typeToString(expr ast.Expr) string {
-	switch e := expr.(type) {
-	case *ast.Ident:
-		return e.Name
-	case *ast.SelectorExpr:
-		return s.typeToString(e.X) + "." + e.Sel.Name
-	case *ast.StarExpr:
-		return "*" + s.typeToString(e.X)
-	case *ast.ArrayType:
-		return "[]" + s.typeToString(e.Elt)
-	default:
-		return "unknown"
-	}
-}
-
+// extractParameters extracts parameters from a single-line templ declaration
+// such as "templ Button(text string) {". Files are parsed with the templ parser;
+// this helper remains for callers that only have the declaration line.
 func extractParameters(line string) []types.ParameterInfo {
-	// Simple parameter extraction from templ declaration
-	// This is a basic implementation - real parser would be more robust
-	if !strings.Contains(line, "(") {
-		return []types.ParameterInfo{}
-	}
+	signature := strings.TrimSpace(line)
+	signature = strings.TrimPrefix(signature, "templ ")
+	signature = strings.TrimSpace(strings.TrimSuffix(signature, "{"))
 
-	start := strings.Index(line, "(")
-	end := strings.LastIndex(line, ")")
-	if start == -1 || end == -1 || start >= end {
-		return []types.ParameterInfo{}
-	}
-
-	paramStr := line[start+1 : end]
-	if strings.TrimSpace(paramStr) == "" {
+	sig, err := parseTemplSignature(signature)
+	if err != nil {
 		return []types.ParameterInfo{}
 	}
 
-	// Basic parameter parsing - handle both "name type" and "name, name type" patterns
-	parts := strings.Split(paramStr, ",")
-	var params []types.ParameterInfo
-
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if part == "" {
-			continue
-		}
-
-		// Split by space to get name and type
-		fields := strings.Fields(part)
-		if len(fields) >= 2 {
-			// Handle "name type" format
-			params = append(params, types.ParameterInfo{
-				Name:     fields[0],
-				Type:     fields[1],
-				Optional: false,
-				Default:  nil,
-			})
-		} else if len(fields) == 1 {
-			// Handle single parameter name (type might be from previous param)
-			params = append(params, types.ParameterInfo{
-				Name:     fields[0],
-				Type:     "string", // Default type
-				Optional: false,
-				Default:  nil,
-			})
-		}
-	}
-
-	return params
+	return sig.params
 }
 
 // sanitizeIdentifier removes dangerous characters from identifiers
@@ -4,7 +4,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/conneroisu/templar/internal/cache"
 	"github.com/conneroisu/templar/internal/registry"
 	"github.com/conneroisu/templar/internal/types"
 	"github.com/stretchr/testify/assert"
@@ -77,6 +79,41 @@ templ Card(title string, content string) {
 	assert.Equal(t, "string", card.Parameters[1].Type)
 }
 
+func TestScanFileDiskCache(t *testing.T) {
+	diskCache, err := cache.Open(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	templFile := "test_disk_cache.templ"
+	content := "package components\n\ntempl Badge(label string) {\n\t<span>{label}</span>\n}\n"
+	require.NoError(t, os.WriteFile(templFile, []byte(content), 0644))
+	defer os.Remove(templFile)
+
+	scanner := NewComponentScanner(registry.NewComponentRegistry())
+	scanner.SetDiskCache(diskCache)
+	require.NoError(t, scanner.ScanFile(templFile))
+	assert.Equal(t, int64(0), scanner.GetMetrics().CacheHits)
+
+	// A new scanner, as after a restart, finds the file's components on disk
+	reg := registry.NewComponentRegistry()
+	restarted := NewComponentScanner(reg)
+	restarted.SetDiskCache(diskCache)
+	require.NoError(t, restarted.ScanFile(templFile))
+	assert.Equal(t, int64(1), restarted.GetMetrics().CacheHits)
+
+	badge, exists := reg.Get("Badge")
+	require.True(t, exists)
+	assert.Equal(t, templFile, badge.FilePath)
+	require.Len(t, badge.Parameters, 1)
+	assert.Equal(t, "label", badge.Parameters[0].Name)
+
+	// Changed content is parsed again
+	require.NoError(t, os.WriteFile(templFile, []byte(content+"\ntempl Tag() {\n}\n"), 0644))
+	changed := NewComponentScanner(registry.NewComponentRegistry())
+	changed.SetDiskCache(diskCache)
+	require.NoError(t, changed.ScanFile(templFile))
+	assert.Equal(t, int64(0), changed.GetMetrics().CacheHits)
+}
+
 func TestScanDirectory(t *testing.T) {
 	reg := registry.NewComponentRegistry()
 	scanner := NewComponentScanner(reg)
@@ -134,6 +171,36 @@ templ Card(title string) {
 	assert.Equal(t, "Card", card.Name)
 }
 
+func TestReconcileDirectory(t *testing.T) {
+	reg := registry.NewComponentRegistry()
+	scanner := NewComponentScanner(reg)
+
+	tempDir := "test_reconcile_dir"
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "cards"), 0755))
+	defer os.RemoveAll(tempDir)
+
+	buttonFile := filepath.Join(tempDir, "button.templ")
+	cardFile := filepath.Join(tempDir, "cards", "card.templ")
+	require.NoError(t, os.WriteFile(buttonFile, []byte("package components\n\ntempl Button() {\n\t<button></button>\n}\n\ntempl Link() {\n\t<a></a>\n}\n"), 0644))
+	require.NoError(t, os.WriteFile(cardFile, []byte("package cards\n\ntempl Card() {\n\t<div></div>\n}\n"), 0644))
+	require.NoError(t, scanner.ScanDirectory(tempDir))
+	require.Equal(t, 3, reg.Count())
+
+	// Changes whose events were lost: a removed component, a deleted file
+	// and a new one
+	require.NoError(t, os.WriteFile(buttonFile, []byte("package components\n\ntempl Button() {\n\t<button></button>\n}\n"), 0644))
+	require.NoError(t, os.Remove(cardFile))
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "cards", "badge.templ"), []byte("package cards\n\ntempl Badge() {\n\t<span></span>\n}\n"), 0644))
+
+	require.NoError(t, scanner.ReconcileDirectory(tempDir))
+
+	names := make([]string, 0, reg.Count())
+	for _, component := range reg.GetAll() {
+		names = append(names, component.Name)
+	}
+	assert.ElementsMatch(t, []string{"Button", "Badge"}, names)
+}
+
 func TestScanFileWithInvalidPath(t *testing.T) {
 	reg := registry.NewComponentRegistry()
 	scanner := NewComponentScanner(reg)
@@ -228,3 +295,110 @@ func TestExtractParameters(t *testing.T) {
 
 // TestIsTemplComponent is removed as it requires complex AST setup
 // The method is tested indirectly through the scanning tests
+
+func TestParseTemplFileWithComponents(t *testing.T) {
+	reg := registry.NewComponentRegistry()
+	scanner := NewComponentScanner(reg)
+	defer scanner.Close()
+
+	content := `package ui
+
+import (
+	"time"
+
+	"example.com/app/models"
+)
+
+// Button renders a clickable button.
+// It supports custom handlers.
+templ Button(
+	text string,
+	onClick func() error,
+	user *models.User,
+) {
+	<button>{ text }</button>
+}
+
+// Detached comment that does not document Card.
+
+templ (c Card) Render(at time.Time) {
+	<div>{ c.Title }</div>
+}
+
+templ List[T any](items []T, lookup map[string]int) {
+	for _, item := range items {
+		@Item(item)
+	}
+}
+
+css primary() {
+	color: red;
+}
+
+script greet(name string) {
+	alert(name);
+}
+`
+
+	components, err := scanner.parseTemplFileWithComponents("ui.templ", []byte(content), "hash", time.Now())
+	require.NoError(t, err)
+	require.Len(t, components, 5)
+
+	byName := make(map[string]*types.ComponentInfo)
+	for _, component := range components {
+		byName[component.Name] = component
+	}
+
+	button := byName["Button"]
+	require.NotNil(t, button)
+	assert.Equal(t, "ui", button.Package)
+	assert.Equal(t, types.ComponentKindTempl, button.Kind)
+	assert.Equal(t, "Button renders a clickable button.\nIt supports custom handlers.", button.Description)
+	assert.Equal(t, 11, button.Line)
+	assert.Equal(t, 1, button.Column)
+	assert.True(t, button.IsExported)
+	assert.True(t, button.IsRenderable)
+	assert.Equal(t, []string{"time", "example.com/app/models"}, button.Imports)
+	require.Len(t, button.Parameters, 3)
+	assert.Equal(t, "func() error", button.Parameters[1].Type)
+	assert.Equal(t, "*models.User", button.Parameters[2].Type)
+	assert.True(t, button.Parameters[2].Optional)
+
+	render := byName["Render"]
+	require.NotNil(t, render)
+	assert.Empty(t, render.Description)
+	require.NotNil(t, render.Receiver)
+	assert.Equal(t, "c", render.Receiver.Name)
+	assert.Equal(t, "Card", render.Receiver.Type)
+	assert.False(t, render.IsRenderable)
+	require.Len(t, render.Parameters, 1)
+	assert.Equal(t, "time.Time", render.Parameters[0].Type)
+
+	list := byName["List"]
+	require.NotNil(t, list)
+	require.Len(t, list.TypeParameters, 1)
+	assert.Equal(t, "T", list.TypeParameters[0].Name)
+	assert.Equal(t, "any", list.TypeParameters[0].Type)
+	assert.Equal(t, "[]T", list.Parameters[0].Type)
+	assert.Equal(t, "map[string]int", list.Parameters[1].Type)
+
+	css := byName["primary"]
+	require.NotNil(t, css)
+	assert.Equal(t, types.ComponentKindCSS, css.Kind)
+	assert.False(t, css.IsExported)
+
+	script := byName["greet"]
+	require.NotNil(t, script)
+	assert.Equal(t, types.ComponentKindScript, script.Kind)
+	require.Len(t, script.Parameters, 1)
+	assert.Equal(t, "name", script.Parameters[0].Name)
+}
+
+func TestParseTemplFileWithComponents_InvalidTemplate(t *testing.T) {
+	reg := registry.NewComponentRegistry()
+	scanner := NewComponentScanner(reg)
+	defer scanner.Close()
+
+	_, err := scanner.parseTemplFileWithComponents("broken.templ", []byte("package ui\n\ntempl Broken() {\n\t<div>\n}\n"), "hash", time.Now())
+	assert.Error(t, err)
+}
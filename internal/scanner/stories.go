@@ -0,0 +1,458 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/conneroisu/templar/internal/types"
+	"github.com/conneroisu/templar/pkg/stories"
+)
+
+// storiesImportPath is the import path Go story files register stories with
+const storiesImportPath = "github.com/conneroisu/templar/pkg/stories"
+
+// safeBackgroundPattern limits story backgrounds to colors and simple CSS
+// functions so they can be placed in a style attribute
+var safeBackgroundPattern = regexp.MustCompile(`^[#A-Za-z0-9(),.%\s-]+$`)
+
+// attachStories loads the story files accompanying a templ file and sets the
+// Examples of the components it declares. Components without stories have
+// their Examples cleared so removed stories disappear on rescan.
+func attachStories(templPath string, components []*types.ComponentInfo) error {
+	byName := make(map[string]*types.ComponentInfo, len(components))
+	for _, component := range components {
+		component.Examples = nil
+		byName[component.Name] = component
+	}
+
+	for _, storyPath := range stories.SidecarFiles(templPath) {
+		content, err := os.ReadFile(storyPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("reading story file %s: %w", storyPath, err)
+		}
+
+		declared, err := parseStoryFile(storyPath, content, components)
+		if err != nil {
+			return err
+		}
+
+		// Attach in a stable order so repeated scans produce the same examples
+		names := make([]string, 0, len(declared))
+		for name := range declared {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			component, ok := byName[name]
+			if !ok {
+				return fmt.Errorf("story file %s: unknown component %q, %s declares: %s",
+					storyPath, name, filepath.Base(templPath), componentNames(components))
+			}
+
+			for _, story := range declared[name] {
+				example, err := storyExample(storyPath, story)
+				if err != nil {
+					return err
+				}
+				for _, existing := range component.Examples {
+					if existing.Name == example.Name {
+						return fmt.Errorf("story file %s: duplicate story %q for component %s", storyPath, example.Name, name)
+					}
+				}
+				component.Examples = append(component.Examples, example)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseStoryFile decodes a story file into stories keyed by component name
+func parseStoryFile(path string, content []byte, components []*types.ComponentInfo) (map[string][]stories.Story, error) {
+	if strings.HasSuffix(path, ".go") {
+		return parseGoStories(path, content)
+	}
+
+	var file stories.File
+	if strings.HasSuffix(path, ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&file); err != nil {
+			return nil, fmt.Errorf("parsing story file %s: %w", path, err)
+		}
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&file); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("parsing story file %s: %w", path, err)
+		}
+	}
+
+	declared := make(map[string][]stories.Story, len(file.Components)+1)
+	for name, componentStories := range file.Components {
+		declared[name] = append(declared[name], componentStories...)
+	}
+
+	if len(file.Stories) > 0 {
+		name := file.Component
+		if name == "" {
+			if len(components) != 1 {
+				return nil, fmt.Errorf("story file %s: set component to one of: %s", path, componentNames(components))
+			}
+			name = components[0].Name
+		}
+		declared[name] = append(declared[name], file.Stories...)
+	}
+
+	return declared, nil
+}
+
+// storyExample validates a story and converts it to a component example
+func storyExample(path string, story stories.Story) (types.ComponentExample, error) {
+	if strings.TrimSpace(story.Name) == "" {
+		return types.ComponentExample{}, fmt.Errorf("story file %s: story without a name", path)
+	}
+	if story.Viewport.Width < 0 || story.Viewport.Height < 0 {
+		return types.ComponentExample{}, fmt.Errorf("story file %s: story %q has a negative viewport", path, story.Name)
+	}
+	if story.Background != "" && !safeBackgroundPattern.MatchString(story.Background) {
+		return types.ComponentExample{}, fmt.Errorf("story file %s: story %q has an invalid background %q", path, story.Name, story.Background)
+	}
+
+	return types.ComponentExample{
+		Name:        story.Name,
+		Description: story.Description,
+		Props:       story.Props,
+		Viewport: types.ExampleViewport{
+			Width:  story.Viewport.Width,
+			Height: story.Viewport.Height,
+		},
+		Wrapper:    story.Wrapper,
+		Background: story.Background,
+		Notes:      story.Notes,
+		Source:     path,
+	}, nil
+}
+
+func componentNames(components []*types.ComponentInfo) string {
+	names := make([]string, len(components))
+	for i, component := range components {
+		names[i] = component.Name
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// parseGoStories extracts the stories.Register calls of a Go story file.
+// The file is never executed, so every story field must be a literal.
+func parseGoStories(path string, content []byte) (map[string][]stories.Story, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, content, 0)
+	if err != nil {
+		return nil, fmt.Errorf("parsing story file %s: %w", path, err)
+	}
+
+	pkgName := ""
+	for _, imp := range file.Imports {
+		if importPath, _ := strconv.Unquote(imp.Path.Value); importPath == storiesImportPath {
+			pkgName = "stories"
+			if imp.Name != nil {
+				pkgName = imp.Name.Name
+			}
+		}
+	}
+
+	declared := make(map[string][]stories.Story)
+	if pkgName == "" {
+		return declared, nil
+	}
+
+	p := &goStoryParser{fset: fset, pkgName: pkgName}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok || !p.isPackageSelector(call.Fun, "Register") {
+			return true
+		}
+
+		var component string
+		var registered []stories.Story
+		component, registered, err = p.register(call)
+		declared[component] = append(declared[component], registered...)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return declared, nil
+}
+
+// goStoryParser evaluates the literal arguments of stories.Register calls
+type goStoryParser struct {
+	fset    *token.FileSet
+	pkgName string
+}
+
+func (p *goStoryParser) errorf(node ast.Node, format string, args ...interface{}) error {
+	return fmt.Errorf("%s: %s", p.fset.Position(node.Pos()), fmt.Sprintf(format, args...))
+}
+
+// isPackageSelector reports whether expr is pkgName.name
+func (p *goStoryParser) isPackageSelector(expr ast.Expr, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == p.pkgName
+}
+
+func (p *goStoryParser) register(call *ast.CallExpr) (string, []stories.Story, error) {
+	if len(call.Args) == 0 {
+		return "", nil, p.errorf(call, "stories.Register needs a component name")
+	}
+
+	component, err := p.stringValue(call.Args[0])
+	if err != nil {
+		return "", nil, p.errorf(call.Args[0], "stories.Register component name must be a string literal")
+	}
+
+	storyArgs := call.Args[1:]
+	if call.Ellipsis.IsValid() && len(storyArgs) == 1 {
+		// stories.Register("Button", []stories.Story{...}...)
+		lit, ok := storyArgs[0].(*ast.CompositeLit)
+		if !ok {
+			return "", nil, p.errorf(storyArgs[0], "stories must be a literal []stories.Story")
+		}
+		storyArgs = lit.Elts
+	}
+
+	registered := make([]stories.Story, 0, len(storyArgs))
+	for _, arg := range storyArgs {
+		story, err := p.story(arg)
+		if err != nil {
+			return "", nil, err
+		}
+		registered = append(registered, story)
+	}
+
+	return component, registered, nil
+}
+
+func (p *goStoryParser) story(expr ast.Expr) (stories.Story, error) {
+	var story stories.Story
+
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return story, p.errorf(expr, "story must be a stories.Story literal")
+	}
+
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return story, p.errorf(elt, "stories.Story fields must be named")
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			return story, p.errorf(kv.Key, "stories.Story fields must be named")
+		}
+
+		var err error
+		switch key.Name {
+		case "Name":
+			story.Name, err = p.stringValue(kv.Value)
+		case "Description":
+			story.Description, err = p.stringValue(kv.Value)
+		case "Wrapper":
+			story.Wrapper, err = p.stringValue(kv.Value)
+		case "Background":
+			story.Background, err = p.stringValue(kv.Value)
+		case "Notes":
+			story.Notes, err = p.stringValue(kv.Value)
+		case "Props":
+			var value interface{}
+			value, err = p.literal(kv.Value)
+			if err == nil {
+				props, ok := value.(map[string]interface{})
+				if !ok && value != nil {
+					return story, p.errorf(kv.Value, "Props must be a map literal")
+				}
+				story.Props = props
+			}
+		case "Viewport":
+			story.Viewport, err = p.viewport(kv.Value)
+		default:
+			return story, p.errorf(key, "unknown stories.Story field %s", key.Name)
+		}
+		if err != nil {
+			return story, err
+		}
+	}
+
+	return story, nil
+}
+
+func (p *goStoryParser) viewport(expr ast.Expr) (stories.Viewport, error) {
+	var viewport stories.Viewport
+
+	value, err := p.literal(expr)
+	if err != nil {
+		return viewport, err
+	}
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return viewport, p.errorf(expr, "Viewport must be a stories.Viewport literal")
+	}
+
+	for name, size := range fields {
+		n, ok := size.(int)
+		if !ok {
+			return viewport, p.errorf(expr, "Viewport %s must be an integer", name)
+		}
+		switch name {
+		case "Width":
+			viewport.Width = n
+		case "Height":
+			viewport.Height = n
+		default:
+			return viewport, p.errorf(expr, "unknown stories.Viewport field %s", name)
+		}
+	}
+
+	return viewport, nil
+}
+
+func (p *goStoryParser) stringValue(expr ast.Expr) (string, error) {
+	value, err := p.literal(expr)
+	if err != nil {
+		return "", err
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", p.errorf(expr, "expected a string literal")
+	}
+	return s, nil
+}
+
+// literal evaluates a constant Go expression: basic literals, true, false,
+// nil, negated numbers and composite literals. Keyed composite literals
+// become maps (struct field names are kept as keys) and unkeyed ones slices.
+func (p *goStoryParser) literal(expr ast.Expr) (interface{}, error) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return p.literal(e.X)
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+			return strconv.Unquote(e.Value)
+		case token.INT:
+			n, err := strconv.ParseInt(e.Value, 0, 64)
+			if err != nil {
+				return nil, p.errorf(e, "invalid integer %s", e.Value)
+			}
+			return int(n), nil
+		case token.FLOAT:
+			f, err := strconv.ParseFloat(e.Value, 64)
+			if err != nil {
+				return nil, p.errorf(e, "invalid number %s", e.Value)
+			}
+			return f, nil
+		}
+	case *ast.Ident:
+		switch e.Name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "nil":
+			return nil, nil
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.SUB {
+			value, err := p.literal(e.X)
+			if err != nil {
+				return nil, err
+			}
+			switch n := value.(type) {
+			case int:
+				return -n, nil
+			case float64:
+				return -n, nil
+			}
+		}
+	case *ast.CompositeLit:
+		return p.compositeLiteral(e)
+	}
+
+	return nil, p.errorf(expr, "story values must be literals")
+}
+
+func (p *goStoryParser) compositeLiteral(lit *ast.CompositeLit) (interface{}, error) {
+	keyed := len(lit.Elts) > 0
+	for _, elt := range lit.Elts {
+		if _, ok := elt.(*ast.KeyValueExpr); !ok {
+			keyed = false
+		}
+	}
+
+	if !keyed {
+		if _, isMap := lit.Type.(*ast.MapType); isMap || p.isPackageSelector(lit.Type, "Props") {
+			return map[string]interface{}{}, nil
+		}
+		items := make([]interface{}, 0, len(lit.Elts))
+		for _, elt := range lit.Elts {
+			item, err := p.literal(elt)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	values := make(map[string]interface{}, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		kv := elt.(*ast.KeyValueExpr)
+
+		var key string
+		if ident, ok := kv.Key.(*ast.Ident); ok {
+			key = ident.Name
+		} else {
+			k, err := p.literal(kv.Key)
+			if err != nil {
+				return nil, err
+			}
+			if key, ok = k.(string); !ok {
+				return nil, p.errorf(kv.Key, "map keys must be strings")
+			}
+		}
+
+		value, err := p.literal(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+
+	return values, nil
+}
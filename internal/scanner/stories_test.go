@@ -0,0 +1,220 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const storiesTemplSource = `package ui
+
+templ Button(text string, variant string) {
+	<button class={ variant }>{ text }</button>
+}
+
+templ Badge(label string) {
+	<span>{ label }</span>
+}
+`
+
+// writeStoriesFixture writes button.templ plus the given story files into a
+// fresh directory and returns the templ file path
+func writeStoriesFixture(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp(".", "storiestest")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	templPath := filepath.Join(dir, "button.templ")
+	require.NoError(t, os.WriteFile(templPath, []byte(storiesTemplSource), 0644))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	return templPath
+}
+
+func TestScanFile_LoadsStories(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name: "yaml",
+			files: map[string]string{"button.stories.yaml": `component: Button
+stories:
+  - name: Primary
+    description: The default call to action
+    props:
+      text: Save
+      variant: primary
+  - name: Mobile
+    props: {text: Save}
+    viewport: {width: 375, height: 667}
+    wrapper: layouts.Centered
+    background: "#1f2937"
+    notes: Stretches to the full width.
+`},
+		},
+		{
+			name: "json",
+			files: map[string]string{"button.stories.json": `{
+  "components": {
+    "Button": [
+      {"name": "Primary", "description": "The default call to action", "props": {"text": "Save", "variant": "primary"}},
+      {"name": "Mobile", "props": {"text": "Save"}, "viewport": {"width": 375, "height": 667},
+       "wrapper": "layouts.Centered", "background": "#1f2937", "notes": "Stretches to the full width."}
+    ]
+  }
+}`},
+		},
+		{
+			name: "go",
+			files: map[string]string{"button_stories.go": `package ui
+
+import "github.com/conneroisu/templar/pkg/stories"
+
+var _ = stories.Register("Button",
+	stories.Story{
+		Name:        "Primary",
+		Description: "The default call to action",
+		Props:       stories.Props{"text": "Save", "variant": "primary"},
+	},
+	stories.Story{
+		Name:       "Mobile",
+		Props:      stories.Props{"text": "Save"},
+		Viewport:   stories.Viewport{Width: 375, Height: 667},
+		Wrapper:    "layouts.Centered",
+		Background: "#1f2937",
+		Notes:      ` + "`Stretches to the full width.`" + `,
+	},
+)
+`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templPath := writeStoriesFixture(t, tt.files)
+			reg := registry.NewComponentRegistry()
+			require.NoError(t, NewComponentScanner(reg).ScanFile(templPath))
+
+			button, exists := reg.Get("Button")
+			require.True(t, exists)
+			require.Len(t, button.Examples, 2)
+
+			primary := button.Examples[0]
+			assert.Equal(t, "Primary", primary.Name)
+			assert.Equal(t, "The default call to action", primary.Description)
+			assert.Equal(t, map[string]interface{}{"text": "Save", "variant": "primary"}, primary.Props)
+
+			mobile := button.Examples[1]
+			assert.Equal(t, "Mobile", mobile.Name)
+			assert.Equal(t, types.ExampleViewport{Width: 375, Height: 667}, mobile.Viewport)
+			assert.Equal(t, "layouts.Centered", mobile.Wrapper)
+			assert.Equal(t, "#1f2937", mobile.Background)
+			assert.Equal(t, "Stretches to the full width.", mobile.Notes)
+			assert.NotEmpty(t, mobile.Source)
+
+			badge, exists := reg.Get("Badge")
+			require.True(t, exists)
+			assert.Empty(t, badge.Examples)
+		})
+	}
+}
+
+func TestScanFile_StoryFileChanges(t *testing.T) {
+	templPath := writeStoriesFixture(t, map[string]string{
+		"button.stories.yml": "component: Button\nstories:\n  - name: Primary\n",
+	})
+	storyPath := filepath.Join(filepath.Dir(templPath), "button.stories.yml")
+
+	reg := registry.NewComponentRegistry()
+	scanner := NewComponentScanner(reg)
+	require.NoError(t, scanner.ScanFile(templPath))
+
+	button, _ := reg.Get("Button")
+	require.Len(t, button.Examples, 1)
+
+	// Scanning the story file rescans its templ file, and the cached templ
+	// metadata must not hide the new stories
+	require.NoError(t, os.WriteFile(storyPath, []byte("component: Button\nstories:\n  - name: Primary\n  - name: Danger\n"), 0644))
+	require.NoError(t, scanner.ScanFile(storyPath))
+
+	button, _ = reg.Get("Button")
+	require.Len(t, button.Examples, 2)
+	assert.Equal(t, "Danger", button.Examples[1].Name)
+
+	require.NoError(t, os.Remove(storyPath))
+	require.NoError(t, scanner.ScanFile(templPath))
+
+	button, _ = reg.Get("Button")
+	assert.Empty(t, button.Examples)
+}
+
+func TestScanFile_StoryErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		message string
+	}{
+		{
+			name:    "unknown component",
+			files:   map[string]string{"button.stories.yaml": "component: Buton\nstories:\n  - name: Primary\n"},
+			message: `unknown component "Buton", button.templ declares: Badge, Button`,
+		},
+		{
+			name:    "component required when the file declares several",
+			files:   map[string]string{"button.stories.yaml": "stories:\n  - name: Primary\n"},
+			message: "set component to one of: Badge, Button",
+		},
+		{
+			name:    "unknown field",
+			files:   map[string]string{"button.stories.yaml": "component: Button\nstories:\n  - name: Primary\n    prop: {}\n"},
+			message: "field prop not found",
+		},
+		{
+			name:    "duplicate story",
+			files:   map[string]string{"button.stories.json": `{"component": "Button", "stories": [{"name": "Primary"}, {"name": "Primary"}]}`},
+			message: `duplicate story "Primary" for component Button`,
+		},
+		{
+			name:    "unsafe background",
+			files:   map[string]string{"button.stories.yaml": "component: Button\nstories:\n  - name: Primary\n    background: \"red; position: fixed\"\n"},
+			message: `invalid background "red; position: fixed"`,
+		},
+		{
+			name: "non-literal go props",
+			files: map[string]string{"button_stories.go": `package ui
+
+import "github.com/conneroisu/templar/pkg/stories"
+
+var label = "Save"
+
+var _ = stories.Register("Button", stories.Story{Name: "Primary", Props: stories.Props{"text": label}})
+`},
+			message: "story values must be literals",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templPath := writeStoriesFixture(t, tt.files)
+			reg := registry.NewComponentRegistry()
+
+			err := NewComponentScanner(reg).ScanFile(templPath)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+
+			// The components themselves are still registered
+			_, exists := reg.Get("Button")
+			assert.True(t, exists)
+		})
+	}
+}
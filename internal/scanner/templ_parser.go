@@ -0,0 +1,238 @@
+package scanner
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"strconv"
+	"strings"
+	"time"
+
+	templparser "github.com/a-h/templ/parser/v2"
+
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// templSignature holds the parts of a templ, css or script declaration
+// signature after it has been parsed as a Go function declaration.
+type templSignature struct {
+	// name is the declared template name
+	name string
+	// receiver is set for templates declared as methods, e.g. templ (c Card) Render()
+	receiver *types.ParameterInfo
+	// typeParams lists generic type parameters, e.g. templ List[T any](items []T)
+	typeParams []types.ParameterInfo
+	// params lists the declared parameters in order
+	params []types.ParameterInfo
+}
+
+// parseTemplSignature parses a templ declaration signature such as
+// "Button(text string)" or "(c Card) Render()" using the Go parser, so that
+// multi-line signatures, generics and function-typed parameters are handled
+// exactly as the Go compiler would see them.
+func parseTemplSignature(signature string) (*templSignature, error) {
+	src := "package p\nfunc " + signature + " {}\n"
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("parsing signature %q: %w", signature, err)
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name == nil {
+			continue
+		}
+
+		sig := &templSignature{
+			name:       fn.Name.Name,
+			typeParams: fieldListToParameters(fn.Type.TypeParams),
+			params:     fieldListToParameters(fn.Type.Params),
+		}
+
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			if recv := fieldListToParameters(fn.Recv); len(recv) > 0 {
+				sig.receiver = &recv[0]
+			}
+		}
+
+		return sig, nil
+	}
+
+	return nil, fmt.Errorf("no declaration found in signature %q", signature)
+}
+
+// fieldListToParameters converts a Go field list into parameter metadata.
+// Unnamed fields are reported with the blank identifier as their name.
+func fieldListToParameters(fields *ast.FieldList) []types.ParameterInfo {
+	params := []types.ParameterInfo{}
+	if fields == nil {
+		return params
+	}
+
+	for _, field := range fields.List {
+		paramType := ""
+		if field.Type != nil {
+			paramType = gotypes.ExprString(field.Type)
+		}
+
+		param := types.ParameterInfo{
+			Type:     paramType,
+			Optional: strings.HasPrefix(paramType, "*"),
+			Default:  nil,
+		}
+
+		if len(field.Names) == 0 {
+			param.Name = "_"
+			params = append(params, param)
+			continue
+		}
+
+		for _, name := range field.Names {
+			param.Name = name.Name
+			params = append(params, param)
+		}
+	}
+
+	return params
+}
+
+// parseTemplFileWithComponents extracts components from templ files using the
+// templ parser. Every templ, css and script declaration becomes a component
+// carrying its parameters, receiver, doc comment and source position.
+func (s *ComponentScanner) parseTemplFileWithComponents(path string, content []byte, hash string, modTime time.Time) ([]*types.ComponentInfo, error) {
+	tf, err := templparser.ParseString(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("parsing templ file %s: %w", path, err)
+	}
+
+	packageName := sanitizeIdentifier(strings.TrimSpace(
+		strings.TrimPrefix(strings.TrimSpace(tf.Package.Expression.Value), "package")))
+	imports := extractTemplImports(tf)
+
+	var components []*types.ComponentInfo
+	for i, node := range tf.Nodes {
+		var (
+			kind      types.ComponentKind
+			signature string
+			nodeRange templparser.Range
+		)
+
+		switch n := node.(type) {
+		case *templparser.HTMLTemplate:
+			kind = types.ComponentKindTempl
+			signature = n.Expression.Value
+			nodeRange = n.Range
+		case *templparser.CSSTemplate:
+			kind = types.ComponentKindCSS
+			signature = n.Expression.Value
+			nodeRange = n.Range
+		case *templparser.ScriptTemplate:
+			kind = types.ComponentKindScript
+			signature = n.Name.Value + "(" + n.Parameters.Value + ")"
+			nodeRange = n.Range
+		default:
+			continue
+		}
+
+		sig, err := parseTemplSignature(signature)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d:%d: invalid %s declaration: %w",
+				path, nodeRange.From.Line+1, nodeRange.From.Col+1, kind, err)
+		}
+
+		name := sanitizeIdentifier(sig.name)
+		if name == "" {
+			continue
+		}
+
+		component := &types.ComponentInfo{
+			Name:           name,
+			Package:        packageName,
+			FilePath:       path,
+			Parameters:     sig.params,
+			Imports:        imports,
+			LastMod:        modTime,
+			Hash:           hash,
+			Dependencies:   []string{},
+			IsExported:     ast.IsExported(name),
+			IsRenderable:   kind == types.ComponentKindTempl && sig.receiver == nil && len(sig.typeParams) == 0,
+			Description:    precedingDocComment(tf.Nodes, i, nodeRange),
+			Kind:           kind,
+			Receiver:       sig.receiver,
+			TypeParameters: sig.typeParams,
+			Line:           int(nodeRange.From.Line) + 1,
+			Column:         int(nodeRange.From.Col) + 1,
+		}
+
+		components = append(components, component)
+	}
+
+	return components, nil
+}
+
+// extractTemplImports collects the import paths declared in the Go sections of a templ file
+func extractTemplImports(tf *templparser.TemplateFile) []string {
+	imports := []string{}
+
+	for _, node := range tf.Nodes {
+		goExpr, ok := node.(*templparser.TemplateFileGoExpression)
+		if !ok {
+			continue
+		}
+
+		file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+goExpr.Expression.Value, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+
+		for _, imp := range file.Imports {
+			if imp.Path == nil {
+				continue
+			}
+			if path, err := strconv.Unquote(imp.Path.Value); err == nil {
+				imports = append(imports, path)
+			}
+		}
+	}
+
+	return imports
+}
+
+// precedingDocComment returns the comment block directly above the template at
+// index i. Comments separated from the declaration by a blank line are ignored,
+// matching how Go attaches doc comments to declarations.
+func precedingDocComment(nodes []templparser.TemplateFileNode, i int, declRange templparser.Range) string {
+	if i == 0 {
+		return ""
+	}
+
+	goExpr, ok := nodes[i-1].(*templparser.TemplateFileGoExpression)
+	if !ok {
+		return ""
+	}
+
+	value := goExpr.Expression.Value
+	endLine := goExpr.Expression.Range.From.Line + uint32(strings.Count(value, "\n"))
+	if endLine+1 != declRange.From.Line {
+		return ""
+	}
+
+	src := "package p\n" + value
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil || len(file.Comments) == 0 {
+		return ""
+	}
+
+	// Only the comment group ending the expression is a doc comment
+	last := file.Comments[len(file.Comments)-1]
+	if fset.Position(last.End()).Offset != len(strings.TrimRight(src, " \t\r\n")) {
+		return ""
+	}
+
+	return strings.TrimSpace(last.Text())
+}
@@ -0,0 +1,430 @@
+package schema
+
+import (
+	"fmt"
+	"go/constant"
+	gotypes "go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// builder converts Go types to schemas, collecting named types into shared
+// definitions
+type builder struct {
+	defs map[string]*Schema
+	// names maps named types to their definition names
+	names map[string]string
+}
+
+func newBuilder(defs map[string]*Schema) *builder {
+	return &builder{defs: defs, names: make(map[string]string)}
+}
+
+// component describes a component's props: an object with a property per
+// parameter, required unless the parameter is a pointer or optional
+func (b *builder) component(component *types.ComponentInfo, sig *gotypes.Signature) *Schema {
+	s := &Schema{
+		Title:       component.Name,
+		Description: component.Description,
+		Type:        "object",
+		Properties:  Properties{},
+		Closed:      true,
+	}
+
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		param := params.At(i)
+		if param.Name() == "" || param.Name() == "_" {
+			continue
+		}
+
+		paramType := param.Type()
+		property := b.schema(paramType)
+		optional := isPointer(paramType)
+		if i < len(component.Parameters) {
+			info := component.Parameters[i]
+			optional = optional || info.Optional
+			if info.Description != "" {
+				property = describe(property, info.Description)
+			}
+			if info.Default != nil {
+				property = withDefault(property, info.Default)
+			}
+		}
+
+		s.Properties = append(s.Properties, Property{Name: param.Name(), Schema: property})
+		if !optional {
+			s.Required = append(s.Required, param.Name())
+		}
+	}
+
+	return s
+}
+
+// schema describes the JSON encoding of a Go type
+func (b *builder) schema(t gotypes.Type) *Schema {
+	t = gotypes.Unalias(t)
+
+	if named, ok := t.(*gotypes.Named); ok {
+		if s, ok := b.named(named); ok {
+			return s
+		}
+	}
+
+	switch u := t.Underlying().(type) {
+	case *gotypes.Basic:
+		return basicSchema(u, goTypeOf(t))
+
+	case *gotypes.Pointer:
+		return b.schema(u.Elem())
+
+	case *gotypes.Slice:
+		if isByte(u.Elem()) {
+			// []byte is encoded as a base64 string
+			return &Schema{Type: "string", ContentEncoding: "base64", GoType: goTypeOf(t)}
+		}
+		return &Schema{Type: "array", Items: b.schema(u.Elem()), GoType: goTypeOf(t)}
+
+	case *gotypes.Array:
+		length := int(u.Len())
+		return &Schema{Type: "array", Items: b.schema(u.Elem()), MaxItems: &length, GoType: goTypeOf(t)}
+
+	case *gotypes.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schema(u.Elem()), GoType: goTypeOf(t)}
+
+	case *gotypes.Struct:
+		return b.object(u, goTypeOf(t))
+
+	default:
+		// Interfaces, functions, channels and type parameters accept any
+		// value as far as the JSON is concerned
+		return &Schema{GoType: typeString(t)}
+	}
+}
+
+// named describes named types with their own encoding and collects named
+// structs and enums into the shared definitions, returning a reference
+func (b *builder) named(named *gotypes.Named) (*Schema, bool) {
+	switch {
+	case isNamed(named, "time", "Time"):
+		return &Schema{Type: "string", Format: "date-time", GoType: "time.Time"}, true
+	case isNamed(named, "time", "Duration"):
+		return &Schema{Type: "integer", Description: "Duration in nanoseconds", GoType: "time.Duration"}, true
+	case hasMethod(named, "MarshalJSON"):
+		return &Schema{GoType: typeString(named)}, true
+	case hasMethod(named, "MarshalText"):
+		return &Schema{Type: "string", GoType: typeString(named)}, true
+	}
+
+	var build func() *Schema
+	switch u := named.Underlying().(type) {
+	case *gotypes.Struct:
+		build = func() *Schema { return b.object(u, typeString(named)) }
+	case *gotypes.Basic:
+		consts := enumConstants(named)
+		if len(consts) == 0 {
+			return nil, false
+		}
+		build = func() *Schema { return enumSchema(named, u, consts) }
+	default:
+		return nil, false
+	}
+
+	key := typeString(named)
+	name, exists := b.names[key]
+	if !exists {
+		name = b.defName(named)
+		b.names[key] = name
+		// Reserve the name first so recursive types refer back to it
+		b.defs[name] = &Schema{}
+		*b.defs[name] = *build()
+		b.defs[name].Title = name
+	}
+	return &Schema{Ref: defsPrefix + name}, true
+}
+
+// defName picks an unused definition name for a named type: its own name,
+// qualified by its package when another type already uses it
+func (b *builder) defName(named *gotypes.Named) string {
+	name := named.Obj().Name()
+	if args := named.TypeArgs(); args != nil {
+		for i := 0; i < args.Len(); i++ {
+			name += exportedName(args.At(i))
+		}
+	}
+	if _, taken := b.defs[name]; !taken {
+		return name
+	}
+
+	if pkg := named.Obj().Pkg(); pkg != nil {
+		qualified := capitalize(pkg.Name()) + name
+		if _, taken := b.defs[qualified]; !taken {
+			return qualified
+		}
+		name = qualified
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s%d", name, i)
+		if _, taken := b.defs[candidate]; !taken {
+			return candidate
+		}
+	}
+}
+
+// jsonField is a struct field as encoding/json sees it
+type jsonField struct {
+	name      string
+	fieldType gotypes.Type
+	optional  bool
+	quoted    bool
+}
+
+// object describes a struct as a JSON object, following the field naming
+// rules of encoding/json
+func (b *builder) object(st *gotypes.Struct, goType string) *Schema {
+	s := &Schema{Type: "object", Properties: Properties{}, GoType: goType}
+
+	for _, field := range jsonFields(st, make(map[*gotypes.Struct]bool)) {
+		property := b.schema(field.fieldType)
+		if field.quoted && (property.Type == "integer" || property.Type == "number" || property.Type == "boolean") {
+			property = &Schema{Type: "string", GoType: property.GoType}
+		}
+		s.Properties = append(s.Properties, Property{Name: field.name, Schema: property})
+		if !field.optional {
+			s.Required = append(s.Required, field.name)
+		}
+	}
+
+	return s
+}
+
+// jsonFields lists the exported fields of a struct under their JSON names
+// in encoding order, promoting the fields of untagged embedded structs in
+// place. Fields declared directly win over promoted ones.
+func jsonFields(st *gotypes.Struct, visited map[*gotypes.Struct]bool) []jsonField {
+	if visited[st] {
+		return nil
+	}
+	visited[st] = true
+
+	// entry is a field declared directly, or an embedded struct whose
+	// fields are promoted in its place
+	type entry struct {
+		field    jsonField
+		embedded *gotypes.Struct
+		pointer  bool
+	}
+	var entries []entry
+	direct := make(map[string]bool)
+
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i)).Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Embedded() && name == "" {
+			fieldType := gotypes.Unalias(field.Type())
+			ptr, pointer := fieldType.(*gotypes.Pointer)
+			if pointer {
+				fieldType = ptr.Elem()
+			}
+			if inner, ok := fieldType.Underlying().(*gotypes.Struct); ok {
+				entries = append(entries, entry{embedded: inner, pointer: pointer})
+				continue
+			}
+		}
+		if !field.Exported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name()
+		}
+		direct[name] = true
+		entries = append(entries, entry{field: jsonField{
+			name:      name,
+			fieldType: field.Type(),
+			optional:  isPointer(field.Type()) || hasOption(options, "omitempty") || hasOption(options, "omitzero"),
+			quoted:    hasOption(options, "string"),
+		}})
+	}
+
+	var fields []jsonField
+	for _, e := range entries {
+		if e.embedded == nil {
+			fields = append(fields, e.field)
+			continue
+		}
+		for _, promoted := range jsonFields(e.embedded, visited) {
+			if direct[promoted.name] || containsField(fields, promoted.name) {
+				continue
+			}
+			// A nil embedded pointer leaves out all of its fields
+			promoted.optional = promoted.optional || e.pointer
+			fields = append(fields, promoted)
+		}
+	}
+
+	return fields
+}
+
+func containsField(fields []jsonField, name string) bool {
+	for _, field := range fields {
+		if field.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasOption(options, option string) bool {
+	for options != "" {
+		var current string
+		current, options, _ = strings.Cut(options, ",")
+		if current == option {
+			return true
+		}
+	}
+	return false
+}
+
+// basicSchema describes a boolean, string or numeric type
+func basicSchema(basic *gotypes.Basic, goType string) *Schema {
+	info := basic.Info()
+	s := &Schema{GoType: goType}
+	switch {
+	case info&gotypes.IsBoolean != 0:
+		s.Type = "boolean"
+	case info&gotypes.IsString != 0:
+		s.Type = "string"
+	case info&gotypes.IsInteger != 0:
+		s.Type = "integer"
+	case info&gotypes.IsFloat != 0:
+		s.Type = "number"
+	}
+	return s
+}
+
+// enumConstants returns the package-level constants declared with a named
+// basic type in declaration order, which are the type's allowed values
+func enumConstants(named *gotypes.Named) []*gotypes.Const {
+	pkg := named.Obj().Pkg()
+	if pkg == nil {
+		return nil
+	}
+
+	var consts []*gotypes.Const
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if c, ok := scope.Lookup(name).(*gotypes.Const); ok && gotypes.Identical(c.Type(), named) {
+			consts = append(consts, c)
+		}
+	}
+	sort.SliceStable(consts, func(i, j int) bool { return consts[i].Pos() < consts[j].Pos() })
+	return consts
+}
+
+// enumSchema describes a named type by the values of its constants
+func enumSchema(named *gotypes.Named, basic *gotypes.Basic, consts []*gotypes.Const) *Schema {
+	s := basicSchema(basic, typeString(named))
+	for _, c := range consts {
+		s.Enum = append(s.Enum, constantJSON(c.Val()))
+		s.EnumNames = append(s.EnumNames, c.Name())
+	}
+	return s
+}
+
+// constantJSON converts a constant value to its JSON representation
+func constantJSON(value constant.Value) interface{} {
+	switch value.Kind() {
+	case constant.String:
+		return constant.StringVal(value)
+	case constant.Bool:
+		return constant.BoolVal(value)
+	case constant.Int:
+		if i, exact := constant.Int64Val(value); exact {
+			return i
+		}
+		if u, exact := constant.Uint64Val(value); exact {
+			return u
+		}
+	}
+	f, _ := constant.Float64Val(value)
+	return f
+}
+
+// describe returns a copy of a schema carrying a description
+func describe(s *Schema, description string) *Schema {
+	copied := *s
+	copied.Description = description
+	return &copied
+}
+
+func withDefault(s *Schema, value interface{}) *Schema {
+	copied := *s
+	copied.Default = value
+	return &copied
+}
+
+func isPointer(t gotypes.Type) bool {
+	_, ok := gotypes.Unalias(t).(*gotypes.Pointer)
+	return ok
+}
+
+func isByte(t gotypes.Type) bool {
+	basic, ok := gotypes.Unalias(t).Underlying().(*gotypes.Basic)
+	return ok && basic.Kind() == gotypes.Byte
+}
+
+func isNamed(named *gotypes.Named, pkgPath, name string) bool {
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkgPath && obj.Name() == name
+}
+
+// hasMethod reports whether a named type or a pointer to it has a method
+func hasMethod(named *gotypes.Named, name string) bool {
+	obj, _, _ := gotypes.LookupFieldOrMethod(gotypes.NewPointer(named), false, named.Obj().Pkg(), name)
+	_, ok := obj.(*gotypes.Func)
+	return ok
+}
+
+// typeString formats a type qualified by package name rather than path
+func typeString(t gotypes.Type) string {
+	return gotypes.TypeString(t, func(pkg *gotypes.Package) string { return pkg.Name() })
+}
+
+// goTypeOf returns the name of a named composite type such as
+// type Tags []string, and nothing for type literals
+func goTypeOf(t gotypes.Type) string {
+	if _, ok := t.(*gotypes.Named); ok {
+		return typeString(t)
+	}
+	return ""
+}
+
+// exportedName turns a type argument into an identifier for a definition name
+func exportedName(t gotypes.Type) string {
+	if named, ok := gotypes.Unalias(t).(*gotypes.Named); ok {
+		return capitalize(named.Obj().Name())
+	}
+	var name strings.Builder
+	for _, r := range typeString(t) {
+		if r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			name.WriteRune(r)
+		}
+	}
+	return capitalize(name.String())
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conneroisu/templar/internal/renderer"
+	"github.com/conneroisu/templar/internal/types"
+)
+
+// Set holds the props schemas of a group of components
+type Set struct {
+	// Components maps component IDs to the schema of their props
+	Components map[string]*Schema
+	// Defs holds the named types shared by the component schemas
+	Defs map[string]*Schema
+	// Errors maps component IDs to the reason their props could not be resolved
+	Errors map[string]error
+}
+
+// Load describes the props of templ components as JSON object schemas.
+// Parameter types are resolved as the renderer resolves them for prop
+// validation, so packages whose *_templ.go files have not been generated yet
+// still resolve.
+func Load(ctx context.Context, components []*types.ComponentInfo) *Set {
+	set := &Set{
+		Components: make(map[string]*Schema),
+		Defs:       make(map[string]*Schema),
+		Errors:     make(map[string]error),
+	}
+	b := newBuilder(set.Defs)
+
+	signatures, errs := renderer.LoadParamTypes(ctx, components)
+	for id, err := range errs {
+		set.Errors[id] = err
+	}
+
+	for _, component := range components {
+		sig, ok := signatures[component.ID]
+		if !ok {
+			continue
+		}
+		set.Components[component.ID] = b.component(component, sig)
+	}
+
+	return set
+}
+
+// Document returns a component's schema as a standalone document with the
+// given $id, referring to shared definitions in DefsFile
+func (set *Set) Document(componentID, documentID string) (*Schema, error) {
+	s, ok := set.Components[componentID]
+	if !ok {
+		return nil, fmt.Errorf("no schema for component %s", componentID)
+	}
+	document := s.withRefBase(DefsFile)
+	document.Schema = Draft
+	document.ID = documentID
+	return document, nil
+}
+
+// DefsDocument returns the document holding the shared definitions
+func (set *Set) DefsDocument() *Schema {
+	return &Schema{
+		Schema: Draft,
+		ID:     DefsFile,
+		Title:  "Shared definitions",
+		Defs:   set.Defs,
+	}
+}
@@ -0,0 +1,134 @@
+// Package schema describes the props of templ components as JSON Schema and
+// TypeScript. Parameter types are resolved with go/packages, so structs,
+// pointers, slices, maps, enums declared as constant sets and embedded
+// structs are described the way encoding/json encodes them.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Draft is the JSON Schema dialect of generated schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// DefsFile is the name of the document holding the definitions shared by
+// component schemas
+const DefsFile = "defs.schema.json"
+
+// defsPrefix starts a reference to a definition in the same document
+const defsPrefix = "#/$defs/"
+
+// Schema is a JSON Schema node
+type Schema struct {
+	Schema               string        `json:"$schema,omitempty"`
+	ID                   string        `json:"$id,omitempty"`
+	Ref                  string        `json:"$ref,omitempty"`
+	Title                string        `json:"title,omitempty"`
+	Description          string        `json:"description,omitempty"`
+	Type                 string        `json:"type,omitempty"`
+	Format               string        `json:"format,omitempty"`
+	ContentEncoding      string        `json:"contentEncoding,omitempty"`
+	Enum                 []interface{} `json:"enum,omitempty"`
+	Properties           Properties    `json:"properties,omitempty"`
+	Required             []string      `json:"required,omitempty"`
+	AdditionalProperties *Schema       `json:"additionalProperties,omitempty"`
+	// Closed disallows properties other than those listed
+	Closed   bool               `json:"-"`
+	Items    *Schema            `json:"items,omitempty"`
+	MinItems *int               `json:"minItems,omitempty"`
+	MaxItems *int               `json:"maxItems,omitempty"`
+	Default  interface{}        `json:"default,omitempty"`
+	Defs     map[string]*Schema `json:"$defs,omitempty"`
+	// GoType is the Go type the node was resolved from
+	GoType string `json:"x-go-type,omitempty"`
+	// EnumNames are the Go constant names of Enum's values
+	EnumNames []string `json:"x-enum-names,omitempty"`
+}
+
+// MarshalJSON writes additionalProperties: false for closed objects
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	if !s.Closed || s.AdditionalProperties != nil {
+		return json.Marshal((*plain)(s))
+	}
+	return json.Marshal(struct {
+		*plain
+		AdditionalProperties bool `json:"additionalProperties"`
+	}{plain: (*plain)(s)})
+}
+
+// Property is a named property of an object schema
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties are the properties of an object schema in declaration order
+type Properties []Property
+
+// MarshalJSON writes the properties as a JSON object, keeping their order
+func (p Properties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, property := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(property.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(property.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Lookup returns the schema of a property
+func (p Properties) Lookup(name string) (*Schema, bool) {
+	for _, property := range p {
+		if property.Name == name {
+			return property.Schema, true
+		}
+	}
+	return nil, false
+}
+
+// DefName returns the definition a reference points to, if it is a
+// reference to a shared definition
+func (s *Schema) DefName() (string, bool) {
+	if s == nil || s.Ref == "" {
+		return "", false
+	}
+	_, name, found := strings.Cut(s.Ref, defsPrefix)
+	return name, found
+}
+
+// withRefBase returns a copy of the schema whose references to shared
+// definitions point into the document named base
+func (s *Schema) withRefBase(base string) *Schema {
+	if s == nil {
+		return nil
+	}
+	copied := *s
+	if name, ok := s.DefName(); ok {
+		copied.Ref = base + defsPrefix + name
+	}
+	copied.Items = s.Items.withRefBase(base)
+	copied.AdditionalProperties = s.AdditionalProperties.withRefBase(base)
+	if s.Properties != nil {
+		copied.Properties = make(Properties, len(s.Properties))
+		for i, property := range s.Properties {
+			copied.Properties[i] = Property{Name: property.Name, Schema: property.Schema.withRefBase(base)}
+		}
+	}
+	return &copied
+}
@@ -0,0 +1,218 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/conneroisu/templar/internal/types"
+)
+
+const testModels = `package models
+
+import "time"
+
+type Variant string
+
+const (
+	VariantPrimary   Variant = "primary"
+	VariantSecondary Variant = "secondary"
+)
+
+type Audit struct {
+	CreatedAt time.Time ` + "`json:\"created_at\"`" + `
+	UpdatedBy *string   ` + "`json:\"updated_by\"`" + `
+}
+
+type User struct {
+	Audit
+	ID       int                ` + "`json:\"id\"`" + `
+	Name     string             ` + "`json:\"name\"`" + `
+	Email    string             ` + "`json:\"email,omitempty\"`" + `
+	Password string             ` + "`json:\"-\"`" + `
+	Manager  *User              ` + "`json:\"manager\"`" + `
+	Scores   map[string]float64 ` + "`json:\"scores\"`" + `
+	internal int
+}
+`
+
+const testTemplate = `package ui
+
+import (
+	"example.com/app/models"
+)
+
+// Card shows a user
+templ Card(user models.User, variant models.Variant, footer *string, tags []string) {
+	<div>{ user.Name }</div>
+}
+
+templ Broken(item Missing) {
+	<div></div>
+}
+`
+
+// writeModule writes a module with a models package and a templ file whose
+// Go code has not been generated, returning the templ file's path
+func writeModule(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":           "module example.com/app\n\ngo 1.24\n",
+		"models/models.go": testModels,
+		"ui/card.templ":    testTemplate,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return filepath.Join(dir, "ui", "card.templ")
+}
+
+func testComponents(templPath string) []*types.ComponentInfo {
+	return []*types.ComponentInfo{
+		{
+			ID:          "example.com/app/ui.Card",
+			Name:        "Card",
+			FilePath:    templPath,
+			Description: "Card shows a user",
+			Kind:        types.ComponentKindTempl,
+			Parameters: []types.ParameterInfo{
+				{Name: "user", Type: "models.User"},
+				{Name: "variant", Type: "models.Variant"},
+				{Name: "footer", Type: "*string", Optional: true},
+				{Name: "tags", Type: "[]string"},
+			},
+		},
+		{
+			ID:         "example.com/app/ui.Broken",
+			Name:       "Broken",
+			FilePath:   templPath,
+			Kind:       types.ComponentKindTempl,
+			Parameters: []types.ParameterInfo{{Name: "item", Type: "Missing"}},
+		},
+		{
+			ID:       "example.com/app/ui.red",
+			Name:     "red",
+			FilePath: templPath,
+			Kind:     types.ComponentKindCSS,
+		},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	set := Load(context.Background(), testComponents(writeModule(t)))
+
+	require.Contains(t, set.Errors, "example.com/app/ui.Broken")
+	assert.Contains(t, set.Errors["example.com/app/ui.Broken"].Error(), "parameter item")
+	assert.NotContains(t, set.Components, "example.com/app/ui.red", "css templates have no props")
+
+	card := set.Components["example.com/app/ui.Card"]
+	require.NotNil(t, card, "errors: %v", set.Errors)
+	assert.Equal(t, "Card shows a user", card.Description)
+	assert.Equal(t, []string{"user", "variant", "tags"}, card.Required)
+
+	user, _ := card.Properties.Lookup("user")
+	assert.Equal(t, "#/$defs/User", user.Ref)
+	variant, _ := card.Properties.Lookup("variant")
+	assert.Equal(t, "#/$defs/Variant", variant.Ref)
+	footer, _ := card.Properties.Lookup("footer")
+	assert.Equal(t, "string", footer.Type)
+	tags, _ := card.Properties.Lookup("tags")
+	assert.Equal(t, "array", tags.Type)
+	assert.Equal(t, "string", tags.Items.Type)
+
+	assert.Equal(t, []interface{}{"primary", "secondary"}, set.Defs["Variant"].Enum)
+	assert.Equal(t, []string{"VariantPrimary", "VariantSecondary"}, set.Defs["Variant"].EnumNames)
+
+	userDef := set.Defs["User"]
+	require.NotNil(t, userDef)
+	names := make([]string, len(userDef.Properties))
+	for i, property := range userDef.Properties {
+		names[i] = property.Name
+	}
+	assert.Equal(t, []string{"created_at", "updated_by", "id", "name", "email", "manager", "scores"}, names)
+	assert.Equal(t, []string{"created_at", "id", "name", "scores"}, userDef.Required)
+
+	createdAt, _ := userDef.Properties.Lookup("created_at")
+	assert.Equal(t, "date-time", createdAt.Format)
+	manager, _ := userDef.Properties.Lookup("manager")
+	assert.Equal(t, "#/$defs/User", manager.Ref, "recursive types refer back to their definition")
+	scores, _ := userDef.Properties.Lookup("scores")
+	assert.Equal(t, "number", scores.AdditionalProperties.Type)
+}
+
+func TestDocument(t *testing.T) {
+	set := Load(context.Background(), testComponents(writeModule(t)))
+
+	document, err := set.Document("example.com/app/ui.Card", "card.schema.json")
+	require.NoError(t, err)
+
+	encoded, err := json.Marshal(document)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, Draft, decoded["$schema"])
+	assert.Equal(t, "card.schema.json", decoded["$id"])
+	assert.Equal(t, false, decoded["additionalProperties"])
+
+	properties := decoded["properties"].(map[string]interface{})
+	assert.Equal(t, "defs.schema.json#/$defs/User", properties["user"].(map[string]interface{})["$ref"])
+	assert.Equal(t, "#/$defs/User", set.Components["example.com/app/ui.Card"].Properties[0].Schema.Ref,
+		"the set's own schema is left untouched")
+
+	_, err = set.Document("example.com/app/ui.Broken", "broken.schema.json")
+	assert.Error(t, err)
+}
+
+func TestTypeScript(t *testing.T) {
+	set := Load(context.Background(), testComponents(writeModule(t)))
+
+	props, err := set.TypeScript("example.com/app/ui.Card")
+	require.NoError(t, err)
+	assert.Equal(t, `// Code generated by templar. DO NOT EDIT.
+
+import type { User, Variant } from "./defs";
+
+/** Card shows a user */
+export interface CardProps {
+  user: User;
+  variant: Variant;
+  footer?: string;
+  tags: string[];
+}
+`, props)
+
+	defs := set.TypeScriptDefs()
+	assert.Contains(t, defs, `export type Variant = "primary" | "secondary";`)
+	assert.Contains(t, defs, `export interface User {
+  created_at: string;
+  updated_by?: string;
+  id: number;
+  name: string;
+  email?: string;
+  manager?: User;
+  scores: Record<string, number>;
+}`)
+}
+
+func TestPropertiesMarshalJSON(t *testing.T) {
+	s := &Schema{
+		Type: "object",
+		Properties: Properties{
+			{Name: "z", Schema: &Schema{Type: "string"}},
+			{Name: "a", Schema: &Schema{Type: "array", Items: &Schema{Type: "integer"}}},
+		},
+	}
+
+	encoded, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"object","properties":{"z":{"type":"string"},"a":{"type":"array","items":{"type":"integer"}}}}`, string(encoded))
+}
@@ -0,0 +1,179 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefsModule is the name of the TypeScript module declaring the shared
+// definitions
+const DefsModule = "defs"
+
+// TypeScriptDefs renders the shared definitions as a TypeScript module:
+// interfaces for objects and union types for enums
+func (set *Set) TypeScriptDefs() string {
+	var out strings.Builder
+	out.WriteString("// Code generated by templar. DO NOT EDIT.\n")
+
+	for _, name := range sortedKeys(set.Defs) {
+		def := set.Defs[name]
+		out.WriteString("\n")
+		writeDoc(&out, "", def.Description)
+		if def.Type == "object" && def.AdditionalProperties == nil {
+			fmt.Fprintf(&out, "export interface %s ", name)
+			writeObject(&out, def, "")
+			out.WriteString("\n")
+			continue
+		}
+		fmt.Fprintf(&out, "export type %s = %s;\n", name, tsType(def, ""))
+	}
+
+	return out.String()
+}
+
+// TypeScript renders a component's props as an exported interface named
+// after the component, importing the shared definitions it refers to
+func (set *Set) TypeScript(id string) (string, error) {
+	s, ok := set.Components[id]
+	if !ok {
+		return "", fmt.Errorf("no schema for component %s", id)
+	}
+
+	var out strings.Builder
+	out.WriteString("// Code generated by templar. DO NOT EDIT.\n\n")
+
+	refs := make(map[string]bool)
+	collectRefs(s, refs)
+	if len(refs) > 0 {
+		fmt.Fprintf(&out, "import type { %s } from \"./%s\";\n\n", strings.Join(sortedKeys(refs), ", "), DefsModule)
+	}
+
+	writeDoc(&out, "", s.Description)
+	fmt.Fprintf(&out, "export interface %sProps ", s.Title)
+	writeObject(&out, s, "")
+	out.WriteString("\n")
+
+	return out.String(), nil
+}
+
+// writeObject writes the members of an object schema as a TypeScript
+// object type
+func writeObject(out *strings.Builder, s *Schema, indent string) {
+	if len(s.Properties) == 0 {
+		out.WriteString("{}")
+		return
+	}
+
+	out.WriteString("{\n")
+	for _, property := range s.Properties {
+		writeDoc(out, indent+"  ", property.Schema.Description)
+		optional := "?"
+		for _, required := range s.Required {
+			if required == property.Name {
+				optional = ""
+				break
+			}
+		}
+		fmt.Fprintf(out, "%s  %s%s: %s;\n", indent, propertyName(property.Name), optional, tsType(property.Schema, indent+"  "))
+	}
+	out.WriteString(indent + "}")
+}
+
+// tsType renders a schema as a TypeScript type expression
+func tsType(s *Schema, indent string) string {
+	if name, ok := s.DefName(); ok {
+		return name
+	}
+
+	if len(s.Enum) > 0 {
+		literals := make([]string, len(s.Enum))
+		for i, value := range s.Enum {
+			encoded, _ := json.Marshal(value)
+			literals[i] = string(encoded)
+		}
+		return strings.Join(literals, " | ")
+	}
+
+	switch s.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		item := tsType(s.Items, indent)
+		if strings.Contains(item, " | ") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "Record<string, " + tsType(s.AdditionalProperties, indent) + ">"
+		}
+		var out strings.Builder
+		writeObject(&out, s, indent)
+		return out.String()
+	default:
+		return "unknown"
+	}
+}
+
+// writeDoc writes a description as a JSDoc comment
+func writeDoc(out *strings.Builder, indent, description string) {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return
+	}
+	lines := strings.Split(strings.ReplaceAll(description, "*/", "*\\/"), "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(out, "%s/** %s */\n", indent, lines[0])
+		return
+	}
+	fmt.Fprintf(out, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(out, "%s * %s\n", indent, strings.TrimSpace(line))
+	}
+	fmt.Fprintf(out, "%s */\n", indent)
+}
+
+// propertyName quotes property names that are not valid identifiers
+func propertyName(name string) string {
+	for i, r := range name {
+		if r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9' {
+			continue
+		}
+		encoded, _ := json.Marshal(name)
+		return string(encoded)
+	}
+	if name == "" {
+		return `""`
+	}
+	return name
+}
+
+// collectRefs records the definitions a schema refers to
+func collectRefs(s *Schema, refs map[string]bool) {
+	if s == nil {
+		return
+	}
+	if name, ok := s.DefName(); ok {
+		refs[name] = true
+	}
+	collectRefs(s.Items, refs)
+	collectRefs(s.AdditionalProperties, refs)
+	for _, property := range s.Properties {
+		collectRefs(property.Schema, refs)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
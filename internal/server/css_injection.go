@@ -0,0 +1,105 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/conneroisu/templar/internal/config"
+	"github.com/conneroisu/templar/internal/watcher"
+)
+
+// staticDir is the project directory served under /static/
+const staticDir = "static"
+
+// serveStatic serves the project's static directory under /static/. Browsers
+// revalidate every response, so reloaded stylesheets are always current.
+func serveStatic(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+	http.StripPrefix("/static/", http.FileServer(http.Dir(staticDir))).ServeHTTP(w, r)
+}
+
+// watchStaticDir adds the static directory to the watched paths when it
+// exists, so that changes to its stylesheets reach the browser
+func watchStaticDir(addRecursive func(string) error) error {
+	if info, err := os.Stat(staticDir); err != nil || !info.IsDir() {
+		return nil
+	}
+	return addRecursive(staticDir)
+}
+
+// splitStylesheetChanges separates the changed stylesheets from the other
+// change events
+func splitStylesheetChanges(events []watcher.ChangeEvent) ([]string, []watcher.ChangeEvent) {
+	var stylesheets []string
+	others := make([]watcher.ChangeEvent, 0, len(events))
+	for _, event := range events {
+		if watcher.CSSFilter(event.Path) {
+			stylesheets = append(stylesheets, event.Path)
+		} else {
+			others = append(others, event)
+		}
+	}
+	return stylesheets, others
+}
+
+// stylesheetMessages returns the messages announcing changed stylesheets: a
+// css_update per stylesheet with development.css_injection, or a single
+// full_reload without it
+func stylesheetMessages(cfg *config.Config, stylesheets []string) []UpdateMessage {
+	if len(stylesheets) == 0 {
+		return nil
+	}
+	if cfg == nil || !cfg.Development.CSSInjection {
+		return []UpdateMessage{{Type: "full_reload", Timestamp: time.Now()}}
+	}
+
+	messages := make([]UpdateMessage, len(stylesheets))
+	for i, path := range stylesheets {
+		messages[i] = cssUpdateMessage(path)
+	}
+	return messages
+}
+
+// cssUpdateMessage returns the css_update message for a changed stylesheet.
+// Its hash is derived from the stylesheet's content and busts the browser
+// cache when the stylesheet is reloaded.
+func cssUpdateMessage(path string) UpdateMessage {
+	hash := sha256.New()
+	if content, err := os.ReadFile(path); err == nil {
+		hash.Write(content)
+	} else {
+		// Removed stylesheets still reload, to drop their rules
+		fmt.Fprint(hash, time.Now().UnixNano())
+	}
+
+	return UpdateMessage{
+		Type:      "css_update",
+		Target:    stylesheetURL(path),
+		Hash:      hex.EncodeToString(hash.Sum(nil)[:8]),
+		Timestamp: time.Now(),
+	}
+}
+
+// stylesheetURL returns the URL path a stylesheet is linked by. Paths are
+// relative to the project, so static/app.css is linked as /static/app.css.
+func stylesheetURL(path string) string {
+	if filepath.IsAbs(path) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil {
+				path = rel
+			}
+		}
+	}
+	return "/" + strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "./")
+}
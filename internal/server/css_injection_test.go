@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/conneroisu/templar/internal/config"
+	"github.com/conneroisu/templar/internal/watcher"
+)
+
+func writeStylesheet(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestStylesheetMessages(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeStylesheet(t, "static/css/app.css", "body { color: red; }")
+
+	cfg := &config.Config{}
+	cfg.Development.CSSInjection = true
+
+	messages := stylesheetMessages(cfg, []string{"static/css/app.css"})
+	require.Len(t, messages, 1)
+	assert.Equal(t, "css_update", messages[0].Type)
+	assert.Equal(t, "/static/css/app.css", messages[0].Target)
+	assert.Len(t, messages[0].Hash, 16)
+
+	// Absolute paths are linked relative to the project
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	absolute := stylesheetMessages(cfg, []string{filepath.Join(wd, "static", "css", "app.css")})
+	assert.Equal(t, messages[0].Target, absolute[0].Target)
+	assert.Equal(t, messages[0].Hash, absolute[0].Hash)
+
+	// The hash follows the content
+	writeStylesheet(t, "static/css/app.css", "body { color: blue; }")
+	assert.NotEqual(t, messages[0].Hash, stylesheetMessages(cfg, []string{"static/css/app.css"})[0].Hash)
+
+	// Without CSS injection the page reloads once
+	cfg.Development.CSSInjection = false
+	messages = stylesheetMessages(cfg, []string{"static/css/app.css", "components/card.css"})
+	require.Len(t, messages, 1)
+	assert.Equal(t, "full_reload", messages[0].Type)
+
+	assert.Empty(t, stylesheetMessages(cfg, nil))
+}
+
+func TestHandleFileChange_Stylesheet(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeStylesheet(t, "static/app.css", ".btn { color: red; }")
+
+	server := setupTestServer(t)
+	server.config.Development.CSSInjection = true
+	server.broadcast = make(chan []byte, 1)
+
+	// Only the stylesheet changed, so nothing is rebuilt
+	require.NoError(t, server.handleFileChange([]watcher.ChangeEvent{
+		{Type: watcher.EventTypeModified, Path: "static/app.css"},
+	}))
+
+	var msg UpdateMessage
+	require.NoError(t, json.Unmarshal(<-server.broadcast, &msg))
+	assert.Equal(t, "css_update", msg.Type)
+	assert.Equal(t, "/static/app.css", msg.Target)
+	assert.NotEmpty(t, msg.Hash)
+}
+
+func TestServeStatic(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeStylesheet(t, "static/css/app.css", ".btn { color: red; }")
+	require.NoError(t, os.WriteFile("secret.txt", []byte("secret"), 0644))
+
+	w := httptest.NewRecorder()
+	serveStatic(w, httptest.NewRequest(http.MethodGet, "/static/css/app.css?templar=abc", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ".btn { color: red; }", w.Body.String())
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+
+	for _, path := range []string{"/static/", "/static/css/", "/static/missing.css", "/static/../secret.txt"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/static/", nil)
+		req.URL.Path = path
+		serveStatic(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"log"
+
+	"github.com/conneroisu/templar/internal/build"
+	"github.com/conneroisu/templar/internal/cache"
+	"github.com/conneroisu/templar/internal/config"
+	"github.com/conneroisu/templar/internal/renderer"
+	"github.com/conneroisu/templar/internal/scanner"
+)
+
+// diskCacheUser is implemented by services that keep their work in the disk
+// cache across restarts
+type diskCacheUser interface {
+	SetDiskCache(diskCache *cache.DiskCache)
+}
+
+var (
+	_ diskCacheUser = (*scanner.ComponentScanner)(nil)
+	_ diskCacheUser = (*build.RefactoredBuildPipeline)(nil)
+	_ diskCacheUser = (*renderer.ComponentRenderer)(nil)
+)
+
+// attachDiskCache opens the disk cache in build.cache_dir and hands it to the
+// services able to use it. The services work without it when the cache is
+// not configured or can't be opened.
+func attachDiskCache(cfg *config.Config, services ...interface{}) *cache.DiskCache {
+	if cfg == nil || cfg.Build.CacheDir == "" {
+		return nil
+	}
+
+	diskCache, err := cache.Open(cfg.Build.CacheDir, cfg.Build.CacheMaxSize)
+	if err != nil {
+		log.Printf("Warning: disk cache disabled: %v", err)
+		return nil
+	}
+
+	for _, service := range services {
+		if user, ok := service.(diskCacheUser); ok {
+			user.SetDiskCache(diskCache)
+		}
+	}
+	return diskCache
+}
@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -12,6 +15,7 @@ import (
 	"github.com/conneroisu/templar/internal/renderer"
 	"github.com/conneroisu/templar/internal/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEnhancedWebInterface(t *testing.T) {
@@ -102,13 +106,53 @@ func TestInlineEditor(t *testing.T) {
 	}
 	reg.Register(component)
 
-	renderer := renderer.NewComponentRenderer(reg)
+	componentRenderer := renderer.NewComponentRenderer(reg)
 	server := &PreviewServer{
 		registry: reg,
-		renderer: renderer,
+		renderer: componentRenderer,
 	}
 
 	t.Run("Inline Render Action", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("skipping inline render in short mode")
+		}
+		for _, tool := range []string{"go", "templ"} {
+			if _, err := exec.LookPath(tool); err != nil {
+				t.Skipf("%s command not available", tool)
+			}
+		}
+
+		// Rendering calls the real component, so it needs a templ source
+		root := writePlaygroundFixture(t)
+		cardPath := filepath.Join(root, "ui", "test_card.templ")
+		require.NoError(t, os.WriteFile(cardPath, []byte(`package ui
+
+templ TestCard(title string, content string, visible bool, count int) {
+	<div class="TestCard">
+		<h3>{ title }</h3>
+		if visible {
+			<p>{ content }</p>
+		}
+	</div>
+}
+`), 0644))
+
+		renderReg := registry.NewComponentRegistry()
+		renderReg.Register(&types.ComponentInfo{
+			Name:       "TestCard",
+			Package:    "ui",
+			FilePath:   cardPath,
+			IsExported: true,
+			Kind:       types.ComponentKindTempl,
+			Parameters: component.Parameters,
+		})
+		renderRenderer := renderer.NewComponentRenderer(renderReg)
+		defer renderRenderer.Close()
+		server := &PreviewServer{
+			registry: renderReg,
+			renderer: renderRenderer,
+		}
+
 		requestBody := map[string]interface{}{
 			"component_name": "TestCard",
 			"props": map[string]interface{}{
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/conneroisu/templar/internal/renderer"
 	"github.com/conneroisu/templar/internal/types"
 )
 
@@ -1115,9 +1116,12 @@ func (s *PreviewServer) generateEnhancedIndexHTML() string {
                 case 'component_update':
                     loadComponents();
                     break;
+                case 'css_update':
+                    swapStylesheets(message.target, message.hash);
+                    break;
             }
         }
-        
+        `+renderer.StylesheetSwapScript+`
         function loadComponents() {
             fetch('/components')
                 .then(response => response.json())
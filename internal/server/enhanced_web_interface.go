@@ -94,7 +94,7 @@ func (s *PreviewServer) handleInlineEditor(w http.ResponseWriter, r *http.Reques
 // handleInlineRender renders component with props for inline preview
 func (s *PreviewServer) handleInlineRender(w http.ResponseWriter, component *types.ComponentInfo, props map[string]interface{}) {
 	// Use the playground renderer for consistent behavior
-	html, err := s.renderComponentWithProps(component.Name, props)
+	html, err := s.renderComponentWithProps(component.ID, props)
 	if err != nil {
 		response := map[string]interface{}{"error": "Render error: " + err.Error()}
 		s.writeJSONResponse(w, response)
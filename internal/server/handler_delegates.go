@@ -48,18 +48,21 @@ func handleComponentsList(w http.ResponseWriter, r *http.Request, registry inter
 
 // handleComponentDetail handles requests for individual component details
 func handleComponentDetail(w http.ResponseWriter, r *http.Request, registry interfaces.ComponentRegistry, renderer *renderer.ComponentRenderer) {
-	// Extract component name from URL path
-	path := r.URL.Path
-	componentName := path[len("/component/"):]
+	// Extract the qualified component ID from URL path
+	componentID := componentIDFromPath(r.URL.Path, "/component/")
 	
-	if componentName == "" {
+	if componentID == "" {
 		http.Error(w, "Component name required", http.StatusBadRequest)
 		return
 	}
 	
-	component, exists := registry.Get(componentName)
-	if !exists {
-		http.Error(w, "Component not found", http.StatusNotFound)
+	if err := validateComponentID(componentID); err != nil {
+		http.Error(w, "Invalid component name: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	component, ok := resolveComponent(w, r, registry, componentID)
+	if !ok {
 		return
 	}
 	
@@ -76,18 +79,21 @@ func handleComponentDetail(w http.ResponseWriter, r *http.Request, registry inte
 
 // handleComponentRender handles component rendering requests
 func handleComponentRender(w http.ResponseWriter, r *http.Request, registry interfaces.ComponentRegistry, renderer *renderer.ComponentRenderer) {
-	// Extract component name from URL path
-	path := r.URL.Path
-	componentName := path[len("/render/"):]
+	// Extract the qualified component ID from URL path
+	componentID := componentIDFromPath(r.URL.Path, "/render/")
 	
-	if componentName == "" {
+	if componentID == "" {
 		http.Error(w, "Component name required", http.StatusBadRequest)
 		return
 	}
 	
-	component, exists := registry.Get(componentName)
-	if !exists {
-		http.Error(w, "Component not found", http.StatusNotFound)
+	if err := validateComponentID(componentID); err != nil {
+		http.Error(w, "Invalid component name: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	
+	component, ok := resolveComponent(w, r, registry, componentID)
+	if !ok {
 		return
 	}
 	
@@ -101,8 +107,7 @@ func handleComponentRender(w http.ResponseWriter, r *http.Request, registry inte
 
 // handleStaticFiles handles static file requests
 func handleStaticFiles(w http.ResponseWriter, r *http.Request) {
-	// Basic static file serving
-	http.FileServer(http.Dir("./static")).ServeHTTP(w, r)
+	serveStatic(w, r)
 }
 
 // handlePlaygroundIndexPage handles playground index page
@@ -2,13 +2,19 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/conneroisu/templar/internal/interfaces"
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/renderer"
 	"github.com/conneroisu/templar/internal/types"
 )
 
@@ -176,7 +182,7 @@ const indexHTML = `<!DOCTYPE html>
                     loadComponents();
                     break;
                 case 'css_update':
-                    updateCSS(message.content);
+                    swapStylesheets(message.target, message.hash);
                     break;
             }
         }
@@ -212,6 +218,21 @@ const indexHTML = `<!DOCTYPE html>
                             '</div>' +
                             '<div class="mt-3 text-xs text-gray-400">Package: ' + (component.package || 'unknown') + '</div>';
                         
+                        // Stories declared in sidecar files link to their preview
+                        const stories = component.Examples || [];
+                        if (stories.length > 0) {
+                            const storyList = document.createElement('div');
+                            storyList.className = 'mt-3 flex flex-wrap gap-2';
+                            stories.forEach(story => {
+                                const link = document.createElement('a');
+                                link.href = '/render/' + component.ID + '?story=' + encodeURIComponent(story.Name);
+                                link.className = 'text-xs bg-blue-50 text-primary rounded px-2 py-1 hover:bg-blue-100';
+                                link.textContent = story.Name;
+                                storyList.appendChild(link);
+                            });
+                            card.appendChild(storyList);
+                        }
+                        
                         container.appendChild(card);
                     });
                 })
@@ -225,13 +246,7 @@ const indexHTML = `<!DOCTYPE html>
                 });
         }
         
-        function updateCSS(content) {
-            // Update CSS without full reload
-            const style = document.createElement('style');
-            style.textContent = content;
-            document.head.appendChild(style);
-        }
-        
+        // Update CSS without full reload` + renderer.StylesheetSwapScript + `        
         // Initialize connection
         connect();
         
@@ -260,19 +275,17 @@ func (s *PreviewServer) handleComponents(w http.ResponseWriter, r *http.Request)
 }
 
 func (s *PreviewServer) handleComponent(w http.ResponseWriter, r *http.Request) {
-	// Extract component name from path
-	path := strings.TrimPrefix(r.URL.Path, "/component/")
-	componentName := strings.Split(path, "/")[0]
+	// Extract the component ID - qualified IDs span several path segments
+	componentID := componentIDFromPath(r.URL.Path, "/component/")
 
-	// Validate component name to prevent path traversal and injection attacks
-	if err := validateComponentName(componentName); err != nil {
+	// Validate component ID to prevent path traversal and injection attacks
+	if err := validateComponentID(componentID); err != nil {
 		http.Error(w, "Invalid component name: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	component, exists := s.registry.Get(componentName)
-	if !exists {
-		http.NotFound(w, r)
+	component, ok := resolveComponent(w, r, s.registry, componentID)
+	if !ok {
 		return
 	}
 
@@ -283,9 +296,7 @@ func (s *PreviewServer) handleComponent(w http.ResponseWriter, r *http.Request)
 }
 
 func (s *PreviewServer) handleStatic(w http.ResponseWriter, r *http.Request) {
-	// Handle static files
-	// For now, just return 404
-	http.NotFound(w, r)
+	serveStatic(w, r)
 }
 
 // renderComponentPage renders an individual component page
@@ -359,27 +370,57 @@ func (s *PreviewServer) handleMultipleFiles(w http.ResponseWriter, r *http.Reque
 }
 
 func (s *PreviewServer) handleRender(w http.ResponseWriter, r *http.Request) {
-	// Extract component name from URL path
-	path := strings.TrimPrefix(r.URL.Path, "/render/")
-	componentName := strings.Split(path, "/")[0]
+	// Extract the component ID from URL path
+	componentID := componentIDFromPath(r.URL.Path, "/render/")
 
-	if componentName == "" {
+	if componentID == "" {
 		http.Error(w, "Component name required", http.StatusBadRequest)
 		return
 	}
 
-	// Render the component
-	html, err := s.renderer.RenderComponent(componentName)
+	if err := validateComponentID(componentID); err != nil {
+		http.Error(w, "Invalid component name: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Render the component, or one of its stories when ?story= is given
+	storyName := r.URL.Query().Get("story")
+	var html string
+	var err error
+	if storyName != "" {
+		html, err = s.renderer.RenderStory(componentID, storyName)
+	} else {
+		html, err = s.renderer.RenderComponent(componentID)
+	}
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error rendering component %s: %v", componentName, err), http.StatusInternalServerError)
+		var ambiguous *registry.AmbiguousComponentError
+		if errors.As(err, &ambiguous) {
+			http.Error(w, ambiguous.Error(), http.StatusConflict)
+			return
+		}
+		var missingStory *renderer.StoryNotFoundError
+		if errors.As(err, &missingStory) {
+			http.Error(w, missingStory.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Error rendering component %s: %v", componentID, err), http.StatusInternalServerError)
 		return
 	}
 
+	title := componentID
+	if component, exists := s.registry.Get(componentID); exists {
+		title = component.Name
+		if storyName != "" {
+			title += " / " + storyName
+		}
+		html = storyNavigation(component, storyName) + renderer.MarkPreview(component.ID, storyName, html)
+	}
+
 	// Get nonce from request context for CSP
 	nonce := GetNonceFromContext(r.Context())
 
 	// Wrap in layout with nonce support
-	fullHTML := s.renderer.RenderComponentWithLayoutAndNonce(componentName, html, nonce)
+	fullHTML := s.renderer.RenderComponentWithLayoutAndNonce(title, html, nonce)
 
 	w.Header().Set("Content-Type", "text/html")
 	if _, err := w.Write([]byte(fullHTML)); err != nil {
@@ -387,9 +428,78 @@ func (s *PreviewServer) handleRender(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// storyNavigation renders links to the stories of a component, highlighting
+// the selected one and showing its notes. Components without stories get no
+// navigation.
+func storyNavigation(component *types.ComponentInfo, selected string) string {
+	if len(component.Examples) == 0 {
+		return ""
+	}
+
+	link := func(name, query string) string {
+		class := "px-3 py-1 rounded text-sm bg-gray-100 text-gray-700 hover:bg-gray-200"
+		if name == selected {
+			class = "px-3 py-1 rounded text-sm bg-primary text-white"
+		}
+		return fmt.Sprintf(`<a href="/render/%s%s" class="%s">%s</a>`,
+			component.ID, query, class, html.EscapeString(defaultString(name, "Default")))
+	}
+
+	var nav strings.Builder
+	nav.WriteString(`<nav class="templar-stories flex flex-wrap gap-2 mb-4">`)
+	nav.WriteString(link("", ""))
+	var notes string
+	for _, example := range component.Examples {
+		nav.WriteString(link(example.Name, "?story="+url.QueryEscape(example.Name)))
+		if example.Name == selected {
+			notes = strings.TrimSpace(strings.Join([]string{example.Description, example.Notes}, "\n\n"))
+		}
+	}
+	nav.WriteString(`</nav>`)
+
+	if notes != "" {
+		nav.WriteString(fmt.Sprintf(`<p class="templar-story-notes text-sm text-gray-600 mb-4 whitespace-pre-line">%s</p>`, html.EscapeString(notes)))
+	}
+
+	return nav.String()
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// resolveComponent looks up a component by qualified ID or unambiguous name,
+// writing a 404 for unknown components and a 409 listing the candidates for
+// ambiguous names. It reports whether a component was found.
+func resolveComponent(w http.ResponseWriter, r *http.Request, reg interfaces.ComponentRegistry, componentID string) (*types.ComponentInfo, bool) {
+	component, err := reg.Resolve(componentID)
+	if err == nil {
+		return component, true
+	}
+
+	var ambiguous *registry.AmbiguousComponentError
+	if errors.As(err, &ambiguous) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return nil, false
+	}
+
+	http.NotFound(w, r)
+	return nil, false
+}
+
+// componentIDFromPath extracts the component ID following prefix in a URL
+// path. IDs contain the component's import path, so every remaining segment
+// belongs to the ID.
+func componentIDFromPath(urlPath, prefix string) string {
+	return strings.Trim(strings.TrimPrefix(urlPath, prefix), "/")
+}
+
 func (s *PreviewServer) renderSingleComponent(w http.ResponseWriter, r *http.Request, component *types.ComponentInfo) {
 	// Render the component directly
-	html, err := s.renderer.RenderComponent(component.Name)
+	html, err := s.renderer.RenderComponent(component.ID)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error rendering component %s: %v", component.Name, err), http.StatusInternalServerError)
 		return
@@ -420,11 +530,16 @@ func (s *PreviewServer) renderComponentSelection(w http.ResponseWriter, r *http.
         <div class="grid gap-4">`, filename, filename)
 
 	for _, component := range components {
+		componentID := component.ID
+		if componentID == "" {
+			componentID = component.Name
+		}
+
 		html += fmt.Sprintf(`
             <a href="/render/%s" class="bg-white rounded-lg shadow p-4 hover:shadow-md transition-shadow">
                 <h2 class="text-lg font-semibold text-blue-600">%s</h2>
                 <p class="text-gray-600 text-sm mt-1">%d parameters</p>
-            </a>`, component.Name, component.Name, len(component.Parameters))
+            </a>`, componentID, component.Name, len(component.Parameters))
 	}
 
 	html += `
@@ -471,6 +586,33 @@ func (s *PreviewServer) renderFileSelection(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+// validateComponentID validates a qualified component ID such as
+// "github.com/acme/app/ui.Button". IDs may contain forward slashes from the
+// import path but are otherwise held to the same rules as component names.
+func validateComponentID(id string) error {
+	if id == "" {
+		return fmt.Errorf("empty component name")
+	}
+
+	if len(id) > 256 {
+		return fmt.Errorf("component ID too long (max 256 characters)")
+	}
+
+	for _, segment := range strings.Split(id, "/") {
+		if segment == "" {
+			return fmt.Errorf("empty path segment in component ID")
+		}
+		if len(segment) > 100 {
+			return fmt.Errorf("component ID segment too long (max 100 characters)")
+		}
+		if err := validateComponentName(segment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // validateComponentName validates component name to prevent security issues
 func validateComponentName(name string) error {
 	// Reject empty names
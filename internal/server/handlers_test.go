@@ -153,6 +153,69 @@ func TestHandleComponent(t *testing.T) {
 	})
 }
 
+func TestHandleComponent_QualifiedID(t *testing.T) {
+	server := setupTestServer(t)
+	server.registry.Register(&types.ComponentInfo{
+		Name:       "Button",
+		Package:    "ui",
+		ImportPath: "example.com/app/ui",
+		FilePath:   "/test/ui/button.templ",
+	})
+	server.registry.Register(&types.ComponentInfo{
+		Name:       "Button",
+		Package:    "marketing",
+		ImportPath: "example.com/app/marketing",
+		FilePath:   "/test/marketing/button.templ",
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		expectedID string
+	}{
+		{"fully qualified ID", "/component/example.com/app/ui.Button", "example.com/app/ui.Button"},
+		{"package qualified name", "/component/marketing.Button", "example.com/app/marketing.Button"},
+		{"unambiguous bare name", "/component/TestButton", "main.TestButton"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			server.handleComponent(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var component types.ComponentInfo
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &component))
+			assert.Equal(t, tt.expectedID, component.ID)
+		})
+	}
+
+	t.Run("ambiguous bare name", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/component/Button", nil)
+		w := httptest.NewRecorder()
+
+		server.handleComponent(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "ambiguous")
+		assert.Contains(t, w.Body.String(), "example.com/app/marketing.Button")
+		assert.Contains(t, w.Body.String(), "example.com/app/ui.Button")
+	})
+
+	t.Run("ambiguous render", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/render/Button", nil)
+		w := httptest.NewRecorder()
+
+		server.handleRender(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "ambiguous")
+	})
+}
+
 func TestHandleStatic(t *testing.T) {
 	server := setupTestServer(t)
 
@@ -161,7 +224,7 @@ func TestHandleStatic(t *testing.T) {
 
 	server.handleStatic(w, req)
 
-	// Currently returns 404 as static handling is not implemented
+	// Files missing from the static directory are not found
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
@@ -188,10 +251,42 @@ func TestHandleRender(t *testing.T) {
 		assert.Contains(t, w.Body.String(), "Error rendering component")
 	})
 
+	t.Run("unknown story", func(t *testing.T) {
+		component, _ := server.registry.Get("TestButton")
+		component.Examples = []types.ComponentExample{{Name: "Primary"}}
+
+		req := httptest.NewRequest(http.MethodGet, "/render/TestButton?story=Ghost", nil)
+		w := httptest.NewRecorder()
+
+		server.handleRender(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), `no story "Ghost", available stories: Primary`)
+	})
+
 	// Note: Testing successful rendering would require actual templ files and Go environment
 	// which is complex for unit tests. Integration tests would be better suited for this.
 }
 
+func TestStoryNavigation(t *testing.T) {
+	component := &types.ComponentInfo{
+		ID:   "example.com/app/ui.Button",
+		Name: "Button",
+		Examples: []types.ComponentExample{
+			{Name: "Primary"},
+			{Name: "Dark mode", Description: "On dark surfaces", Notes: "Uses <strong> contrast"},
+		},
+	}
+
+	nav := storyNavigation(component, "Dark mode")
+	assert.Contains(t, nav, `href="/render/example.com/app/ui.Button"`)
+	assert.Contains(t, nav, `href="/render/example.com/app/ui.Button?story=Primary"`)
+	assert.Contains(t, nav, `href="/render/example.com/app/ui.Button?story=Dark+mode" class="px-3 py-1 rounded text-sm bg-primary text-white"`)
+	assert.Contains(t, nav, "On dark surfaces\n\nUses &lt;strong&gt; contrast")
+
+	assert.Empty(t, storyNavigation(&types.ComponentInfo{Name: "Badge"}, ""))
+}
+
 func TestHandleTargetFiles(t *testing.T) {
 	t.Run("single target file", func(t *testing.T) {
 		cfg := &config.Config{
@@ -2,11 +2,14 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/conneroisu/templar/internal/mockdata"
+	"github.com/conneroisu/templar/internal/registry"
 	"github.com/conneroisu/templar/internal/renderer"
 	"github.com/conneroisu/templar/internal/types"
 	"golang.org/x/text/cases"
@@ -21,6 +24,8 @@ type PlaygroundRequest struct {
 	ViewportSize  ViewportSize           `json:"viewport_size,omitempty"`
 	MockData      bool                   `json:"mock_data,omitempty"`
 	GenerateCode  bool                   `json:"generate_code,omitempty"`
+	// Story selects a story whose props form the base that Props override
+	Story string `json:"story,omitempty"`
 }
 
 // PlaygroundResponse represents a response from the interactive playground
@@ -31,9 +36,22 @@ type PlaygroundResponse struct {
 	CurrentProps      map[string]interface{} `json:"current_props"`
 	MockDataSuggests  map[string]interface{} `json:"mock_data_suggestions"`
 	ComponentMetadata *ComponentMetadata     `json:"metadata"`
+	Stories           []PlaygroundStory      `json:"stories,omitempty"`
+	Story             string                 `json:"story,omitempty"`
 	Error             string                 `json:"error,omitempty"`
 }
 
+// PlaygroundStory describes a named variant declared in a story file
+type PlaygroundStory struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Props       map[string]interface{} `json:"props,omitempty"`
+	Viewport    ViewportSize           `json:"viewport,omitempty"`
+	Wrapper     string                 `json:"wrapper,omitempty"`
+	Background  string                 `json:"background,omitempty"`
+	Notes       string                 `json:"notes,omitempty"`
+}
+
 // PropDefinition describes a component property
 type PropDefinition struct {
 	Name        string      `json:"name"`
@@ -77,33 +95,75 @@ func (s *PreviewServer) handlePlaygroundRender(w http.ResponseWriter, r *http.Re
 	}
 
 	// Validate component name
-	if err := validateComponentName(req.ComponentName); err != nil {
+	if err := validateComponentID(req.ComponentName); err != nil {
 		response := PlaygroundResponse{Error: "Invalid component name: " + err.Error()}
 		s.writeJSONResponse(w, response)
 		return
 	}
 
 	// Get component from registry
-	component, exists := s.registry.Get(req.ComponentName)
-	if !exists {
+	component, err := s.registry.Resolve(req.ComponentName)
+	if err != nil {
+		var ambiguous *registry.AmbiguousComponentError
 		response := PlaygroundResponse{Error: fmt.Sprintf("Component '%s' not found", req.ComponentName)}
+		if errors.As(err, &ambiguous) {
+			response.Error = err.Error()
+		}
 		s.writeJSONResponse(w, response)
 		return
 	}
 
-	// Generate mock data if requested
-	if req.MockData || len(req.Props) == 0 {
-		req.Props = s.generateIntelligentMockData(component)
+	var story *types.ComponentExample
+	if req.Story != "" {
+		// Story props are the base, explicit props override them
+		example, err := renderer.FindStory(component, req.Story)
+		if err != nil {
+			response := PlaygroundResponse{Error: err.Error(), Stories: playgroundStories(component)}
+			s.writeJSONResponse(w, response)
+			return
+		}
+		story = &example
+		storyProps, err := s.renderer.StoryProps(component, example)
+		if err != nil {
+			s.writeJSONResponse(w, PlaygroundResponse{Error: err.Error(), Stories: playgroundStories(component)})
+			return
+		}
+		req.Props = mergeProps(storyProps, req.Props)
+		if req.ViewportSize.Width == 0 && req.ViewportSize.Height == 0 {
+			req.ViewportSize = ViewportSize{Width: example.Viewport.Width, Height: example.Viewport.Height, Name: example.Name}
+		}
+	} else if req.MockData || len(req.Props) == 0 {
+		// Generate mock data if requested
+		req.Props, err = s.mockProps(component)
+		if err != nil {
+			s.writeJSONResponse(w, PlaygroundResponse{Error: err.Error(), Stories: playgroundStories(component)})
+			return
+		}
 	}
 
-	// Render component with custom renderer that supports prop injection
-	html, err := s.renderComponentWithProps(req.ComponentName, req.Props)
+	// Render the real component with the props decoded into its parameter types
+	html, err := s.renderComponentWithProps(component.ID, req.Props)
 	if err != nil {
 		response := PlaygroundResponse{Error: "Render error: " + err.Error()}
+		var propErr *renderer.PropError
+		if errors.As(err, &propErr) {
+			response.Error = "Invalid props: " + propErr.Error()
+		}
+		response.AvailableProps = s.extractPropDefinitions(component)
+		response.CurrentProps = req.Props
+		response.Stories = playgroundStories(component)
 		s.writeJSONResponse(w, response)
 		return
 	}
 
+	if story != nil {
+		html, err = s.renderer.PresentStory(component, *story, html)
+		if err != nil {
+			s.writeJSONResponse(w, PlaygroundResponse{Error: "Render error: " + err.Error(), Stories: playgroundStories(component)})
+			return
+		}
+	}
+
 	// Wrap in playground layout
 	html = s.wrapInPlaygroundLayout(req.ComponentName, html, req.Theme, req.ViewportSize)
 
@@ -114,6 +174,8 @@ func (s *PreviewServer) handlePlaygroundRender(w http.ResponseWriter, r *http.Re
 		CurrentProps:      req.Props,
 		MockDataSuggests:  s.generateMockDataSuggestions(component),
 		ComponentMetadata: s.buildComponentMetadata(component),
+		Stories:           playgroundStories(component),
+		Story:             req.Story,
 	}
 
 	// Generate code if requested
@@ -133,15 +195,14 @@ func (s *PreviewServer) handlePlaygroundComponent(w http.ResponseWriter, r *http
 	}
 
 	// Validate component name
-	if err := validateComponentName(componentName); err != nil {
+	if err := validateComponentID(componentName); err != nil {
 		http.Error(w, "Invalid component name: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	// Check if component exists
-	component, exists := s.registry.Get(componentName)
-	if !exists {
-		http.NotFound(w, r)
+	component, ok := resolveComponent(w, r, s.registry, componentName)
+	if !ok {
 		return
 	}
 
@@ -159,19 +220,60 @@ func (s *PreviewServer) handlePlaygroundIndex(w http.ResponseWriter, r *http.Req
 	w.Write([]byte(html))
 }
 
-// renderComponentWithProps renders a component with custom props
-func (s *PreviewServer) renderComponentWithProps(componentName string, props map[string]interface{}) (string, error) {
-	// Create a temporary renderer with custom mock data
-	renderer := s.createCustomRenderer(props)
-	return renderer.RenderComponent(componentName)
+// playgroundStories lists the stories declared for a component
+func playgroundStories(component *types.ComponentInfo) []PlaygroundStory {
+	stories := make([]PlaygroundStory, 0, len(component.Examples))
+	for _, example := range component.Examples {
+		stories = append(stories, PlaygroundStory{
+			Name:        example.Name,
+			Description: example.Description,
+			Props:       example.Props,
+			Viewport:    ViewportSize{Width: example.Viewport.Width, Height: example.Viewport.Height},
+			Wrapper:     example.Wrapper,
+			Background:  example.Background,
+			Notes:       example.Notes,
+		})
+	}
+	return stories
 }
 
-// generateIntelligentMockData creates contextually appropriate mock data
+// mergeProps returns base with overrides applied on top
+func mergeProps(base, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overrides))
+	for name, value := range base {
+		merged[name] = value
+	}
+	for name, value := range overrides {
+		merged[name] = value
+	}
+	return merged
+}
+
+// renderComponentWithProps renders the real component, decoding props into
+// its parameter types
+func (s *PreviewServer) renderComponentWithProps(componentID string, props map[string]interface{}) (string, error) {
+	return s.renderer.RenderComponentWithProps(componentID, props)
+}
+
+// mockProps generates mock data for a component and merges the default
+// fixtures from preview.mock_data over it
+func (s *PreviewServer) mockProps(component *types.ComponentInfo) (map[string]interface{}, error) {
+	props, err := s.renderer.Fixtures().Apply(component, mockdata.DefaultVariant, s.generateIntelligentMockData(component))
+	if err != nil {
+		return nil, fmt.Errorf("loading mock data fixtures: %w", err)
+	}
+	return props, nil
+}
+
+// generateIntelligentMockData creates contextually appropriate mock data.
+// Parameters without a mock value are left out and render with their zero value.
 func (s *PreviewServer) generateIntelligentMockData(component *types.ComponentInfo) map[string]interface{} {
 	mockData := make(map[string]interface{})
 	
 	for _, param := range component.Parameters {
-		mockData[param.Name] = s.generateMockValueForType(param.Name, param.Type)
+		if value := s.generateMockValueForType(param.Name, param.Type); value != nil {
+			mockData[param.Name] = value
+		}
 	}
 	
 	return mockData
@@ -193,8 +295,8 @@ func (s *PreviewServer) generateMockValueForType(paramName, paramType string) in
 	case "float64", "float32":
 		return s.generateMockFloat(paramLower)
 	default:
-		// For complex types, return a JSON-serializable placeholder
-		return fmt.Sprintf("mock_%s", paramName)
+		// Complex types have no generic mock; the parameter keeps its zero value
+		return nil
 	}
 }
 
@@ -535,114 +637,6 @@ func (s *PreviewServer) formatPropForCode(key string, value interface{}) string
 	}
 }
 
-// createCustomRenderer creates a renderer with custom mock data
-func (s *PreviewServer) createCustomRenderer(props map[string]interface{}) *CustomMockRenderer {
-	return &CustomMockRenderer{
-		baseRenderer: s.renderer,
-		customProps:  props,
-	}
-}
-
-// CustomMockRenderer extends the base renderer with custom prop injection
-type CustomMockRenderer struct {
-	baseRenderer *renderer.ComponentRenderer
-	customProps  map[string]interface{}
-}
-
-// RenderComponent renders with injected props (mock implementation for playground)
-func (cmr *CustomMockRenderer) RenderComponent(componentName string) (string, error) {
-	// For the playground, we generate mock HTML based on the component name and props
-	// This allows the playground to work without actual templ compilation
-	return cmr.generateMockHTML(componentName, cmr.customProps)
-}
-
-// generateMockHTML creates mock HTML representation of a component
-func (cmr *CustomMockRenderer) generateMockHTML(componentName string, props map[string]interface{}) (string, error) {
-	var html strings.Builder
-	
-	// Create a representative HTML structure based on component name
-	html.WriteString(fmt.Sprintf(`<div class="component %s-component" data-component="%s">`, 
-		strings.ToLower(componentName), componentName))
-	
-	// Add component name as header
-	html.WriteString(fmt.Sprintf(`<h3 class="component-title">%s</h3>`, componentName))
-	
-	// Render props as content
-	if len(props) > 0 {
-		html.WriteString(`<div class="component-props">`)
-		
-		for key, value := range props {
-			html.WriteString(fmt.Sprintf(`<div class="prop-item">
-				<strong class="prop-name">%s:</strong> 
-				<span class="prop-value">%v</span>
-			</div>`, key, value))
-		}
-		
-		html.WriteString(`</div>`)
-	}
-	
-	// Add mock content based on component type
-	switch strings.ToLower(componentName) {
-	case "button":
-		text := "Click Me"
-		if textProp, exists := props["text"]; exists {
-			text = fmt.Sprintf("%v", textProp)
-		}
-		html.WriteString(fmt.Sprintf(`<button class="btn mock-button">%s</button>`, text))
-		
-	case "card":
-		title := "Card Title"
-		if titleProp, exists := props["title"]; exists {
-			title = fmt.Sprintf("%v", titleProp)
-		}
-		content := "Sample card content"
-		if contentProp, exists := props["content"]; exists {
-			content = fmt.Sprintf("%v", contentProp)
-		}
-		html.WriteString(fmt.Sprintf(`
-			<div class="card mock-card">
-				<div class="card-header">%s</div>
-				<div class="card-body">%s</div>
-			</div>`, title, content))
-			
-	case "input":
-		placeholder := "Enter text..."
-		if placeholderProp, exists := props["placeholder"]; exists {
-			placeholder = fmt.Sprintf("%v", placeholderProp)
-		}
-		html.WriteString(fmt.Sprintf(`<input type="text" class="form-input mock-input" placeholder="%s">`, placeholder))
-		
-	default:
-		// Generic component representation
-		html.WriteString(fmt.Sprintf(`
-			<div class="mock-component-content">
-				<p>This is a mock representation of the <code>%s</code> component.</p>
-				<p>In a real implementation, this would render the actual templ component.</p>
-			</div>`, componentName))
-	}
-	
-	html.WriteString(`</div>`)
-	
-	// Add basic styling
-	html.WriteString(`
-		<style>
-			.component { border: 2px dashed #e2e8f0; padding: 20px; margin: 10px; border-radius: 8px; }
-			.component-title { color: #1e293b; margin: 0 0 15px 0; font-size: 1.2em; }
-			.component-props { background: #f8fafc; padding: 15px; border-radius: 6px; margin: 15px 0; }
-			.prop-item { margin: 5px 0; }
-			.prop-name { color: #3b82f6; }
-			.prop-value { background: #e0f2fe; padding: 2px 6px; border-radius: 3px; font-family: monospace; }
-			.mock-button { background: #3b82f6; color: white; padding: 8px 16px; border: none; border-radius: 4px; cursor: pointer; }
-			.mock-card { border: 1px solid #e2e8f0; border-radius: 6px; }
-			.card-header { background: #f8fafc; padding: 12px; font-weight: bold; border-bottom: 1px solid #e2e8f0; }
-			.card-body { padding: 15px; }
-			.mock-input { border: 1px solid #d1d5db; padding: 8px; border-radius: 4px; width: 200px; }
-			.mock-component-content { background: #fef3c7; padding: 15px; border-radius: 6px; color: #92400e; }
-		</style>`)
-	
-	return html.String(), nil
-}
-
 // writeJSONResponse writes a JSON response
 func (s *PreviewServer) writeJSONResponse(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -5,30 +5,123 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/conneroisu/templar/internal/mockdata"
 	"github.com/conneroisu/templar/internal/registry"
 	"github.com/conneroisu/templar/internal/renderer"
+	"github.com/conneroisu/templar/internal/scanner"
 	"github.com/conneroisu/templar/internal/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// writePlaygroundFixture writes a module with a templ component whose
+// parameters use structs, pointers, slices, maps, time.Time and named enums,
+// returning its root directory.
+func writePlaygroundFixture(t *testing.T) string {
+	t.Helper()
+
+	root, err := os.MkdirTemp(".", "playgroundtest")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	goSum, err := os.ReadFile(filepath.Join("..", "..", "go.sum"))
+	require.NoError(t, err)
+
+	files := map[string]string{
+		"go.mod": "module example.com/playground\n\ngo 1.24\n\nrequire github.com/a-h/templ v0.3.906\n",
+		"go.sum": string(goSum),
+		"ui/types.go": `package ui
+
+type Variant string
+
+const (
+	VariantPrimary Variant = "primary"
+	VariantDanger  Variant = "danger"
+)
+
+type Author struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+type Link struct {
+	Label string ` + "`json:\"label\"`" + `
+	Href  string ` + "`json:\"href\"`" + `
+}
+`,
+		"ui/card.templ": `package ui
+
+import (
+	"fmt"
+	"time"
+)
+
+templ Card(title string, variant Variant, author *Author, links []Link, stats map[string]int, published time.Time) {
+	<article class={ string(variant) }>
+		<h2>{ title }</h2>
+		if author != nil {
+			<p class="author">{ author.Name }</p>
+		}
+		for _, link := range links {
+			<a href={ templ.SafeURL(link.Href) }>{ link.Label }</a>
+		}
+		<span class="views">{ fmt.Sprint(stats["views"]) }</span>
+		<time>{ published.Format("2006-01-02") }</time>
+	</article>
+}
+`,
+		"ui/card.stories.yaml": `stories:
+  - name: Featured
+    props:
+      title: Featured post
+      variant: VariantPrimary
+      author: {name: Grace}
+    viewport: {width: 375, height: 667}
+    background: "#f3f4f6"
+    notes: Shown on the landing page.
+`,
+		"mocks/cards.yaml": `ui.Card:
+  default:
+    author: {$ref: shared/author}
+    stats: {views: 7}
+  Featured:
+    stats: {views: 99}
+`,
+		"mocks/shared/author.json": `{"name": "Linus"}`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	return root
+}
+
 func TestPlaygroundRender(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping playground render in short mode")
+	}
+	for _, tool := range []string{"go", "templ"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s command not available", tool)
+		}
+	}
+
 	// Setup
+	root := writePlaygroundFixture(t)
 	reg := registry.NewComponentRegistry()
-	component := &types.ComponentInfo{
-		Name:    "TestButton",
-		Package: "components",
-		Parameters: []types.ParameterInfo{
-			{Name: "text", Type: "string", Optional: false},
-			{Name: "variant", Type: "string", Optional: true},
-			{Name: "disabled", Type: "bool", Optional: true},
-		},
-	}
-	reg.Register(component)
+	require.NoError(t, scanner.NewComponentScanner(reg).ScanDirectory(root))
 
 	renderer := renderer.NewComponentRenderer(reg)
+	renderer.SetFixtures(mockdata.NewFixtures(filepath.Join(root, "mocks")))
+	defer renderer.Close()
 	server := &PreviewServer{
 		registry: reg,
 		renderer: renderer,
@@ -43,37 +136,108 @@ func TestPlaygroundRender(t *testing.T) {
 		{
 			name: "valid component with mock data",
 			request: PlaygroundRequest{
-				ComponentName: "TestButton",
+				ComponentName: "Card",
 				MockData:      true,
 				GenerateCode:  true,
 			},
 			expectError: false,
 			validateFunc: func(t *testing.T, resp PlaygroundResponse) {
 				assert.NotEmpty(t, resp.AvailableProps)
-				assert.Equal(t, 3, len(resp.AvailableProps))
+				assert.Equal(t, 6, len(resp.AvailableProps))
 				assert.NotEmpty(t, resp.CurrentProps)
 				assert.NotEmpty(t, resp.GeneratedCode)
 				assert.NotNil(t, resp.ComponentMetadata)
+				assert.Contains(t, resp.HTML, "<article")
+				// Fixtures from mock_data override the generated values
+				assert.Equal(t, map[string]interface{}{"name": "Linus"}, resp.CurrentProps["author"])
+				assert.Contains(t, resp.HTML, `<p class="author">Linus</p>`)
+				assert.Contains(t, resp.HTML, `<span class="views">7</span>`)
 			},
 		},
 		{
-			name: "valid component with custom props",
+			name: "valid component with typed props",
 			request: PlaygroundRequest{
-				ComponentName: "TestButton",
+				ComponentName: "example.com/playground/ui.Card",
 				Props: map[string]interface{}{
-					"text":     "Click Me",
-					"variant":  "primary",
-					"disabled": false,
+					"title":     "Release notes",
+					"variant":   "VariantDanger",
+					"author":    map[string]interface{}{"name": "Ada"},
+					"links":     []interface{}{map[string]interface{}{"label": "Docs", "href": "/docs"}},
+					"stats":     map[string]interface{}{"views": 42},
+					"published": "2024-05-01T10:00:00Z",
 				},
 				GenerateCode: true,
 			},
 			expectError: false,
 			validateFunc: func(t *testing.T, resp PlaygroundResponse) {
-				assert.Equal(t, "Click Me", resp.CurrentProps["text"])
-				assert.Equal(t, "primary", resp.CurrentProps["variant"])
-				assert.Equal(t, false, resp.CurrentProps["disabled"])
-				assert.Contains(t, resp.GeneratedCode, "TestButton(")
-				assert.Contains(t, resp.GeneratedCode, "Click Me")
+				assert.Equal(t, "Release notes", resp.CurrentProps["title"])
+				assert.Contains(t, resp.HTML, `<article class="danger">`)
+				assert.Contains(t, resp.HTML, "<h2>Release notes</h2>")
+				assert.Contains(t, resp.HTML, `<p class="author">Ada</p>`)
+				assert.Contains(t, resp.HTML, `<a href="/docs">Docs</a>`)
+				assert.Contains(t, resp.HTML, `<span class="views">42</span>`)
+				assert.Contains(t, resp.HTML, "<time>2024-05-01</time>")
+				assert.Contains(t, resp.GeneratedCode, "Card(")
+				assert.Contains(t, resp.GeneratedCode, "Release notes")
+			},
+		},
+		{
+			name: "prop that does not match its type",
+			request: PlaygroundRequest{
+				ComponentName: "ui.Card",
+				Props: map[string]interface{}{
+					"links": []interface{}{map[string]interface{}{"label": "Docs", "href": 7}},
+				},
+			},
+			expectError: true,
+			validateFunc: func(t *testing.T, resp PlaygroundResponse) {
+				assert.Equal(t, "Invalid props: prop links[0].href: expected string, got number", resp.Error)
+				assert.Empty(t, resp.HTML)
+			},
+		},
+		{
+			name: "invalid enum value",
+			request: PlaygroundRequest{
+				ComponentName: "Card",
+				Props:         map[string]interface{}{"variant": "ghost"},
+			},
+			expectError: true,
+			validateFunc: func(t *testing.T, resp PlaygroundResponse) {
+				assert.Contains(t, resp.Error, `prop variant: "ghost" is not a valid ui.Variant`)
+			},
+		},
+		{
+			name: "story props with overrides",
+			request: PlaygroundRequest{
+				ComponentName: "Card",
+				Story:         "Featured",
+				Props:         map[string]interface{}{"title": "Overridden"},
+			},
+			expectError: false,
+			validateFunc: func(t *testing.T, resp PlaygroundResponse) {
+				require.Len(t, resp.Stories, 1)
+				assert.Equal(t, "Featured", resp.Stories[0].Name)
+				assert.Equal(t, ViewportSize{Width: 375, Height: 667}, resp.Stories[0].Viewport)
+				assert.Equal(t, "Shown on the landing page.", resp.Stories[0].Notes)
+				assert.Equal(t, "Featured", resp.Story)
+				assert.Equal(t, "Overridden", resp.CurrentProps["title"])
+				assert.Contains(t, resp.HTML, `data-story="Featured" style="background: #f3f4f6; width: 375px; min-height: 667px"`)
+				assert.Contains(t, resp.HTML, `<article class="primary">`)
+				assert.Contains(t, resp.HTML, "<h2>Overridden</h2>")
+				assert.Contains(t, resp.HTML, `<p class="author">Grace</p>`)
+				assert.Contains(t, resp.HTML, `<span class="views">99</span>`)
+			},
+		},
+		{
+			name: "unknown story",
+			request: PlaygroundRequest{
+				ComponentName: "Card",
+				Story:         "Ghost",
+			},
+			expectError: true,
+			validateFunc: func(t *testing.T, resp PlaygroundResponse) {
+				assert.Equal(t, `component Card has no story "Ghost", available stories: Featured`, resp.Error)
+				assert.Len(t, resp.Stories, 1)
 			},
 		},
 		{
@@ -307,6 +307,11 @@ func (s *PreviewServer) wrapInPlaygroundLayout(componentName, html, theme string
                 <!-- Props will be dynamically populated -->
             </div>
             
+            <div id="storyNotesSection" style="display: none;">
+                <div class="subsection-title">Story Notes</div>
+                <div class="metadata-value" id="storyNotes"></div>
+            </div>
+            
             <div class="subsection-title">Generated Code</div>
             <div class="code-output" id="generatedCode">
                 Loading...
@@ -325,6 +330,10 @@ func (s *PreviewServer) wrapInPlaygroundLayout(componentName, html, theme string
                     <option value="dark">Dark Theme</option>
                 </select>
                 
+                <select id="storySelect" class="prop-select" style="width: auto; display: none;">
+                    <option value="">Default props</option>
+                </select>
+                
                 <div class="viewport-preset" data-preset="mobile">
                     📱 Mobile (375×667)
                 </div>
@@ -364,6 +373,8 @@ func (s *PreviewServer) wrapInPlaygroundLayout(componentName, html, theme string
         let componentName = '%s';
         let currentTheme = '%s';
         let currentViewport = { width: %d, height: %d, name: '%s' };
+        let currentStory = '';
+        let stories = [];
         
         // Initialize playground
         document.addEventListener('DOMContentLoaded', function() {
@@ -385,6 +396,21 @@ func (s *PreviewServer) wrapInPlaygroundLayout(componentName, html, theme string
                 refreshComponent();
             });
             
+            // Story selector: a story replaces the props and, when it declares
+            // one, the viewport
+            document.getElementById('storySelect').addEventListener('change', function() {
+                currentStory = this.value;
+                currentProps = {};
+                const story = stories.find(s => s.name === currentStory);
+                if (story && story.viewport && story.viewport.width) {
+                    currentViewport = { width: story.viewport.width, height: story.viewport.height, name: story.name };
+                    document.querySelectorAll('.viewport-preset').forEach(p => p.classList.remove('active'));
+                    updateViewport();
+                }
+                updateStoryNotes(story);
+                loadComponentData();
+            });
+            
             // Viewport presets
             document.querySelectorAll('.viewport-preset').forEach(preset => {
                 preset.addEventListener('click', function() {
@@ -432,8 +458,9 @@ func (s *PreviewServer) wrapInPlaygroundLayout(componentName, html, theme string
                         props: currentProps,
                         theme: currentTheme,
                         viewport_size: currentViewport,
-                        mock_data: true,
-                        generate_code: true
+                        mock_data: currentStory === '',
+                        generate_code: true,
+                        story: currentStory
                     })
                 });
                 
@@ -444,6 +471,8 @@ func (s *PreviewServer) wrapInPlaygroundLayout(componentName, html, theme string
                     return;
                 }
                 
+                updateStorySelect(data.stories || []);
+                updateComponentHTML(data.html);
                 updatePropEditor(data.available_props, data.current_props);
                 updateGeneratedCode(data.generated_code);
                 updateMetadata(data.metadata);
@@ -527,12 +556,19 @@ func (s *PreviewServer) wrapInPlaygroundLayout(componentName, html, theme string
                     return numberInput;
                     
                 case 'string':
-                default:
                     const textInput = document.createElement('input');
                     textInput.type = 'text';
                     textInput.className = 'prop-input';
                     textInput.value = currentValue || '';
                     return textInput;
+                    
+                default:
+                    // Structs, slices, maps, enums and times are edited as JSON
+                    const jsonInput = document.createElement('textarea');
+                    jsonInput.className = 'prop-input';
+                    jsonInput.rows = 4;
+                    jsonInput.value = currentValue === undefined || currentValue === null ? '' : JSON.stringify(currentValue, null, 2);
+                    return jsonInput;
             }
         }
         
@@ -551,8 +587,20 @@ func (s *PreviewServer) wrapInPlaygroundLayout(componentName, html, theme string
                 case 'float32':
                     currentProps[propName] = parseFloat(value) || 0.0;
                     break;
-                default:
+                case 'string':
                     currentProps[propName] = value;
+                    break;
+                default:
+                    if (value.trim() === '') {
+                        delete currentProps[propName];
+                        break;
+                    }
+                    try {
+                        currentProps[propName] = JSON.parse(value);
+                    } catch (error) {
+                        // Bare words such as enum constant names are sent as strings
+                        currentProps[propName] = value.trim();
+                    }
             }
             
             // Debounce refresh
@@ -571,7 +619,8 @@ func (s *PreviewServer) wrapInPlaygroundLayout(componentName, html, theme string
                         theme: currentTheme,
                         viewport_size: currentViewport,
                         mock_data: false,
-                        generate_code: true
+                        generate_code: true,
+                        story: currentStory
                     })
                 });
                 
@@ -601,6 +650,26 @@ func (s *PreviewServer) wrapInPlaygroundLayout(componentName, html, theme string
             }
         }
         
+        function updateStorySelect(available) {
+            stories = available;
+            const select = document.getElementById('storySelect');
+            select.style.display = stories.length > 0 ? '' : 'none';
+            select.length = 1;
+            stories.forEach(story => {
+                const option = document.createElement('option');
+                option.value = story.name;
+                option.textContent = story.name;
+                select.appendChild(option);
+            });
+            select.value = currentStory;
+        }
+        
+        function updateStoryNotes(story) {
+            const notes = story ? [story.description, story.notes].filter(Boolean).join('\n\n') : '';
+            document.getElementById('storyNotes').textContent = notes;
+            document.getElementById('storyNotesSection').style.display = notes ? '' : 'none';
+        }
+        
         function updateGeneratedCode(code) {
             const codeOutput = document.getElementById('generatedCode');
             codeOutput.textContent = code || 'No code generated';
@@ -669,10 +738,13 @@ func (s *PreviewServer) wrapInPlaygroundLayout(componentName, html, theme string
 // generatePlaygroundHTML creates the main playground interface for a component
 func (s *PreviewServer) generatePlaygroundHTML(component *types.ComponentInfo) string {
 	// Generate initial mock data
-	mockData := s.generateIntelligentMockData(component)
-	
+	mockData, err := s.mockProps(component)
+
 	// Render component with mock data
-	html, err := s.renderComponentWithProps(component.Name, mockData)
+	html := ""
+	if err == nil {
+		html, err = s.renderComponentWithProps(component.ID, mockData)
+	}
 	if err != nil {
 		html = fmt.Sprintf(`<div class="error">Error rendering component: %s</div>`, err.Error())
 	}
@@ -696,7 +768,7 @@ func (s *PreviewServer) generatePlaygroundIndexHTML(components []*types.Componen
 					<span class="preview-badge">Click to Preview</span>
 				</div>
 			</div>
-		`, component.Name, component.Name, component.Package, len(component.Parameters)))
+		`, component.ID, component.Name, component.Package, len(component.Parameters)))
 	}
 	
 	return fmt.Sprintf(`<!DOCTYPE html>
@@ -43,7 +43,8 @@ func NewRefactoredPreviewServer(
 ) (*RefactoredPreviewServer, error) {
 	
 	// Create renderer
-	renderer := renderer.NewComponentRenderer(registry)
+	renderer := newPreviewRenderer(cfg, registry)
+	attachDiskCache(cfg, scanner, buildPipeline, renderer)
 	
 	// Create origin validator (implements OriginValidator interface)
 	originValidator := &ServerOriginValidator{config: cfg}
@@ -66,6 +67,9 @@ func NewRefactoredPreviewServer(
 		OriginValidator: originValidator,
 	})
 	
+	// Create tracer of file changes
+	tracer, traces := newTracer(cfg)
+	
 	// Create service orchestrator
 	orchestrator := NewServiceOrchestrator(ServiceDependencies{
 		Config:        cfg,
@@ -76,6 +80,7 @@ func NewRefactoredPreviewServer(
 		Renderer:      renderer,
 		Monitor:       monitor,
 		WSManager:     wsManager,
+		Tracer:        tracer,
 	})
 	
 	// Create HTTP handlers adapter that implements HTTPHandlers interface
@@ -89,6 +94,7 @@ func NewRefactoredPreviewServer(
 	
 	// Create HTTP router
 	httpRouter := NewHTTPRouter(cfg, handlerAdapter, middlewareChain)
+	httpRouter.RegisterCustomRoute("/api/traces", handleTraces(traces))
 	
 	server := &RefactoredPreviewServer{
 		config:          cfg,
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/conneroisu/templar/internal/interfaces"
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/types"
+	"github.com/conneroisu/templar/pkg/stories"
+)
+
+// changeGraph is implemented by registries that can tell which components a
+// changed file affects through their dependency graph
+type changeGraph interface {
+	ComponentsForFile(filePath string) []*types.ComponentInfo
+	GetTransitiveDependents(componentNames ...string) []*types.ComponentInfo
+}
+
+var _ changeGraph = (*registry.ComponentRegistry)(nil)
+
+// affectedComponents returns the components to rebuild after files changed:
+// those declared in a changed templ file or using a changed Go package, and
+// every component depending on them in turn. Story files stand for the templ
+// file they describe, and generated _templ.go files are the output of builds
+// rather than a cause for them. It returns false when a change can't be
+// traced to any component, calling for a full rebuild.
+func affectedComponents(componentRegistry interfaces.ComponentRegistry, paths []string) ([]*types.ComponentInfo, bool) {
+	graph, ok := componentRegistry.(changeGraph)
+
+	affected := make(map[string]*types.ComponentInfo)
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_templ.go") {
+			continue
+		}
+		if templPath := stories.TemplFile(path); templPath != "" {
+			path = templPath
+		}
+
+		var components []*types.ComponentInfo
+		if ok {
+			components = graph.ComponentsForFile(path)
+		} else {
+			components = componentsInFile(componentRegistry, path)
+		}
+		if len(components) == 0 {
+			return nil, false
+		}
+		for _, component := range components {
+			affected[component.ID] = component
+		}
+	}
+
+	if ok && len(affected) > 0 {
+		ids := make([]string, 0, len(affected))
+		for id := range affected {
+			ids = append(ids, id)
+		}
+		for _, dependent := range graph.GetTransitiveDependents(ids...) {
+			affected[dependent.ID] = dependent
+		}
+	}
+
+	components := make([]*types.ComponentInfo, 0, len(affected))
+	for _, component := range affected {
+		components = append(components, component)
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].ID < components[j].ID })
+	return components, true
+}
+
+// componentsInFile returns the components declared in a file
+func componentsInFile(componentRegistry interfaces.ComponentRegistry, path string) []*types.ComponentInfo {
+	var components []*types.ComponentInfo
+	for _, component := range componentRegistry.GetAll() {
+		if filepath.Clean(component.FilePath) == filepath.Clean(path) {
+			components = append(components, component)
+		}
+	}
+	return components
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/types"
+)
+
+func TestAffectedComponents(t *testing.T) {
+	// The registry rejects component paths under the system's temp directory
+	root, err := os.MkdirTemp(".", "rebuildtest")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.24\n",
+		"ui/card.templ": `package ui
+
+templ Card() {
+	<div></div>
+}
+`,
+		"pages/page.templ": `package pages
+
+import "example.com/app/ui"
+
+templ Page() {
+	@ui.Card()
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	reg := registry.NewComponentRegistry()
+	reg.Register(&types.ComponentInfo{Name: "Card", Package: "ui", FilePath: filepath.Join(root, "ui/card.templ"), Kind: types.ComponentKindTempl})
+	reg.Register(&types.ComponentInfo{Name: "Page", Package: "pages", FilePath: filepath.Join(root, "pages/page.templ"), Kind: types.ComponentKindTempl,
+		Imports: []string{"example.com/app/ui"}})
+	require.NoError(t, reg.UpdateAllDependencies())
+
+	names := func(paths ...string) []string {
+		t.Helper()
+		components, ok := affectedComponents(reg, paths)
+		require.True(t, ok)
+		result := make([]string, 0, len(components))
+		for _, component := range components {
+			result = append(result, component.Name)
+		}
+		return result
+	}
+
+	// A changed component is rebuilt with its dependents
+	assert.Equal(t, []string{"Page"}, names(filepath.Join(root, "pages/page.templ")))
+	assert.Equal(t, []string{"Page", "Card"}, names(filepath.Join(root, "ui/card.templ")))
+	assert.Equal(t, []string{"Page", "Card"}, names(filepath.Join(root, "ui/card.stories.yaml")))
+
+	// Go files affect the components using their package
+	assert.Equal(t, []string{"Page", "Card"}, names(filepath.Join(root, "ui/variants.go")))
+
+	// Generated files are the output of builds
+	assert.Empty(t, names(filepath.Join(root, "ui/card_templ.go")))
+
+	// Changes that can't be traced to components rebuild everything
+	_, ok := affectedComponents(reg, []string{filepath.Join(root, "cmd/main.go")})
+	assert.False(t, ok)
+}
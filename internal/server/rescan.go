@@ -0,0 +1,52 @@
+package server
+
+import (
+	"log"
+
+	"github.com/conneroisu/templar/internal/interfaces"
+	"github.com/conneroisu/templar/internal/scanner"
+	"github.com/conneroisu/templar/internal/watcher"
+)
+
+// rescanningWatcher is implemented by file watchers that report the roots
+// to rescan when their file events were lost
+type rescanningWatcher interface {
+	AddRescanHandler(handler watcher.RescanHandlerFunc)
+}
+
+// directoryReconciler is implemented by scanners that can bring the
+// registry back in line with the components on disk
+type directoryReconciler interface {
+	ReconcileDirectory(dir string) error
+}
+
+var (
+	_ rescanningWatcher   = (*watcher.FileWatcher)(nil)
+	_ directoryReconciler = (*scanner.ComponentScanner)(nil)
+)
+
+// watchRescans rescans the roots a file watcher lost events under through
+// the scanner, then calls rebuild to bring the previews up to date
+func watchRescans(fileWatcher interfaces.FileWatcher, componentScanner interfaces.ComponentScanner, rebuild func()) {
+	rescanning, ok := fileWatcher.(rescanningWatcher)
+	if !ok {
+		return
+	}
+
+	rescanning.AddRescanHandler(func(roots []string) error {
+		for _, root := range roots {
+			log.Printf("File events under %s were missed, rescanning", root)
+			var err error
+			if reconciler, ok := componentScanner.(directoryReconciler); ok {
+				err = reconciler.ReconcileDirectory(root)
+			} else {
+				err = componentScanner.ScanDirectory(root)
+			}
+			if err != nil {
+				log.Printf("Failed to rescan %s: %v", root, err)
+			}
+		}
+		rebuild()
+		return nil
+	})
+}
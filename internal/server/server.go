@@ -15,19 +15,25 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/conneroisu/templar/internal/build"
+	"github.com/conneroisu/templar/internal/cache"
 	"github.com/conneroisu/templar/internal/config"
 	"github.com/conneroisu/templar/internal/errors"
 	"github.com/conneroisu/templar/internal/interfaces"
+	"github.com/conneroisu/templar/internal/mockdata"
 	"github.com/conneroisu/templar/internal/monitoring"
 	"github.com/conneroisu/templar/internal/registry"
 	"github.com/conneroisu/templar/internal/renderer"
 	"github.com/conneroisu/templar/internal/scanner"
+	"github.com/conneroisu/templar/internal/tracing"
 	"github.com/conneroisu/templar/internal/types"
 	"github.com/conneroisu/templar/internal/validation"
 	"github.com/conneroisu/templar/internal/version"
@@ -59,6 +65,7 @@ type PreviewServer struct {
 	scanner         interfaces.ComponentScanner
 	renderer        *renderer.ComponentRenderer
 	buildPipeline   interfaces.BuildPipeline
+	diskCache       *cache.DiskCache
 	lastBuildErrors []*errors.ParsedError
 	shutdownOnce    sync.Once
 	isShutdown      bool
@@ -69,6 +76,9 @@ type PreviewServer struct {
 	monitor *monitoring.TemplarMonitor
 	// Rate limiting
 	rateLimiter *TokenBucketManager
+	// Tracing of file changes, nil when disabled
+	tracer *tracing.Tracer
+	traces *tracing.Recorder
 }
 
 // UpdateMessage represents a message sent to the browser
@@ -76,23 +86,31 @@ type UpdateMessage struct {
 	Type      string    `json:"type"`
 	Target    string    `json:"target,omitempty"`
 	Content   string    `json:"content,omitempty"`
+	Hash      string    `json:"hash,omitempty"` // content hash of css_update stylesheets
+	Overlay   string    `json:"overlay,omitempty"` // error overlay markup of build_error messages
 	Timestamp time.Time `json:"timestamp"`
+	TraceID   string    `json:"trace_id,omitempty"` // trace of the change that caused the message
 }
 
 // New creates a new preview server (deprecated: use NewWithDependencies)
 func New(cfg *config.Config) (*PreviewServer, error) {
 	registry := registry.NewComponentRegistry()
 
-	fileWatcher, err := watcher.NewFileWatcher(300 * time.Millisecond)
+	fileWatcher, err := watcher.NewFileWatcherWithOptions(300*time.Millisecond, watcher.Options{
+		Backend:         cfg.Development.Watcher.Backend,
+		PollInterval:    cfg.Development.Watcher.PollInterval,
+		MaxPollInterval: cfg.Development.Watcher.MaxPollInterval,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create file watcher: %w", err)
 	}
 
 	scanner := scanner.NewComponentScanner(registry)
-	renderer := renderer.NewComponentRenderer(registry)
+	renderer := newPreviewRenderer(cfg, registry)
 
 	// Create build pipeline
 	buildPipeline := build.NewRefactoredBuildPipeline(4, registry)
+	diskCache := attachDiskCache(cfg, scanner, buildPipeline, renderer)
 
 	// Initialize monitoring if enabled
 	var templatorMonitor *monitoring.TemplarMonitor
@@ -105,6 +123,7 @@ func New(cfg *config.Config) (*PreviewServer, error) {
 			log.Printf("Server monitoring initialized")
 		}
 	}
+	tracer, traces := newTracer(cfg)
 
 	return &PreviewServer{
 		config:          cfg,
@@ -117,8 +136,11 @@ func New(cfg *config.Config) (*PreviewServer, error) {
 		scanner:         scanner,
 		renderer:        renderer,
 		buildPipeline:   buildPipeline,
+		diskCache:       diskCache,
 		lastBuildErrors: make([]*errors.ParsedError, 0),
 		monitor:         templatorMonitor,
+		tracer:          tracer,
+		traces:          traces,
 	}, nil
 }
 
@@ -132,7 +154,9 @@ func NewWithDependencies(
 	buildPipeline interfaces.BuildPipeline,
 	monitor *monitoring.TemplarMonitor,
 ) *PreviewServer {
-	renderer := renderer.NewComponentRenderer(componentRegistry)
+	renderer := newPreviewRenderer(cfg, componentRegistry)
+	diskCache := attachDiskCache(cfg, scanner, buildPipeline, renderer)
+	tracer, traces := newTracer(cfg)
 
 	return &PreviewServer{
 		config:          cfg,
@@ -145,9 +169,33 @@ func NewWithDependencies(
 		scanner:         scanner,
 		renderer:        renderer,
 		buildPipeline:   buildPipeline,
+		diskCache:       diskCache,
 		lastBuildErrors: make([]*errors.ParsedError, 0),
 		monitor:         monitor,
+		tracer:          tracer,
+		traces:          traces,
+	}
+}
+
+// newPreviewRenderer creates the component renderer used by the preview
+// servers, with the prop fixtures configured by preview.mock_data and the
+// layout configured by preview.wrapper
+func newPreviewRenderer(cfg *config.Config, componentRegistry interfaces.ComponentRegistry) *renderer.ComponentRenderer {
+	componentRenderer := renderer.NewComponentRenderer(componentRegistry)
+	if cfg == nil {
+		return componentRenderer
+	}
+
+	if dir := cfg.Preview.FixturesDir(); dir != "" {
+		fixtures, err := mockdata.LoadFixtures(dir)
+		if err != nil {
+			log.Printf("Warning: mock data fixtures: %v", err)
+		}
+		componentRenderer.SetFixtures(fixtures)
 	}
+	componentRenderer.SetWrapper(cfg.Preview.Wrapper)
+
+	return componentRenderer
 }
 
 // NewRefactoredWithDependencies creates a new refactored preview server with proper SRP
@@ -222,6 +270,7 @@ func (s *PreviewServer) Start(ctx context.Context) error {
 	mux.HandleFunc("/api/build/metrics", s.handleBuildMetrics)
 	mux.HandleFunc("/api/build/errors", s.handleBuildErrors)
 	mux.HandleFunc("/api/build/cache", s.handleBuildCache)
+	mux.HandleFunc("/api/traces", handleTraces(s.traces))
 
 	// Root handler depends on whether specific files are targeted
 	if len(s.config.TargetFiles) > 0 {
@@ -259,8 +308,7 @@ func (s *PreviewServer) Start(ctx context.Context) error {
 
 func (s *PreviewServer) setupFileWatcher(ctx context.Context) {
 	// Add filters (convert to interface types)
-	s.watcher.AddFilter(interfaces.FileFilterFunc(watcher.TemplFilter))
-	s.watcher.AddFilter(interfaces.FileFilterFunc(watcher.GoFilter))
+	s.watcher.AddFilter(watcher.AnyFilter(watcher.TemplFilter, watcher.GoFilter, watcher.StoryFilter, watcher.CSSFilter))
 	s.watcher.AddFilter(interfaces.FileFilterFunc(watcher.NoTestFilter))
 	s.watcher.AddFilter(interfaces.FileFilterFunc(watcher.NoVendorFilter))
 	s.watcher.AddFilter(interfaces.FileFilterFunc(watcher.NoGitFilter))
@@ -275,6 +323,14 @@ func (s *PreviewServer) setupFileWatcher(ctx context.Context) {
 		return s.handleFileChange(changeEvents)
 	})
 
+	// Rescan directories whose file events were lost
+	watchRescans(s.watcher, s.scanner, func() {
+		if s.renderer != nil {
+			s.renderer.Invalidate()
+		}
+		s.triggerFullRebuild(context.Background())
+	})
+
 	// Add watch paths
 	for _, path := range s.config.Components.ScanPaths {
 		if err := s.watcher.AddRecursive(path); err != nil {
@@ -282,6 +338,18 @@ func (s *PreviewServer) setupFileWatcher(ctx context.Context) {
 		}
 	}
 
+	// The wrapper layout may live outside the scan paths
+	if wrapperFile := s.wrapperFile(); wrapperFile != "" {
+		if err := s.watcher.AddPath(filepath.Dir(wrapperFile)); err != nil {
+			log.Printf("Failed to watch wrapper %s: %v", wrapperFile, err)
+		}
+	}
+
+	// Stylesheets served under /static/ are reloaded in place
+	if err := watchStaticDir(s.watcher.AddRecursive); err != nil {
+		log.Printf("Failed to watch static directory: %v", err)
+	}
+
 	// Start watching
 	if err := s.watcher.Start(ctx); err != nil {
 		log.Printf("Failed to start file watcher: %v", err)
@@ -299,38 +367,75 @@ func (s *PreviewServer) initialScan() error {
 		}
 	}
 
+	if wrapperFile := s.wrapperFile(); wrapperFile != "" {
+		if err := s.scanner.ScanFile(wrapperFile); err != nil {
+			log.Printf("Error scanning wrapper %s: %v", wrapperFile, err)
+		}
+	}
+
 	log.Printf("Found %d components", s.registry.Count())
 	return nil
 }
 
+// wrapperFile returns the existing .templ file named by preview.wrapper, or ""
+func (s *PreviewServer) wrapperFile() string {
+	if s.renderer == nil {
+		return ""
+	}
+	wrapperFile := s.renderer.WrapperFile()
+	if wrapperFile == "" {
+		return ""
+	}
+	if _, err := os.Stat(wrapperFile); err != nil {
+		return ""
+	}
+	return wrapperFile
+}
+
 func (s *PreviewServer) handleFileChange(events []watcher.ChangeEvent) error {
-	componentsToRebuild := make(map[string]*types.ComponentInfo)
+	ctx, span := startChangeTrace(s.tracer, events)
+	defer span.End()
+
+	// Stylesheet changes need no rebuild, the browser reloads them
+	stylesheets, events := splitStylesheetChanges(events)
+	for _, msg := range stylesheetMessages(s.config, stylesheets) {
+		log.Printf("Stylesheet changed: %s", msg.Target)
+		s.broadcastMessageWithContext(ctx, msg)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	changedPaths := make([]string, len(events))
+	for i, event := range events {
+		changedPaths[i] = event.Path
+	}
+
+	// Go and templ changes require the render host to be rebuilt
+	if s.renderer != nil {
+		s.renderer.InvalidateFiles(changedPaths)
+	}
 
 	for _, event := range events {
 		log.Printf("File changed: %s (%s)", event.Path, event.Type)
 
 		// Rescan the file
-		if err := s.scanner.ScanFile(event.Path); err != nil {
+		if err := scanFile(ctx, s.scanner, event.Path); err != nil {
 			log.Printf("Failed to rescan file %s: %v", event.Path, err)
 		}
-
-		// Find components in the changed file
-		components := s.registry.GetAll()
-		for _, component := range components {
-			if component.FilePath == event.Path {
-				componentsToRebuild[component.Name] = component
-			}
-		}
 	}
 
-	// Queue components for rebuild
-	for _, component := range componentsToRebuild {
-		s.buildPipeline.BuildWithPriority(component)
+	// Rebuild the changed components and their dependents, or everything
+	// when a change can't be traced to components
+	components, ok := affectedComponents(s.registry, changedPaths)
+	if !ok {
+		span.SetAttribute("full_rebuild", true)
+		s.triggerFullRebuild(ctx)
+		return nil
 	}
-
-	// If no specific components to rebuild, do a full rebuild
-	if len(componentsToRebuild) == 0 {
-		s.triggerFullRebuild()
+	span.SetAttribute("components", len(components))
+	for _, component := range components {
+		buildComponentWithPriority(ctx, s.buildPipeline, component)
 	}
 
 	return nil
@@ -447,6 +552,17 @@ func (s *PreviewServer) isAllowedOrigin(origin string) bool {
 }
 
 func (s *PreviewServer) broadcastMessage(msg UpdateMessage) {
+	s.broadcastMessageWithContext(context.Background(), msg)
+}
+
+// broadcastMessageWithContext broadcasts a message caused by the change
+// traced by ctx, tagging it with the trace ID
+func (s *PreviewServer) broadcastMessageWithContext(ctx context.Context, msg UpdateMessage) {
+	msg.TraceID = tracing.TraceIDFromContext(ctx)
+	_, span := tracing.Start(ctx, "websocket.broadcast")
+	span.SetAttribute("type", msg.Type)
+	defer span.End()
+
 	// Marshal message to JSON
 	jsonData, err := json.Marshal(msg)
 	if err != nil {
@@ -461,36 +577,77 @@ func (s *PreviewServer) broadcastMessage(msg UpdateMessage) {
 
 // handleBuildResult handles build results from the build pipeline
 func (s *PreviewServer) handleBuildResult(result build.BuildResult) {
+	ctx := buildResultContext(result)
 	if result.Error != nil {
 		// Store build errors
 		s.lastBuildErrors = result.ParsedErrors
 
 		// Broadcast error message
-		msg := UpdateMessage{
-			Type:      "build_error",
-			Content:   errors.FormatErrorsForBrowser(result.ParsedErrors),
-			Timestamp: time.Now(),
-		}
-		s.broadcastMessage(msg)
+		s.broadcastMessageWithContext(ctx, buildErrorMessage(s.config, result.ParsedErrors))
 	} else {
 		// Clear previous errors
 		s.lastBuildErrors = make([]*errors.ParsedError, 0)
 
 		// Broadcast success message
-		msg := UpdateMessage{
+		s.broadcastMessageWithContext(ctx, buildSuccessMessage(ctx, s.config, s.renderer, result.Component))
+	}
+}
+
+// buildErrorMessage returns the message announcing a failed build, carrying
+// the errors as plain text. With development.error_overlay it also carries
+// the error overlay preview pages show.
+func buildErrorMessage(cfg *config.Config, parsedErrors []*errors.ParsedError) UpdateMessage {
+	var content strings.Builder
+	for _, parsedError := range parsedErrors {
+		content.WriteString(parsedError.FormatError())
+	}
+
+	msg := UpdateMessage{
+		Type:      "build_error",
+		Content:   content.String(),
+		Timestamp: time.Now(),
+	}
+	if cfg != nil && cfg.Development.ErrorOverlay {
+		msg.Overlay = errors.FormatErrorOverlay(parsedErrors, errors.OverlayOptions{
+			EditorURL:     cfg.Development.EditorURL,
+			ContextRadius: 3,
+		})
+	}
+	return msg
+}
+
+// buildSuccessMessage returns the message announcing a rebuilt component.
+// With development.state_preservation it is a component_update carrying the
+// component's freshly rendered preview, which preview pages morph into place.
+// A component that fails to render reloads the pages to show the error. The
+// render is traced as part of the trace ctx belongs to.
+func buildSuccessMessage(ctx context.Context, cfg *config.Config, componentRenderer *renderer.ComponentRenderer, component *types.ComponentInfo) UpdateMessage {
+	if cfg == nil || !cfg.Development.StatePreservation || componentRenderer == nil {
+		return UpdateMessage{
 			Type:      "build_success",
-			Target:    result.Component.Name,
+			Target:    component.Name,
 			Timestamp: time.Now(),
 		}
-		s.broadcastMessage(msg)
+	}
+
+	html, err := componentRenderer.RenderComponentWithContext(ctx, component.ID)
+	if err != nil {
+		log.Printf("Failed to render %s for a partial reload: %v", component.ID, err)
+		return UpdateMessage{Type: "full_reload", Target: component.ID, Timestamp: time.Now()}
+	}
+	return UpdateMessage{
+		Type:      "component_update",
+		Target:    component.ID,
+		Content:   html,
+		Timestamp: time.Now(),
 	}
 }
 
 // triggerFullRebuild triggers a full rebuild of all components
-func (s *PreviewServer) triggerFullRebuild() {
+func (s *PreviewServer) triggerFullRebuild(ctx context.Context) {
 	components := s.registry.GetAll()
 	for _, component := range components {
-		s.buildPipeline.Build(component)
+		buildComponent(ctx, s.buildPipeline, component)
 	}
 }
 
@@ -539,11 +696,21 @@ func (s *PreviewServer) Shutdown(ctx context.Context) error {
 			s.watcher.Stop()
 		}
 
+		// Stop the render host process
+		if s.renderer != nil {
+			s.renderer.Close()
+		}
+
 		// MEMORY LEAK FIX: Stop rate limiter to clean up goroutines
 		if s.rateLimiter != nil {
 			s.rateLimiter.Stop()
 		}
 
+		// Export the remaining spans
+		if err := s.tracer.Shutdown(ctx); err != nil {
+			log.Printf("Failed to flush traces: %v", err)
+		}
+
 		// Close all WebSocket connections
 		s.clientsMutex.Lock()
 		for conn, client := range s.clients {
@@ -723,6 +890,11 @@ func (s *PreviewServer) handleBuildCache(w http.ResponseWriter, r *http.Request)
 			response["hit_rate"] = cache.GetHitRate()
 			response["evictions"] = cache.GetEvictions()
 		}
+		if s.diskCache != nil {
+			if stats, err := s.diskCache.Stats(); err == nil {
+				response["disk"] = stats
+			}
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(response)
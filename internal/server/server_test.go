@@ -8,6 +8,10 @@ import (
 	"time"
 
 	"github.com/conneroisu/templar/internal/config"
+	"github.com/conneroisu/templar/internal/errors"
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/renderer"
+	"github.com/conneroisu/templar/internal/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/coder/websocket"
@@ -237,6 +241,56 @@ func TestUpdateMessage_Structure(t *testing.T) {
 	assert.Equal(t, timestamp, msg.Timestamp)
 }
 
+func TestBuildSuccessMessage(t *testing.T) {
+	reg := registry.NewComponentRegistry()
+	component := &types.ComponentInfo{
+		Name:     "Missing",
+		Package:  "ui",
+		FilePath: "ui/missing.templ",
+	}
+	reg.Register(component)
+	componentRenderer := renderer.NewComponentRenderer(reg)
+	cfg := &config.Config{}
+
+	// Without state preservation the build is announced as before
+	msg := buildSuccessMessage(context.Background(), cfg, componentRenderer, component)
+	assert.Equal(t, "build_success", msg.Type)
+	assert.Equal(t, "Missing", msg.Target)
+
+	// A component that cannot be rendered reloads the page
+	cfg.Development.StatePreservation = true
+	msg = buildSuccessMessage(context.Background(), cfg, componentRenderer, component)
+	assert.Equal(t, "full_reload", msg.Type)
+	assert.Equal(t, component.ID, msg.Target)
+}
+
+func TestBuildErrorMessage(t *testing.T) {
+	parsedErrors := []*errors.ParsedError{{
+		Type:     errors.BuildErrorTypeTemplSyntax,
+		Severity: errors.ErrorSeverityError,
+		File:     "ui/card.templ",
+		Line:     3,
+		Message:  "unexpected EOF",
+	}}
+	cfg := &config.Config{}
+
+	// Without the error overlay the errors are sent as plain text only
+	msg := buildErrorMessage(cfg, parsedErrors)
+	assert.Equal(t, "build_error", msg.Type)
+	assert.Contains(t, msg.Content, "ui/card.templ:3")
+	assert.Contains(t, msg.Content, "unexpected EOF")
+	assert.NotContains(t, msg.Content, "<")
+	assert.Empty(t, msg.Overlay)
+
+	cfg.Development.ErrorOverlay = true
+	cfg.Development.EditorURL = "vscode://file/{file}:{line}:{column}"
+	msg = buildErrorMessage(cfg, parsedErrors)
+	assert.Equal(t, "build_error", msg.Type)
+	assert.Contains(t, msg.Content, "unexpected EOF")
+	assert.Contains(t, msg.Overlay, "unexpected EOF")
+	assert.Contains(t, msg.Overlay, `href="vscode://file/`)
+}
+
 func TestPreviewServer_FileWatcherIntegration(t *testing.T) {
 	cfg := &config.Config{
 		Server: config.ServerConfig{
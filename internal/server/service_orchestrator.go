@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"os/exec"
 	"runtime"
 	"sync"
@@ -14,7 +15,9 @@ import (
 	"github.com/conneroisu/templar/internal/interfaces"
 	"github.com/conneroisu/templar/internal/monitoring"
 	"github.com/conneroisu/templar/internal/renderer"
+	"github.com/conneroisu/templar/internal/tracing"
 	"github.com/conneroisu/templar/internal/watcher"
+	"github.com/conneroisu/templar/pkg/stories"
 )
 
 // ServiceOrchestrator coordinates business logic and service interactions
@@ -53,6 +56,9 @@ type ServiceOrchestrator struct {
 	// WebSocket manager for real-time client communication
 	wsManager *WebSocketManager               // WebSocket connection management
 	
+	// Tracing of file changes - nil when disabled
+	tracer *tracing.Tracer                    // Starts a trace per batch of changes
+	
 	// Build state management - thread-safe build error tracking
 	lastBuildErrors []*errors.ParsedError     // Latest build errors for clients
 	buildMutex      sync.RWMutex              // Protects lastBuildErrors access
@@ -73,6 +79,7 @@ type ServiceDependencies struct {
 	Renderer      *renderer.ComponentRenderer
 	Monitor       *monitoring.TemplarMonitor
 	WSManager     *WebSocketManager
+	Tracer        *tracing.Tracer
 }
 
 // NewServiceOrchestrator creates a new service orchestrator with dependency injection
@@ -122,6 +129,7 @@ func NewServiceOrchestrator(deps ServiceDependencies) *ServiceOrchestrator {
 		renderer:      deps.Renderer,      // Component rendering (optional)
 		monitor:       deps.Monitor,       // Monitoring system (optional)
 		wsManager:     deps.WSManager,     // WebSocket management (optional)
+		tracer:        deps.Tracer,        // Change tracing (optional)
 		ctx:           ctx,                // Cancellation context
 		cancel:        cancel,             // Cancellation function
 		lastBuildErrors: nil,              // No build errors initially
@@ -179,8 +187,7 @@ func (so *ServiceOrchestrator) setupFileWatcher(ctx context.Context) {
 	}
 	
 	// Add filters for relevant file types
-	so.fileWatcher.AddFilter(interfaces.FileFilterFunc(watcher.TemplFilter))
-	so.fileWatcher.AddFilter(interfaces.FileFilterFunc(watcher.GoFilter))
+	so.fileWatcher.AddFilter(watcher.AnyFilter(watcher.TemplFilter, watcher.GoFilter, watcher.StoryFilter, watcher.CSSFilter))
 	so.fileWatcher.AddFilter(interfaces.FileFilterFunc(watcher.NoTestFilter))
 	so.fileWatcher.AddFilter(interfaces.FileFilterFunc(watcher.NoVendorFilter))
 	so.fileWatcher.AddFilter(interfaces.FileFilterFunc(watcher.NoGitFilter))
@@ -191,14 +198,25 @@ func (so *ServiceOrchestrator) setupFileWatcher(ctx context.Context) {
 		for i, event := range events {
 			// Convert interfaces.ChangeEvent to watcher.ChangeEvent
 			changeEvents[i] = watcher.ChangeEvent{
-				Type:    event.Type,
-				Path:    event.Path,
-				ModTime: event.ModTime,
-				Size:    event.Size,
+				Type:       event.Type,
+				Path:       event.Path,
+				ModTime:    event.ModTime,
+				Size:       event.Size,
+				DetectedAt: event.DetectedAt,
 			}
 		}
 		return so.handleFileChange(changeEvents)
 	})
+
+	// Rescan directories whose file events were lost
+	if so.scanner != nil {
+		watchRescans(so.fileWatcher, so.scanner, func() {
+			if so.renderer != nil {
+				so.renderer.Invalidate()
+			}
+			so.triggerFullRebuild(context.Background())
+		})
+	}
 	
 	// Add watch paths from configuration
 	for _, path := range so.config.Components.ScanPaths {
@@ -206,6 +224,11 @@ func (so *ServiceOrchestrator) setupFileWatcher(ctx context.Context) {
 			log.Printf("Warning: Failed to watch directory %s: %v", path, err)
 		}
 	}
+
+	// Stylesheets served under /static/ are reloaded in place
+	if err := watchStaticDir(so.fileWatcher.AddRecursive); err != nil {
+		log.Printf("Warning: Failed to watch static directory: %v", err)
+	}
 }
 
 // initialScan performs the initial component scanning
@@ -220,6 +243,17 @@ func (so *ServiceOrchestrator) initialScan() error {
 			log.Printf("Warning: Failed to scan directory %s: %v", path, err)
 		}
 	}
+
+	// The wrapper layout may live outside the scan paths
+	if so.renderer != nil {
+		if wrapperFile := so.renderer.WrapperFile(); wrapperFile != "" {
+			if _, err := os.Stat(wrapperFile); err == nil {
+				if err := so.scanner.ScanFile(wrapperFile); err != nil {
+					log.Printf("Warning: Failed to scan wrapper %s: %v", wrapperFile, err)
+				}
+			}
+		}
+	}
 	
 	log.Printf("Initial component scan completed")
 	return nil
@@ -233,74 +267,62 @@ func (so *ServiceOrchestrator) handleFileChange(events []watcher.ChangeEvent) er
 	
 	log.Printf("Processing %d file changes", len(events))
 	
-	// Group events by type for efficient processing
-	var templFiles []string
-	var otherFiles []string
+	ctx, span := startChangeTrace(so.tracer, events)
+	defer span.End()
 	
-	for _, event := range events {
-		if watcher.TemplFilter(event.Path) {
-			templFiles = append(templFiles, event.Path)
-		} else if watcher.GoFilter(event.Path) {
-			otherFiles = append(otherFiles, event.Path)
-		}
+	// Stylesheet changes need no rebuild, the browser reloads them
+	stylesheets, events := splitStylesheetChanges(events)
+	for _, message := range stylesheetMessages(so.config, stylesheets) {
+		so.broadcast(ctx, message)
 	}
-	
-	// Process template file changes
-	for _, filePath := range templFiles {
-		if err := so.processTemplateFileChange(filePath); err != nil {
-			log.Printf("Error processing template file %s: %v", filePath, err)
-		}
+	if len(events) == 0 {
+		return nil
 	}
 	
-	// Process other file changes
-	for _, filePath := range otherFiles {
-		if err := so.processGeneralFileChange(filePath); err != nil {
-			log.Printf("Error processing file %s: %v", filePath, err)
-		}
+	changedPaths := make([]string, len(events))
+	for i, event := range events {
+		changedPaths[i] = event.Path
 	}
-	
-	// Broadcast change notification
-	so.broadcastFileChangeNotification(len(events))
-	
-	return nil
-}
 
-// processTemplateFileChange handles changes to template files
-func (so *ServiceOrchestrator) processTemplateFileChange(filePath string) error {
-	// Re-scan the specific file
-	if so.scanner != nil {
-		if err := so.scanner.ScanFile(filePath); err != nil {
-			return fmt.Errorf("failed to rescan template file: %w", err)
-		}
+	// Go and templ changes require the render host to be rebuilt
+	if so.renderer != nil {
+		so.renderer.InvalidateFiles(changedPaths)
 	}
 	
-	// Get the component from the registry
-	if so.registry != nil {
-		// Find component by file path
-		components := so.registry.GetAll()
-		for _, component := range components {
-			if component.FilePath == filePath {
-				// Trigger rebuild for this specific component
-				if so.buildPipeline != nil {
-					so.buildPipeline.Build(component)
-				}
-				break
+	// Re-scan the changed templ files, story files standing for the templ
+	// file they describe
+	for _, event := range events {
+		filePath := event.Path
+		if watcher.StoryFilter(filePath) {
+			filePath = stories.TemplFile(filePath)
+		} else if !watcher.TemplFilter(filePath) {
+			continue
+		}
+		if so.scanner != nil {
+			if err := scanFile(ctx, so.scanner, filePath); err != nil {
+				log.Printf("Error processing template file %s: %v", filePath, err)
 			}
 		}
 	}
-	
-	return nil
-}
 
-// processGeneralFileChange handles changes to non-template files
-func (so *ServiceOrchestrator) processGeneralFileChange(filePath string) error {
-	log.Printf("Processing general file change: %s", filePath)
-	
-	// For Go files, trigger a full rebuild since they might affect templates
-	if watcher.GoFilter(filePath) {
-		so.triggerFullRebuild()
+	// Rebuild the changed components and their dependents, or everything
+	// when a change can't be traced to components
+	if so.registry != nil && so.buildPipeline != nil {
+		components, ok := affectedComponents(so.registry, changedPaths)
+		if ok {
+			span.SetAttribute("components", len(components))
+			for _, component := range components {
+				buildComponent(ctx, so.buildPipeline, component)
+			}
+		} else {
+			span.SetAttribute("full_rebuild", true)
+			so.triggerFullRebuild(ctx)
+		}
 	}
 	
+	// Broadcast change notification
+	so.broadcastFileChangeNotification(ctx, len(events))
+	
 	return nil
 }
 
@@ -332,7 +354,7 @@ func (so *ServiceOrchestrator) handleBuildResult(result build.BuildResult) {
 }
 
 // triggerFullRebuild initiates a complete rebuild of all components
-func (so *ServiceOrchestrator) triggerFullRebuild() {
+func (so *ServiceOrchestrator) triggerFullRebuild(ctx context.Context) {
 	log.Printf("Triggering full component rebuild")
 	
 	if so.registry == nil {
@@ -343,21 +365,19 @@ func (so *ServiceOrchestrator) triggerFullRebuild() {
 	components := so.registry.GetAll()
 	for _, component := range components {
 		if so.buildPipeline != nil {
-			so.buildPipeline.Build(component)
+			buildComponent(ctx, so.buildPipeline, component)
 		}
 	}
 }
 
 // broadcastFileChangeNotification sends file change notifications to WebSocket clients
-func (so *ServiceOrchestrator) broadcastFileChangeNotification(eventCount int) {
-	if so.wsManager != nil {
-		message := UpdateMessage{
-			Type:      "file_change",
-			Content:   fmt.Sprintf("%d files changed", eventCount),
-			Timestamp: GetCurrentTime(),
-		}
-		so.wsManager.BroadcastMessage(message)
+func (so *ServiceOrchestrator) broadcastFileChangeNotification(ctx context.Context, eventCount int) {
+	message := UpdateMessage{
+		Type:      "file_change",
+		Content:   fmt.Sprintf("%d files changed", eventCount),
+		Timestamp: GetCurrentTime(),
 	}
+	so.broadcast(ctx, message)
 }
 
 // broadcastBuildResult sends build results to WebSocket clients
@@ -365,21 +385,37 @@ func (so *ServiceOrchestrator) broadcastBuildResult(result build.BuildResult) {
 	if so.wsManager == nil {
 		return
 	}
-	
-	messageType := "build_success"
-	content := "Build completed successfully"
+	ctx := buildResultContext(result)
 	
 	if len(result.ParsedErrors) > 0 {
-		messageType = "build_error"
-		content = fmt.Sprintf("Build failed with %d errors", len(result.ParsedErrors))
+		so.broadcast(ctx, buildErrorMessage(so.config, result.ParsedErrors))
+		return
+	} else if so.config.Development.StatePreservation && result.Component != nil {
+		so.broadcast(ctx, buildSuccessMessage(ctx, so.config, so.renderer, result.Component))
+		return
 	}
 	
 	message := UpdateMessage{
-		Type:      messageType,
-		Content:   content,
+		Type:      "build_success",
+		Content:   "Build completed successfully",
 		Timestamp: GetCurrentTime(),
 	}
 	
+	so.broadcast(ctx, message)
+}
+
+// broadcast sends a message caused by the change traced by ctx to WebSocket
+// clients, tagging it with the trace ID
+func (so *ServiceOrchestrator) broadcast(ctx context.Context, message UpdateMessage) {
+	if so.wsManager == nil {
+		return
+	}
+	
+	message.TraceID = tracing.TraceIDFromContext(ctx)
+	_, span := tracing.Start(ctx, "websocket.broadcast")
+	span.SetAttribute("type", message.Type)
+	defer span.End()
+	
 	so.wsManager.BroadcastMessage(message)
 }
 
@@ -455,6 +491,16 @@ func (so *ServiceOrchestrator) Shutdown(ctx context.Context) error {
 			so.buildPipeline.Stop()
 		}
 		
+		// Stop the render host process
+		if so.renderer != nil {
+			so.renderer.Close()
+		}
+		
+		// Export the remaining spans
+		if err := so.tracer.Shutdown(ctx); err != nil {
+			log.Printf("Warning: Failed to flush traces: %v", err)
+		}
+		
 		log.Printf("Service orchestrator shut down successfully")
 	})
 	
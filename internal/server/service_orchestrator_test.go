@@ -35,6 +35,13 @@ func (m *MockComponentRegistry) Get(name string) (*types.ComponentInfo, bool) {
 	return nil, false
 }
 
+func (m *MockComponentRegistry) Resolve(name string) (*types.ComponentInfo, error) {
+	if component, exists := m.Get(name); exists {
+		return component, nil
+	}
+	return nil, fmt.Errorf("component not found: %s", name)
+}
+
 func (m *MockComponentRegistry) GetAll() []*types.ComponentInfo {
 	return m.components
 }
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/conneroisu/templar/internal/build"
+	"github.com/conneroisu/templar/internal/config"
+	"github.com/conneroisu/templar/internal/interfaces"
+	"github.com/conneroisu/templar/internal/scanner"
+	"github.com/conneroisu/templar/internal/tracing"
+	"github.com/conneroisu/templar/internal/types"
+	"github.com/conneroisu/templar/internal/watcher"
+)
+
+// contextScanner is implemented by scanners that trace the files they scan
+type contextScanner interface {
+	ScanFileWithContext(ctx context.Context, path string) error
+}
+
+// contextBuilder is implemented by build pipelines that trace the builds
+// they run
+type contextBuilder interface {
+	BuildWithContext(ctx context.Context, component *types.ComponentInfo) error
+	BuildWithPriorityContext(ctx context.Context, component *types.ComponentInfo)
+}
+
+var (
+	_ contextScanner = (*scanner.ComponentScanner)(nil)
+	_ contextBuilder = (*build.RefactoredBuildPipeline)(nil)
+)
+
+// newTracer creates the tracer configured by monitoring.tracing, and the
+// recorder serving /api/traces. Both are nil when tracing is disabled.
+func newTracer(cfg *config.Config) (*tracing.Tracer, *tracing.Recorder) {
+	if cfg == nil || !cfg.Monitoring.Tracing.Enabled {
+		return nil, nil
+	}
+	tracingConfig := cfg.Monitoring.Tracing
+
+	recorder := tracing.NewRecorder(tracingConfig.MaxTraces)
+	exporters := []tracing.Exporter{recorder}
+	if tracingConfig.OTLPEndpoint != "" {
+		exporters = append(exporters, tracing.NewOTLPExporter(tracingConfig.OTLPEndpoint))
+	}
+	if tracingConfig.File != "" {
+		fileExporter, err := tracing.NewFileExporter(tracingConfig.File)
+		if err != nil {
+			log.Printf("Warning: Failed to open trace file: %v", err)
+		} else {
+			exporters = append(exporters, fileExporter)
+		}
+	}
+
+	return tracing.NewTracer(exporters...), recorder
+}
+
+// startChangeTrace starts the trace of a batch of file changes. The trace
+// starts when the first change was detected, and the time spent debouncing
+// is recorded as its first span.
+func startChangeTrace(tracer *tracing.Tracer, events []watcher.ChangeEvent) (context.Context, *tracing.Span) {
+	now := time.Now()
+	detectedAt := now
+	for _, event := range events {
+		if !event.DetectedAt.IsZero() && event.DetectedAt.Before(detectedAt) {
+			detectedAt = event.DetectedAt
+		}
+	}
+
+	ctx, span := tracer.Start(context.Background(), "file_change", tracing.WithStartTime(detectedAt))
+	span.SetAttribute("files", len(events))
+	if len(events) == 1 {
+		span.SetAttribute("file", events[0].Path)
+	}
+
+	_, debounce := tracing.Start(ctx, "watcher.debounce", tracing.WithStartTime(detectedAt))
+	debounce.EndAt(now)
+
+	return ctx, span
+}
+
+// scanFile rescans a changed file, tracing the scan if the scanner can
+func scanFile(ctx context.Context, componentScanner interfaces.ComponentScanner, path string) error {
+	if traced, ok := componentScanner.(contextScanner); ok {
+		return traced.ScanFileWithContext(ctx, path)
+	}
+	return componentScanner.ScanFile(path)
+}
+
+// buildComponent queues a component build, tracing it if the pipeline can
+func buildComponent(ctx context.Context, pipeline interfaces.BuildPipeline, component *types.ComponentInfo) error {
+	if traced, ok := pipeline.(contextBuilder); ok {
+		return traced.BuildWithContext(ctx, component)
+	}
+	return pipeline.Build(component)
+}
+
+// buildComponentWithPriority queues a high priority component build,
+// tracing it if the pipeline can
+func buildComponentWithPriority(ctx context.Context, pipeline interfaces.BuildPipeline, component *types.ComponentInfo) {
+	if traced, ok := pipeline.(contextBuilder); ok {
+		traced.BuildWithPriorityContext(ctx, component)
+		return
+	}
+	pipeline.BuildWithPriority(component)
+}
+
+// buildResultContext returns the context of the trace a build belongs to
+func buildResultContext(result build.BuildResult) context.Context {
+	if result.Context != nil {
+		return result.Context
+	}
+	return context.Background()
+}
+
+// handleTraces serves the recent traces of file changes. GET /api/traces
+// lists them newest first; GET /api/traces?id=<trace id> returns one with
+// its spans.
+func handleTraces(recorder *tracing.Recorder) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if recorder == nil {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": "tracing is disabled, enable it with monitoring.tracing.enabled",
+			})
+			return
+		}
+
+		if id := r.URL.Query().Get("id"); id != "" {
+			trace, found := recorder.Trace(id)
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(map[string]interface{}{"error": "trace not found"})
+				return
+			}
+			json.NewEncoder(w).Encode(trace)
+			return
+		}
+
+		traces := recorder.Traces()
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"traces":    traces,
+			"count":     len(traces),
+			"timestamp": time.Now().Unix(),
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/conneroisu/templar/internal/build"
+	"github.com/conneroisu/templar/internal/config"
+	"github.com/conneroisu/templar/internal/registry"
+	"github.com/conneroisu/templar/internal/scanner"
+	"github.com/conneroisu/templar/internal/tracing"
+	"github.com/conneroisu/templar/internal/watcher"
+)
+
+func TestHandleFileChange_Trace(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("button.templ", []byte(`package main
+
+templ Button(text string) {
+	<button>{ text }</button>
+}
+`), 0644))
+
+	cfg := &config.Config{}
+	cfg.Components.ScanPaths = []string{"."}
+	cfg.Monitoring.Tracing.Enabled = true
+
+	reg := registry.NewComponentRegistry()
+	componentScanner := scanner.NewComponentScanner(reg)
+	require.NoError(t, componentScanner.ScanFile("button.templ"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pipeline := build.NewRefactoredBuildPipeline(1, reg)
+	require.NoError(t, pipeline.Start(ctx))
+	defer pipeline.Stop()
+
+	server := NewWithDependencies(cfg, reg, nil, componentScanner, pipeline, nil)
+	server.renderer = nil
+	server.broadcast = make(chan []byte, 16)
+	pipeline.AddCallback(func(result interface{}) {
+		if buildResult, ok := result.(build.BuildResult); ok {
+			server.handleBuildResult(buildResult)
+		}
+	})
+
+	detectedAt := time.Now().Add(-50 * time.Millisecond)
+	require.NoError(t, server.handleFileChange([]watcher.ChangeEvent{
+		{Type: watcher.EventTypeModified, Path: "button.templ", DetectedAt: detectedAt},
+	}))
+
+	// The reload message carries the trace of the change
+	var msg UpdateMessage
+	select {
+	case data := <-server.broadcast:
+		require.NoError(t, json.Unmarshal(data, &msg))
+	case <-time.After(30 * time.Second):
+		t.Fatal("no build result was broadcast")
+	}
+	assert.Contains(t, []string{"build_success", "build_error"}, msg.Type)
+	require.Len(t, msg.TraceID, 32)
+
+	require.NoError(t, server.tracer.Flush(context.Background()))
+	trace, found := server.traces.Trace(msg.TraceID)
+	require.True(t, found)
+	assert.Equal(t, "file_change", trace.Root)
+	assert.Equal(t, detectedAt.UnixNano(), trace.Start.UnixNano())
+	for _, stage := range []string{"watcher.debounce", "scanner.scan_file", "build.queue", "build.component", "websocket.broadcast"} {
+		assert.Contains(t, trace.Stages, stage)
+	}
+	assert.GreaterOrEqual(t, trace.Stages["watcher.debounce"], 50.0)
+
+	// The trace is listed by /api/traces
+	w := httptest.NewRecorder()
+	handleTraces(server.traces)(w, httptest.NewRequest(http.MethodGet, "/api/traces", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Traces []tracing.Trace `json:"traces"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Traces, 1)
+	assert.Equal(t, msg.TraceID, list.Traces[0].TraceID)
+	assert.Empty(t, list.Traces[0].Spans)
+
+	w = httptest.NewRecorder()
+	handleTraces(server.traces)(w, httptest.NewRequest(http.MethodGet, "/api/traces?id="+msg.TraceID, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var full tracing.Trace
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &full))
+	assert.Equal(t, trace.SpanCount, len(full.Spans))
+
+	require.NoError(t, server.tracer.Shutdown(context.Background()))
+}
+
+func TestHandleTraces_Disabled(t *testing.T) {
+	tracer, traces := newTracer(&config.Config{})
+	assert.Nil(t, tracer)
+	assert.Nil(t, traces)
+
+	w := httptest.NewRecorder()
+	handleTraces(nil)(w, httptest.NewRequest(http.MethodGet, "/api/traces", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "monitoring.tracing.enabled")
+}
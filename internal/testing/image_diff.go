@@ -0,0 +1,315 @@
+package testing
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Region is a rectangle of a screenshot, in pixels, left out of comparisons.
+// Ignore regions cover content that changes between runs, such as dates or
+// animations.
+type Region struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// ImageDiffOptions configures the perceptual image comparison
+type ImageDiffOptions struct {
+	// Threshold is the largest perceived color difference between two pixels
+	// still treated as equal, from 0 (exact) to 1 (anything matches), measured
+	// as a YIQ color distance. Zero selects DefaultDiffThreshold.
+	Threshold float64 `json:"threshold,omitempty"`
+	// IncludeAntiAliasing counts pixels that look like anti-aliasing around
+	// edges as differences instead of tolerating them
+	IncludeAntiAliasing bool `json:"include_anti_aliasing,omitempty"`
+	// IgnoreRegions are left out of the comparison
+	IgnoreRegions []Region `json:"ignore_regions,omitempty"`
+}
+
+// DefaultDiffThreshold tolerates the color noise of font rendering and image
+// scaling while catching visible changes
+const DefaultDiffThreshold = 0.1
+
+// ImageDiffResult reports how two images differ
+type ImageDiffResult struct {
+	// DiffPixels counts the pixels that differ visibly
+	DiffPixels int `json:"diff_pixels"`
+	// AntiAliasedPixels counts differing pixels tolerated as anti-aliasing
+	AntiAliasedPixels int `json:"anti_aliased_pixels"`
+	// ComparedPixels counts the pixels outside ignore regions
+	ComparedPixels int `json:"compared_pixels"`
+	// PercentDiff is DiffPixels as a percentage of ComparedPixels
+	PercentDiff float64 `json:"percent_diff"`
+	// Diff highlights differences in red and anti-aliasing in yellow over a
+	// faded copy of the baseline
+	Diff *image.NRGBA `json:"-"`
+}
+
+// maxYIQDelta is the largest possible YIQ color distance
+const maxYIQDelta = 35215
+
+var (
+	diffColor        = color.NRGBA{R: 255, A: 255}
+	antiAliasedColor = color.NRGBA{R: 255, G: 255, A: 255}
+	ignoredColor     = color.NRGBA{R: 200, G: 220, B: 255, A: 255}
+)
+
+// CompareImages compares two images pixel by pixel. Images of different
+// sizes are compared over the larger size, and pixels present in only one
+// of them count as differences.
+func CompareImages(baseline, actual image.Image, options ImageDiffOptions) *ImageDiffResult {
+	threshold := options.Threshold
+	if threshold <= 0 {
+		threshold = DefaultDiffThreshold
+	}
+	maxDelta := maxYIQDelta * threshold * threshold
+
+	baseBounds, actualBounds := baseline.Bounds(), actual.Bounds()
+	width := max(baseBounds.Dx(), actualBounds.Dx())
+	height := max(baseBounds.Dy(), actualBounds.Dy())
+	common := image.Rect(0, 0, min(baseBounds.Dx(), actualBounds.Dx()), min(baseBounds.Dy(), actualBounds.Dy()))
+
+	img1, img2 := toNRGBA(baseline), toNRGBA(actual)
+	result := &ImageDiffResult{Diff: image.NewNRGBA(image.Rect(0, 0, width, height))}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if ignored(options.IgnoreRegions, x, y) {
+				result.Diff.SetNRGBA(x, y, ignoredColor)
+				continue
+			}
+			result.ComparedPixels++
+
+			if !(image.Point{X: x, Y: y}).In(common) {
+				result.Diff.SetNRGBA(x, y, diffColor)
+				result.DiffPixels++
+				continue
+			}
+
+			delta := colorDelta(img1, img2, x, y, x, y, false)
+			if math.Abs(delta) <= maxDelta {
+				result.Diff.SetNRGBA(x, y, fadedPixel(img1, x, y))
+				continue
+			}
+
+			if !options.IncludeAntiAliasing && (antiAliased(img1, img2, x, y, common) || antiAliased(img2, img1, x, y, common)) {
+				result.Diff.SetNRGBA(x, y, antiAliasedColor)
+				result.AntiAliasedPixels++
+				continue
+			}
+
+			result.Diff.SetNRGBA(x, y, diffColor)
+			result.DiffPixels++
+		}
+	}
+
+	if result.ComparedPixels > 0 {
+		result.PercentDiff = float64(result.DiffPixels) / float64(result.ComparedPixels) * 100
+	}
+	return result
+}
+
+// ComparePNGFiles compares two PNG files. When they differ, the highlighted
+// diff is written to diffPath; otherwise a stale diff at diffPath is removed.
+func ComparePNGFiles(baselinePath, actualPath, diffPath string, options ImageDiffOptions) (*ImageDiffResult, error) {
+	baseline, err := readPNG(baselinePath)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := readPNG(actualPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := CompareImages(baseline, actual, options)
+	if result.DiffPixels == 0 {
+		if err := os.Remove(diffPath); err != nil && !os.IsNotExist(err) {
+			return result, err
+		}
+		return result, nil
+	}
+
+	if err := writePNG(diffPath, result.Diff); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func readPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return img, nil
+}
+
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+// toNRGBA converts an image to non-premultiplied RGBA with its origin at 0,0
+func toNRGBA(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	if nrgba, ok := img.(*image.NRGBA); ok && bounds.Min == (image.Point{}) {
+		return nrgba
+	}
+
+	converted := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(converted, converted.Bounds(), img, bounds.Min, draw.Src)
+	return converted
+}
+
+func ignored(regions []Region, x, y int) bool {
+	for _, region := range regions {
+		if x >= region.X && x < region.X+region.Width && y >= region.Y && y < region.Y+region.Height {
+			return true
+		}
+	}
+	return false
+}
+
+// colorDelta returns the perceived difference between two pixels as a YIQ
+// distance, after blending translucent pixels over white. The sign tells
+// which pixel is lighter; yOnly restricts it to the brightness difference.
+func colorDelta(img1, img2 *image.NRGBA, x1, y1, x2, y2 int, yOnly bool) float64 {
+	c1, c2 := img1.NRGBAAt(x1, y1), img2.NRGBAAt(x2, y2)
+	if c1 == c2 {
+		return 0
+	}
+
+	r1, g1, b1 := blendWhite(c1)
+	r2, g2, b2 := blendWhite(c2)
+
+	lum1, lum2 := rgbToY(r1, g1, b1), rgbToY(r2, g2, b2)
+	y := lum1 - lum2
+	if yOnly {
+		return y
+	}
+
+	i := rgbToI(r1, g1, b1) - rgbToI(r2, g2, b2)
+	q := rgbToQ(r1, g1, b1) - rgbToQ(r2, g2, b2)
+	delta := 0.5053*y*y + 0.299*i*i + 0.1957*q*q
+	if lum1 > lum2 {
+		return -delta
+	}
+	return delta
+}
+
+func blendWhite(c color.NRGBA) (float64, float64, float64) {
+	alpha := float64(c.A) / 255
+	blend := func(v uint8) float64 {
+		return 255 + (float64(v)-255)*alpha
+	}
+	return blend(c.R), blend(c.G), blend(c.B)
+}
+
+func rgbToY(r, g, b float64) float64 { return r*0.29889531 + g*0.58662247 + b*0.11448223 }
+func rgbToI(r, g, b float64) float64 { return r*0.59597799 - g*0.27417610 - b*0.32180189 }
+func rgbToQ(r, g, b float64) float64 { return r*0.21147017 - g*0.52261711 + b*0.31114694 }
+
+// fadedPixel renders an unchanged pixel as a light gray, so differences
+// stand out in the diff image
+func fadedPixel(img *image.NRGBA, x, y int) color.NRGBA {
+	r, g, b := blendWhite(img.NRGBAAt(x, y))
+	v := uint8(255 + (rgbToY(r, g, b)-255)*0.1)
+	return color.NRGBA{R: v, G: v, B: v, A: 255}
+}
+
+// antiAliased reports whether the pixel at x,y of img looks like
+// anti-aliasing: it lies between a darker and a lighter neighbour, at least
+// one of which sits in a flat area of both images. This follows the
+// approach of Vysniauskas, "Anti-aliased Pixel and Intensity Slope Detector".
+func antiAliased(img, other *image.NRGBA, x, y int, bounds image.Rectangle) bool {
+	x0, y0 := max(x-1, bounds.Min.X), max(y-1, bounds.Min.Y)
+	x1, y1 := min(x+1, bounds.Max.X-1), min(y+1, bounds.Max.Y-1)
+
+	zeroes := 0
+	if x == x0 || x == x1 || y == y0 || y == y1 {
+		zeroes = 1
+	}
+
+	var minDelta, maxDelta float64
+	var minX, minY, maxX, maxY int
+	for nx := x0; nx <= x1; nx++ {
+		for ny := y0; ny <= y1; ny++ {
+			if nx == x && ny == y {
+				continue
+			}
+
+			delta := colorDelta(img, img, x, y, nx, ny, true)
+			switch {
+			case delta == 0:
+				zeroes++
+				// Too many identical neighbours for a gradient
+				if zeroes > 2 {
+					return false
+				}
+			case delta < minDelta:
+				minDelta, minX, minY = delta, nx, ny
+			case delta > maxDelta:
+				maxDelta, maxX, maxY = delta, nx, ny
+			}
+		}
+	}
+
+	if minDelta == 0 || maxDelta == 0 {
+		return false
+	}
+
+	return (hasManySiblings(img, minX, minY, bounds) && hasManySiblings(other, minX, minY, bounds)) ||
+		(hasManySiblings(img, maxX, maxY, bounds) && hasManySiblings(other, maxX, maxY, bounds))
+}
+
+// hasManySiblings reports whether more than two neighbours of a pixel share
+// its exact color
+func hasManySiblings(img *image.NRGBA, x, y int, bounds image.Rectangle) bool {
+	x0, y0 := max(x-1, bounds.Min.X), max(y-1, bounds.Min.Y)
+	x1, y1 := min(x+1, bounds.Max.X-1), min(y+1, bounds.Max.Y-1)
+
+	zeroes := 0
+	if x == x0 || x == x1 || y == y0 || y == y1 {
+		zeroes = 1
+	}
+
+	c := img.NRGBAAt(x, y)
+	for nx := x0; nx <= x1; nx++ {
+		for ny := y0; ny <= y1; ny++ {
+			if nx == x && ny == y {
+				continue
+			}
+			if img.NRGBAAt(nx, ny) == c {
+				zeroes++
+			}
+			if zeroes > 2 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package testing
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// squareImage returns a white image with a black square at 10,10
+func squareImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			if x >= 10 && x < 20 && y >= 10 && y < 20 {
+				c = color.NRGBA{A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestCompareImages_Identical(t *testing.T) {
+	result := CompareImages(squareImage(40, 30), squareImage(40, 30), ImageDiffOptions{})
+
+	if result.DiffPixels != 0 || result.PercentDiff != 0 {
+		t.Errorf("Expected no differences, got %d pixels (%.2f%%)", result.DiffPixels, result.PercentDiff)
+	}
+	if result.ComparedPixels != 1200 {
+		t.Errorf("Expected 1200 compared pixels, got %d", result.ComparedPixels)
+	}
+}
+
+func TestCompareImages_Threshold(t *testing.T) {
+	actual := squareImage(40, 30)
+	// A barely visible shift of the background
+	for x := 0; x < 40; x++ {
+		actual.SetNRGBA(x, 0, color.NRGBA{R: 250, G: 250, B: 250, A: 255})
+	}
+
+	if result := CompareImages(squareImage(40, 30), actual, ImageDiffOptions{}); result.DiffPixels != 0 {
+		t.Errorf("Expected the default threshold to tolerate faint changes, got %d pixels", result.DiffPixels)
+	}
+
+	result := CompareImages(squareImage(40, 30), actual, ImageDiffOptions{Threshold: 0.01})
+	if result.DiffPixels != 40 {
+		t.Errorf("Expected 40 differing pixels with a strict threshold, got %d", result.DiffPixels)
+	}
+}
+
+func TestCompareImages_ChangesAndIgnoreRegions(t *testing.T) {
+	actual := squareImage(40, 30)
+	// Recolor the square red
+	for y := 10; y < 20; y++ {
+		for x := 10; x < 20; x++ {
+			actual.SetNRGBA(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+
+	result := CompareImages(squareImage(40, 30), actual, ImageDiffOptions{})
+	if result.DiffPixels != 100 {
+		t.Fatalf("Expected 100 differing pixels, got %d", result.DiffPixels)
+	}
+	if result.Diff.NRGBAAt(15, 15) != diffColor {
+		t.Errorf("Expected changed pixels to be highlighted, got %v", result.Diff.NRGBAAt(15, 15))
+	}
+	if faded := result.Diff.NRGBAAt(0, 0); faded == diffColor || faded.A != 255 {
+		t.Errorf("Expected unchanged pixels to be faded, got %v", faded)
+	}
+
+	result = CompareImages(squareImage(40, 30), actual, ImageDiffOptions{
+		IgnoreRegions: []Region{{X: 10, Y: 10, Width: 10, Height: 5}},
+	})
+	if result.DiffPixels != 50 || result.ComparedPixels != 1150 {
+		t.Errorf("Expected 50 of 1150 pixels to differ, got %d of %d", result.DiffPixels, result.ComparedPixels)
+	}
+}
+
+func TestCompareImages_AntiAliasing(t *testing.T) {
+	baseline := squareImage(40, 30)
+	actual := squareImage(40, 30)
+	// Soften the left edge of the square, as a different rasterizer would
+	for y := 10; y < 20; y++ {
+		actual.SetNRGBA(9, y, color.NRGBA{R: 128, G: 128, B: 128, A: 255})
+	}
+
+	result := CompareImages(baseline, actual, ImageDiffOptions{})
+	if result.DiffPixels != 0 || result.AntiAliasedPixels == 0 {
+		t.Errorf("Expected the softened edge to be tolerated as anti-aliasing, got %d differing and %d anti-aliased pixels",
+			result.DiffPixels, result.AntiAliasedPixels)
+	}
+
+	result = CompareImages(baseline, actual, ImageDiffOptions{IncludeAntiAliasing: true})
+	if result.DiffPixels != 10 {
+		t.Errorf("Expected 10 differing pixels when anti-aliasing counts, got %d", result.DiffPixels)
+	}
+}
+
+func TestCompareImages_SizeChange(t *testing.T) {
+	result := CompareImages(squareImage(40, 30), squareImage(40, 35), ImageDiffOptions{})
+	if result.DiffPixels != 200 || result.ComparedPixels != 1400 {
+		t.Errorf("Expected the 200 added pixels to differ, got %d of %d", result.DiffPixels, result.ComparedPixels)
+	}
+}
+
+func TestComparePNGFiles(t *testing.T) {
+	dir := t.TempDir()
+	baselinePath := filepath.Join(dir, "baselines", "Button.png")
+	actualPath := filepath.Join(dir, "Button.png")
+	diffPath := filepath.Join(dir, "baselines", "Button.diff.png")
+
+	if err := writePNG(baselinePath, squareImage(40, 30)); err != nil {
+		t.Fatal(err)
+	}
+	actual := squareImage(40, 30)
+	actual.SetNRGBA(30, 5, color.NRGBA{B: 255, A: 255})
+	if err := writePNG(actualPath, actual); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ComparePNGFiles(baselinePath, actualPath, diffPath, ImageDiffOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 1 {
+		t.Errorf("Expected 1 differing pixel, got %d", result.DiffPixels)
+	}
+
+	diff, err := readPNG(diffPath)
+	if err != nil {
+		t.Fatalf("Expected a diff image next to the baseline: %v", err)
+	}
+	if got := color.NRGBAModel.Convert(diff.At(30, 5)); got != diffColor {
+		t.Errorf("Expected the changed pixel to be highlighted in the diff image, got %v", got)
+	}
+
+	// Matching images remove the stale diff
+	if _, err := ComparePNGFiles(baselinePath, baselinePath, diffPath, ImageDiffOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(diffPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the stale diff image to be removed")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -29,6 +30,7 @@ type VisualRegressionTester struct {
 	screenshotDir   string
 	enablePuppeteer bool
 	serverPort      int
+	diffOptions     ImageDiffOptions
 }
 
 // TestCase represents a visual regression test case
@@ -42,6 +44,9 @@ type TestCase struct {
 	Viewport     Viewport               `json:"viewport,omitempty"`
 	WaitFor      string                 `json:"wait_for,omitempty"`
 	Screenshot   bool                   `json:"screenshot,omitempty"`
+	Background   string                 `json:"background,omitempty"`
+	// IgnoreRegions are left out of the screenshot comparison
+	IgnoreRegions []Region              `json:"ignore_regions,omitempty"`
 }
 
 // Viewport defines the browser viewport size for screenshot tests
@@ -66,6 +71,8 @@ type RegressionResult struct {
 	VisualDiff      bool     `json:"visual_diff"`
 	PixelDiff       int      `json:"pixel_diff"`
 	PercentDiff     float64  `json:"percent_diff"`
+	ComparedPixels  int      `json:"compared_pixels"`
+	AntiAliasedPixels int    `json:"anti_aliased_pixels"`
 }
 
 // NewVisualRegressionTester creates a new visual regression tester
@@ -95,6 +102,7 @@ func NewVisualRegressionTesterWithOptions(goldenDir string, updateMode bool, opt
 		vrt.serverPort = options.ServerPort
 	}
 	vrt.enablePuppeteer = options.EnablePuppeteer
+	vrt.diffOptions = options.Diff
 	
 	return vrt
 }
@@ -104,6 +112,9 @@ type VisualTestOptions struct {
 	ScreenshotDir   string
 	EnablePuppeteer bool
 	ServerPort      int
+	// Diff configures the screenshot comparison; ignore regions given here
+	// apply to every test case
+	Diff ImageDiffOptions
 }
 
 // checkPuppeteerAvailable checks if Puppeteer or similar tools are available
@@ -125,6 +136,53 @@ func (vrt *VisualRegressionTester) RegisterComponents(components []*types.Compon
 	}
 }
 
+// StoryTestCases returns a test case for every story declared for the
+// registered components, so the stories shown in the preview UI are the
+// variants under regression test
+func (vrt *VisualRegressionTester) StoryTestCases() []TestCase {
+	components := vrt.registry.GetAll()
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].ID < components[j].ID
+	})
+
+	var testCases []TestCase
+	for _, component := range components {
+		for _, example := range component.Examples {
+			testCases = append(testCases, TestCase{
+				Name:        component.Name + "/" + example.Name,
+				Component:   component.ID,
+				Props:       example.Props,
+				GoldenFile:  storyGoldenFile(component, example),
+				Description: example.Description,
+				Tags:        []string{"story"},
+				Viewport:    Viewport{Width: example.Viewport.Width, Height: example.Viewport.Height},
+				Screenshot:  true,
+				Background:  example.Background,
+			})
+		}
+	}
+
+	return testCases
+}
+
+// storyGoldenFile names the golden file of a story, e.g. ui/Button-primary.golden.html
+func storyGoldenFile(component *types.ComponentInfo, example types.ComponentExample) string {
+	clean := func(name string) string {
+		return strings.Map(func(r rune) rune {
+			if r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return '-'
+		}, name)
+	}
+
+	name := clean(component.Name) + "-" + strings.ToLower(clean(example.Name)) + ".golden.html"
+	if component.Package != "" {
+		return filepath.Join(clean(component.Package), name)
+	}
+	return name
+}
+
 // RunTest executes a single visual regression test
 func (vrt *VisualRegressionTester) RunTest(t *testing.T, testCase TestCase) *RegressionResult {
 	result := &RegressionResult{
@@ -160,6 +218,8 @@ func (vrt *VisualRegressionTester) RunTest(t *testing.T, testCase TestCase) *Reg
 		result.VisualDiff = screenshotResult.VisualDiff
 		result.PixelDiff = screenshotResult.PixelDiff
 		result.PercentDiff = screenshotResult.PercentDiff
+		result.ComparedPixels = screenshotResult.ComparedPixels
+		result.AntiAliasedPixels = screenshotResult.AntiAliasedPixels
 		
 		if !screenshotResult.Passed {
 			result.Passed = false
@@ -382,6 +442,13 @@ func (vrt *VisualRegressionTester) GenerateReport(results []*RegressionResult) s
 				if result.Error != nil {
 					report.WriteString(fmt.Sprintf("**Error**: %s\n", result.Error.Error()))
 				} else {
+					if result.VisualDiff {
+						report.WriteString(fmt.Sprintf("**Pixel Diff**: %d of %d pixels (%.2f%%)\n",
+							result.PixelDiff, result.ComparedPixels, result.PercentDiff))
+						if result.DiffImagePath != "" {
+							report.WriteString(fmt.Sprintf("**Diff Image**: %s\n", result.DiffImagePath))
+						}
+					}
 					report.WriteString(fmt.Sprintf("**Expected Hash**: %s\n", result.ExpectedHash))
 					report.WriteString(fmt.Sprintf("**Actual Hash**: %s\n", result.OutputHash))
 					if result.Diff != "" {
@@ -454,7 +521,7 @@ func (vrt *VisualRegressionTester) runScreenshotTest(t *testing.T, testCase Test
 	// Screenshot paths
 	screenshotPath := filepath.Join(vrt.screenshotDir, fmt.Sprintf("%s.png", testCase.Name))
 	baselinePath := filepath.Join(vrt.screenshotDir, "baselines", fmt.Sprintf("%s.png", testCase.Name))
-	diffPath := filepath.Join(vrt.screenshotDir, "diffs", fmt.Sprintf("%s_diff.png", testCase.Name))
+	diffPath := filepath.Join(vrt.screenshotDir, "baselines", fmt.Sprintf("%s.diff.png", testCase.Name))
 
 	// Take screenshot
 	if err := vrt.takeScreenshot(tempFile, screenshotPath, testCase.Viewport); err != nil {
@@ -480,18 +547,22 @@ func (vrt *VisualRegressionTester) runScreenshotTest(t *testing.T, testCase Test
 	result.BaselinePath = baselinePath
 
 	// Perform image comparison
-	pixelDiff, percentDiff, err := vrt.compareImages(baselinePath, screenshotPath, diffPath)
+	diff, err := vrt.compareImages(baselinePath, screenshotPath, diffPath, testCase)
 	if err != nil {
 		return result, fmt.Errorf("failed to compare images: %w", err)
 	}
 
-	result.PixelDiff = pixelDiff
-	result.PercentDiff = percentDiff
-	result.DiffImagePath = diffPath
+	result.PixelDiff = diff.DiffPixels
+	result.PercentDiff = diff.PercentDiff
+	result.ComparedPixels = diff.ComparedPixels
+	result.AntiAliasedPixels = diff.AntiAliasedPixels
+	if diff.DiffPixels > 0 {
+		result.DiffImagePath = diffPath
+	}
 
 	// Determine if test passed (allow small differences)
 	threshold := 0.1 // 0.1% difference threshold
-	if percentDiff <= threshold {
+	if diff.PercentDiff <= threshold {
 		result.Passed = true
 	} else {
 		result.VisualDiff = true
@@ -509,6 +580,10 @@ func (vrt *VisualRegressionTester) createFullHTMLPage(componentHTML []byte, test
 	if viewport.Height == 0 {
 		viewport.Height = 720
 	}
+	background := "white"
+	if testCase.Background != "" {
+		background = testCase.Background
+	}
 
 	html := fmt.Sprintf(`<!DOCTYPE html>
 <html lang="en">
@@ -521,7 +596,7 @@ func (vrt *VisualRegressionTester) createFullHTMLPage(componentHTML []byte, test
             margin: 0;
             padding: 20px;
             font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
-            background: white;
+            background: %s;
         }
         .test-container {
             width: %dpx;
@@ -535,7 +610,7 @@ func (vrt *VisualRegressionTester) createFullHTMLPage(componentHTML []byte, test
     </div>
     %s
 </body>
-</html>`, testCase.Name, viewport.Width-40, viewport.Height-40, string(componentHTML), vrt.getWaitForScript(testCase.WaitFor))
+</html>`, testCase.Name, background, viewport.Width-40, viewport.Height-40, string(componentHTML), vrt.getWaitForScript(testCase.WaitFor))
 
 	return html
 }
@@ -655,44 +730,14 @@ func (vrt *VisualRegressionTester) updateBaseline(screenshotPath, baselinePath s
 	return err
 }
 
-// compareImages compares two images and returns pixel difference and percentage
-func (vrt *VisualRegressionTester) compareImages(baselinePath, screenshotPath, diffPath string) (int, float64, error) {
-	// For now, use a simple file size comparison as a basic difference metric
-	// In a production system, you would use an image comparison library like ImageMagick or a Go image library
-	
-	baselineInfo, err := os.Stat(baselinePath)
-	if err != nil {
-		return 0, 0, err
-	}
-
-	screenshotInfo, err := os.Stat(screenshotPath)
-	if err != nil {
-		return 0, 0, err
-	}
-
-	sizeDiff := int(screenshotInfo.Size() - baselineInfo.Size())
-	if sizeDiff < 0 {
-		sizeDiff = -sizeDiff
-	}
-
-	// Calculate percentage difference based on file size (rough approximation)
-	percentDiff := float64(sizeDiff) / float64(baselineInfo.Size()) * 100
-
-	// Create a simple diff marker file
-	if percentDiff > 0.1 {
-		diffInfo := fmt.Sprintf("Baseline: %d bytes\nScreenshot: %d bytes\nDifference: %d bytes (%.2f%%)",
-			baselineInfo.Size(), screenshotInfo.Size(), sizeDiff, percentDiff)
-		
-		if err := os.MkdirAll(filepath.Dir(diffPath), 0755); err != nil {
-			return sizeDiff, percentDiff, err
-		}
-		
-		if err := ioutil.WriteFile(diffPath+".txt", []byte(diffInfo), 0644); err != nil {
-			return sizeDiff, percentDiff, err
-		}
-	}
+// compareImages compares a screenshot with its baseline pixel by pixel,
+// skipping the test case's ignore regions, and writes the highlighted diff
+// next to the baseline when they differ
+func (vrt *VisualRegressionTester) compareImages(baselinePath, screenshotPath, diffPath string, testCase TestCase) (*ImageDiffResult, error) {
+	options := vrt.diffOptions
+	options.IgnoreRegions = append(append([]Region(nil), options.IgnoreRegions...), testCase.IgnoreRegions...)
 
-	return sizeDiff, percentDiff, nil
+	return ComparePNGFiles(baselinePath, screenshotPath, diffPath, options)
 }
 
 // StartTestServer starts a test server for component preview testing
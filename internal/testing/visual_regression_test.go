@@ -332,3 +332,50 @@ func TestHashingConsistency(t *testing.T) {
 		t.Error("Different content produced same hash")
 	}
 }
+
+// TestStoryTestCases tests that every declared story becomes a test case
+func TestStoryTestCases(t *testing.T) {
+	vrt := NewVisualRegressionTester(filepath.Join(".", "golden"), false)
+
+	vrt.RegisterComponents([]*types.ComponentInfo{
+		{
+			Name:     "Button",
+			Package:  "components",
+			FilePath: "button.templ",
+			Examples: []types.ComponentExample{
+				{Name: "Primary", Props: map[string]interface{}{"text": "Save"}},
+				{
+					Name:       "Dark Mobile",
+					Viewport:   types.ExampleViewport{Width: 375, Height: 667},
+					Background: "#111827",
+				},
+			},
+		},
+		{
+			Name:     "Card",
+			Package:  "components",
+			FilePath: "card.templ",
+		},
+	})
+
+	testCases := vrt.StoryTestCases()
+	if len(testCases) != 2 {
+		t.Fatalf("Expected 2 story test cases, got %d", len(testCases))
+	}
+
+	primary := testCases[0]
+	if primary.Name != "Button/Primary" || primary.Props["text"] != "Save" {
+		t.Errorf("Unexpected test case for Primary story: %+v", primary)
+	}
+	if primary.GoldenFile != filepath.Join("components", "Button-primary.golden.html") {
+		t.Errorf("Unexpected golden file %s", primary.GoldenFile)
+	}
+
+	mobile := testCases[1]
+	if mobile.Viewport != (Viewport{Width: 375, Height: 667}) || mobile.Background != "#111827" {
+		t.Errorf("Story viewport and background were not carried over: %+v", mobile)
+	}
+	if mobile.GoldenFile != filepath.Join("components", "Button-dark-mobile.golden.html") {
+		t.Errorf("Unexpected golden file %s", mobile.GoldenFile)
+	}
+}
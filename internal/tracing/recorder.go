@@ -0,0 +1,156 @@
+package tracing
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default limits of a Recorder
+const (
+	DefaultMaxTraces = 100
+	maxSpansPerTrace = 2000
+)
+
+// Trace is a recorded trace
+type Trace struct {
+	TraceID    string    `json:"trace_id"`
+	Root       string    `json:"root"`
+	Start      time.Time `json:"start"`
+	DurationMS float64   `json:"duration_ms"`
+	SpanCount  int       `json:"span_count"`
+	Error      bool      `json:"error"`
+
+	// Stages sums the time spent in the spans of each name, so that slow
+	// stages of a change stand out without reading every span
+	Stages map[string]float64 `json:"stages_ms"`
+
+	Spans []SpanData `json:"spans,omitempty"`
+}
+
+// recordedTrace accumulates the spans of one trace
+type recordedTrace struct {
+	spans   []SpanData
+	updated time.Time
+}
+
+// Recorder is an exporter keeping the most recent traces in memory
+type Recorder struct {
+	mu        sync.RWMutex
+	maxTraces int
+	traces    map[string]*recordedTrace
+}
+
+// NewRecorder creates a recorder keeping up to maxTraces traces
+func NewRecorder(maxTraces int) *Recorder {
+	if maxTraces <= 0 {
+		maxTraces = DefaultMaxTraces
+	}
+	return &Recorder{
+		maxTraces: maxTraces,
+		traces:    make(map[string]*recordedTrace),
+	}
+}
+
+// ExportSpans implements Exporter
+func (r *Recorder) ExportSpans(ctx context.Context, spans []SpanData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, span := range spans {
+		trace, exists := r.traces[span.TraceID]
+		if !exists {
+			trace = &recordedTrace{}
+			r.traces[span.TraceID] = trace
+		}
+		if len(trace.spans) < maxSpansPerTrace {
+			trace.spans = append(trace.spans, span)
+		}
+		trace.updated = now
+	}
+
+	// Evict the traces updated least recently
+	for len(r.traces) > r.maxTraces {
+		oldestID, oldest := "", time.Time{}
+		for id, trace := range r.traces {
+			if oldestID == "" || trace.updated.Before(oldest) {
+				oldestID, oldest = id, trace.updated
+			}
+		}
+		delete(r.traces, oldestID)
+	}
+
+	return nil
+}
+
+// Shutdown implements Exporter
+func (r *Recorder) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Traces returns summaries of the recorded traces, newest first
+func (r *Recorder) Traces() []Trace {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	traces := make([]Trace, 0, len(r.traces))
+	for id, recorded := range r.traces {
+		trace := summarize(id, recorded.spans)
+		trace.Spans = nil
+		traces = append(traces, trace)
+	}
+	sort.Slice(traces, func(i, j int) bool {
+		return traces[i].Start.After(traces[j].Start)
+	})
+
+	return traces
+}
+
+// Trace returns a recorded trace with its spans ordered by start time
+func (r *Recorder) Trace(id string) (Trace, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	recorded, exists := r.traces[id]
+	if !exists {
+		return Trace{}, false
+	}
+	return summarize(id, recorded.spans), true
+}
+
+// summarize builds a Trace from its spans
+func summarize(id string, spans []SpanData) Trace {
+	trace := Trace{
+		TraceID:   id,
+		SpanCount: len(spans),
+		Stages:    make(map[string]float64),
+		Spans:     append([]SpanData(nil), spans...),
+	}
+	sort.SliceStable(trace.Spans, func(i, j int) bool {
+		return trace.Spans[i].Start.Before(trace.Spans[j].Start)
+	})
+
+	var end time.Time
+	for _, span := range trace.Spans {
+		if span.ParentID == "" {
+			trace.Root = span.Name
+		}
+		if trace.Start.IsZero() || span.Start.Before(trace.Start) {
+			trace.Start = span.Start
+		}
+		if span.End.After(end) {
+			end = span.End
+		}
+		if span.Error != "" {
+			trace.Error = true
+		}
+		trace.Stages[span.Name] += span.DurationMS
+	}
+	if !end.IsZero() {
+		trace.DurationMS = float64(end.Sub(trace.Start).Microseconds()) / 1000
+	}
+
+	return trace
+}
@@ -0,0 +1,474 @@
+// Package tracing records trace spans of the path a file change takes
+// through templar: the watcher debounce, component scanning, the build
+// pipeline, rendering and the WebSocket broadcast that reloads the browser.
+//
+// Spans are propagated through context.Context. A Tracer starts the root
+// span of a trace; subsystems start child spans with Start, which records
+// nothing when the context carries no span, so untraced calls cost next to
+// nothing. Ended spans are batched and handed to pluggable exporters: an
+// OTLP/HTTP exporter for OpenTelemetry collectors, a JSON-lines file
+// exporter, and an in-memory Recorder backing the /api/traces view.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"maps"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default batching of ended spans
+const (
+	DefaultBatchSize     = 256
+	DefaultBatchInterval = time.Second
+	defaultQueueSize     = 4096
+)
+
+// TraceID identifies a trace
+type TraceID [16]byte
+
+// String returns the ID as 32 lowercase hex digits
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// String returns the ID as 16 lowercase hex digits
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid reports whether the ID is set
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// SpanData is the record of an ended span handed to exporters
+type SpanData struct {
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Name       string                 `json:"name"`
+	Start      time.Time              `json:"start"`
+	End        time.Time              `json:"end"`
+	DurationMS float64                `json:"duration_ms"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// Duration returns how long the span took
+func (d SpanData) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
+
+// Exporter receives batches of ended spans
+type Exporter interface {
+	// ExportSpans exports a batch of spans
+	ExportSpans(ctx context.Context, spans []SpanData) error
+
+	// Shutdown flushes and releases the exporter
+	Shutdown(ctx context.Context) error
+}
+
+// Span is an operation within a trace. A nil *Span is valid and records
+// nothing, which is what Start returns for untraced contexts.
+type Span struct {
+	tracer   *Tracer
+	traceID  TraceID
+	spanID   SpanID
+	parentID SpanID
+	name     string
+	start    time.Time
+
+	mu         sync.Mutex
+	attributes map[string]interface{}
+	err        string
+	ended      bool
+}
+
+// TraceID returns the ID of the span's trace
+func (s *Span) TraceID() TraceID {
+	if s == nil {
+		return TraceID{}
+	}
+	return s.traceID
+}
+
+// SpanID returns the ID of the span
+func (s *Span) SpanID() SpanID {
+	if s == nil {
+		return SpanID{}
+	}
+	return s.spanID
+}
+
+// String describes the span for logs
+func (s *Span) String() string {
+	if s == nil {
+		return "<untraced>"
+	}
+	return fmt.Sprintf("%s (trace %s, span %s)", s.name, s.traceID, s.spanID)
+}
+
+// SetAttribute records a key/value pair describing the operation. It is
+// ignored once the span has ended.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	if s.attributes == nil {
+		s.attributes = make(map[string]interface{})
+	}
+	s.attributes[key] = value
+}
+
+// RecordError marks the span as failed. A nil error, or one recorded after
+// the span has ended, is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	s.err = err.Error()
+}
+
+// End ends the span and queues it for export. Only the first call has an
+// effect.
+func (s *Span) End() {
+	s.EndAt(time.Now())
+}
+
+// EndAt ends the span at the given time, for operations measured after the
+// fact
+func (s *Span) EndAt(end time.Time) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+
+	data := SpanData{
+		TraceID:    s.traceID.String(),
+		SpanID:     s.spanID.String(),
+		Name:       s.name,
+		Start:      s.start,
+		End:        end,
+		DurationMS: float64(end.Sub(s.start).Microseconds()) / 1000,
+		Attributes: maps.Clone(s.attributes),
+		Error:      s.err,
+	}
+	if s.parentID.IsValid() {
+		data.ParentID = s.parentID.String()
+	}
+	s.mu.Unlock()
+
+	s.tracer.enqueue(data)
+}
+
+// spanKey is the context key of the current span
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span, so that spans started
+// from it become its children. It lets work queued by one goroutine and run
+// by another, with its own cancellation, continue a trace.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	if span == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span carried by ctx, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// TraceIDFromContext returns the ID of the trace ctx belongs to, or "" when
+// it is not traced
+func TraceIDFromContext(ctx context.Context) string {
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return ""
+	}
+	return span.traceID.String()
+}
+
+// StartOption configures a span being started
+type StartOption func(*startConfig)
+
+type startConfig struct {
+	start time.Time
+}
+
+// WithStartTime starts the span at the given time rather than now, for
+// spans covering time spent before they could be started, such as a
+// debounce delay
+func WithStartTime(start time.Time) StartOption {
+	return func(cfg *startConfig) {
+		cfg.start = start
+	}
+}
+
+// Start starts a span as a child of the span carried by ctx. When ctx is
+// not traced it returns ctx and a nil span.
+func Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	span := parent.tracer.newSpan(parent.traceID, parent.spanID, name, opts)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Tracer starts traces and exports their ended spans in batches
+type Tracer struct {
+	exporters     []Exporter
+	batchSize     int
+	batchInterval time.Duration
+
+	queue    chan SpanData
+	flushReq chan chan struct{}
+	done     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+	dropped  atomic.Int64
+
+	// failing tracks the exporters whose last export failed, so that a
+	// collector that is down is reported once rather than every batch
+	failing map[Exporter]bool
+}
+
+// NewTracer creates a tracer exporting to the given exporters
+func NewTracer(exporters ...Exporter) *Tracer {
+	t := &Tracer{
+		exporters:     exporters,
+		batchSize:     DefaultBatchSize,
+		batchInterval: DefaultBatchInterval,
+		queue:         make(chan SpanData, defaultQueueSize),
+		flushReq:      make(chan chan struct{}),
+		done:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+		failing:       make(map[Exporter]bool),
+	}
+	go t.run()
+	return t
+}
+
+// Start starts the root span of a new trace. A nil tracer returns ctx and a
+// nil span.
+func (t *Tracer) Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	span := t.newSpan(newTraceID(), SpanID{}, name, opts)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Dropped returns how many spans were dropped because the export queue was
+// full
+func (t *Tracer) Dropped() int64 {
+	if t == nil {
+		return 0
+	}
+	return t.dropped.Load()
+}
+
+// Flush exports the spans ended so far
+func (t *Tracer) Flush(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	flushed := make(chan struct{})
+	select {
+	case t.flushReq <- flushed:
+	case <-t.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown exports the remaining spans and shuts the exporters down
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	t.stopOnce.Do(func() {
+		close(t.done)
+	})
+
+	select {
+	case <-t.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	var firstErr error
+	for _, exporter := range t.exporters {
+		if err := exporter.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// newSpan creates a started span
+func (t *Tracer) newSpan(traceID TraceID, parentID SpanID, name string, opts []StartOption) *Span {
+	cfg := startConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.start.IsZero() {
+		cfg.start = time.Now()
+	}
+
+	return &Span{
+		tracer:   t,
+		traceID:  traceID,
+		spanID:   newSpanID(),
+		parentID: parentID,
+		name:     name,
+		start:    cfg.start,
+	}
+}
+
+// enqueue queues an ended span for export, dropping it if the queue is full
+// so that a slow exporter never holds up the traced work
+func (t *Tracer) enqueue(data SpanData) {
+	select {
+	case <-t.done:
+		t.dropped.Add(1)
+		return
+	default:
+	}
+
+	select {
+	case t.queue <- data:
+	default:
+		t.dropped.Add(1)
+	}
+}
+
+// run batches ended spans and exports them
+func (t *Tracer) run() {
+	defer close(t.stopped)
+
+	ticker := time.NewTicker(t.batchInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, t.batchSize)
+	export := func() {
+		if len(batch) > 0 {
+			t.export(batch)
+			batch = make([]SpanData, 0, t.batchSize)
+		}
+	}
+	drain := func() {
+		for {
+			select {
+			case data := <-t.queue:
+				batch = append(batch, data)
+				if len(batch) >= t.batchSize {
+					export()
+				}
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case data := <-t.queue:
+			batch = append(batch, data)
+			if len(batch) >= t.batchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case flushed := <-t.flushReq:
+			drain()
+			export()
+			close(flushed)
+		case <-t.done:
+			drain()
+			export()
+			return
+		}
+	}
+}
+
+// export hands a batch to every exporter
+func (t *Tracer) export(batch []SpanData) {
+	for _, exporter := range t.exporters {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := exporter.ExportSpans(ctx, batch)
+		cancel()
+
+		if err != nil && !t.failing[exporter] {
+			log.Printf("Warning: exporting trace spans: %v", err)
+		} else if err == nil && t.failing[exporter] {
+			log.Printf("Exporting trace spans recovered")
+		}
+		t.failing[exporter] = err != nil
+	}
+}
+
+// newTraceID returns a random trace ID
+func newTraceID() TraceID {
+	var id TraceID
+	for id == (TraceID{}) {
+		putUint64(id[:8], rand.Uint64())
+		putUint64(id[8:], rand.Uint64())
+	}
+	return id
+}
+
+// newSpanID returns a random span ID
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		putUint64(id[:], rand.Uint64())
+	}
+	return id
+}
+
+// putUint64 writes v into b big-endian
+func putUint64(b []byte, v uint64) {
+	for i := 7; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+}
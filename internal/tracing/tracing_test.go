@@ -0,0 +1,218 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpansFormATrace(t *testing.T) {
+	recorder := NewRecorder(10)
+	tracer := NewTracer(recorder)
+	defer tracer.Shutdown(context.Background())
+
+	detected := time.Now().Add(-300 * time.Millisecond)
+	ctx, root := tracer.Start(context.Background(), "file_change", WithStartTime(detected))
+	require.NotNil(t, root)
+
+	_, debounce := Start(ctx, "watcher.debounce", WithStartTime(detected))
+	debounce.End()
+
+	buildCtx, build := Start(ctx, "build.component")
+	build.SetAttribute("component", "Button")
+	build.RecordError(errors.New("templ generate failed"))
+
+	// Work continued on another goroutine with its own context
+	workerCtx := ContextWithSpan(context.Background(), SpanFromContext(buildCtx))
+	_, render := Start(workerCtx, "renderer.render")
+	render.End()
+	build.End()
+	root.End()
+	root.End()
+
+	require.NoError(t, tracer.Flush(context.Background()))
+
+	traceID := TraceIDFromContext(ctx)
+	assert.Len(t, traceID, 32)
+	assert.Equal(t, traceID, TraceIDFromContext(workerCtx))
+
+	trace, found := recorder.Trace(traceID)
+	require.True(t, found)
+	assert.Equal(t, "file_change", trace.Root)
+	assert.Equal(t, 4, trace.SpanCount)
+	assert.True(t, trace.Error)
+	assert.GreaterOrEqual(t, trace.DurationMS, 300.0)
+	assert.GreaterOrEqual(t, trace.Stages["watcher.debounce"], 300.0)
+
+	spans := make(map[string]SpanData)
+	for _, span := range trace.Spans {
+		spans[span.Name] = span
+	}
+	assert.Empty(t, spans["file_change"].ParentID)
+	assert.Equal(t, spans["file_change"].SpanID, spans["build.component"].ParentID)
+	assert.Equal(t, spans["build.component"].SpanID, spans["renderer.render"].ParentID)
+	assert.Equal(t, "Button", spans["build.component"].Attributes["component"])
+	assert.Equal(t, "templ generate failed", spans["build.component"].Error)
+
+	summaries := recorder.Traces()
+	require.Len(t, summaries, 1)
+	assert.Nil(t, summaries[0].Spans)
+}
+
+func TestEndedSpanIsImmutable(t *testing.T) {
+	recorder := NewRecorder(10)
+	tracer := NewTracer(recorder)
+	defer tracer.Shutdown(context.Background())
+
+	ctx, span := tracer.Start(context.Background(), "build.component")
+	span.SetAttribute("component", "Button")
+	span.End()
+
+	// Late writes race with the export unless the span ignores them
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			span.SetAttribute("component", "Card")
+			span.SetAttribute("late", i)
+			span.RecordError(errors.New("too late"))
+		}
+	}()
+	require.NoError(t, tracer.Flush(context.Background()))
+	<-done
+
+	trace, found := recorder.Trace(TraceIDFromContext(ctx))
+	require.True(t, found)
+	require.Len(t, trace.Spans, 1)
+	assert.Equal(t, map[string]interface{}{"component": "Button"}, trace.Spans[0].Attributes)
+	assert.Empty(t, trace.Spans[0].Error)
+}
+
+func TestUntracedContext(t *testing.T) {
+	ctx := context.Background()
+
+	spanCtx, span := Start(ctx, "scanner.scan_file")
+	assert.Nil(t, span)
+	assert.Equal(t, ctx, spanCtx)
+	assert.Empty(t, TraceIDFromContext(ctx))
+
+	// Nil spans and tracers are no-ops
+	span.SetAttribute("file", "button.templ")
+	span.RecordError(errors.New("ignored"))
+	span.End()
+
+	var tracer *Tracer
+	_, root := tracer.Start(ctx, "file_change")
+	assert.Nil(t, root)
+	assert.NoError(t, tracer.Flush(ctx))
+	assert.NoError(t, tracer.Shutdown(ctx))
+}
+
+func TestRecorderKeepsRecentTraces(t *testing.T) {
+	recorder := NewRecorder(2)
+	start := time.Now()
+
+	for i, id := range []string{"a", "b", "c"} {
+		span := SpanData{TraceID: id, SpanID: id, Name: "file_change", Start: start.Add(time.Duration(i) * time.Second)}
+		require.NoError(t, recorder.ExportSpans(context.Background(), []SpanData{span}))
+	}
+
+	traces := recorder.Traces()
+	require.Len(t, traces, 2)
+	assert.Equal(t, "c", traces[0].TraceID)
+	assert.Equal(t, "b", traces[1].TraceID)
+
+	_, found := recorder.Trace("a")
+	assert.False(t, found)
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		path string
+		body map[string]interface{}
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		path = r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+	}))
+	defer collector.Close()
+
+	tracer := NewTracer(NewOTLPExporter(collector.URL))
+	ctx, root := tracer.Start(context.Background(), "file_change")
+	_, build := Start(ctx, "build.component")
+	build.SetAttribute("component", "Button")
+	build.SetAttribute("cache_hit", false)
+	build.SetAttribute("workers", 4)
+	build.RecordError(errors.New("failed"))
+	build.End()
+	root.End()
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "/v1/traces", path)
+
+	resourceSpans := body["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	resource := resourceSpans["resource"].(map[string]interface{})
+	assert.Contains(t, resource["attributes"], map[string]interface{}{
+		"key": "service.name", "value": map[string]interface{}{"stringValue": "templar"},
+	})
+
+	spans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+	require.Len(t, spans, 2)
+	span := spans[0].(map[string]interface{})
+	assert.Equal(t, "build.component", span["name"])
+	assert.Equal(t, root.SpanID().String(), span["parentSpanId"])
+	assert.Equal(t, root.TraceID().String(), span["traceId"])
+	assert.IsType(t, "", span["startTimeUnixNano"])
+	assert.Equal(t, 2.0, span["status"].(map[string]interface{})["code"])
+	assert.Contains(t, span["attributes"], map[string]interface{}{
+		"key": "workers", "value": map[string]interface{}{"intValue": "4"},
+	})
+	assert.Contains(t, span["attributes"], map[string]interface{}{
+		"key": "cache_hit", "value": map[string]interface{}{"boolValue": false},
+	})
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces", "spans.jsonl")
+	exporter, err := NewFileExporter(path)
+	require.NoError(t, err)
+
+	tracer := NewTracer(exporter)
+	ctx, root := tracer.Start(context.Background(), "file_change")
+	_, scan := Start(ctx, "scanner.scan_file")
+	scan.End()
+	root.End()
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var span SpanData
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &span))
+		assert.Equal(t, root.TraceID().String(), span.TraceID)
+		names = append(names, span.Name)
+	}
+	assert.Equal(t, []string{"scanner.scan_file", "file_change"}, names)
+}
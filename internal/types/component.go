@@ -8,10 +8,15 @@ import "time"
 // including its structure, dependencies, and runtime information used by the
 // scanner, registry, and build pipeline.
 type ComponentInfo struct {
-	// Name is the component identifier (e.g., "Button", "CardHeader")
+	// ID is the fully qualified component identifier: the import path followed
+	// by the component name (e.g., "github.com/acme/app/components/ui.Button")
+	ID string
+	// Name is the component name as declared (e.g., "Button", "CardHeader")
 	Name string
 	// Package is the Go package name where the component is defined
 	Package string
+	// ImportPath is the Go import path of the package declaring the component
+	ImportPath string
 	// FilePath is the absolute path to the .templ file containing the component
 	FilePath string
 	// Parameters describes the component's input parameters and their types
@@ -22,7 +27,7 @@ type ComponentInfo struct {
 	LastMod time.Time
 	// Hash provides a CRC32 checksum for efficient change detection
 	Hash string
-	// Dependencies lists other components or files this component depends on
+	// Dependencies lists the IDs of other components this component renders
 	Dependencies []string
 	// Metadata stores plugin-specific or custom component information
 	Metadata map[string]interface{}
@@ -34,8 +39,29 @@ type ComponentInfo struct {
 	Description string
 	// Examples contains sample usage scenarios for the component
 	Examples []ComponentExample
+	// Kind identifies the templ declaration the component was parsed from
+	Kind ComponentKind
+	// Receiver describes the method receiver for templates declared as methods (nil otherwise)
+	Receiver *ParameterInfo
+	// TypeParameters lists the generic type parameters declared on the template
+	TypeParameters []ParameterInfo
+	// Line is the 1-based line of the declaration within FilePath
+	Line int
+	// Column is the 1-based column of the declaration within FilePath
+	Column int
+	// AcceptsChildren reports whether the template renders { children... }
+	AcceptsChildren bool
 }
 
+// ComponentKind identifies the kind of templ declaration a component was parsed from
+type ComponentKind string
+
+const (
+	ComponentKindTempl  ComponentKind = "templ"
+	ComponentKindCSS    ComponentKind = "css"
+	ComponentKindScript ComponentKind = "script"
+)
+
 // ParameterInfo describes a component parameter extracted from the templ
 // function signature during AST analysis.
 type ParameterInfo struct {
@@ -51,7 +77,8 @@ type ParameterInfo struct {
 	Description string
 }
 
-// ComponentExample represents a usage example for a component
+// ComponentExample represents a usage example for a component, typically a
+// named variant declared in a story file
 type ComponentExample struct {
 	// Name is the example identifier
 	Name string
@@ -61,6 +88,24 @@ type ComponentExample struct {
 	Props map[string]interface{}
 	// Code contains the example templ code
 	Code string
+	// Viewport is the frame size the example is previewed at (zero means the default)
+	Viewport ExampleViewport
+	// Wrapper names the layout component the example is rendered inside
+	Wrapper string
+	// Background is the CSS background shown behind the example
+	Background string
+	// Notes contains free-form documentation shown alongside the example
+	Notes string
+	// Source is the story file the example was declared in
+	Source string
+}
+
+// ExampleViewport describes the frame size used when previewing an example
+type ExampleViewport struct {
+	// Width is the frame width in CSS pixels
+	Width int
+	// Height is the frame height in CSS pixels
+	Height int
 }
 
 // EventType represents the type of component change event
@@ -0,0 +1,332 @@
+package watcher
+
+import (
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Backends a FileWatcher can detect changes with
+const (
+	// BackendAuto uses fsnotify, falling back to polling when the operating
+	// system's watch limits are reached
+	BackendAuto = "auto"
+	// BackendFSNotify uses the operating system's file notifications
+	BackendFSNotify = "fsnotify"
+	// BackendPoll periodically compares the watched directories with their
+	// last snapshot, which works on network file systems, Docker bind mounts
+	// and WSL drives that don't deliver notifications
+	BackendPoll = "poll"
+)
+
+// Defaults for the polling backend
+const (
+	DefaultPollInterval    = 500 * time.Millisecond
+	DefaultMaxPollInterval = 5 * time.Second
+
+	// maxHashSize is the largest file whose content is hashed to tell
+	// modifications from timestamp updates
+	maxHashSize = 1 << 20
+)
+
+var hashTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Options configures how a FileWatcher detects changes
+type Options struct {
+	// Backend is BackendAuto, BackendFSNotify or BackendPoll. Empty selects
+	// BackendAuto.
+	Backend string
+	// PollInterval is how often the polling backend checks for changes. It
+	// doubles while nothing changes, up to MaxPollInterval.
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration
+}
+
+// backend reports the changes to the entries of watched directories as
+// fsnotify events, so every backend feeds the same event handling
+type backend interface {
+	// Add watches the entries of a directory, not of its subdirectories
+	Add(path string) error
+	Events() <-chan fsnotify.Event
+	Errors() <-chan error
+	Close() error
+	Name() string
+}
+
+// newBackend creates the backend selected by the options
+func newBackend(opts Options) (backend, error) {
+	switch opts.Backend {
+	case BackendPoll:
+		return newPollingBackend(opts), nil
+	case BackendFSNotify:
+		return newFSNotifyBackend()
+	case "", BackendAuto:
+		b, err := newFSNotifyBackend()
+		if err != nil && isWatchLimit(err) {
+			return newPollingBackend(opts), nil
+		}
+		return b, err
+	default:
+		return nil, errors.New("unknown file watcher backend: " + opts.Backend)
+	}
+}
+
+// isWatchLimit reports whether an error is caused by reaching the limit on
+// inotify instances or watches
+func isWatchLimit(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE)
+}
+
+// fsnotifyBackend detects changes through the operating system's file
+// notifications
+type fsnotifyBackend struct {
+	watcher *fsnotify.Watcher
+}
+
+func newFSNotifyBackend() (*fsnotifyBackend, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &fsnotifyBackend{watcher: watcher}, nil
+}
+
+func (b *fsnotifyBackend) Add(path string) error         { return b.watcher.Add(path) }
+func (b *fsnotifyBackend) Events() <-chan fsnotify.Event { return b.watcher.Events }
+func (b *fsnotifyBackend) Errors() <-chan error          { return b.watcher.Errors }
+func (b *fsnotifyBackend) Close() error                  { return b.watcher.Close() }
+func (b *fsnotifyBackend) Name() string                  { return BackendFSNotify }
+
+// fileState is what the polling backend knows of a directory entry
+type fileState struct {
+	modTime time.Time
+	size    int64
+	isDir   bool
+	hash    uint32
+	hashed  bool
+}
+
+// pollingBackend detects changes by comparing the entries of the watched
+// directories with their last snapshot. Polling slows down while the tree is
+// idle and speeds up again as soon as something changes.
+type pollingBackend struct {
+	interval    time.Duration
+	maxInterval time.Duration
+	events      chan fsnotify.Event
+	errors      chan error
+	done        chan struct{}
+	closeOnce   sync.Once
+
+	mutex sync.Mutex
+	dirs  map[string]map[string]fileState // snapshots by directory
+}
+
+func newPollingBackend(opts Options) *pollingBackend {
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	maxInterval := max(opts.MaxPollInterval, interval)
+	if opts.MaxPollInterval <= 0 {
+		maxInterval = max(DefaultMaxPollInterval, interval)
+	}
+
+	p := &pollingBackend{
+		interval:    interval,
+		maxInterval: maxInterval,
+		events:      make(chan fsnotify.Event, 100),
+		errors:      make(chan error, 1),
+		done:        make(chan struct{}),
+		dirs:        make(map[string]map[string]fileState),
+	}
+	go p.run()
+	return p
+}
+
+// Add snapshots a directory, reporting later changes to its entries
+func (p *pollingBackend) Add(path string) error {
+	entries, err := readSnapshot(path)
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, ok := p.dirs[path]; !ok {
+		p.dirs[path] = entries
+	}
+	return nil
+}
+
+func (p *pollingBackend) Events() <-chan fsnotify.Event { return p.events }
+func (p *pollingBackend) Errors() <-chan error          { return p.errors }
+func (p *pollingBackend) Name() string                  { return BackendPoll }
+
+// Close stops polling
+func (p *pollingBackend) Close() error {
+	p.closeOnce.Do(func() { close(p.done) })
+	return nil
+}
+
+func (p *pollingBackend) run() {
+	interval := p.interval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-timer.C:
+		}
+
+		if p.poll() {
+			interval = p.interval
+		} else {
+			// Back off while the tree is idle
+			interval = min(interval*2, p.maxInterval)
+		}
+		timer.Reset(interval)
+	}
+}
+
+// poll compares each watched directory with its snapshot, reporting the
+// differences. It returns whether anything changed.
+func (p *pollingBackend) poll() bool {
+	p.mutex.Lock()
+	dirs := make(map[string]map[string]fileState, len(p.dirs))
+	for dir, entries := range p.dirs {
+		dirs[dir] = entries
+	}
+	p.mutex.Unlock()
+
+	changed := false
+	for dir, previous := range dirs {
+		current, err := readSnapshot(dir)
+		if err != nil && !os.IsNotExist(err) {
+			p.sendError(err)
+			continue
+		}
+
+		// A removed directory's entries are reported as removed, and it is
+		// no longer watched
+		events := diffSnapshots(previous, current)
+
+		p.mutex.Lock()
+		if _, ok := p.dirs[dir]; ok {
+			if current == nil {
+				delete(p.dirs, dir)
+			} else {
+				p.dirs[dir] = current
+			}
+		}
+		p.mutex.Unlock()
+
+		for _, event := range events {
+			changed = true
+			select {
+			case p.events <- event:
+			case <-p.done:
+				return changed
+			}
+		}
+	}
+	return changed
+}
+
+func (p *pollingBackend) sendError(err error) {
+	select {
+	case p.errors <- err:
+	case <-p.done:
+	}
+}
+
+// diffSnapshots returns the events turning one snapshot of a directory into
+// another, carrying the content hashes over to the current snapshot. Files
+// whose modification time changed are only reported if their content did
+// too, so timestamp updates don't trigger rebuilds.
+func diffSnapshots(previous, current map[string]fileState) []fsnotify.Event {
+	var events []fsnotify.Event
+
+	for path, state := range current {
+		old, existed := previous[path]
+		switch {
+		case !existed:
+			events = append(events, fsnotify.Event{Name: path, Op: fsnotify.Create})
+			current[path] = hashState(path, state)
+		case old.isDir != state.isDir:
+			events = append(events,
+				fsnotify.Event{Name: path, Op: fsnotify.Remove},
+				fsnotify.Event{Name: path, Op: fsnotify.Create})
+			current[path] = hashState(path, state)
+		case state.isDir:
+		case old.size == state.size && old.modTime.Equal(state.modTime):
+			state.hash, state.hashed = old.hash, old.hashed
+			current[path] = state
+		default:
+			state = hashState(path, state)
+			current[path] = state
+			if old.size == state.size && old.hashed && state.hashed && old.hash == state.hash {
+				continue
+			}
+			events = append(events, fsnotify.Event{Name: path, Op: fsnotify.Write})
+		}
+	}
+
+	for path := range previous {
+		if _, exists := current[path]; !exists {
+			events = append(events, fsnotify.Event{Name: path, Op: fsnotify.Remove})
+		}
+	}
+	return events
+}
+
+// readSnapshot reads the state of a directory's entries
+func readSnapshot(dir string) (map[string]fileState, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := make(map[string]fileState, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			// Removed since the directory was read
+			continue
+		}
+		snapshot[filepath.Join(dir, entry.Name())] = fileState{
+			modTime: info.ModTime(),
+			size:    info.Size(),
+			isDir:   info.IsDir(),
+		}
+	}
+	return snapshot, nil
+}
+
+// hashState adds the hash of a file's content to its state, unless it is a
+// directory or too large to hash
+func hashState(path string, state fileState) fileState {
+	if state.isDir || state.size > maxHashSize {
+		return state
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return state
+	}
+	defer file.Close()
+
+	hash := crc32.New(hashTable)
+	if _, err := io.Copy(hash, io.LimitReader(file, maxHashSize+1)); err != nil {
+		return state
+	}
+	state.hash, state.hashed = hash.Sum32(), true
+	return state
+}
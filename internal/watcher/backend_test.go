@@ -0,0 +1,146 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/conneroisu/templar/internal/interfaces"
+)
+
+func TestNewBackend(t *testing.T) {
+	poller, err := newBackend(Options{Backend: BackendPoll})
+	require.NoError(t, err)
+	defer poller.Close()
+	assert.Equal(t, BackendPoll, poller.Name())
+	assert.Equal(t, DefaultPollInterval, poller.(*pollingBackend).interval)
+	assert.Equal(t, DefaultMaxPollInterval, poller.(*pollingBackend).maxInterval)
+
+	notifier, err := newBackend(Options{})
+	require.NoError(t, err)
+	defer notifier.Close()
+	assert.Equal(t, BackendFSNotify, notifier.Name())
+
+	_, err = newBackend(Options{Backend: "kqueue"})
+	assert.Error(t, err)
+
+	assert.True(t, isWatchLimit(os.NewSyscallError("inotify_add_watch", syscall.ENOSPC)))
+	assert.True(t, isWatchLimit(os.NewSyscallError("inotify_init1", syscall.EMFILE)))
+	assert.False(t, isWatchLimit(os.ErrNotExist))
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	card := filepath.Join(dir, "card.templ")
+	require.NoError(t, os.WriteFile(card, []byte("templ Card() {}"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "cards"), 0755))
+
+	snapshot := func() map[string]fileState {
+		current, err := readSnapshot(dir)
+		require.NoError(t, err)
+		return current
+	}
+
+	// New entries are created and hashed
+	previous := snapshot()
+	events := diffSnapshots(map[string]fileState{}, previous)
+	assert.ElementsMatch(t, []fsnotify.Event{
+		{Name: card, Op: fsnotify.Create},
+		{Name: filepath.Join(dir, "cards"), Op: fsnotify.Create},
+	}, events)
+	assert.True(t, previous[card].hashed)
+
+	// Unchanged entries keep their hash
+	current := snapshot()
+	assert.Empty(t, diffSnapshots(previous, current))
+	assert.Equal(t, previous[card].hash, current[card].hash)
+
+	// A timestamp update without a content change is not reported
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(card, later, later))
+	previous, current = current, snapshot()
+	assert.Empty(t, diffSnapshots(previous, current))
+
+	// Content changes are reported whether or not the size changed
+	require.NoError(t, os.WriteFile(card, []byte("templ Card() {!}"), 0644))
+	previous, current = current, snapshot()
+	assert.Equal(t, []fsnotify.Event{{Name: card, Op: fsnotify.Write}}, diffSnapshots(previous, current))
+
+	require.NoError(t, os.WriteFile(card, []byte("templ Card() {?}"), 0644))
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(card, later, later))
+	previous, current = current, snapshot()
+	assert.Equal(t, []fsnotify.Event{{Name: card, Op: fsnotify.Write}}, diffSnapshots(previous, current))
+
+	// Removed entries, and those of a removed directory, are reported
+	require.NoError(t, os.Remove(card))
+	previous, current = current, snapshot()
+	assert.Equal(t, []fsnotify.Event{{Name: card, Op: fsnotify.Remove}}, diffSnapshots(previous, current))
+	assert.Equal(t, []fsnotify.Event{{Name: filepath.Join(dir, "cards"), Op: fsnotify.Remove}}, diffSnapshots(current, nil))
+}
+
+func TestFileWatcherPolling(t *testing.T) {
+	watcher, err := NewFileWatcherWithOptions(20*time.Millisecond, Options{
+		Backend:         BackendPoll,
+		PollInterval:    10 * time.Millisecond,
+		MaxPollInterval: 40 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer watcher.Stop()
+	assert.Equal(t, BackendPoll, watcher.GetStats()["backend"])
+
+	// New directories are validated outside the test's goroutine, so the
+	// root lies within the working directory
+	root, err := os.MkdirTemp(".", "test_temp_polling")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	require.NoError(t, watcher.AddRecursive(root))
+	watcher.AddFilter(interfaces.FileFilterFunc(TemplFilter))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan ChangeEvent, 10)
+	watcher.AddHandler(func(events []ChangeEvent) error {
+		for _, event := range events {
+			changes <- event
+		}
+		return nil
+	})
+	require.NoError(t, watcher.Start(ctx))
+
+	expect := func(eventType EventType, path string) {
+		t.Helper()
+		select {
+		case event := <-changes:
+			assert.Equal(t, eventType, event.Type)
+			assert.Equal(t, path, event.Path)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s of %s was not reported", eventType, path)
+		}
+	}
+
+	card := filepath.Join(root, "card.templ")
+	require.NoError(t, os.WriteFile(card, []byte("templ Card() {}"), 0644))
+	expect(EventTypeCreated, card)
+
+	require.NoError(t, os.WriteFile(card, []byte("templ Card() { <div></div> }"), 0644))
+	expect(EventTypeModified, card)
+
+	// Files in directories created later are picked up
+	dir := filepath.Join(root, "cards")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	badge := filepath.Join(dir, "badge.templ")
+	require.NoError(t, os.WriteFile(badge, []byte("templ Badge() {}"), 0644))
+	expect(EventTypeCreated, badge)
+
+	require.NoError(t, os.Remove(card))
+	expect(EventTypeDeleted, card)
+}
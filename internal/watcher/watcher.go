@@ -10,17 +10,20 @@ package watcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/conneroisu/templar/internal/interfaces"
+	"github.com/conneroisu/templar/pkg/stories"
 )
 
 // Constants for memory management
@@ -54,14 +57,34 @@ var (
 
 // FileWatcher watches for file changes with intelligent debouncing
 type FileWatcher struct {
-	watcher   *fsnotify.Watcher
-	debouncer *Debouncer
-	filters   []interfaces.FileFilter
-	handlers  []interfaces.ChangeHandlerFunc
-	mutex     sync.RWMutex
-	stopped   bool
+	watcher        backend
+	options        Options
+	debouncer      *Debouncer
+	filters        []interfaces.FileFilter
+	handlers       []interfaces.ChangeHandlerFunc
+	rescanHandlers []RescanHandlerFunc
+	mutex          sync.RWMutex
+	stopped        bool
+
+	// Directories added to the backend, watched again when it is replaced,
+	// and a channel closed when it is
+	watched        map[string]bool
+	backendChanged chan struct{}
+
+	// Recursively watched roots, and those awaiting a rescan after events
+	// under them were lost
+	rescanMutex sync.Mutex
+	roots       []string
+	rescanRoots map[string]bool
+	rescanTimer *time.Timer
+	overflows   int64
+	rescans     int64
 }
 
+// RescanHandlerFunc handles the rescan of watched roots whose file events
+// were lost, reconciling their state with the disk
+type RescanHandlerFunc func(roots []string) error
+
 // Type aliases for convenience and backward compatibility
 type ChangeEvent = interfaces.ChangeEvent
 type EventType = interfaces.EventType
@@ -91,11 +114,19 @@ type Debouncer struct {
 	maxBatchSize  int
 	droppedEvents int64  // Counter for monitoring dropped events
 	totalEvents   int64  // Counter for total events processed
+	onDrop        func(events []ChangeEvent) // Called with the events dropped
 }
 
-// NewFileWatcher creates a new file watcher
+// NewFileWatcher creates a new file watcher, using fsnotify unless the
+// operating system's watch limits are reached
 func NewFileWatcher(debounceDelay time.Duration) (*FileWatcher, error) {
-	watcher, err := fsnotify.NewWatcher()
+	return NewFileWatcherWithOptions(debounceDelay, Options{})
+}
+
+// NewFileWatcherWithOptions creates a new file watcher detecting changes
+// with the backend selected by the options
+func NewFileWatcherWithOptions(debounceDelay time.Duration, opts Options) (*FileWatcher, error) {
+	watcher, err := newBackend(opts)
 	if err != nil {
 		return nil, err
 	}
@@ -110,10 +141,19 @@ func NewFileWatcher(debounceDelay time.Duration) (*FileWatcher, error) {
 	}
 
 	fw := &FileWatcher{
-		watcher:   watcher,
-		debouncer: debouncer,
-		filters:   make([]interfaces.FileFilter, 0),
-		handlers:  make([]interfaces.ChangeHandlerFunc, 0),
+		watcher:        watcher,
+		options:        opts,
+		debouncer:      debouncer,
+		filters:        make([]interfaces.FileFilter, 0),
+		handlers:       make([]interfaces.ChangeHandlerFunc, 0),
+		watched:        make(map[string]bool),
+		backendChanged: make(chan struct{}),
+		rescanRoots:    make(map[string]bool),
+	}
+	debouncer.onDrop = func(events []ChangeEvent) {
+		for _, event := range events {
+			fw.scheduleRescan(event.Path)
+		}
 	}
 
 	return fw, nil
@@ -133,6 +173,15 @@ func (fw *FileWatcher) AddHandler(handler interfaces.ChangeHandlerFunc) {
 	fw.handlers = append(fw.handlers, handler)
 }
 
+// AddRescanHandler adds a handler called with the watched roots to rescan
+// when their file events were lost, either dropped under backpressure or
+// overflowing the operating system's event queue
+func (fw *FileWatcher) AddRescanHandler(handler RescanHandlerFunc) {
+	fw.mutex.Lock()
+	defer fw.mutex.Unlock()
+	fw.rescanHandlers = append(fw.rescanHandlers, handler)
+}
+
 // AddPath adds a path to watch
 func (fw *FileWatcher) AddPath(path string) error {
 	// Validate and clean the path
@@ -140,10 +189,50 @@ func (fw *FileWatcher) AddPath(path string) error {
 	if err != nil {
 		return fmt.Errorf("invalid path: %w", err)
 	}
-	return fw.watcher.Add(cleanPath)
+	return fw.add(cleanPath)
 }
 
-// AddRecursive adds a directory and all subdirectories to watch
+// add watches a directory through the backend. In auto mode, reaching the
+// fsnotify watch limits switches the watcher over to polling.
+func (fw *FileWatcher) add(dir string) error {
+	fw.mutex.Lock()
+	defer fw.mutex.Unlock()
+
+	err := fw.watcher.Add(dir)
+	if err != nil && isWatchLimit(err) && fw.watcher.Name() == BackendFSNotify &&
+		(fw.options.Backend == "" || fw.options.Backend == BackendAuto) {
+		log.Printf("File watch limit reached (%v), falling back to polling", err)
+		if err = fw.switchToPollingLocked(); err == nil {
+			err = fw.watcher.Add(dir)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	fw.watched[dir] = true
+	return nil
+}
+
+// switchToPollingLocked replaces the backend with a polling one watching the
+// same directories. The caller must hold fw.mutex.
+func (fw *FileWatcher) switchToPollingLocked() error {
+	poller := newPollingBackend(fw.options)
+	for dir := range fw.watched {
+		if err := poller.Add(dir); err != nil && !os.IsNotExist(err) {
+			poller.Close()
+			return err
+		}
+	}
+
+	previous := fw.watcher
+	fw.watcher = poller
+	close(fw.backendChanged)
+	fw.backendChanged = make(chan struct{})
+	return previous.Close()
+}
+
+// AddRecursive adds a directory and all subdirectories to watch.
+// Directories created under it later are watched as they appear.
 func (fw *FileWatcher) AddRecursive(root string) error {
 	// Validate and clean the root path
 	cleanRoot, err := fw.validatePath(root)
@@ -151,7 +240,20 @@ func (fw *FileWatcher) AddRecursive(root string) error {
 		return fmt.Errorf("invalid root path: %w", err)
 	}
 
-	return filepath.Walk(cleanRoot, func(path string, info os.FileInfo, err error) error {
+	if err := fw.addTree(cleanRoot, nil); err != nil {
+		return err
+	}
+
+	fw.rescanMutex.Lock()
+	fw.roots = append(fw.roots, cleanRoot)
+	fw.rescanMutex.Unlock()
+	return nil
+}
+
+// addTree watches a directory and its subdirectories, calling onFile with
+// the files found in them
+func (fw *FileWatcher) addTree(dir string, onFile func(path string, info os.FileInfo)) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -163,13 +265,38 @@ func (fw *FileWatcher) AddRecursive(root string) error {
 				log.Printf("Skipping invalid directory path: %s", path)
 				return nil
 			}
-			return fw.watcher.Add(cleanPath)
+			return fw.add(cleanPath)
 		}
 
+		if onFile != nil {
+			onFile(path, info)
+		}
 		return nil
 	})
 }
 
+// watchNewDirectory watches a directory created under a watched root. Files
+// can be written to it before its watch is added, so the files it already
+// contains are reported as created.
+func (fw *FileWatcher) watchNewDirectory(dir string) {
+	err := fw.addTree(dir, func(path string, info os.FileInfo) {
+		if !fw.includes(path) {
+			return
+		}
+		fw.queueEvent(ChangeEvent{
+			Type:    EventTypeCreated,
+			Path:    path,
+			ModTime: info.ModTime(),
+			Size:    info.Size(),
+		})
+	})
+	if err != nil && !os.IsNotExist(err) {
+		// Changes made under the directory may have been missed
+		log.Printf("Failed to watch new directory %s: %v", dir, err)
+		fw.scheduleRescan(dir)
+	}
+}
+
 // isInTestMode detects if we're running in test mode by checking the call stack
 func isInTestMode() bool {
 	// Get the call stack
@@ -278,18 +405,49 @@ func (fw *FileWatcher) Stop() error {
 	// Clear pending events to release memory
 	fw.debouncer.pending = nil
 
+	fw.rescanMutex.Lock()
+	if fw.rescanTimer != nil {
+		fw.rescanTimer.Stop()
+		fw.rescanTimer = nil
+	}
+	fw.rescanMutex.Unlock()
+
 	// Close the file system watcher (this will close its internal channels)
 	return fw.watcher.Close()
 }
 
 func (fw *FileWatcher) watchLoop(ctx context.Context) {
 	for {
+		fw.mutex.RLock()
+		backend, changed := fw.watcher, fw.backendChanged
+		fw.mutex.RUnlock()
+
 		select {
 		case <-ctx.Done():
 			return
-		case event := <-fw.watcher.Events:
+		case <-changed:
+			// Read from the new backend
+		case event, ok := <-backend.Events():
+			if !ok {
+				if fw.closed(backend) {
+					return
+				}
+				continue
+			}
 			fw.handleFsnotifyEvent(event)
-		case err := <-fw.watcher.Errors:
+		case err, ok := <-backend.Errors():
+			if !ok {
+				if fw.closed(backend) {
+					return
+				}
+				continue
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				// The kernel dropped events, which may concern any root
+				log.Printf("File watcher event queue overflowed, rescanning watched directories")
+				fw.scheduleRescan("")
+				continue
+			}
 			// Only log actual errors, ignore nil errors
 			if err != nil {
 				log.Printf("File watcher error: %v", err)
@@ -298,18 +456,27 @@ func (fw *FileWatcher) watchLoop(ctx context.Context) {
 	}
 }
 
-func (fw *FileWatcher) handleFsnotifyEvent(event fsnotify.Event) {
-	// Apply filters
+// closed reports whether a backend whose channels were closed is still in
+// use, meaning the watcher was stopped rather than switched to polling
+func (fw *FileWatcher) closed(backend backend) bool {
 	fw.mutex.RLock()
-	filters := fw.filters
-	fw.mutex.RUnlock()
+	defer fw.mutex.RUnlock()
+	return fw.watcher == backend
+}
 
-	for _, filter := range filters {
-		if !filter.ShouldInclude(event.Name) {
+func (fw *FileWatcher) handleFsnotifyEvent(event fsnotify.Event) {
+	// New directories are watched rather than reported
+	if event.Op&fsnotify.Create == fsnotify.Create {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			fw.watchNewDirectory(event.Name)
 			return
 		}
 	}
 
+	if !fw.includes(event.Name) {
+		return
+	}
+
 	// Get file info
 	info, err := os.Stat(event.Name)
 	var modTime time.Time
@@ -335,11 +502,32 @@ func (fw *FileWatcher) handleFsnotifyEvent(event fsnotify.Event) {
 		eventType = EventTypeModified
 	}
 
-	changeEvent := ChangeEvent{
+	fw.queueEvent(ChangeEvent{
 		Type:    eventType,
 		Path:    event.Name,
 		ModTime: modTime,
 		Size:    size,
+	})
+}
+
+// includes reports whether a path matches all filters
+func (fw *FileWatcher) includes(path string) bool {
+	fw.mutex.RLock()
+	filters := fw.filters
+	fw.mutex.RUnlock()
+
+	for _, filter := range filters {
+		if !filter.ShouldInclude(path) {
+			return false
+		}
+	}
+	return true
+}
+
+// queueEvent passes an event on to the debouncer
+func (fw *FileWatcher) queueEvent(changeEvent ChangeEvent) {
+	if changeEvent.DetectedAt.IsZero() {
+		changeEvent.DetectedAt = time.Now()
 	}
 
 	// Send to debouncer with backpressure handling
@@ -348,13 +536,85 @@ func (fw *FileWatcher) handleFsnotifyEvent(event fsnotify.Event) {
 		// Event sent successfully
 		fw.debouncer.totalEvents++
 	default:
-		// Channel full - implement backpressure by dropping events
+		// Channel full - implement backpressure by dropping events, and
+		// rescan to pick up the change later
 		fw.debouncer.droppedEvents++
 		log.Printf("Warning: Dropping file event for %s due to backpressure (dropped: %d, total: %d)", 
-			event.Name, fw.debouncer.droppedEvents, fw.debouncer.totalEvents)
+			changeEvent.Path, fw.debouncer.droppedEvents, fw.debouncer.totalEvents)
+		fw.scheduleRescan(changeEvent.Path)
 	}
 }
 
+// scheduleRescan records that events under the root containing path were
+// lost, or under every root for an empty path. The roots are rescanned once
+// the events settle.
+func (fw *FileWatcher) scheduleRescan(path string) {
+	fw.rescanMutex.Lock()
+	defer fw.rescanMutex.Unlock()
+
+	affected := false
+	for _, root := range fw.roots {
+		if path == "" || isWithin(root, path) {
+			fw.rescanRoots[root] = true
+			affected = true
+		}
+	}
+	if !affected {
+		return
+	}
+
+	fw.overflows++
+	if fw.rescanTimer == nil {
+		fw.rescanTimer = time.AfterFunc(fw.debouncer.delay, fw.rescan)
+	}
+}
+
+// rescan calls the rescan handlers with the roots awaiting a rescan
+func (fw *FileWatcher) rescan() {
+	fw.rescanMutex.Lock()
+	roots := make([]string, 0, len(fw.rescanRoots))
+	for root := range fw.rescanRoots {
+		roots = append(roots, root)
+	}
+	fw.rescanRoots = make(map[string]bool)
+	fw.rescanTimer = nil
+	fw.rescans++
+	fw.rescanMutex.Unlock()
+
+	fw.mutex.RLock()
+	handlers := fw.rescanHandlers
+	stopped := fw.stopped
+	fw.mutex.RUnlock()
+
+	if stopped {
+		return
+	}
+	sort.Strings(roots)
+	for _, handler := range handlers {
+		if err := handler(roots); err != nil {
+			log.Printf("File watcher rescan handler error: %v", err)
+		}
+	}
+}
+
+// isWithin reports whether path is root or lies under it
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		// Relative roots are matched against absolute event paths
+		absRoot, rootErr := filepath.Abs(root)
+		absPath, pathErr := filepath.Abs(path)
+		if rootErr != nil || pathErr != nil {
+			return false
+		}
+		rel, err = filepath.Rel(absRoot, absPath)
+		if err != nil {
+			return false
+		}
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 func (fw *FileWatcher) processEvents(ctx context.Context) {
 	for {
 		select {
@@ -395,6 +655,9 @@ func (d *Debouncer) addEvent(event ChangeEvent) {
 	if len(d.pending) >= MaxPendingEvents {
 		// Implement LRU eviction by removing oldest events
 		evictCount := MaxPendingEvents / 4 // Remove 25% of events for better efficiency
+		if d.onDrop != nil {
+			d.onDrop(d.pending[:evictCount])
+		}
 		copy(d.pending, d.pending[evictCount:])
 		d.pending = d.pending[:len(d.pending)-evictCount]
 		d.droppedEvents += int64(evictCount)
@@ -447,8 +710,13 @@ func (d *Debouncer) flushLocked() {
 	}
 	events = events[:0]
 
-	// Deduplicate events by path (keep latest event for each path)
+	// Deduplicate events by path (keep latest event for each path, but
+	// when the path first changed)
 	for _, event := range d.pending {
+		if previous, exists := eventMap[event.Path]; exists && !previous.DetectedAt.IsZero() &&
+			(event.DetectedAt.IsZero() || previous.DetectedAt.Before(event.DetectedAt)) {
+			event.DetectedAt = previous.DetectedAt
+		}
 		eventMap[event.Path] = event
 	}
 
@@ -477,6 +745,9 @@ func (d *Debouncer) flushLocked() {
 		// Channel full - implement backpressure by dropping entire batch
 		d.droppedEvents += int64(len(eventsCopy))
 		log.Printf("Warning: Dropping event batch of %d events due to output channel backpressure", len(eventsCopy))
+		if d.onDrop != nil {
+			d.onDrop(eventsCopy)
+		}
 	}
 
 	// Clear pending events - reuse underlying array if capacity is reasonable  
@@ -515,6 +786,29 @@ func GoFilter(path string) bool {
 	return filepath.Ext(path) == ".go"
 }
 
+// CSSFilter matches stylesheets
+func CSSFilter(path string) bool {
+	return filepath.Ext(path) == ".css"
+}
+
+// StoryFilter matches component story files
+func StoryFilter(path string) bool {
+	return stories.IsStoryFile(path)
+}
+
+// AnyFilter includes paths matched by any of the given filters. Filters added
+// to a watcher must all match, so alternatives are combined with AnyFilter.
+func AnyFilter(filters ...interfaces.FileFilterFunc) interfaces.FileFilterFunc {
+	return func(path string) bool {
+		for _, filter := range filters {
+			if filter(path) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 func NoTestFilter(path string) bool {
 	base := filepath.Base(path)
 	matched1, _ := filepath.Match("*_test.go", base)
@@ -532,10 +826,21 @@ func NoGitFilter(path string) bool {
 
 // GetStats returns current file watcher statistics for monitoring
 func (fw *FileWatcher) GetStats() map[string]interface{} {
+	fw.mutex.RLock()
+	backend := fw.watcher.Name()
+	fw.mutex.RUnlock()
+
+	fw.rescanMutex.Lock()
+	overflows, rescans := fw.overflows, fw.rescans
+	fw.rescanMutex.Unlock()
+
 	fw.debouncer.mutex.Lock()
 	defer fw.debouncer.mutex.Unlock()
 	
 	return map[string]interface{}{
+		"backend":           backend,
+		"overflows":         overflows,
+		"rescans":           rescans,
 		"pending_events":    len(fw.debouncer.pending),
 		"dropped_events":    fw.debouncer.droppedEvents,
 		"total_events":      fw.debouncer.totalEvents,
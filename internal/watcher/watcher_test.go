@@ -197,6 +197,26 @@ func TestGoFilter(t *testing.T) {
 	}
 }
 
+func TestCSSFilter(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"style.css", true},
+		{"static/css/app.css", true},
+		{"style.css.map", false},
+		{"component.templ", false},
+		{"main.go", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			result := CSSFilter(tc.path)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
 func TestNoTestFilter(t *testing.T) {
 	testCases := []struct {
 		path     string
@@ -306,6 +326,26 @@ func TestDebouncer(t *testing.T) {
 	}
 }
 
+func TestDebouncerKeepsFirstDetection(t *testing.T) {
+	debouncer := &Debouncer{
+		output: make(chan []ChangeEvent, 1),
+	}
+
+	first := time.Now().Add(-time.Second)
+	debouncer.pending = []ChangeEvent{
+		{Path: "card.templ", Type: EventTypeModified, Size: 1, DetectedAt: first},
+		{Path: "card.templ", Type: EventTypeModified, Size: 2, DetectedAt: first.Add(500 * time.Millisecond)},
+	}
+	debouncer.flushLocked()
+
+	events := <-debouncer.output
+	require.Len(t, events, 1)
+
+	// The latest event is kept, but the change dates from the first one
+	assert.Equal(t, int64(2), events[0].Size)
+	assert.Equal(t, first, events[0].DetectedAt)
+}
+
 func TestChangeEvent(t *testing.T) {
 	now := time.Now()
 	event := ChangeEvent{
@@ -426,3 +466,102 @@ func TestAddRecursive(t *testing.T) {
 	err = watcher.AddRecursive("../../../etc")
 	assert.Error(t, err)
 }
+
+func TestFileWatcherWatchesNewDirectories(t *testing.T) {
+	watcher, err := NewFileWatcher(50 * time.Millisecond)
+	require.NoError(t, err)
+	defer watcher.Stop()
+
+	// New directories are validated outside the test's goroutine, so the
+	// root lies within the working directory
+	root, err := os.MkdirTemp(".", "test_temp_new_dirs")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	require.NoError(t, watcher.AddRecursive(root))
+	watcher.AddFilter(interfaces.FileFilterFunc(TemplFilter))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	paths := make(chan string, 10)
+	watcher.AddHandler(func(events []ChangeEvent) error {
+		for _, event := range events {
+			paths <- event.Path
+		}
+		return nil
+	})
+	require.NoError(t, watcher.Start(ctx))
+
+	// A component folder created at runtime, with a file already inside
+	dir := filepath.Join(root, "components", "cards")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "card.templ"), []byte("package cards"), 0644))
+
+	select {
+	case path := <-paths:
+		assert.Equal(t, filepath.Join(dir, "card.templ"), path)
+	case <-time.After(2 * time.Second):
+		t.Fatal("file in new directory was not reported")
+	}
+
+	// Later changes in the new directory are reported too
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "badge.templ"), []byte("package cards"), 0644))
+	for {
+		select {
+		case path := <-paths:
+			if path == filepath.Join(dir, "badge.templ") {
+				return
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("change in new directory was not reported")
+		}
+	}
+}
+
+func TestFileWatcherRescansAfterLostEvents(t *testing.T) {
+	watcher, err := NewFileWatcher(10 * time.Millisecond)
+	require.NoError(t, err)
+	defer watcher.Stop()
+
+	first, second := t.TempDir(), t.TempDir()
+	require.NoError(t, watcher.AddRecursive(first))
+	require.NoError(t, watcher.AddRecursive(second))
+
+	rescans := make(chan []string, 10)
+	watcher.AddRescanHandler(func(roots []string) error {
+		rescans <- roots
+		return nil
+	})
+
+	// Events dropped under backpressure rescan their root once
+	for i := 0; i < cap(watcher.debouncer.events)+5; i++ {
+		watcher.queueEvent(ChangeEvent{Type: EventTypeModified, Path: filepath.Join(first, fmt.Sprintf("c%d.templ", i))})
+	}
+	select {
+	case roots := <-rescans:
+		assert.Equal(t, []string{filepath.Clean(first)}, roots)
+	case <-time.After(time.Second):
+		t.Fatal("dropped events did not trigger a rescan")
+	}
+
+	// An overflowing event queue rescans every root
+	watcher.scheduleRescan("")
+	select {
+	case roots := <-rescans:
+		assert.ElementsMatch(t, []string{filepath.Clean(first), filepath.Clean(second)}, roots)
+	case <-time.After(time.Second):
+		t.Fatal("overflow did not trigger a rescan")
+	}
+
+	stats := watcher.GetStats()
+	assert.Equal(t, int64(6), stats["overflows"])
+	assert.Equal(t, int64(2), stats["rescans"])
+
+	// Paths outside the roots are not rescanned
+	watcher.scheduleRescan(filepath.Join(filepath.Dir(first), "elsewhere", "x.templ"))
+	select {
+	case roots := <-rescans:
+		t.Fatalf("unexpected rescan of %v", roots)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
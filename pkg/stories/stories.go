@@ -0,0 +1,155 @@
+// Package stories declares named variants ("stories") of templ components.
+//
+// Stories live next to the component they describe, either in a YAML or JSON
+// sidecar file or in a Go file registering them:
+//
+//	components/button.templ
+//	components/button.stories.yaml   (or .stories.yml / .stories.json)
+//	components/button_stories.go
+//
+// A sidecar file lists the stories of one component:
+//
+//	component: Button
+//	stories:
+//	  - name: Primary
+//	    props: {text: Save, variant: primary}
+//	  - name: Mobile
+//	    props: {text: Save}
+//	    viewport: {width: 375, height: 667}
+//	    background: "#1f2937"
+//	    notes: Buttons stretch to the full width on small screens.
+//
+// The component key may be omitted when the templ file declares a single
+// component, and a "components" map may be used to describe several
+// components from the same file.
+//
+// Go story files call Register with literal values:
+//
+//	var _ = stories.Register("Button",
+//		stories.Story{Name: "Primary", Props: stories.Props{"text": "Save"}},
+//	)
+//
+// Templar reads these declarations statically, so props must be literals.
+// The same stories are enumerated by the preview UI, the playground, the
+// static site generator and the visual regression tester.
+package stories
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Props holds the values passed to the component's parameters, keyed by
+// parameter name
+type Props map[string]interface{}
+
+// Viewport is the frame size a story is previewed at, in CSS pixels
+type Viewport struct {
+	Width  int `json:"width,omitempty" yaml:"width,omitempty"`
+	Height int `json:"height,omitempty" yaml:"height,omitempty"`
+}
+
+// Story is a named variant of a component
+type Story struct {
+	// Name identifies the story within its component
+	Name string `json:"name" yaml:"name"`
+	// Description summarises what the story demonstrates
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Props are the parameter values the component is rendered with
+	Props Props `json:"props,omitempty" yaml:"props,omitempty"`
+	// Viewport is the frame size the story is previewed at
+	Viewport Viewport `json:"viewport,omitempty" yaml:"viewport,omitempty"`
+	// Wrapper names a layout component the story is rendered inside
+	Wrapper string `json:"wrapper,omitempty" yaml:"wrapper,omitempty"`
+	// Background is the CSS background shown behind the story
+	Background string `json:"background,omitempty" yaml:"background,omitempty"`
+	// Notes contains free-form documentation shown alongside the story
+	Notes string `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+// File is the layout of a YAML or JSON story sidecar file
+type File struct {
+	// Component names the component Stories belong to
+	Component string `json:"component,omitempty" yaml:"component,omitempty"`
+	// Stories lists the stories of Component
+	Stories []Story `json:"stories,omitempty" yaml:"stories,omitempty"`
+	// Components maps component names to their stories
+	Components map[string][]Story `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+// sidecarSuffixes are the file name suffixes of story files, replacing the
+// ".templ" extension of the component file they belong to
+var sidecarSuffixes = []string{".stories.yaml", ".stories.yml", ".stories.json", "_stories.go"}
+
+// IsStoryFile reports whether path names a story file
+func IsStoryFile(path string) bool {
+	return storySuffix(path) != ""
+}
+
+// TemplFile returns the templ file a story file belongs to, or "" when path
+// is not a story file
+func TemplFile(path string) string {
+	suffix := storySuffix(path)
+	if suffix == "" {
+		return ""
+	}
+	return strings.TrimSuffix(path, suffix) + ".templ"
+}
+
+// SidecarFiles returns the story file paths that may accompany templPath
+func SidecarFiles(templPath string) []string {
+	base := strings.TrimSuffix(templPath, filepath.Ext(templPath))
+	paths := make([]string, len(sidecarSuffixes))
+	for i, suffix := range sidecarSuffixes {
+		paths[i] = base + suffix
+	}
+	return paths
+}
+
+func storySuffix(path string) string {
+	name := filepath.Base(path)
+	for _, suffix := range sidecarSuffixes {
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			return suffix
+		}
+	}
+	return ""
+}
+
+var (
+	registeredMu sync.RWMutex
+	registered   = make(map[string][]Story)
+)
+
+// Register records stories for the named component. It returns true so it
+// can be used in package-level variable declarations.
+func Register(component string, stories ...Story) bool {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+
+	registered[component] = append(registered[component], stories...)
+	return true
+}
+
+// For returns the stories registered for the named component
+func For(component string) []Story {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+
+	return append([]Story(nil), registered[component]...)
+}
+
+// Components returns the names of components with registered stories
+func Components() []string {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+
+	names := make([]string, 0, len(registered))
+	for name := range registered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,48 @@
+package stories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoryFilePaths(t *testing.T) {
+	tests := []struct {
+		path  string
+		templ string
+	}{
+		{"components/button.stories.yaml", "components/button.templ"},
+		{"components/button.stories.yml", "components/button.templ"},
+		{"components/button.stories.json", "components/button.templ"},
+		{"components/button_stories.go", "components/button.templ"},
+		{"components/button.templ", ""},
+		{"components/button_templ.go", ""},
+		{"components/.stories.yaml", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.templ != "", IsStoryFile(tt.path))
+			assert.Equal(t, tt.templ, TemplFile(tt.path))
+		})
+	}
+
+	assert.Equal(t, []string{
+		"ui/card.stories.yaml",
+		"ui/card.stories.yml",
+		"ui/card.stories.json",
+		"ui/card_stories.go",
+	}, SidecarFiles("ui/card.templ"))
+}
+
+func TestRegister(t *testing.T) {
+	assert.True(t, Register("RegisterTestButton", Story{Name: "Primary", Props: Props{"text": "Save"}}))
+	Register("RegisterTestButton", Story{Name: "Danger"})
+
+	registered := For("RegisterTestButton")
+	assert.Len(t, registered, 2)
+	assert.Equal(t, "Primary", registered[0].Name)
+	assert.Equal(t, "Danger", registered[1].Name)
+	assert.Contains(t, Components(), "RegisterTestButton")
+	assert.Empty(t, For("RegisterTestMissing"))
+}